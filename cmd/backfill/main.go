@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"divminder-crawler/internal/api"
+	"divminder-crawler/internal/cache"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/httpx"
+	"divminder-crawler/internal/models"
+	"divminder-crawler/internal/overrides"
+	"divminder-crawler/internal/proxy"
+	"divminder-crawler/internal/scraper"
+	"divminder-crawler/pkg/crawler"
+
+	"github.com/joho/godotenv"
+)
+
+const (
+	fmpDailyLimit = 250             // FMP free tier calls per day
+	callInterval  = 2 * time.Second // Delay between FMP API calls
+)
+
+// historyClient is the part of api.FMPClient used by the backfill
+type historyClient interface {
+	HasCachedDividendHistory(symbol string, years int) bool
+	GetDividendHistory(symbol string, years int) ([]models.DividendEvent, error)
+	EnrichWithGroupInfo(events []models.DividendEvent, etfMap map[string]models.ETF) []models.DividendEvent
+	GetHistoricalPrices(symbol string, fromDate, toDate time.Time) ([]models.PricePoint, error)
+}
+
+// backfillResult counts what a backfill run did
+type backfillResult struct {
+	saved    int
+	cached   int
+	failed   []string
+	deferred []string // Not fetched because the call budget ran out
+}
+
+func main() {
+	years := flag.Int("years", 5, "Years of dividend history to fetch per symbol")
+	outputDir := flag.String("out", "docs", "Directory to write dividends_{SYMBOL}.json files to")
+	maxCalls := flag.Int("max-calls", fmpDailyLimit, "Maximum FMP API calls this run; cached symbols don't count")
+	maxEvents := flag.Int("max-events", 0, "Keep only the N most recent events per symbol in saved files; stats still cover every event (0 keeps all)")
+	overridesPath := flag.String("overrides", overrides.DefaultFile, "Per-symbol name/group/frequency/description overrides applied over the defaults")
+	redisURL := flag.String("redis-url", "", "Share the API cache through Redis (redis://[:password@]host:port[/db]); requires a build with -tags redis")
+	proxyURL := flag.String("proxy", "", "Route scraper and API requests through this http(s) or socks5 proxy URL; defaults to HTTP_PROXY/HTTPS_PROXY")
+	compact := flag.Bool("compact", false, "Write JSON output without indentation to shrink published files")
+	pretty := flag.Bool("pretty", false, "Write indented JSON output (the default); overrides -compact")
+	apiRetries := flag.Int("api-retries", httpx.DefaultRetryPolicy().MaxRetries, "Retries for API requests that fail with a 5xx status or a transient network error (0 disables)")
+	requestTimeout := flag.Duration("request-timeout", 0, "Give up on an API call, retries included, after this long so a slow symbol doesn't stall the batch (0 uses only the 30s per-attempt timeout)")
+	cacheTTL := flag.Duration("cache-ttl", 7*24*time.Hour, "How long fetched histories stay cached so later runs resume instead of refetching")
+	yieldSeries := flag.Bool("yield-series", false, "Also fetch daily prices and set each event's yield from the close on its ex-date; costs one more API call per symbol")
+	flag.Parse()
+
+	if *years < 1 {
+		log.Fatalf("Invalid -years %d: must be at least 1", *years)
+	}
+	if *maxEvents < 0 {
+		log.Fatalf("Invalid -max-events %d: must not be negative", *maxEvents)
+	}
+	if *apiRetries < 0 {
+		log.Fatalf("Invalid -api-retries %d: must not be negative", *apiRetries)
+	}
+	if *requestTimeout < 0 {
+		log.Fatalf("Invalid -request-timeout %s: must not be negative", *requestTimeout)
+	}
+	retryPolicy := httpx.DefaultRetryPolicy()
+	retryPolicy.MaxRetries = *apiRetries
+	if err := proxy.Configure(*proxyURL); err != nil {
+		log.Fatalf("Invalid -proxy: %v", err)
+	}
+	export.SetCompactJSON(*compact && !*pretty)
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("No .env file found, using environment variables")
+	}
+
+	apiKey := os.Getenv("FMP_API_KEY")
+	if apiKey == "" {
+		log.Fatal("FMP_API_KEY environment variable is required")
+	}
+
+	if err := export.EnsureOutputDir(*outputDir); err != nil {
+		log.Fatal(err)
+	}
+
+	client := api.NewFMPClient(apiKey).WithTTL(*cacheTTL, *cacheTTL).WithRetryPolicy(retryPolicy).WithRequestTimeout(*requestTimeout)
+	if *redisURL != "" {
+		redisCache, err := cache.NewRedisCache(*redisURL, "divminder", *cacheTTL)
+		if err != nil {
+			log.Fatalf("Invalid -redis-url: %v", err)
+		}
+		client.WithCache(redisCache)
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			log.Printf("Failed to close FMP client: %v", err)
+		}
+	}()
+	etfMap := buildETFMap(scraper.GetYieldMaxETFGroups())
+	symbolOverrides, err := overrides.Load(*overridesPath)
+	if err != nil {
+		log.Fatalf("Failed to load overrides: %v", err)
+	}
+	for symbol, etf := range etfMap {
+		if symbolOverrides.ApplyETF(&etf) {
+			etfMap[symbol] = etf
+		}
+	}
+
+	log.Printf("Backfilling %d years of dividend history for %d ETFs...", *years, len(etfMap))
+	b := &backfiller{
+		client:      client,
+		fallback:    []crawler.Source{{Name: crawler.SourceYieldMax, Provider: scraper.NewDividendTableScraper()}},
+		preferred:   symbolOverrides.PreferredSources(),
+		etfMap:      etfMap,
+		years:       *years,
+		maxCalls:    *maxCalls,
+		maxEvents:   *maxEvents,
+		yieldSeries: *yieldSeries,
+		outputDir:   *outputDir,
+		sleep:       time.Sleep,
+	}
+	result := b.run()
+
+	log.Println("\n=== Backfill Complete ===")
+	log.Printf("Saved: %d ETFs (%d from cache)", result.saved, result.cached)
+	if len(result.failed) > 0 {
+		log.Printf("Failed: %v", result.failed)
+	}
+	if len(result.deferred) > 0 {
+		log.Printf("Call budget reached; rerun to resume %d ETFs: %v", len(result.deferred), result.deferred)
+	}
+}
+
+// backfiller fetches and saves each symbol's history within an FMP call
+// budget. Histories come from a MultiProvider whose default order is FMP and
+// then the fallback sources, so a symbol preferring another source in the
+// overrides file tries that first.
+type backfiller struct {
+	client      historyClient
+	fallback    []crawler.Source // Tried after FMP, e.g. the YieldMax dividend tables
+	preferred   map[string]string
+	etfMap      map[string]models.ETF
+	years       int
+	maxCalls    int
+	maxEvents   int
+	yieldSeries bool
+	outputDir   string
+	sleep       func(time.Duration)
+
+	calls int // FMP API calls made so far
+}
+
+// spend takes one FMP call from the budget, pausing between calls. It
+// reports false once the budget is used up.
+func (b *backfiller) spend() bool {
+	if b.calls >= b.maxCalls {
+		return false
+	}
+	if b.calls > 0 {
+		b.sleep(callInterval)
+	}
+	b.calls++
+	return true
+}
+
+// ScrapeDividendHistoryContext makes the FMP client a history source that
+// spends the budget only on symbols it hasn't cached
+func (b *backfiller) ScrapeDividendHistoryContext(ctx context.Context, symbol string) (*models.DividendHistory, error) {
+	if !b.client.HasCachedDividendHistory(symbol, b.years) && !b.spend() {
+		return nil, api.ErrCallBudgetExhausted
+	}
+	events, err := b.client.GetDividendHistory(symbol, b.years)
+	if err != nil {
+		return nil, err
+	}
+	events = b.client.EnrichWithGroupInfo(events, b.etfMap)
+	return &models.DividendHistory{Symbol: symbol, Events: events}, nil
+}
+
+// run backfills every symbol in alphabetical order. Cached symbols are saved
+// without spending an API call, so a rerun resumes where the previous one ran
+// out of budget. With yieldSeries each history also spends a call on its
+// price series, when the budget allows.
+func (b *backfiller) run() backfillResult {
+	symbols := make([]string, 0, len(b.etfMap))
+	for symbol := range b.etfMap {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	provider := crawler.NewMultiProvider(append([]crawler.Source{{Name: crawler.SourceFMP, Provider: b}}, b.fallback...)...)
+	provider.SetPreferred(b.preferred)
+
+	var result backfillResult
+	for i, symbol := range symbols {
+		log.Printf("[%d/%d] Backfilling %s...", i+1, len(symbols), symbol)
+		cached := b.client.HasCachedDividendHistory(symbol, b.years)
+		fetched, err := provider.ScrapeDividendHistoryContext(context.Background(), symbol)
+		if errors.Is(err, api.ErrCallBudgetExhausted) {
+			result.deferred = append(result.deferred, symbol)
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to fetch history for %s: %v", symbol, err)
+			result.failed = append(result.failed, symbol)
+			continue
+		}
+
+		history := buildHistory(b.etfMap[symbol], fetched.Events)
+		if b.yieldSeries && len(history.Events) > 0 {
+			if !b.spend() {
+				log.Printf("Call budget reached; saving %s without yields", symbol)
+			} else {
+				applyYieldSeries(b.client, &history)
+			}
+		}
+		history.Target = history.TargetTrackingAt(0, time.Now())
+		history.TrimEvents(b.maxEvents)
+		filename := filepath.Join(b.outputDir, fmt.Sprintf("dividends_%s.json", symbol))
+		if _, err := export.SaveHistory(filename, &history); err != nil {
+			log.Printf("Failed to save %s: %v", symbol, err)
+			result.failed = append(result.failed, symbol)
+			continue
+		}
+
+		result.saved++
+		if cached {
+			result.cached++
+		}
+		log.Printf("Saved %s (%d events)", symbol, len(history.Events))
+	}
+
+	return result
+}
+
+// applyYieldSeries prices every event of history from the symbol's daily
+// closes, which lets Target12 funds be measured against the target. A failed
+// fetch leaves the yields unset.
+func applyYieldSeries(client historyClient, history *models.DividendHistory) {
+	// Events are sorted newest first; start early enough to price the oldest
+	oldest := history.Events[len(history.Events)-1].ExDate.Add(-models.MaxPriceLag)
+	newest := history.Events[0].ExDate
+	prices, err := client.GetHistoricalPrices(history.Symbol, oldest, newest)
+	if err != nil {
+		log.Printf("Failed to fetch prices for %s, saving without yields: %v", history.Symbol, err)
+		return
+	}
+	priced := history.ApplyYieldSeries(prices)
+	log.Printf("Priced %d/%d events for %s", priced, len(history.Events), history.Symbol)
+}
+
+// buildETFMap creates the symbol -> ETF map EnrichWithGroupInfo expects
+func buildETFMap(groups map[string]string) map[string]models.ETF {
+	etfMap := make(map[string]models.ETF, len(groups))
+	for symbol, group := range groups {
+		etfMap[symbol] = models.ETF{
+			Symbol:    symbol,
+			Group:     group,
+			Frequency: scraper.GroupFrequency(group),
+			Active:    true,
+		}
+	}
+	return etfMap
+}
+
+// buildHistory wraps fetched events in a history sorted newest first
+func buildHistory(etf models.ETF, events []models.DividendEvent) models.DividendHistory {
+	models.SortEventsDescending(events)
+
+	history := models.DividendHistory{
+		Symbol:    etf.Symbol,
+		Name:      etf.Name,
+		Group:     etf.Group,
+		Frequency: etf.Frequency,
+		Events:    events,
+		Stats:     models.CalculateStats(events),
+		UpdatedAt: time.Now(),
+	}
+	history.DetectFrequencyRegimes()
+	return history
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+	"divminder-crawler/pkg/crawler"
+)
+
+// stubFMP serves canned histories, remembering what it fetched as cached
+type stubFMP struct {
+	cached  map[string]bool
+	failing map[string]bool
+	fetched []string // Symbols that cost an API call
+	weeks   int      // Weekly events returned per symbol; 0 returns one
+}
+
+func (s *stubFMP) HasCachedDividendHistory(symbol string, years int) bool {
+	return s.cached[symbol]
+}
+
+func (s *stubFMP) GetDividendHistory(symbol string, years int) ([]models.DividendEvent, error) {
+	if !s.cached[symbol] {
+		s.fetched = append(s.fetched, symbol)
+	}
+	if s.failing[symbol] {
+		return nil, errors.New("HTTP 500")
+	}
+	s.cached[symbol] = true
+	last := time.Date(2024, 6, 5, 0, 0, 0, 0, models.MarketLocation)
+	events := make([]models.DividendEvent, max(s.weeks, 1))
+	for i := range events {
+		exDate := last.AddDate(0, 0, -7*i)
+		events[i] = models.DividendEvent{Symbol: symbol, ExDate: exDate, PayDate: exDate.AddDate(0, 0, 1), Amount: 0.5}
+	}
+	return events, nil
+}
+
+func (s *stubFMP) EnrichWithGroupInfo(events []models.DividendEvent, etfMap map[string]models.ETF) []models.DividendEvent {
+	for i := range events {
+		events[i].Group = etfMap[events[i].Symbol].Group
+	}
+	return events
+}
+
+func (s *stubFMP) GetHistoricalPrices(symbol string, fromDate, toDate time.Time) ([]models.PricePoint, error) {
+	return nil, errors.New("not stubbed")
+}
+
+// newTestBackfiller backfills etfMap from client into dir without pausing
+func newTestBackfiller(client historyClient, etfMap map[string]models.ETF, maxCalls, maxEvents int, dir string) *backfiller {
+	return &backfiller{
+		client:    client,
+		etfMap:    etfMap,
+		years:     5,
+		maxCalls:  maxCalls,
+		maxEvents: maxEvents,
+		outputDir: dir,
+		sleep:     func(time.Duration) {},
+	}
+}
+
+func TestBackfillResumesFromCache(t *testing.T) {
+	dir := t.TempDir()
+	etfMap := buildETFMap(map[string]string{"AAAA": "GroupA", "BBBB": "GroupB", "CCCC": "GroupC", "DDDD": "Weekly"})
+	client := &stubFMP{cached: map[string]bool{"AAAA": true}, failing: map[string]bool{}}
+
+	var sleeps []time.Duration
+	sleep := func(d time.Duration) { sleeps = append(sleeps, d) }
+
+	// Two calls cover BBBB and CCCC; AAAA is cached and free, DDDD waits
+	b := newTestBackfiller(client, etfMap, 2, 0, dir)
+	b.sleep = sleep
+	first := b.run()
+	if first.saved != 3 || first.cached != 1 || !reflect.DeepEqual(first.deferred, []string{"DDDD"}) {
+		t.Errorf("first run = %+v, want 3 saved (1 cached) and DDDD deferred", first)
+	}
+	if !reflect.DeepEqual(client.fetched, []string{"BBBB", "CCCC"}) {
+		t.Errorf("first run fetched %v, want [BBBB CCCC]", client.fetched)
+	}
+	if !reflect.DeepEqual(sleeps, []time.Duration{callInterval}) {
+		t.Errorf("slept %v, want one callInterval between the two calls", sleeps)
+	}
+
+	// The rerun serves the first three from the cache and spends its call on DDDD
+	client.fetched = nil
+	b = newTestBackfiller(client, etfMap, 1, 0, dir)
+	b.sleep = sleep
+	second := b.run()
+	if second.saved != 4 || second.cached != 3 || len(second.deferred) != 0 {
+		t.Errorf("second run = %+v, want 4 saved (3 cached), none deferred", second)
+	}
+	if !reflect.DeepEqual(client.fetched, []string{"DDDD"}) {
+		t.Errorf("second run fetched %v, want [DDDD]", client.fetched)
+	}
+
+	histories, err := export.LoadHistories(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) != len(etfMap) {
+		t.Fatalf("saved %d histories, want %d", len(histories), len(etfMap))
+	}
+	for _, history := range histories {
+		group := etfMap[history.Symbol].Group
+		if history.Group != group || len(history.Events) != 1 || history.Events[0].Group != group {
+			t.Errorf("%s saved as %+v, want group %s with one event", history.Symbol, history, group)
+		}
+	}
+}
+
+func TestBackfillRecordsFailures(t *testing.T) {
+	etfMap := buildETFMap(map[string]string{"GOOD": "GroupA", "BAD": "GroupB"})
+	client := &stubFMP{cached: map[string]bool{}, failing: map[string]bool{"BAD": true}}
+
+	result := newTestBackfiller(client, etfMap, 10, 0, t.TempDir()).run()
+	if result.saved != 1 || !reflect.DeepEqual(result.failed, []string{"BAD"}) {
+		t.Errorf("result = %+v, want GOOD saved and BAD failed", result)
+	}
+}
+
+func TestBackfillCapsSavedEvents(t *testing.T) {
+	dir := t.TempDir()
+	etfMap := buildETFMap(map[string]string{"WEEK": "Weekly"})
+	client := &stubFMP{cached: map[string]bool{}, failing: map[string]bool{}, weeks: 30}
+
+	if result := newTestBackfiller(client, etfMap, 10, 12, dir).run(); result.saved != 1 {
+		t.Fatalf("result = %+v, want WEEK saved", result)
+	}
+
+	histories, err := export.LoadHistories(dir)
+	if err != nil || len(histories) != 1 {
+		t.Fatalf("loaded %d histories (%v), want 1", len(histories), err)
+	}
+	history := histories[0]
+	if len(history.Events) != 12 {
+		t.Errorf("saved %d events, want 12", len(history.Events))
+	}
+	if history.Stats.TotalPayments != 30 {
+		t.Errorf("stats cover %d payments, want all 30", history.Stats.TotalPayments)
+	}
+	for i := 1; i < len(history.Events); i++ {
+		if !history.Events[i].ExDate.Before(history.Events[i-1].ExDate) {
+			t.Fatalf("saved events not newest first at %d", i)
+		}
+	}
+}
+
+// stubSource serves a one-event history for every symbol except those in failing
+type stubSource struct {
+	amount  float64
+	failing map[string]bool
+	calls   []string
+}
+
+func (s *stubSource) ScrapeDividendHistoryContext(ctx context.Context, symbol string) (*models.DividendHistory, error) {
+	s.calls = append(s.calls, symbol)
+	if s.failing[symbol] {
+		return nil, errors.New("no table")
+	}
+	exDate := time.Date(2024, 6, 5, 0, 0, 0, 0, models.MarketLocation)
+	return &models.DividendHistory{Symbol: symbol, Events: []models.DividendEvent{
+		{Symbol: symbol, ExDate: exDate, Amount: s.amount},
+	}}, nil
+}
+
+func TestBackfillSourceOrder(t *testing.T) {
+	dir := t.TempDir()
+	etfMap := buildETFMap(map[string]string{"CONY": "GroupC", "TSLY": "GroupA", "ULTY": "Weekly", "GONE": "GroupB"})
+	client := &stubFMP{cached: map[string]bool{}, failing: map[string]bool{"ULTY": true, "GONE": true}}
+	yieldMax := &stubSource{amount: 0.9, failing: map[string]bool{"GONE": true}}
+
+	b := newTestBackfiller(client, etfMap, 10, 0, dir)
+	b.fallback = []crawler.Source{{Name: crawler.SourceYieldMax, Provider: yieldMax}}
+	b.preferred = map[string]string{"CONY": "yieldmax"}
+	result := b.run()
+
+	if result.saved != 3 || !reflect.DeepEqual(result.failed, []string{"GONE"}) {
+		t.Errorf("result = %+v, want 3 saved and GONE failed", result)
+	}
+	// CONY prefers YieldMax and costs no API call; ULTY falls back to it after FMP fails
+	if !reflect.DeepEqual(client.fetched, []string{"GONE", "TSLY", "ULTY"}) {
+		t.Errorf("FMP fetched %v, want [GONE TSLY ULTY]", client.fetched)
+	}
+	if !reflect.DeepEqual(yieldMax.calls, []string{"CONY", "GONE", "ULTY"}) {
+		t.Errorf("YieldMax fetched %v, want [CONY GONE ULTY]", yieldMax.calls)
+	}
+
+	histories, err := export.LoadHistories(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantAmounts := map[string]float64{"CONY": 0.9, "TSLY": 0.5, "ULTY": 0.9}
+	for _, history := range histories {
+		if got := history.Events[0].Amount; got != wantAmounts[history.Symbol] {
+			t.Errorf("%s saved amount %v, want %v", history.Symbol, got, wantAmounts[history.Symbol])
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+)
+
+// aliasStub is left at a retired ticker's path so old links find the successor
+type aliasStub struct {
+	OldSymbol string `json:"oldSymbol"`
+	RenamedTo string `json:"renamedTo"`
+	Path      string `json:"path"`
+}
+
+// aliasArchiveDir holds the histories of retired tickers, out of the way of
+// tools that read every file in the output directory
+const aliasArchiveDir = "aliases"
+
+// mergeAliasedHistories folds the saved history of every renamed fund into its
+// successor's file. The old history is archived on first sight and merged again
+// on later runs, since a fresh scrape of the successor overwrites its file; the
+// old path is removed, or replaced with a redirect stub when aliasStubs is set.
+func (t *crawlTail) mergeAliasedHistories() {
+	oldSymbols := make([]string, 0, len(t.aliases))
+	for oldSymbol := range t.aliases {
+		oldSymbols = append(oldSymbols, oldSymbol)
+	}
+	sort.Strings(oldSymbols)
+
+	for _, oldSymbol := range oldSymbols {
+		newSymbol := t.aliases[oldSymbol]
+		added, err := t.mergeAliasedHistory(oldSymbol, newSymbol)
+		if err != nil {
+			t.logger.Errorf("Failed to merge %s history into %s: %v", oldSymbol, newSymbol, err)
+			continue
+		}
+		if added >= 0 {
+			t.logger.Infof("Merged %d events from %s into %s", added, oldSymbol, newSymbol)
+		}
+	}
+}
+
+// mergeAliasedHistory merges one renamed fund, returning -1 when there is no
+// saved history under the old symbol. The -years and -max-events limits are
+// applied to the merged history, so the legacy events are merged before the
+// successor's file is trimmed again.
+func (t *crawlTail) mergeAliasedHistory(oldSymbol, newSymbol string) (int, error) {
+	oldFile := fmt.Sprintf("dividends_%s.json", oldSymbol)
+	oldPath := filepath.Join(t.outputDir, oldFile)
+	archivePath := filepath.Join(t.outputDir, aliasArchiveDir, oldFile)
+	newFile := fmt.Sprintf("dividends_%s.json", newSymbol)
+	newPath := filepath.Join(t.outputDir, newFile)
+
+	legacy := loadSavedHistory(oldPath)
+	if legacy != nil && legacy.Symbol != "" {
+		if err := os.MkdirAll(filepath.Dir(archivePath), 0755); err != nil {
+			return 0, fmt.Errorf("failed to create alias archive: %w", err)
+		}
+		if archived := loadSavedHistory(archivePath); archived != nil {
+			legacy.MergeHistory(archived)
+		}
+		if _, err := export.SaveHistory(archivePath, legacy); err != nil {
+			return 0, err
+		}
+		if t.aliasStubs {
+			stub := aliasStub{OldSymbol: oldSymbol, RenamedTo: newSymbol, Path: newFile}
+			if err := export.SaveJSON(oldPath, stub); err != nil {
+				return 0, err
+			}
+		} else if err := os.Remove(oldPath); err != nil {
+			return 0, fmt.Errorf("failed to remove %s: %w", oldPath, err)
+		}
+	} else {
+		legacy = loadSavedHistory(archivePath)
+	}
+	if legacy == nil || legacy.Symbol == "" {
+		return -1, nil
+	}
+
+	successor := loadSavedHistory(newPath)
+	if successor == nil {
+		successor = &models.DividendHistory{
+			Symbol:    newSymbol,
+			Name:      legacy.Name,
+			Group:     legacy.Group,
+			Frequency: legacy.Frequency,
+		}
+	}
+
+	now := t.clock.Now()
+	added := successor.MergeHistory(legacy)
+	successor.KeepYearsAt(t.years, now)
+	successor.SortEventsDescending()
+	successor.Stats = models.CalculateStatsAt(successor.Events, now)
+	if t.targetTracking {
+		// No price is known here; the tracking the scrape saved at the
+		// current price is kept unless the events' yields can measure it
+		if tracking := successor.TargetTrackingAt(0, now); tracking != nil {
+			successor.Target = tracking
+		}
+	}
+	successor.TrimEvents(t.maxEvents)
+	successor.UpdatedAt = now
+	if _, err := export.SaveHistory(newPath, successor); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// monthlyEvents returns one event per month, newest first, ending at last
+func monthlyEvents(symbol string, last time.Time, amounts ...float64) []models.DividendEvent {
+	events := make([]models.DividendEvent, len(amounts))
+	for i, amount := range amounts {
+		exDate := last.AddDate(0, -i, 0)
+		events[i] = models.DividendEvent{
+			Symbol:    symbol,
+			ExDate:    exDate,
+			PayDate:   exDate.AddDate(0, 0, 1),
+			Amount:    amount,
+			Frequency: "monthly",
+		}
+	}
+	return events
+}
+
+func saveTestHistory(t *testing.T, dir, symbol string, events []models.DividendEvent) {
+	t.Helper()
+	history := &models.DividendHistory{Symbol: symbol, Frequency: "monthly", Events: events}
+	if _, err := export.SaveHistory(filepath.Join(dir, "dividends_"+symbol+".json"), history); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newAliasTail(dir string, stubs bool, maxEvents int) *crawlTail {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return &crawlTail{
+		outputDir:  dir,
+		clock:      clock.NewFake(time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)),
+		aliases:    map[string]string{"OLDY": "NEWY"},
+		aliasStubs: stubs,
+		maxEvents:  maxEvents,
+		logger:     logger,
+	}
+}
+
+func TestMergeAliasedHistory(t *testing.T) {
+	last := time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		stubs     bool
+		maxEvents int
+		want      []float64
+	}{
+		{
+			// NEWY's own March through June events win over OLDY's on the same dates
+			name: "combined and deduped",
+			want: []float64{0.5, 0.6, 0.7, 0.8, 0.3, 0.2},
+		},
+		{
+			name:  "redirect stub",
+			stubs: true,
+			want:  []float64{0.5, 0.6, 0.7, 0.8, 0.3, 0.2},
+		},
+		{
+			// Trimming after the merge keeps the newest events, not the successor's own
+			name:      "trimmed after merge",
+			maxEvents: 5,
+			want:      []float64{0.5, 0.6, 0.7, 0.8, 0.3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			saveTestHistory(t, dir, "OLDY", monthlyEvents("OLDY", last.AddDate(0, -2, 0), 0.1, 0.1, 0.3, 0.2))
+			saveTestHistory(t, dir, "NEWY", monthlyEvents("NEWY", last, 0.5, 0.6, 0.7, 0.8))
+
+			tail := newAliasTail(dir, tt.stubs, tt.maxEvents)
+			added, err := tail.mergeAliasedHistory("OLDY", "NEWY")
+			if err != nil {
+				t.Fatalf("mergeAliasedHistory: %v", err)
+			}
+			if added != 2 {
+				t.Errorf("added %d events, want 2", added)
+			}
+
+			merged := loadSavedHistory(filepath.Join(dir, "dividends_NEWY.json"))
+			if merged == nil {
+				t.Fatal("successor history not saved")
+			}
+			amounts := make([]float64, len(merged.Events))
+			for i, e := range merged.Events {
+				amounts[i] = e.Amount
+				if e.Symbol != "NEWY" {
+					t.Errorf("event %d has symbol %q, want NEWY", i, e.Symbol)
+				}
+				if i > 0 && !e.ExDate.Before(merged.Events[i-1].ExDate) {
+					t.Errorf("event %d on %v not older than the one before", i, e.ExDate)
+				}
+			}
+			if len(amounts) != len(tt.want) {
+				t.Fatalf("amounts = %v, want %v", amounts, tt.want)
+			}
+			for i := range tt.want {
+				if amounts[i] != tt.want[i] {
+					t.Fatalf("amounts = %v, want %v", amounts, tt.want)
+				}
+			}
+			// Stats cover the whole merged history, before it is trimmed
+			if merged.Stats.TotalPayments != 6 {
+				t.Errorf("stats count %d payments, want 6", merged.Stats.TotalPayments)
+			}
+
+			if archived := loadSavedHistory(filepath.Join(dir, aliasArchiveDir, "dividends_OLDY.json")); archived == nil || len(archived.Events) != 4 {
+				t.Errorf("legacy history not archived: %+v", archived)
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, "dividends_OLDY.json"))
+			if !tt.stubs {
+				if !os.IsNotExist(err) {
+					t.Errorf("old history left in place without -alias-stubs (err %v)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("redirect stub not written: %v", err)
+			}
+			var stub aliasStub
+			if err := json.Unmarshal(data, &stub); err != nil {
+				t.Fatal(err)
+			}
+			if stub.RenamedTo != "NEWY" || stub.Path != "dividends_NEWY.json" {
+				t.Errorf("stub = %+v, want a redirect to NEWY", stub)
+			}
+		})
+	}
+}
+
+func TestMergeAliasedHistoryFromArchive(t *testing.T) {
+	dir := t.TempDir()
+	last := time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC)
+	saveTestHistory(t, dir, "OLDY", monthlyEvents("OLDY", last.AddDate(0, -4, 0), 0.3, 0.2))
+	saveTestHistory(t, dir, "NEWY", monthlyEvents("NEWY", last, 0.5, 0.6))
+
+	tail := newAliasTail(dir, false, 0)
+	if _, err := tail.mergeAliasedHistory("OLDY", "NEWY"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh scrape overwrites the successor; the next run merges the archive again
+	saveTestHistory(t, dir, "NEWY", monthlyEvents("NEWY", last, 0.5, 0.6))
+	added, err := tail.mergeAliasedHistory("OLDY", "NEWY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 2 {
+		t.Errorf("added %d events from the archive, want 2", added)
+	}
+	if merged := loadSavedHistory(filepath.Join(dir, "dividends_NEWY.json")); merged == nil || len(merged.Events) != 4 {
+		t.Errorf("merged history = %+v, want 4 events", merged)
+	}
+
+	if added, err := tail.mergeAliasedHistory("GONE", "NEWY"); err != nil || added != -1 {
+		t.Errorf("missing legacy history: added %d, err %v; want -1, nil", added, err)
+	}
+}
+
+func TestMergeAliasedHistoryTracksTarget(t *testing.T) {
+	dir := t.TempDir()
+	last := time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC)
+	saveTestHistory(t, dir, "OLDY", monthlyEvents("OLDY", last.AddDate(0, -2, 0), 0.15, 0.15))
+
+	scraped := &models.TargetTracking{TargetRate: models.Target12Rate, AnnualizedActualRate: 14, VarianceFromTarget: 2}
+	successor := &models.DividendHistory{Symbol: "NEWY", Group: "Target12", Frequency: "monthly", Events: monthlyEvents("NEWY", last, 0.15, 0.15), Target: scraped}
+	if _, err := export.SaveHistory(filepath.Join(dir, "dividends_NEWY.json"), successor); err != nil {
+		t.Fatal(err)
+	}
+
+	tail := newAliasTail(dir, false, 0)
+	tail.targetTracking = true
+	if _, err := tail.mergeAliasedHistory("OLDY", "NEWY"); err != nil {
+		t.Fatal(err)
+	}
+	merged := loadSavedHistory(filepath.Join(dir, "dividends_NEWY.json"))
+	// Without yields or a price the scrape's measurement stands
+	if merged == nil || merged.Target == nil || *merged.Target != *scraped {
+		t.Fatalf("merged tracking = %+v, want the scraped %+v", merged.Target, scraped)
+	}
+
+	// With yields on the events, the merged history is measured again
+	saveTestHistory(t, dir, "OLDY", monthlyEvents("OLDY", last.AddDate(0, -2, 0), 0.15, 0.15))
+	merged.Events[0].Yield = 10
+	merged.Events[1].Yield = 11
+	if _, err := export.SaveHistory(filepath.Join(dir, "dividends_NEWY.json"), merged); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tail.mergeAliasedHistory("OLDY", "NEWY"); err != nil {
+		t.Fatal(err)
+	}
+	remeasured := loadSavedHistory(filepath.Join(dir, "dividends_NEWY.json"))
+	if remeasured.Target == nil || remeasured.Target.AnnualizedActualRate != 10.5 || remeasured.Target.VarianceFromTarget != -1.5 {
+		t.Errorf("remeasured tracking = %+v, want 10.5%% and -1.5 points", remeasured.Target)
+	}
+}
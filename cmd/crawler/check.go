@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"divminder-crawler/internal/api"
+	"divminder-crawler/internal/cache"
+	"divminder-crawler/internal/scraper"
+
+	"github.com/sirupsen/logrus"
+)
+
+// dependencyCheck is a single external dependency verified by `crawler check`
+type dependencyCheck struct {
+	name     string
+	critical bool
+	run      func() error // nil when the dependency isn't configured
+}
+
+// checkResult is the outcome of a dependencyCheck
+type checkResult struct {
+	name     string
+	critical bool
+	skipped  bool
+	err      error
+}
+
+// runCheckCommand verifies the YieldMax site and the configured API keys and
+// returns the process exit code
+func runCheckCommand(logger *logrus.Logger, ttls cache.TTLs) int {
+	results := runChecks(buildDependencyChecks(ttls, api.DefaultMetadataCacheDir))
+
+	for _, result := range results {
+		fields := logrus.Fields{"dependency": result.name, "critical": result.critical}
+		switch {
+		case result.skipped:
+			logger.WithFields(fields).Warn("Dependency not configured, skipping")
+		case result.err != nil:
+			logger.WithFields(fields).Errorf("Dependency check failed: %v", result.err)
+		default:
+			logger.WithFields(fields).Info("Dependency check passed")
+		}
+	}
+
+	exitCode := checkExitCode(results)
+	if exitCode != 0 {
+		logger.Error("One or more critical dependencies are down")
+	} else {
+		logger.Info("All critical dependencies are healthy")
+	}
+
+	return exitCode
+}
+
+// buildDependencyChecks assembles the checks from the environment. The YieldMax
+// site is always critical; API keys are critical only when configured.
+// Alpha Vantage lookups are cached under avCacheDir.
+func buildDependencyChecks(ttls cache.TTLs, avCacheDir string) []dependencyCheck {
+	fullScraper := scraper.NewYieldMaxFullScraper()
+
+	checks := []dependencyCheck{
+		{name: "yieldmax-schedule", critical: true, run: fullScraper.CheckSchedulePage},
+	}
+
+	avCheck := dependencyCheck{name: "alpha-vantage", critical: true}
+	if keys := api.ParseAPIKeys(os.Getenv("ALPHA_VANTAGE_API_KEY")); len(keys) > 0 {
+		avCheck.run = alphaVantageCheck(keys, ttls, avCacheDir)
+	}
+	checks = append(checks, avCheck)
+
+	fmpCheck := dependencyCheck{name: "fmp", critical: true}
+	if apiKey := os.Getenv("FMP_API_KEY"); apiKey != "" {
+		fmpCheck.run = api.NewFMPClient(apiKey).WithTTL(ttls.History, ttls.Calendar).TestConnection
+	}
+	checks = append(checks, fmpCheck)
+
+	return checks
+}
+
+// alphaVantageCheck tests the connection with keys. A key that is set but
+// can't work, such as the demo key or one of the wrong length, fails the
+// check without a request rather than being skipped as unconfigured.
+func alphaVantageCheck(keys []string, ttls cache.TTLs, cacheDir string) func() error {
+	client := api.NewAlphaVantageClient(keys...)
+	if err := client.KeyError(); err != nil {
+		return func() error { return err }
+	}
+	for i, key := range keys {
+		if err := api.ValidateAlphaVantageKey(key); err != nil {
+			return func() error { return fmt.Errorf("key #%d: %w", i, err) }
+		}
+	}
+	return client.WithCacheDir(cacheDir).WithTTL(ttls.Metadata).TestConnection
+}
+
+// runChecks executes each configured check in order
+func runChecks(checks []dependencyCheck) []checkResult {
+	results := make([]checkResult, 0, len(checks))
+
+	for _, check := range checks {
+		result := checkResult{name: check.name, critical: check.critical}
+		if check.run == nil {
+			result.skipped = true
+		} else {
+			result.err = check.run()
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// checkExitCode returns 1 if any critical, configured dependency failed
+func checkExitCode(results []checkResult) int {
+	for _, result := range results {
+		if result.critical && !result.skipped && result.err != nil {
+			return 1
+		}
+	}
+	return 0
+}
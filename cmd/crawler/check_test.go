@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"divminder-crawler/internal/api"
+	"divminder-crawler/internal/cache"
+)
+
+// httpCheck fails unless url answers 200 OK
+func httpCheck(url string) func() error {
+	return func() error {
+		resp, err := http.Get(url)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+func TestCheckExitCode(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer up.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	tests := []struct {
+		name   string
+		checks []dependencyCheck
+		want   int
+	}{
+		{
+			name: "all up",
+			checks: []dependencyCheck{
+				{name: "yieldmax-schedule", critical: true, run: httpCheck(up.URL)},
+				{name: "fmp", critical: true, run: httpCheck(up.URL)},
+			},
+			want: 0,
+		},
+		{
+			name: "critical down",
+			checks: []dependencyCheck{
+				{name: "yieldmax-schedule", critical: true, run: httpCheck(down.URL)},
+				{name: "fmp", critical: true, run: httpCheck(up.URL)},
+			},
+			want: 1,
+		},
+		{
+			name: "non-critical down",
+			checks: []dependencyCheck{
+				{name: "yieldmax-schedule", critical: true, run: httpCheck(up.URL)},
+				{name: "optional", critical: false, run: httpCheck(down.URL)},
+			},
+			want: 0,
+		},
+		{
+			name: "unconfigured key skipped",
+			checks: []dependencyCheck{
+				{name: "yieldmax-schedule", critical: true, run: httpCheck(up.URL)},
+				{name: "alpha-vantage", critical: true},
+			},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := runChecks(tt.checks)
+			if len(results) != len(tt.checks) {
+				t.Fatalf("got %d results for %d checks", len(results), len(tt.checks))
+			}
+			for i, result := range results {
+				if result.skipped != (tt.checks[i].run == nil) {
+					t.Errorf("%s skipped = %v", result.name, result.skipped)
+				}
+			}
+			if got := checkExitCode(results); got != tt.want {
+				t.Errorf("checkExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckEnrichmentKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		require bool
+		apiKey  string
+		want    error
+	}{
+		{"missing key without the flag", false, "", nil},
+		{"demo key without the flag", false, "demo", nil},
+		{"missing key with the flag", true, "", api.ErrNoAPIKey},
+		{"whitespace key with the flag", true, " , ", api.ErrNoAPIKey},
+		{"demo key with the flag", true, "demo", api.ErrDemoAPIKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkEnrichmentKey(tt.require, tt.apiKey, cache.DefaultTTLs(), t.TempDir())
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("checkEnrichmentKey = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("checkEnrichmentKey = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildDependencyChecksFailsUnusableKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want error
+	}{
+		{"demo key", "demo", api.ErrDemoAPIKey},
+		{"malformed key", "ABC123", api.ErrMalformedAPIKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ALPHA_VANTAGE_API_KEY", tt.key)
+			t.Setenv("FMP_API_KEY", "")
+
+			var avCheck dependencyCheck
+			for _, check := range buildDependencyChecks(cache.DefaultTTLs(), t.TempDir()) {
+				if check.name == "alpha-vantage" {
+					avCheck = check
+				}
+			}
+			if avCheck.run == nil {
+				t.Fatal("alpha-vantage check skipped for a configured key")
+			}
+
+			results := runChecks([]dependencyCheck{avCheck})
+			if !errors.Is(results[0].err, tt.want) {
+				t.Errorf("alpha-vantage check = %v, want %v", results[0].err, tt.want)
+			}
+			if code := checkExitCode(results); code != 1 {
+				t.Errorf("checkExitCode() = %d, want 1", code)
+			}
+		})
+	}
+}
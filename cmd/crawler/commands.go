@@ -0,0 +1,58 @@
+package main
+
+import (
+	"divminder-crawler/internal/commands/fixdata"
+	"divminder-crawler/internal/commands/list"
+	"divminder-crawler/internal/commands/recompute"
+	"divminder-crawler/internal/commands/reconcile"
+	"divminder-crawler/internal/commands/schedule"
+	"divminder-crawler/internal/commands/scrape"
+	"divminder-crawler/internal/commands/scrapecached"
+	"divminder-crawler/internal/commands/scrapeoptimized"
+	"divminder-crawler/internal/commands/testscraper"
+	"divminder-crawler/internal/commands/validate"
+)
+
+// subcommands maps `crawler <name>` to the tool it runs with the remaining args
+var subcommands = map[string]func(args []string){
+	"scrape":    runScrapeCommand,
+	"fix":       fixdata.Run,
+	"test":      testscraper.Run,
+	"validate":  validate.Run,
+	"recompute": recompute.Run,
+	"schedule":  schedule.Run,
+	"reconcile": reconcile.Run,
+	"list":      list.Run,
+}
+
+// runScrapeCommand runs the sequential scraper, or the cached or optimized one
+// when -cached or -optimized is given
+func runScrapeCommand(args []string) {
+	mode, rest := splitScrapeMode(args)
+	switch mode {
+	case "cached":
+		scrapecached.Run(rest)
+	case "optimized":
+		scrapeoptimized.Run(rest)
+	default:
+		scrape.Run(rest)
+	}
+}
+
+// splitScrapeMode removes the -cached/-optimized mode flags from args,
+// returning the last mode given and the args for the selected scraper
+func splitScrapeMode(args []string) (string, []string) {
+	mode := ""
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch arg {
+		case "-cached", "--cached":
+			mode = "cached"
+		case "-optimized", "--optimized":
+			mode = "optimized"
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return mode, rest
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSplitScrapeMode(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantMode string
+		wantRest []string
+	}{
+		{nil, "", []string{}},
+		{[]string{"-group", "Weekly"}, "", []string{"-group", "Weekly"}},
+		{[]string{"--cached", "-max-age", "12h"}, "cached", []string{"-max-age", "12h"}},
+		{[]string{"-out", "data", "-optimized"}, "optimized", []string{"-out", "data"}},
+		{[]string{"-cached", "-optimized"}, "optimized", []string{}},
+	}
+	for _, tt := range tests {
+		mode, rest := splitScrapeMode(tt.args)
+		if mode != tt.wantMode || !reflect.DeepEqual(rest, tt.wantRest) {
+			t.Errorf("splitScrapeMode(%q) = %q, %q; want %q, %q", tt.args, mode, rest, tt.wantMode, tt.wantRest)
+		}
+	}
+}
+
+func TestSubcommandNames(t *testing.T) {
+	var names []string
+	for name, run := range subcommands {
+		if run == nil {
+			t.Errorf("subcommand %q has no function", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	want := []string{"fix", "list", "recompute", "reconcile", "schedule", "scrape", "test", "validate"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("subcommands = %v, want %v", names, want)
+	}
+
+	// `crawler check` is handled after flag setup, not dispatched like the tools
+	if _, ok := subcommands["check"]; ok {
+		t.Error("check must not be a standalone subcommand")
+	}
+}
@@ -2,13 +2,26 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"hash/fnv"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"time"
 
 	"divminder-crawler/internal/api"
+	"divminder-crawler/internal/cache"
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/cmdutil"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/httpx"
+	"divminder-crawler/internal/metrics"
 	"divminder-crawler/internal/models"
+	"divminder-crawler/internal/notifier"
+	"divminder-crawler/internal/overrides"
+	"divminder-crawler/internal/proxy"
 	"divminder-crawler/internal/scraper"
 
 	"github.com/joho/godotenv"
@@ -16,70 +29,258 @@ import (
 )
 
 func main() {
+	concurrency := flag.Int("concurrency", scraper.DefaultDetailConcurrency, "Number of concurrent ETF detail scrapes")
+	requestInterval := flag.Duration("request-interval", time.Second, "Minimum average interval between ETF detail requests")
+	tsvPath := flag.String("tsv", "", "Also write a Google Sheets-compatible TSV summary to this path (e.g. data/summary.tsv)")
+	var refresh bool
+	flag.BoolVar(&refresh, "refresh", false, "Bypass cached API responses and detail pages for this run (fresh results are still cached)")
+	flag.BoolVar(&refresh, "no-cache", false, "Alias for -refresh")
+	outputDir := flag.String("out", "docs", "Directory to write the JSON API files to")
+	groupSchedules := flag.Bool("group-schedules", true, "Also write schedule_{GROUP}.json with each group's ETFs and upcoming events")
+	fixWeekends := flag.Bool("fix-weekends", false, "Move scraped ex-dates that fall on a weekend to the prior Friday instead of only warning about them")
+	years := flag.Int("years", 0, "Keep only events from the last N years, computing stats from that window (0 keeps all)")
+	maxEvents := flag.Int("max-events", 0, "Keep only the N most recent events per symbol in saved files; stats still cover every event (0 keeps all)")
+	includeInactive := flag.Bool("include-inactive", false, "Also scrape symbols marked inactive after repeated 404s")
+	inactiveAfter := flag.Int("inactive-after", scraper.DefaultInactiveAfter, "Consecutive crawls whose detail page returned 404 after which a symbol is marked inactive and skipped")
+	discover := flag.Bool("discover", true, "Scrape the YieldMax ETF listing for new symbols and include them in this run")
+	outputsFlag := flag.String("outputs", "all", "Comma-separated artifacts to produce: schedule, summary, histories, enriched; skipped ones skip the scraping they need")
+	onlyUpcoming := flag.Bool("only-upcoming", false, "Only refresh the schedule and next dates (schedule_v3.json, group schedules, etfs.json), skipping history scraping")
+	slim := flag.Bool("slim", false, "Omit event arrays from all_dividends.json, keeping only stats and the manifest")
+	defaultTTLs := cache.DefaultTTLs()
+	var ttls cache.TTLs
+	flag.DurationVar(&ttls.History, "history-ttl", defaultTTLs.History, "How long cached FMP dividend histories stay fresh")
+	flag.DurationVar(&ttls.Calendar, "calendar-ttl", defaultTTLs.Calendar, "How long cached FMP dividend calendars stay fresh")
+	flag.DurationVar(&ttls.Metadata, "metadata-ttl", defaultTTLs.Metadata, "How long cached Alpha Vantage metadata stays fresh")
+	flag.DurationVar(&ttls.Detail, "detail-ttl", defaultTTLs.Detail, "How long cached ETF detail pages stay fresh (0 disables caching)")
+	overridesPath := flag.String("overrides", overrides.DefaultFile, "Per-symbol name/group/frequency/description overrides applied over scraped data")
+	groupsFile := flag.String("groups-file", scraper.DefaultScrapedGroupsFile, "Where the group mapping scraped from the schedule page is kept between runs (empty keeps it in memory only)")
+	webhookURL := flag.String("webhook-url", "", "POST a JSON alert here when a fund's newest distribution moves past -amount-change-threshold")
+	amountChangeThreshold := flag.Float64("amount-change-threshold", notifier.DefaultAmountChangeThreshold, "Percent change from the prior distribution that triggers a webhook alert")
+	requireEnrichment := flag.Bool("require-enrichment", false, "Exit at startup if ALPHA_VANTAGE_API_KEY is missing or rejected instead of continuing without metadata enrichment")
+	redisURL := flag.String("redis-url", "", "Share the API cache through Redis (redis://[:password@]host:port[/db]); requires a build with -tags redis")
+	syntheticSeed := flag.Int64("synthetic-seed", 1, "Seed for the placeholder histories written when a detail scrape fails, combined with each symbol so reruns produce the same amounts")
+	asOfDate := flag.String("as-of", "", "Build the schedule and upcoming-event aggregates as of this past date (YYYY-MM-DD) for backtests; defaults to now")
+	proxyURL := flag.String("proxy", "", "Route scraper and API requests through this http(s) or socks5 proxy URL; defaults to HTTP_PROXY/HTTPS_PROXY")
+	compact := flag.Bool("compact", false, "Write JSON output without indentation to shrink published files")
+	pretty := flag.Bool("pretty", false, "Write indented JSON output (the default); overrides -compact")
+	rotationWeeks := flag.Int("rotation-weeks", export.DefaultRotationWeeks, "Weeks ahead to cover in rotation_calendar.json (0 skips it)")
+	gzipOutput := flag.Bool("gzip", false, "Also write a .gz copy of every JSON/NDJSON file in the output directory")
+	pruneOlderThan := flag.Duration("prune-older-than", 0, "Remove per-symbol files not rewritten within this long (e.g. 720h), such as those of delisted tickers (0 keeps them)")
+	targetTracking := flag.Bool("target-tracking", true, "Compare each Target12 fund's trailing 12-month distributions at its current price with the 12% target in its history")
+	aliasStubs := flag.Bool("alias-stubs", false, "Leave a redirect stub at a renamed fund's old history file instead of deleting it after merging")
+	apiRetries := flag.Int("api-retries", httpx.DefaultRetryPolicy().MaxRetries, "Retries for API requests that fail with a 5xx status or a transient network error (0 disables)")
+	requestTimeout := flag.Duration("request-timeout", 0, "Give up on an API call, retries included, after this long so a slow symbol doesn't stall the batch (0 uses only the 30s per-attempt timeout)")
+	debugRequests := flag.Bool("debug-requests", false, "Log each scrape request's status, size and whether a table was found, plus a summary of unexpected statuses, at debug level")
+	siteRateLimit := flag.Int("site-rate-limit", 0, "Cap requests to yieldmaxetfs.com per minute across all scrapers in the run (0 means no cap)")
+	lockStaleAfter := flag.Duration("lock-stale-after", cmdutil.DefaultLockStaleAfter, "Take over a run lock older than this, left by a crawl that died on another machine (0 never does)")
+	metricsAddr := flag.String("metrics-addr", "", "Serve Prometheus metrics on this address (e.g. :9090) while the crawler runs")
+	flag.Parse()
+
 	// Load environment variables
 	_ = godotenv.Load()
 
+	// `crawler scrape|fix|test` run the standalone scraping tools
+	if run, ok := subcommands[flag.Arg(0)]; ok {
+		run(flag.Args()[1:])
+		return
+	}
+
 	// Setup logging
 	logger := logrus.New()
 	logger.SetLevel(logrus.InfoLevel)
 	logger.SetFormatter(&logrus.JSONFormatter{})
 
+	if *concurrency < 1 {
+		logger.Fatalf("Invalid -concurrency %d: must be at least 1", *concurrency)
+	}
+	if *requestInterval <= 0 {
+		logger.Fatalf("Invalid -request-interval %s: must be positive", *requestInterval)
+	}
+	if *maxEvents < 0 {
+		logger.Fatalf("Invalid -max-events %d: must not be negative", *maxEvents)
+	}
+	if *years < 0 {
+		logger.Fatalf("Invalid -years %d: must not be negative", *years)
+	}
+	if *inactiveAfter < 1 {
+		logger.Fatalf("Invalid -inactive-after %d: must be at least 1", *inactiveAfter)
+	}
+	if *rotationWeeks < 0 {
+		logger.Fatalf("Invalid -rotation-weeks %d: must not be negative", *rotationWeeks)
+	}
+	if *pruneOlderThan < 0 {
+		logger.Fatalf("Invalid -prune-older-than %s: must not be negative", *pruneOlderThan)
+	}
+	if *apiRetries < 0 {
+		logger.Fatalf("Invalid -api-retries %d: must not be negative", *apiRetries)
+	}
+	if *requestTimeout < 0 {
+		logger.Fatalf("Invalid -request-timeout %s: must not be negative", *requestTimeout)
+	}
+	outputs, err := parseOutputs(*outputsFlag)
+	if err != nil {
+		logger.Fatalf("Invalid -outputs: %v", err)
+	}
+	retryPolicy := httpx.DefaultRetryPolicy()
+	retryPolicy.MaxRetries = *apiRetries
+	asOf := time.Now()
+	var runClock clock.Clock = clock.Real{}
+	if *asOfDate != "" {
+		parsed, err := models.ParseMarketDate("2006-01-02", *asOfDate)
+		if err != nil {
+			logger.Fatalf("Invalid -as-of %q: must be YYYY-MM-DD", *asOfDate)
+		}
+		asOf = parsed
+		runClock = clock.NewFake(asOf)
+	}
+	if err := proxy.Configure(*proxyURL); err != nil {
+		logger.Fatalf("Invalid -proxy: %v", err)
+	}
+	if *siteRateLimit < 0 {
+		logger.Fatalf("Invalid -site-rate-limit %d: must not be negative", *siteRateLimit)
+	}
+	scraper.SetSiteRateLimit(*siteRateLimit)
+	scraper.SetRequestLogging(*debugRequests)
+	defer scraper.LogRequestSummary()
+	export.SetCompactJSON(*compact && !*pretty)
+
+	// `crawler check` validates external dependencies without crawling
+	if flag.Arg(0) == "check" {
+		os.Exit(runCheckCommand(logger, ttls))
+	}
+
+	if err := checkEnrichmentKey(*requireEnrichment, os.Getenv("ALPHA_VANTAGE_API_KEY"), ttls, api.DefaultMetadataCacheDir); err != nil {
+		logger.Fatalf("-require-enrichment: %v", err)
+	}
+
+	if *metricsAddr != "" {
+		metrics.Serve(*metricsAddr, func(err error) {
+			logger.Errorf("Metrics server failed: %v", err)
+		})
+		logger.Infof("Serving metrics on %s/metrics", *metricsAddr)
+	}
+
 	logger.Info("Starting DivMinder crawler with comprehensive YieldMax scraping...")
 
 	// Create output directory
-	outputDir := "docs"
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		logger.Fatalf("Failed to create output directory: %v", err)
+	if err := export.EnsureOutputDir(*outputDir); err != nil {
+		logger.Fatal(err)
 	}
-	
-	// Use new comprehensive scraper
-	fullScraper := scraper.NewYieldMaxFullScraper()
-	if err := fullScraper.ScrapeAndSaveAllData(outputDir); err != nil {
-		logger.Errorf("Full scraper failed: %v", err)
-		logger.Info("Falling back to improved scraper...")
-		// Continue with existing code as fallback
-	} else {
-		logger.Info("Successfully completed comprehensive data scraping!")
-		return
+
+	// Keep an overrunning scheduled crawl from writing the same files
+	runLock, err := cmdutil.AcquireRunLock(cmdutil.LockPath(*outputDir), *lockStaleAfter)
+	if err != nil {
+		logger.Fatalf("Not starting: %v", err)
 	}
+	defer func() {
+		if err := runLock.Release(); err != nil {
+			logger.Warn(err)
+		}
+	}()
 
-	// Initialize improved YieldMax scraper
-	improvedScraper := scraper.NewImprovedYieldMaxScraper()
+	logger.Infof("Producing outputs: %s", outputs)
 
-	// Scrape distribution schedule with improved logic
-	logger.Info("Scraping distribution schedule with improved parser...")
-	schedule, err := improvedScraper.GetScheduleImproved()
+	symbolOverrides, err := overrides.Load(*overridesPath)
 	if err != nil {
-		logger.Errorf("Failed to scrape improved schedule: %v", err)
-	} else {
-		logger.Infof("Successfully scraped schedule with %d groups and %d upcoming events",
-			len(schedule.Groups), len(schedule.Upcoming))
+		logger.Warnf("Failed to load overrides, using scraped values: %v", err)
+		symbolOverrides = overrides.Overrides{}
+	}
+	aliases := symbolOverrides.Aliases()
+	groupResolver := scraper.NewGroupResolverFromFile(symbolOverrides, *groupsFile)
+	symbolTracker := cmdutil.LoadSymbolTracker(*outputDir, *inactiveAfter)
 
-		// Save improved schedule to JSON
-		if err := saveToJSON(filepath.Join(outputDir, "schedule_v3.json"), schedule); err != nil {
-			logger.Errorf("Failed to save improved schedule: %v", err)
+	// Initialize improved YieldMax scraper
+	improvedScraper := scraper.NewImprovedYieldMaxScraper()
+	improvedScraper.SetGroupsFile(*groupsFile)
+	improvedScraper.SetClock(runClock)
+
+	// Scrape distribution schedule with improved logic before either crawl;
+	// the API summary embeds it, so it is scraped for either output
+	var schedule *models.Schedule
+	if outputs.has(outputSchedule) || outputs.has(outputSummary) {
+		logger.Info("Scraping distribution schedule with improved parser...")
+		scraped, err := improvedScraper.GetScheduleImproved()
+		if err != nil {
+			logger.Errorf("Failed to scrape improved schedule: %v", err)
 		} else {
-			logger.Info("Improved schedule saved to schedule_v3.json")
+			logger.Infof("Successfully scraped schedule with %d groups and %d upcoming events",
+				len(scraped.Groups), len(scraped.Upcoming))
+			schedule = scraped
 		}
 	}
+	if schedule != nil && outputs.has(outputSchedule) {
+		writeScheduleOutputs(*outputDir, schedule, *groupSchedules, runClock, logger)
+	}
+
+	tail := &crawlTail{
+		outputDir:      *outputDir,
+		outputs:        outputs,
+		schedule:       schedule,
+		clock:          runClock,
+		aliases:        aliases,
+		aliasStubs:     *aliasStubs,
+		years:          *years,
+		maxEvents:      *maxEvents,
+		targetTracking: *targetTracking,
+		pruneOlderThan: *pruneOlderThan,
+		slim:           *slim,
+		rotationWeeks:  *rotationWeeks,
+		tsvPath:        *tsvPath,
+		gzip:           *gzipOutput,
+		symbols:        symbolTracker,
+		logger:         logger,
+	}
+	if *webhookURL != "" {
+		tail.amountWatcher = &notifier.AmountChangeWatcher{
+			Notifier:     notifier.NewWebhook(*webhookURL),
+			ThresholdPct: *amountChangeThreshold,
+		}
+	}
+	if !*onlyUpcoming && outputs.has(outputHistories) {
+		tail.rememberHistories()
+	}
 
-	// Get comprehensive ETF list
-	logger.Info("Getting comprehensive ETF list...")
-	etfs, err := improvedScraper.GetImprovedETFList()
-	if err != nil {
-		logger.Errorf("Failed to get ETF list: %v", err)
-		// Fallback to basic ETF generation if scraping fails
-		etfs = generateBasicETFList()
-		logger.Infof("Using fallback ETF list with %d ETFs", len(etfs))
-	} else {
-		logger.Infof("Successfully retrieved %d ETFs", len(etfs))
+	// Both scrapers include funds on the ETF listing that the group map lacks
+	var discovered []string
+	if *discover {
+		symbols, err := scraper.DiscoverSymbols()
+		if err != nil {
+			logger.Warnf("Symbol discovery failed: %v", err)
+		}
+		discovered = symbols
 	}
 
-	// Save ETF list to JSON
-	if err := saveToJSON(filepath.Join(outputDir, "etfs.json"), etfs); err != nil {
-		logger.Errorf("Failed to save ETF list: %v", err)
+	// Use new comprehensive scraper
+	fullScraper := scraper.NewYieldMaxFullScraper()
+	fullScraper.SetDiscoveredSymbols(discovered)
+	fullScraper.SetOverrides(symbolOverrides)
+	fullScraper.SetGroupResolver(groupResolver)
+	fullScraper.SetTargetTracking(*targetTracking)
+	fullScraper.SetClock(runClock)
+	fullScraper.SetMaxEvents(*maxEvents)
+	fullScraper.SetYears(*years)
+	fullScraper.SetFixWeekends(*fixWeekends)
+	fullScraper.SetDetailConcurrency(*concurrency, *requestInterval)
+	fullScraper.SetSymbolTracker(symbolTracker, *includeInactive)
+	if *onlyUpcoming {
+		logger.Info("Refreshing only the schedule and next dates, skipping history scraping")
+	} else if !outputs.has(outputHistories) {
+		logger.Info("Histories not in -outputs, skipping history scraping")
+	} else if result, err := fullScraper.ScrapeAndSaveAllData(*outputDir); err != nil {
+		logger.Errorf("Full scraper failed: %v", err)
+		logger.Info("Falling back to improved scraper...")
+		// Continue with existing code as fallback
 	} else {
-		logger.Info("ETF list saved to etfs.json")
+		logger.Info("Successfully completed comprehensive data scraping!")
+		tail.run(result.ETFs, nil, result.Yields)
+		return
+	}
+
+	etfs := buildETFList(improvedScraper, discovered, groupResolver, symbolOverrides, logger)
+	symbolTracker.MarkActive(etfs)
+	if *onlyUpcoming {
+		refreshUpcoming(*outputDir, outputs, etfs, *gzipOutput, logger)
+		return
 	}
 
 	// Initialize Alpha Vantage client if API key is available
@@ -87,11 +288,26 @@ func main() {
 	var enrichedETFs []models.ETF
 	var metadataMap map[string]*models.ETFMetadata
 
-	if apiKey != "" && apiKey != "demo" {
+	if !outputs.has(outputEnriched) {
+		logger.Info("Enriched not in -outputs, skipping Alpha Vantage enrichment")
+	} else if avClient := api.NewAlphaVantageClient(api.ParseAPIKeys(apiKey)...); avClient.KeyError() == nil {
 		logger.Info("Alpha Vantage API key found, enriching ETF data...")
 
-		// Initialize Alpha Vantage client
-		avClient := api.NewAlphaVantageClient(apiKey)
+		// Configure the Alpha Vantage client
+		avClient.WithTTL(ttls.Metadata).WithRetryPolicy(retryPolicy).WithRequestTimeout(*requestTimeout)
+		if *redisURL != "" {
+			redisCache, err := cache.NewRedisCache(*redisURL, "divminder", ttls.Metadata)
+			if err != nil {
+				logger.Fatalf("Invalid -redis-url: %v", err)
+			}
+			avClient.WithCache(redisCache)
+		}
+		avClient.SetBypassCache(refresh)
+		defer func() {
+			if err := avClient.Close(); err != nil {
+				logger.Warnf("Failed to close Alpha Vantage client: %v", err)
+			}
+		}()
 
 		// Test connection first
 		if err := avClient.TestConnection(); err != nil {
@@ -116,7 +332,7 @@ func main() {
 				logger.Infof("Successfully fetched metadata for %d ETFs", len(metadataMap))
 
 				// Save raw metadata
-				if err := saveToJSON(filepath.Join(outputDir, "etf_metadata.json"), metadataMap); err != nil {
+				if err := export.SaveJSON(filepath.Join(*outputDir, "etf_metadata.json"), metadataMap); err != nil {
 					logger.Errorf("Failed to save ETF metadata: %v", err)
 				} else {
 					logger.Info("ETF metadata saved to etf_metadata.json")
@@ -124,7 +340,7 @@ func main() {
 			}
 		}
 	} else {
-		logger.Warn("No Alpha Vantage API key configured (set ALPHA_VANTAGE_API_KEY environment variable)")
+		logger.Warnf("No usable Alpha Vantage API key (set ALPHA_VANTAGE_API_KEY environment variable): %v", avClient.KeyError())
 		logger.Info("Continuing with basic ETF data...")
 	}
 
@@ -132,15 +348,16 @@ func main() {
 	enrichedETFs = enrichETFsWithMetadata(etfs, metadataMap, logger)
 
 	// Save enriched ETF list
-	if err := saveToJSON(filepath.Join(outputDir, "etfs_enriched.json"), enrichedETFs); err != nil {
-		logger.Errorf("Failed to save enriched ETF list: %v", err)
-	} else {
-		logger.Info("Enriched ETF list saved to etfs_enriched.json")
+	if outputs.has(outputEnriched) {
+		if err := export.SaveJSON(filepath.Join(*outputDir, "etfs_enriched.json"), enrichedETFs); err != nil {
+			logger.Errorf("Failed to save enriched ETF list: %v", err)
+		} else {
+			logger.Info("Enriched ETF list saved to etfs_enriched.json")
+		}
 	}
 
 	// Scrape real dividend history from YieldMax website
 	logger.Info("Scraping real dividend history from YieldMax...")
-	detailScraper := scraper.NewETFDetailScraper()
 	
 	// Get symbols to scrape
 	var symbolsToScrape []string
@@ -153,39 +370,68 @@ func main() {
 			symbolsToScrape = append(symbolsToScrape, etf.Symbol)
 		}
 	}
-	
-	// Scrape details for each ETF
-	for _, symbol := range symbolsToScrape {
-		logger.Infof("Scraping details for %s", symbol)
+
+	if !outputs.has(outputHistories) {
+		logger.Info("Histories not in -outputs, skipping the detail scrape")
+		symbolsToScrape = nil
+	}
+	symbolsToScrape, skipped := symbolTracker.FilterActive(symbolsToScrape, *includeInactive)
+	if len(skipped) > 0 {
+		logger.Infof("Skipping %d inactive symbols (pass -include-inactive to retry them): %v", len(skipped), skipped)
+	}
+
+	// Scrape details with a bounded worker pool sharing one rate limiter
+	logger.Infof("Scraping details for %d ETFs with %d workers", len(symbolsToScrape), *concurrency)
+	limiter := api.NewRateLimiter(*concurrency, *requestInterval)
+	newDetailScraper := func() scraper.DetailScraper {
+		detailScraper := scraper.NewETFDetailScraper().WithTTL(ttls.Detail)
+		detailScraper.SetBypassCache(refresh)
+		return detailScraper
+	}
+	yields := make(map[string]float64)
+
+	for result := range scraper.ScrapeDetails(symbolsToScrape, *concurrency, limiter, newDetailScraper) {
+		symbol := result.Symbol
+		if symbolTracker.RecordResult(symbol, result.Err, runClock.Now()) {
+			logger.Warnf("Marking %s inactive after %d consecutive 404s", symbol, symbolTracker.InactiveAfter())
+		}
 		
-		if detail, err := detailScraper.GetETFDetail(symbol); err == nil {
+		if result.Err == nil {
+			detail := result.Detail
+			if detail.CurrentYield > 0 {
+				yields[symbol] = detail.CurrentYield
+			}
 			// Create dividend history structure
 			history := models.DividendHistory{
 				Symbol:    detail.Symbol,
 				Name:      detail.Name,
-				Group:     scraper.GetYieldMaxETFGroups()[symbol],
+				Group:     groupResolver.ResolveGroup(symbol),
 				Frequency: detail.Frequency,
 				Events:    detail.DividendHistory,
-				UpdatedAt: time.Now(),
+				UpdatedAt: runClock.Now(),
 			}
 			
 			// Calculate stats
-			if len(history.Events) > 0 {
-				var totalAmount float64
-				for _, event := range history.Events {
-					totalAmount += event.Amount
-				}
-				history.Stats.TotalPayments = len(history.Events)
-				history.Stats.AverageAmount = totalAmount / float64(len(history.Events))
-				history.Stats.LastAmount = history.Events[0].Amount
+			checkWeekendExDates(&history, *fixWeekends, logger)
+			history.KeepYears(*years)
+			history.SortEventsDescending()
+			history.DetectFrequencyRegimes()
+			// An override frequency wins over the one detected from the events
+			symbolOverrides.ApplyHistory(&history)
+			history.Stats = models.CalculateStats(history.Events)
+			if *targetTracking {
+				history.Target = history.TargetTrackingAt(detail.CurrentPrice, asOf)
 			}
+			history.TrimEvents(*maxEvents)
 			
 			// Save to file
 			filename := fmt.Sprintf("dividends_%s.json", symbol)
-			if err := saveToJSON(filepath.Join(outputDir, filename), history); err != nil {
+			if written, err := export.SaveHistory(filepath.Join(*outputDir, filename), &history); err != nil {
 				logger.Errorf("Failed to save history for %s: %v", symbol, err)
-			} else {
+			} else if written {
 				logger.Infof("Real dividend history saved for %s with %d events", symbol, len(history.Events))
+			} else {
+				logger.Infof("Dividend history for %s unchanged, not rewritten", symbol)
 			}
 			
 			// Update ETF with current price and yield if available
@@ -193,43 +439,241 @@ func main() {
 				if etf.Symbol == symbol {
 					if detail.CurrentPrice > 0 {
 						// Update in the main ETF list (would need to add these fields)
-						logger.Infof("Updated %s: Price=$%.2f, Yield=%.2f%%", symbol, detail.CurrentPrice, detail.CurrentYield)
+						logger.Infof("Updated %s: Price=$%.2f, NAV=$%.2f, Premium/Discount=%.2f%%, Yield=%.2f%%",
+							symbol, detail.CurrentPrice, detail.NAV, detail.PremiumDiscountPct, detail.CurrentYield)
 					}
-					if detail.Frequency != "" && detail.Frequency != etf.Frequency {
-						etfs[i].Frequency = detail.Frequency
-						logger.Infof("Updated %s frequency to %s", symbol, detail.Frequency)
+					if history.Frequency != "" && history.Frequency != etf.Frequency {
+						etfs[i].Frequency = history.Frequency
+						logger.Infof("Updated %s frequency to %s", symbol, history.Frequency)
+					}
+					if detail.InceptionDate != nil {
+						etfs[i].InceptionDate = detail.InceptionDate
 					}
 					break
 				}
 			}
 		} else {
-			logger.Errorf("Failed to scrape details for %s: %v", symbol, err)
+			logger.Errorf("Failed to scrape details for %s: %v", symbol, result.Err)
 			// Fall back to synthetic data
 			for _, etf := range etfs {
 				if etf.Symbol == symbol {
-					history := generateEnhancedHistory(etf)
+					history := generateEnhancedHistory(etf, runClock, symbolSeed(*syntheticSeed, etf.Symbol))
+					symbolOverrides.ApplyHistory(&history)
+					history.KeepYears(*years)
+					if *targetTracking {
+						history.Target = history.TargetTrackingAt(0, asOf)
+					}
+					history.TrimEvents(*maxEvents)
 					filename := fmt.Sprintf("dividends_%s.json", etf.Symbol)
-					if err := saveToJSON(filepath.Join(outputDir, filename), history); err != nil {
+					if _, err := export.SaveHistory(filepath.Join(*outputDir, filename), &history); err != nil {
 						logger.Errorf("Failed to save synthetic history for %s: %v", etf.Symbol, err)
 					}
 					break
 				}
 			}
 		}
-		
-		// Rate limiting
-		time.Sleep(2 * time.Second)
 	}
 
-	// Generate comprehensive API summary
-	summary := generateComprehensiveAPISummary(enrichedETFs, schedule, metadataMap)
-	if err := saveToJSON(filepath.Join(outputDir, "api_summary_v3.json"), summary); err != nil {
-		logger.Errorf("Failed to save comprehensive API summary: %v", err)
+	tail.run(enrichedETFs, metadataMap, yields)
+
+	logger.Info("Enhanced crawler with Alpha Vantage integration completed successfully!")
+}
+
+// checkWeekendExDates logs, and optionally fixes, ex-dates that fall on a weekend
+func checkWeekendExDates(history *models.DividendHistory, fix bool, logger *logrus.Logger) {
+	for _, weekend := range history.CheckWeekendExDates(fix) {
+		exDate := weekend.Event.ExDate.Format("2006-01-02")
+		if weekend.Corrected.IsZero() {
+			logger.Warnf("%s ex-date %s falls on a %s (pass -fix-weekends to move it)", history.Symbol, exDate, weekend.Event.ExDate.Weekday())
+			continue
+		}
+		logger.Warnf("Moved %s ex-date %s from a %s to %s", history.Symbol, exDate, weekend.Event.ExDate.Weekday(), weekend.Corrected.Format("2006-01-02"))
+	}
+}
+
+// writeScheduleOutputs writes schedule_v3.json, the per-group schedule files
+// when groupSchedules is set and the today/tomorrow ex-date lists as of clk
+func writeScheduleOutputs(outputDir string, schedule *models.Schedule, groupSchedules bool, clk clock.Clock, logger *logrus.Logger) {
+	if err := export.SaveJSON(filepath.Join(outputDir, export.ScheduleFileName), schedule); err != nil {
+		logger.Errorf("Failed to save improved schedule: %v", err)
 	} else {
-		logger.Info("Comprehensive API summary saved")
+		logger.Info("Improved schedule saved to schedule_v3.json")
 	}
 
-	logger.Info("Enhanced crawler with Alpha Vantage integration completed successfully!")
+	if groupSchedules {
+		if paths, err := export.SaveGroupSchedules(outputDir, schedule); err != nil {
+			logger.Errorf("Failed to save group schedules: %v", err)
+		} else {
+			logger.Infof("Saved %d group schedule files", len(paths))
+		}
+	}
+
+	if err := export.SaveExDateLists(outputDir, schedule, clk); err != nil {
+		logger.Errorf("Failed to save ex-date lists: %v", err)
+	} else {
+		logger.Infof("Saved %s and %s", export.TodayExFileName, export.TomorrowExFileName)
+	}
+}
+
+// buildETFList gets the comprehensive ETF list, falling back to the basic
+// list, with discovered funds appended and overrides applied
+func buildETFList(ys *scraper.ImprovedYieldMaxScraper, discovered []string, groups *scraper.GroupResolver, symbolOverrides overrides.Overrides, logger *logrus.Logger) []models.ETF {
+	logger.Info("Getting comprehensive ETF list...")
+	etfs, err := ys.GetImprovedETFList()
+	if err != nil {
+		logger.Errorf("Failed to get ETF list: %v", err)
+		// Fallback to basic ETF generation if scraping fails
+		etfs = generateBasicETFList(groups)
+		logger.Infof("Using fallback ETF list with %d ETFs", len(etfs))
+	} else {
+		logger.Infof("Successfully retrieved %d ETFs", len(etfs))
+	}
+
+	etfs = appendDiscoveredETFs(etfs, discovered, groups)
+
+	// Hand-maintained overrides win over scraped and guessed values
+	if applied := symbolOverrides.ApplyETFs(etfs); applied > 0 {
+		logger.Infof("Applied overrides to %d ETFs", applied)
+	}
+	return etfs
+}
+
+// saveETFList writes etfs.json, part of the summary output
+func saveETFList(outputDir string, etfs []models.ETF, logger *logrus.Logger) {
+	if err := export.SaveJSON(filepath.Join(outputDir, "etfs.json"), etfs); err != nil {
+		logger.Errorf("Failed to save ETF list: %v", err)
+	} else {
+		logger.Info("ETF list saved to etfs.json")
+	}
+}
+
+// refreshUpcoming ends an -only-upcoming run after the schedule outputs are
+// written: it saves the ETF list and the index, leaving histories, metadata
+// and the aggregates built from them as the last full run wrote them
+func refreshUpcoming(outputDir string, outputs outputSet, etfs []models.ETF, gzip bool, logger *logrus.Logger) {
+	if outputs.has(outputSummary) {
+		saveETFList(outputDir, etfs, logger)
+	}
+	writeIndex(outputDir, logger)
+	finishOutput(outputDir, gzip, logger)
+}
+
+// writeRotationCalendar writes rotation_calendar.json for the weeks from
+// clk's time, ordering groups by the schedule and the saved histories
+func writeRotationCalendar(outputDir string, schedule *models.Schedule, histories []models.DividendHistory, clk clock.Clock, weeks int, logger *logrus.Logger) {
+	rotationEvents := export.RotationEvents(schedule, histories)
+	if err := export.SaveRotationCalendar(outputDir, rotationEvents, clk, weeks); err != nil {
+		logger.Errorf("Failed to save rotation calendar: %v", err)
+	} else {
+		logger.Infof("Rotation calendar saved to %s", export.RotationCalendarFileName)
+	}
+}
+
+// writeIndex writes index.json listing the files in outputDir; it runs after
+// the data files are written so the index reflects everything the run produced
+func writeIndex(outputDir string, logger *logrus.Logger) {
+	count, err := export.SaveIndex(outputDir)
+	if err != nil {
+		logger.Errorf("Failed to save file index: %v", err)
+		return
+	}
+	logger.Infof("File index saved to %s (%d files)", filepath.Join(outputDir, export.IndexFileName), count)
+}
+
+// checkEnrichmentKey verifies, when require is set, that apiKey is set and
+// accepted by Alpha Vantage, telling a rejected key apart from an
+// unreachable API. Without require it never fails. The test lookup is cached
+// under cacheDir.
+func checkEnrichmentKey(require bool, apiKey string, ttls cache.TTLs, cacheDir string) error {
+	if !require {
+		return nil
+	}
+	client := api.NewAlphaVantageClient(api.ParseAPIKeys(apiKey)...)
+	if err := client.KeyError(); err != nil {
+		return fmt.Errorf("ALPHA_VANTAGE_API_KEY is not usable: %w", err)
+	}
+	err := client.WithCacheDir(cacheDir).WithTTL(ttls.Metadata).TestConnection()
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, api.ErrInvalidAPIKey):
+		return fmt.Errorf("ALPHA_VANTAGE_API_KEY was rejected: %w", err)
+	case errors.Is(err, api.ErrNetwork):
+		return fmt.Errorf("Alpha Vantage is unreachable: %w", err)
+	}
+	return err
+}
+
+// pruneStaleFiles removes the per-symbol files in outputDir that no run has
+// rewritten within olderThan, except those of etfs; the rest belong to
+// delisted tickers. 0 keeps them all.
+func pruneStaleFiles(outputDir string, olderThan time.Duration, etfs []models.ETF, logger *logrus.Logger) {
+	if olderThan <= 0 {
+		return
+	}
+	symbols := make([]string, len(etfs))
+	for i, etf := range etfs {
+		symbols[i] = etf.Symbol
+	}
+	removed, err := export.PruneStaleSymbolFiles(outputDir, olderThan, time.Now(), symbols)
+	if err != nil {
+		logger.Errorf("Failed to prune stale files: %v", err)
+	}
+	for _, path := range removed {
+		logger.Infof("Pruned stale file %s", path)
+	}
+}
+
+// finishOutput writes gzip copies of the data files when gzip is set and
+// logs the total size of the output; it runs after writeIndex
+func finishOutput(outputDir string, gzip bool, logger *logrus.Logger) {
+	if gzip {
+		if count, err := export.WriteGzipCopies(outputDir); err != nil {
+			logger.Errorf("Failed to write gzip copies: %v", err)
+		} else {
+			logger.Infof("Wrote %d gzip copies", count)
+		}
+	}
+	if report, err := export.MeasureOutput(outputDir); err != nil {
+		logger.Errorf("Failed to measure output: %v", err)
+	} else {
+		logger.Infof("Output size of %s: %s", outputDir, report)
+	}
+}
+
+// writeCombinedDividends writes all_dividends.json from the saved histories
+func writeCombinedDividends(outputDir string, slim bool, logger *logrus.Logger) {
+	count, err := export.SaveCombinedDividends(outputDir, slim)
+	if err != nil {
+		logger.Errorf("Failed to save combined dividends: %v", err)
+		return
+	}
+	logger.Infof("Combined dividends saved to %s (%d ETFs)", filepath.Join(outputDir, export.CombinedDividendsFileName), count)
+}
+
+// writeTotalsByYear writes totals_by_year.json from the saved histories
+func writeTotalsByYear(outputDir string, logger *logrus.Logger) {
+	count, err := export.SaveTotalsByYear(outputDir)
+	if err != nil {
+		logger.Errorf("Failed to save calendar totals: %v", err)
+		return
+	}
+	logger.Infof("Calendar totals saved to %s (%d ETFs)", filepath.Join(outputDir, export.TotalsByYearFileName), count)
+}
+
+// exportSheetsTSV writes the per-ETF TSV summary from the saved histories
+func exportSheetsTSV(tsvPath, outputDir string, schedule *models.Schedule, logger *logrus.Logger) {
+	histories, err := export.LoadHistories(outputDir)
+	if err != nil {
+		logger.Errorf("Failed to load histories for TSV export: %v", err)
+		return
+	}
+
+	if err := export.NewSheetsExporter(histories, schedule).Save(tsvPath); err != nil {
+		logger.Errorf("Failed to save TSV summary: %v", err)
+	} else {
+		logger.Infof("TSV summary saved to %s (%d ETFs)", tsvPath, len(histories))
+	}
 }
 
 // getTopETFs returns the most important YieldMax ETFs for metadata enrichment
@@ -311,27 +755,55 @@ func enrichETFsWithMetadata(etfs []models.ETF, metadataMap map[string]*models.ET
 	return enrichedETFs
 }
 
-// saveToJSON saves data to a JSON file with proper formatting
-func saveToJSON(filename string, data interface{}) error {
-	file, err := os.Create(filename)
+// loadSavedHistory reads a previously saved history, returning nil when there is none
+func loadSavedHistory(filename string) *models.DividendHistory {
+	data, err := os.ReadFile(filename)
 	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", filename, err)
+		return nil
 	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
+	var history models.DividendHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil
+	}
+	return &history
+}
 
-	if err := encoder.Encode(data); err != nil {
-		return fmt.Errorf("failed to encode JSON: %w", err)
+// appendDiscoveredETFs adds discovered symbols that are not yet in etfs,
+// grouped by groups
+func appendDiscoveredETFs(etfs []models.ETF, discovered []string, groups *scraper.GroupResolver) []models.ETF {
+	known := make(map[string]bool, len(etfs))
+	for _, etf := range etfs {
+		known[etf.Symbol] = true
+	}
+	for _, symbol := range discovered {
+		if known[symbol] {
+			continue
+		}
+		etfs = append(etfs, models.ETF{
+			Symbol:      symbol,
+			Name:        fmt.Sprintf("YieldMax %s ETF", symbol),
+			Group:       groups.ResolveGroup(symbol),
+			Description: "Discovered on the YieldMax ETF listing",
+			Active:      true,
+		})
 	}
+	return etfs
+}
 
-	return nil
+// symbolSeed derives a symbol's synthetic-history seed from the run's seed, so
+// symbols get different amounts that are still the same on every rerun
+func symbolSeed(seed int64, symbol string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(symbol))
+	return seed + int64(h.Sum64())
 }
 
-// generateEnhancedHistory creates more realistic dividend history data
-func generateEnhancedHistory(etf models.ETF) models.DividendHistory {
-	now := time.Now()
+// generateEnhancedHistory creates more realistic dividend history data. The
+// output depends only on the clock's time and the seed, and every event is
+// marked Estimated since none of it was scraped.
+func generateEnhancedHistory(etf models.ETF, clk clock.Clock, seed int64) models.DividendHistory {
+	now := clk.Now()
+	rng := rand.New(rand.NewSource(seed))
 	var events []models.DividendEvent
 
 	// Generate different patterns based on ETF group
@@ -365,7 +837,7 @@ func generateEnhancedHistory(etf models.ETF) models.DividendHistory {
 		eventDate := now.AddDate(0, 0, -weeksBack*7)
 
 		// Add some randomness to amounts
-		amountVariation := (float64(i%5) - 2) * volatility * baseAmount / 2
+		amountVariation := (rng.Float64()*2 - 1) * volatility * baseAmount
 		amount := baseAmount + amountVariation
 		if amount < 0.05 {
 			amount = 0.05 // Minimum amount
@@ -376,50 +848,17 @@ func generateEnhancedHistory(etf models.ETF) models.DividendHistory {
 			ExDate:      eventDate.AddDate(0, 0, -2), // Ex-date 2 days before
 			PayDate:     eventDate,                   // Pay date
 			DeclareDate: eventDate.AddDate(0, 0, -5), // Declare date 5 days before
-			Amount:      amount,
+			Amount:      models.RoundAmount(amount),
 			Group:       etf.Group,
 			Frequency:   etf.Frequency,
+			Estimated:   true,
+			Source:      models.SourceSynthetic,
 		}
 
 		events = append(events, event)
 	}
 
-	// Calculate enhanced stats
-	var totalAmount float64
-	var ytdTotal float64
-	yearStart := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
-
-	for _, event := range events {
-		totalAmount += event.Amount
-		if event.PayDate.After(yearStart) {
-			ytdTotal += event.Amount
-		}
-	}
-
-	avgAmount := totalAmount / float64(len(events))
-	lastAmount := 0.0
-	prevAmount := 0.0
-
-	if len(events) > 0 {
-		lastAmount = events[0].Amount
-	}
-	if len(events) > 1 {
-		prevAmount = events[1].Amount
-	}
-
-	changePercent := 0.0
-	if prevAmount > 0 {
-		changePercent = ((lastAmount - prevAmount) / prevAmount) * 100
-	}
-
-	stats := models.DividendStats{
-		TotalPayments:     len(events),
-		AverageAmount:     avgAmount,
-		LastAmount:        lastAmount,
-		YearToDateTotal:   ytdTotal,
-		TrailingYearTotal: totalAmount,
-		ChangePercent:     changePercent,
-	}
+	models.SortEventsDescending(events)
 
 	return models.DividendHistory{
 		Symbol:    etf.Symbol,
@@ -427,61 +866,61 @@ func generateEnhancedHistory(etf models.ETF) models.DividendHistory {
 		Group:     etf.Group,
 		Frequency: etf.Frequency,
 		Events:    events,
-		Stats:     stats,
+		Stats:     models.CalculateStatsAt(events, now),
 		UpdatedAt: now,
 	}
 }
 
-// generateBasicETFList generates a basic list of ETFs as fallback
-func generateBasicETFList() []models.ETF {
+// generateBasicETFList generates a basic list of ETFs as fallback, grouped
+// through groups like the scraped list
+func generateBasicETFList(groups *scraper.GroupResolver) []models.ETF {
 	// Basic ETF data for fallback
 	basicETFs := []struct {
 		Symbol    string
 		Name      string
-		Group     string
 		Frequency string
 	}{
 		// Target 12 ETFs
-		{"BIGY", "YieldMax Big Tech Target 12 ETF", "Target12", "monthly"},
-		{"SOXY", "YieldMax Semiconductor Sector Target 12 ETF", "Target12", "monthly"},
-		{"RNTY", "YieldMax Tech Innovators Target 12 ETF", "Target12", "monthly"},
-		{"KLIP", "YieldMax ESG Target 12 ETF", "Target12", "monthly"},
-		{"ALTY", "YieldMax Alternative Energy Target 12 ETF", "Target12", "monthly"},
+		{"BIGY", "YieldMax Big Tech Target 12 ETF", "monthly"},
+		{"SOXY", "YieldMax Semiconductor Sector Target 12 ETF", "monthly"},
+		{"RNTY", "YieldMax Tech Innovators Target 12 ETF", "monthly"},
+		{"KLIP", "YieldMax ESG Target 12 ETF", "monthly"},
+		{"ALTY", "YieldMax Alternative Energy Target 12 ETF", "monthly"},
 
 		// Weekly Payers
-		{"CHPY", "YieldMax Healthcare Weekly Payer ETF", "Weekly", "weekly"},
-		{"GPTY", "YieldMax Gaming & Entertainment Weekly ETF", "Weekly", "weekly"},
-		{"LFGY", "YieldMax Large Cap Growth Weekly ETF", "Weekly", "weekly"},
-		{"QDTY", "YieldMax Quality Dividend Weekly ETF", "Weekly", "weekly"},
-		{"RDTY", "YieldMax Real Estate Weekly ETF", "Weekly", "weekly"},
-		{"SDTY", "YieldMax Small Cap Dividend Weekly ETF", "Weekly", "weekly"},
-		{"ULTY", "YieldMax Utilities Weekly ETF", "Weekly", "weekly"},
-		{"YMAG", "YieldMax Magnificent 7 Weekly ETF", "Weekly", "weekly"},
-		{"YMAX", "YieldMax Universe Weekly ETF", "Weekly", "weekly"},
+		{"CHPY", "YieldMax Healthcare Weekly Payer ETF", "weekly"},
+		{"GPTY", "YieldMax Gaming & Entertainment Weekly ETF", "weekly"},
+		{"LFGY", "YieldMax Large Cap Growth Weekly ETF", "weekly"},
+		{"QDTY", "YieldMax Quality Dividend Weekly ETF", "weekly"},
+		{"RDTY", "YieldMax Real Estate Weekly ETF", "weekly"},
+		{"SDTY", "YieldMax Small Cap Dividend Weekly ETF", "weekly"},
+		{"ULTY", "YieldMax Utilities Weekly ETF", "weekly"},
+		{"YMAG", "YieldMax Magnificent 7 Weekly ETF", "weekly"},
+		{"YMAX", "YieldMax Universe Weekly ETF", "weekly"},
 
 		// Group A ETFs
-		{"TSLY", "YieldMax TSLA Option Income Strategy ETF", "GroupA", "weekly"},
-		{"NVDY", "YieldMax NVDA Option Income Strategy ETF", "GroupA", "weekly"},
-		{"MSTY", "YieldMax MSTR Option Income Strategy ETF", "GroupA", "weekly"},
-		{"OARK", "YieldMax ARKK Option Income Strategy ETF", "GroupA", "weekly"},
-		{"APLY", "YieldMax AAPL Option Income Strategy ETF", "GroupA", "weekly"},
+		{"TSLY", "YieldMax TSLA Option Income Strategy ETF", "weekly"},
+		{"NVDY", "YieldMax NVDA Option Income Strategy ETF", "weekly"},
+		{"MSTY", "YieldMax MSTR Option Income Strategy ETF", "weekly"},
+		{"OARK", "YieldMax ARKK Option Income Strategy ETF", "weekly"},
+		{"APLY", "YieldMax AAPL Option Income Strategy ETF", "weekly"},
 
 		// Group B ETFs
-		{"AMZY", "YieldMax AMZN Option Income Strategy ETF", "GroupB", "weekly"},
-		{"CONY", "YieldMax COIN Option Income Strategy ETF", "GroupB", "weekly"},
-		{"FBY", "YieldMax META Option Income Strategy ETF", "GroupB", "weekly"},
-		{"NFLY", "YieldMax NFLX Option Income Strategy ETF", "GroupB", "weekly"},
-		{"MSFO", "YieldMax MSFT Option Income Strategy ETF", "GroupB", "weekly"},
+		{"AMZY", "YieldMax AMZN Option Income Strategy ETF", "weekly"},
+		{"FBY", "YieldMax META Option Income Strategy ETF", "weekly"},
+		{"NFLY", "YieldMax NFLX Option Income Strategy ETF", "weekly"},
+		{"MSFO", "YieldMax MSFT Option Income Strategy ETF", "weekly"},
 
 		// Group C ETFs
-		{"PLTY", "YieldMax PLTR Option Income Strategy ETF", "GroupC", "weekly"},
-		{"SPYY", "YieldMax S&P 500 Option Income ETF", "GroupC", "weekly"},
-		{"INTY", "YieldMax INTC Option Income Strategy ETF", "GroupC", "weekly"},
+		{"CONY", "YieldMax COIN Option Income Strategy ETF", "weekly"},
+		{"PLTY", "YieldMax PLTR Option Income Strategy ETF", "weekly"},
+		{"SPYY", "YieldMax S&P 500 Option Income ETF", "weekly"},
+		{"INTY", "YieldMax INTC Option Income Strategy ETF", "weekly"},
 
 		// Group D ETFs
-		{"GDXY", "YieldMax Gold Miners Option Income ETF", "GroupD", "weekly"},
-		{"LCID", "YieldMax LCID Option Income Strategy ETF", "GroupD", "weekly"},
-		{"PEY", "YieldMax PEP Option Income Strategy ETF", "GroupD", "weekly"},
+		{"GDXY", "YieldMax Gold Miners Option Income ETF", "weekly"},
+		{"LCID", "YieldMax LCID Option Income Strategy ETF", "weekly"},
+		{"PEY", "YieldMax PEP Option Income Strategy ETF", "weekly"},
 	}
 
 	var etfs []models.ETF
@@ -489,75 +928,13 @@ func generateBasicETFList() []models.ETF {
 		etf := models.ETF{
 			Symbol:      data.Symbol,
 			Name:        data.Name,
-			Group:       data.Group,
+			Group:       groups.ResolveGroup(data.Symbol),
 			Frequency:   data.Frequency,
 			Description: "Option income strategy ETF",
+			Active:      true,
 		}
 		etfs = append(etfs, etf)
 	}
 
 	return etfs
 }
-
-// generateComprehensiveAPISummary creates a comprehensive API summary
-func generateComprehensiveAPISummary(etfs []models.ETF, schedule *models.Schedule, metadataMap map[string]*models.ETFMetadata) models.APIResponse {
-	// Count ETFs by group
-	groupCounts := make(map[string]int)
-	for _, etf := range etfs {
-		groupCounts[etf.Group]++
-	}
-
-	summary := map[string]interface{}{
-		"totalETFs": len(etfs),
-		"groups": map[string]interface{}{
-			"GroupA":   groupCounts["GroupA"],
-			"GroupB":   groupCounts["GroupB"],
-			"GroupC":   groupCounts["GroupC"],
-			"GroupD":   groupCounts["GroupD"],
-			"Weekly":   groupCounts["Weekly"],
-			"Target12": groupCounts["Target12"],
-		},
-		"endpoints": map[string]string{
-			"etfs":          "/etfs.json",
-			"etfs_enriched": "/etfs_enriched.json",
-			"schedule":      "/schedule_v3.json",
-			"history":       "/dividends_{SYMBOL}.json",
-			"metadata":      "/etf_metadata.json",
-			"api_info":      "/api_summary_v3.json",
-		},
-		"features": []string{
-			"Real-time YieldMax schedule scraping",
-			"Enhanced ETF group mapping",
-			"Alpha Vantage metadata integration",
-			"Comprehensive dividend history",
-			"Upcoming events prediction",
-			"Rate-limited API calls",
-			"JSON API for mobile apps",
-		},
-		"dataSources": []string{
-			"YieldMax official website",
-			"Alpha Vantage API",
-		},
-		"updateFrequency": "Daily at 00:05 KST",
-		"lastUpdated":     time.Now().Format(time.RFC3339),
-		"version":         "3.0.0",
-		"status":          "operational",
-	}
-
-	if schedule != nil {
-		summary["nextUpdate"] = schedule.UpdatedAt.Add(24 * time.Hour).Format(time.RFC3339)
-		summary["upcomingEvents"] = len(schedule.Upcoming)
-		summary["totalGroups"] = len(schedule.Groups)
-	}
-
-	if metadataMap != nil {
-		summary["enrichedETFs"] = len(metadataMap)
-		summary["metadataSource"] = "Alpha Vantage"
-	}
-
-	return models.APIResponse{
-		Success:   true,
-		Data:      summary,
-		Timestamp: time.Now(),
-	}
-}
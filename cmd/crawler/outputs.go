@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Artifacts the crawler can be told to produce with -outputs
+const (
+	outputSchedule  = "schedule"  // schedule_v3.json, group schedules, today/tomorrow ex-date lists and the rotation calendar
+	outputSummary   = "summary"   // etfs.json and api_summary_v3.json
+	outputHistories = "histories" // dividends_{SYMBOL}.json and the combined files; drives the detail scrape
+	outputEnriched  = "enriched"  // etf_metadata.json and etfs_enriched.json; drives the Alpha Vantage calls
+)
+
+// allOutputs lists every artifact, in the order the crawler produces them
+var allOutputs = []string{outputSchedule, outputSummary, outputHistories, outputEnriched}
+
+// outputSet is the set of artifacts a run produces
+type outputSet map[string]bool
+
+// parseOutputs parses a comma-separated -outputs value. An empty value or
+// "all" selects every artifact.
+func parseOutputs(value string) (outputSet, error) {
+	set := make(outputSet)
+	value = strings.TrimSpace(value)
+	if value == "" || value == "all" {
+		for _, name := range allOutputs {
+			set[name] = true
+		}
+		return set, nil
+	}
+
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !isOutput(name) {
+			return nil, fmt.Errorf("unknown output %q (want %s)", name, strings.Join(allOutputs, ", "))
+		}
+		set[name] = true
+	}
+	if len(set) == 0 {
+		return nil, fmt.Errorf("no outputs selected")
+	}
+	return set, nil
+}
+
+func isOutput(name string) bool {
+	for _, known := range allOutputs {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+// has reports whether the run produces the named artifact
+func (s outputSet) has(name string) bool {
+	return s[name]
+}
+
+// String lists the selected artifacts for logs
+func (s outputSet) String() string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+	"divminder-crawler/internal/scraper"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestParseOutputs(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{value: "", want: allOutputs},
+		{value: "all", want: allOutputs},
+		{value: "schedule, Summary", want: []string{outputSchedule, outputSummary}},
+		{value: "histories,", want: []string{outputHistories}},
+		{value: "schedule,charts", wantErr: true},
+		{value: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			set, err := parseOutputs(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseOutputs(%q) = %v, want an error", tt.value, set)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseOutputs(%q): %v", tt.value, err)
+			}
+			if len(set) != len(tt.want) {
+				t.Errorf("parseOutputs(%q) = %v, want %v", tt.value, set, tt.want)
+			}
+			for _, name := range tt.want {
+				if !set.has(name) {
+					t.Errorf("parseOutputs(%q) = %v, missing %s", tt.value, set, name)
+				}
+			}
+		})
+	}
+}
+
+func TestCrawlTailWritesOnlySelectedOutputs(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	last := time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC)
+	etfs := []models.ETF{
+		{Symbol: "TSLY", Group: "GroupA", Frequency: "monthly"},
+		{Symbol: "GONE", Group: "GroupB", Frequency: "monthly"},
+	}
+
+	tests := []struct {
+		outputs string
+		written []string
+		skipped []string
+	}{
+		{
+			outputs: "histories",
+			written: []string{export.CombinedDividendsFileName, export.TotalsByYearFileName, export.IndexFileName},
+			skipped: []string{"etfs.json", "api_summary_v3.json"},
+		},
+		{
+			outputs: "summary",
+			written: []string{"etfs.json", "api_summary_v3.json", export.IndexFileName},
+			skipped: []string{export.CombinedDividendsFileName, export.TotalsByYearFileName},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.outputs, func(t *testing.T) {
+			dir := t.TempDir()
+			saveTestHistory(t, dir, "TSLY", monthlyEvents("TSLY", last, 0.4, 0.5))
+			outputs, err := parseOutputs(tt.outputs)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// GONE 404s during this run's detail scrape, after the list was built
+			tracker := scraper.NewSymbolTracker(filepath.Join(dir, "symbol_status.json"), 1)
+			tracker.RecordResult("GONE", scraper.ErrNotFound, last)
+
+			tail := &crawlTail{
+				outputDir: dir,
+				outputs:   outputs,
+				clock:     clock.NewFake(time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)),
+				symbols:   tracker,
+				logger:    logger,
+			}
+			tail.run(append([]models.ETF(nil), etfs...), nil, nil)
+
+			for _, name := range tt.written {
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Errorf("%s not written: %v", name, err)
+				}
+			}
+			for _, name := range tt.skipped {
+				if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+					t.Errorf("%s written without being selected (err %v)", name, err)
+				}
+			}
+
+			if !outputs.has(outputSummary) {
+				return
+			}
+			data, err := os.ReadFile(filepath.Join(dir, "etfs.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var saved []models.ETF
+			if err := json.Unmarshal(data, &saved); err != nil {
+				t.Fatal(err)
+			}
+			if len(saved) != 2 || !saved[0].Active || saved[1].Active {
+				t.Errorf("etfs.json = %+v, want TSLY active and GONE inactive", saved)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"time"
+
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+	"divminder-crawler/internal/scraper"
+)
+
+// apiSummary is the api_summary_v3.json payload. It is a struct rather than
+// a map so its fields always serialize in the same order.
+type apiSummary struct {
+	TotalETFs       int                `json:"totalETFs"`
+	Groups          groupCounts        `json:"groups"`
+	Endpoints       apiEndpoints       `json:"endpoints"`
+	Features        []string           `json:"features"`
+	DataSources     []string           `json:"dataSources"`
+	UpdateFrequency string             `json:"updateFrequency"`
+	LastUpdated     string             `json:"lastUpdated"`
+	Version         string             `json:"version"`
+	Status          string             `json:"status"`
+	NextUpdate      string             `json:"nextUpdate,omitempty"`     // Set with a schedule
+	UpcomingEvents  *int               `json:"upcomingEvents,omitempty"` // Set with a schedule
+	TotalGroups     *int               `json:"totalGroups,omitempty"`    // Set with a schedule
+	EnrichedETFs    *int               `json:"enrichedETFs,omitempty"`   // Set with metadata
+	MetadataSource  string             `json:"metadataSource,omitempty"` // Set with metadata
+	Aggregates      *export.Aggregates `json:"aggregates,omitempty"`
+}
+
+// groupCounts is the number of ETFs in each group
+type groupCounts struct {
+	GroupA   int `json:"GroupA"`
+	GroupB   int `json:"GroupB"`
+	GroupC   int `json:"GroupC"`
+	GroupD   int `json:"GroupD"`
+	Weekly   int `json:"Weekly"`
+	Target12 int `json:"Target12"`
+	Unknown  int `json:"Unknown"`
+}
+
+// apiEndpoints lists the published files relative to the site root
+type apiEndpoints struct {
+	ETFs          string `json:"etfs"`
+	ETFsEnriched  string `json:"etfs_enriched"`
+	Schedule      string `json:"schedule"`
+	GroupSchedule string `json:"group_schedule"`
+	History       string `json:"history"`
+	Metadata      string `json:"metadata"`
+	APIInfo       string `json:"api_info"`
+	Rotation      string `json:"rotation"`
+	Index         string `json:"index"`
+}
+
+// generateComprehensiveAPISummary creates a comprehensive API summary as of
+// now; aggregates is optional
+func generateComprehensiveAPISummary(etfs []models.ETF, schedule *models.Schedule, metadataMap map[string]*models.ETFMetadata, aggregates *export.Aggregates, now time.Time) models.APIResponse {
+	// Count ETFs by group
+	counts := make(map[string]int)
+	for _, etf := range etfs {
+		counts[etf.Group]++
+	}
+
+	summary := apiSummary{
+		TotalETFs: len(etfs),
+		Groups: groupCounts{
+			GroupA:   counts["GroupA"],
+			GroupB:   counts["GroupB"],
+			GroupC:   counts["GroupC"],
+			GroupD:   counts["GroupD"],
+			Weekly:   counts["Weekly"],
+			Target12: counts["Target12"],
+			Unknown:  counts[scraper.UnknownGroup],
+		},
+		Endpoints: apiEndpoints{
+			ETFs:          "/etfs.json",
+			ETFsEnriched:  "/etfs_enriched.json",
+			Schedule:      "/schedule_v3.json",
+			GroupSchedule: "/schedule_{GROUP}.json",
+			History:       "/dividends_{SYMBOL}.json",
+			Metadata:      "/etf_metadata.json",
+			APIInfo:       "/api_summary_v3.json",
+			Rotation:      "/rotation_calendar.json",
+			Index:         "/index.json",
+		},
+		Features: []string{
+			"Real-time YieldMax schedule scraping",
+			"Enhanced ETF group mapping",
+			"Alpha Vantage metadata integration",
+			"Comprehensive dividend history",
+			"Upcoming events prediction",
+			"Rate-limited API calls",
+			"JSON API for mobile apps",
+		},
+		DataSources: []string{
+			"YieldMax official website",
+			"Alpha Vantage API",
+		},
+		UpdateFrequency: "Daily at 00:05 KST",
+		LastUpdated:     now.Format(time.RFC3339),
+		Version:         "3.0.0",
+		Status:          "operational",
+		Aggregates:      aggregates,
+	}
+
+	if schedule != nil {
+		upcoming, groups := len(schedule.Upcoming), len(schedule.Groups)
+		summary.NextUpdate = schedule.UpdatedAt.Add(24 * time.Hour).Format(time.RFC3339)
+		summary.UpcomingEvents = &upcoming
+		summary.TotalGroups = &groups
+	}
+
+	if metadataMap != nil {
+		enriched := len(metadataMap)
+		summary.EnrichedETFs = &enriched
+		summary.MetadataSource = "Alpha Vantage"
+	}
+
+	return models.APIResponse{
+		Success:   true,
+		Data:      summary,
+		Timestamp: now,
+	}
+}
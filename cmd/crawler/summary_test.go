@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+)
+
+func TestGenerateComprehensiveAPISummaryIsByteStable(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	etfs := []models.ETF{
+		{Symbol: "TSLY", Group: "GroupA"},
+		{Symbol: "NVDY", Group: "GroupB"},
+		{Symbol: "ULTY", Group: "Weekly"},
+		{Symbol: "BIGY", Group: "Target12"},
+		{Symbol: "NEWY", Group: "Unknown"},
+	}
+	schedule := &models.Schedule{
+		Groups:    []models.GroupSchedule{{Group: "GroupA"}, {Group: "Weekly"}},
+		Upcoming:  []models.DividendEvent{{Symbol: "TSLY", ExDate: now.AddDate(0, 0, 1)}},
+		UpdatedAt: now,
+	}
+	metadata := map[string]*models.ETFMetadata{"TSLY": {Symbol: "TSLY"}, "ULTY": {Symbol: "ULTY"}}
+	aggregates := &export.Aggregates{
+		YearToDateTotal:     4.2,
+		AverageYieldByGroup: map[string]float64{"Weekly": 80, "GroupA": 66.8, "GroupB": 50},
+		UpcomingNext7Days:   1,
+	}
+
+	encode := func() []byte {
+		var buf bytes.Buffer
+		if err := export.WriteJSON(&buf, generateComprehensiveAPISummary(etfs, schedule, metadata, aggregates, now)); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	first := encode()
+	for i := 0; i < 20; i++ {
+		if again := encode(); !bytes.Equal(first, again) {
+			t.Fatalf("run %d differs:\n%s\nwant\n%s", i, again, first)
+		}
+	}
+
+	// Fields come out in declaration order
+	text := string(first)
+	order := []string{`"totalETFs"`, `"groups"`, `"endpoints"`, `"features"`, `"lastUpdated"`, `"nextUpdate"`, `"enrichedETFs"`, `"aggregates"`}
+	last := -1
+	for _, key := range order {
+		i := strings.Index(text, key)
+		if i < 0 || i < last {
+			t.Fatalf("%s missing or out of order in\n%s", key, text)
+		}
+		last = i
+	}
+
+	var decoded struct {
+		Data struct {
+			Groups      map[string]int `json:"groups"`
+			LastUpdated string         `json:"lastUpdated"`
+		} `json:"data"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+	if err := json.Unmarshal(first, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Data.Groups["GroupA"] != 1 || decoded.Data.Groups["Target12"] != 1 || decoded.Data.Groups["Unknown"] != 1 {
+		t.Errorf("groups = %v", decoded.Data.Groups)
+	}
+	// Dated by the run clock, not the wall clock
+	if decoded.Data.LastUpdated != "2025-06-10T12:00:00Z" || !decoded.Timestamp.Equal(now) {
+		t.Errorf("lastUpdated %s, timestamp %v; want the run time %v", decoded.Data.LastUpdated, decoded.Timestamp, now)
+	}
+}
+
+func TestGenerateComprehensiveAPISummaryOmitsMissingInputs(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	data, err := json.Marshal(generateComprehensiveAPISummary(nil, nil, nil, nil, now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"nextUpdate", "upcomingEvents", "totalGroups", "enrichedETFs", "metadataSource", "aggregates"} {
+		if strings.Contains(string(data), `"`+key+`"`) {
+			t.Errorf("summary without a schedule, metadata or aggregates has %s: %s", key, data)
+		}
+	}
+	if !strings.Contains(string(data), `"totalETFs":0`) {
+		t.Errorf("summary %s lacks totalETFs 0", data)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+)
+
+var updateGolden = flag.Bool("update", false, "Rewrite golden files in testdata")
+
+func TestGenerateEnhancedHistoryGolden(t *testing.T) {
+	etfs := []models.ETF{
+		{Symbol: "ULTY", Name: "YieldMax Ultra Option Income Strategy ETF", Group: "Weekly", Frequency: "weekly"},
+		{Symbol: "BIGY", Name: "YieldMax Big Tech Target 12 ETF", Group: "Target12", Frequency: "monthly"},
+	}
+	clk := clock.NewFake(time.Date(2025, 6, 10, 12, 0, 0, 0, models.MarketLocation))
+
+	for _, etf := range etfs {
+		t.Run(etf.Symbol, func(t *testing.T) {
+			history := generateEnhancedHistory(etf, clk, symbolSeed(1, etf.Symbol))
+			for _, event := range history.Events {
+				if !event.Estimated || event.Source != models.SourceSynthetic {
+					t.Fatalf("event %+v not marked as an estimated synthetic event", event)
+				}
+			}
+
+			var got bytes.Buffer
+			if err := export.WriteJSON(&got, history); err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", "synthetic_"+etf.Symbol+".golden.json")
+			if *updateGolden {
+				if err := os.WriteFile(golden, got.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("history differs from %s (run with -update if the change is intended):\n%s", golden, got.String())
+			}
+		})
+	}
+}
+
+func TestSymbolSeed(t *testing.T) {
+	if symbolSeed(1, "ULTY") != symbolSeed(1, "ULTY") {
+		t.Error("symbolSeed isn't stable for the same seed and symbol")
+	}
+	if symbolSeed(1, "ULTY") == symbolSeed(1, "YMAX") {
+		t.Error("different symbols share a seed")
+	}
+	if symbolSeed(1, "ULTY") == symbolSeed(2, "ULTY") {
+		t.Error("different run seeds give a symbol the same seed")
+	}
+
+	// Same group and clock, so only the per-symbol seed tells them apart
+	clk := clock.NewFake(time.Date(2025, 6, 10, 12, 0, 0, 0, models.MarketLocation))
+	ulty := generateEnhancedHistory(models.ETF{Symbol: "ULTY", Group: "Weekly"}, clk, symbolSeed(1, "ULTY"))
+	ymax := generateEnhancedHistory(models.ETF{Symbol: "YMAX", Group: "Weekly"}, clk, symbolSeed(1, "YMAX"))
+	if ulty.Events[0].Amount == ymax.Events[0].Amount && ulty.Events[1].Amount == ymax.Events[1].Amount {
+		t.Error("symbols in the same group got identical synthetic amounts")
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"path/filepath"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+	"divminder-crawler/internal/notifier"
+	"divminder-crawler/internal/scraper"
+
+	"github.com/sirupsen/logrus"
+)
+
+// crawlTail holds what the steps that end every crawl need, whichever
+// scraper wrote the histories
+type crawlTail struct {
+	outputDir      string
+	outputs        outputSet
+	schedule       *models.Schedule // Nil when the schedule wasn't scraped
+	clock          clock.Clock
+	aliases        map[string]string
+	aliasStubs     bool
+	years          int // Years of history kept per saved history; 0 keeps all
+	maxEvents      int // Most recent events kept per saved history; 0 keeps all
+	targetTracking bool
+	pruneOlderThan time.Duration
+	slim           bool
+	rotationWeeks  int
+	tsvPath        string
+	gzip           bool
+	symbols        *scraper.SymbolTracker // Consecutive 404s per symbol, saved once the crawl is done
+	logger         *logrus.Logger
+
+	amountWatcher *notifier.AmountChangeWatcher      // Nil without -webhook-url
+	previous      map[string]*models.DividendHistory // Histories saved before the crawl, by symbol
+}
+
+// rememberHistories keeps the histories saved by the last run so run can
+// tell which distributions this crawl added. Only the amount watcher needs
+// them.
+func (t *crawlTail) rememberHistories() {
+	if t.amountWatcher == nil {
+		return
+	}
+	histories, err := export.LoadHistories(t.outputDir)
+	if err != nil {
+		t.logger.Warnf("Failed to load saved histories for amount change alerts: %v", err)
+		return
+	}
+	t.previous = make(map[string]*models.DividendHistory, len(histories))
+	for i := range histories {
+		t.previous[histories[i].Symbol] = &histories[i]
+	}
+}
+
+// run sets etfs' Active flags from the 404 state and saves it with the ETF
+// list, post-processes the saved histories and sends amount change alerts,
+// builds the aggregates, rotation calendar and API summary for etfs, then
+// writes the TSV export, index and gzip copies. metadataMap may be nil;
+// yields holds each symbol's current yield in percent.
+func (t *crawlTail) run(etfs []models.ETF, metadataMap map[string]*models.ETFMetadata, yields map[string]float64) {
+	if t.symbols != nil {
+		t.symbols.MarkActive(etfs)
+		if err := t.symbols.Save(); err != nil {
+			t.logger.Warnf("Failed to save symbol status: %v", err)
+		}
+	}
+	// Saved after the detail scrape so Active reflects this run's 404s
+	if t.outputs.has(outputSummary) {
+		saveETFList(t.outputDir, etfs, t.logger)
+	}
+
+	if t.outputs.has(outputHistories) {
+		pruneStaleFiles(t.outputDir, t.pruneOlderThan, etfs, t.logger)
+		t.mergeAliasedHistories()
+		writeCombinedDividends(t.outputDir, t.slim, t.logger)
+		writeTotalsByYear(t.outputDir, t.logger)
+	}
+
+	// Generate comprehensive API summary
+	var aggregates *export.Aggregates
+	if histories, err := export.LoadHistories(t.outputDir); err != nil {
+		t.logger.Warnf("Failed to load histories for summary aggregates: %v", err)
+	} else {
+		built := export.BuildAggregates(histories, t.schedule, yields, t.clock.Now())
+		aggregates = &built
+
+		if t.outputs.has(outputHistories) {
+			t.notifyAmountChanges(histories)
+		}
+
+		if t.rotationWeeks > 0 && t.outputs.has(outputSchedule) {
+			writeRotationCalendar(t.outputDir, t.schedule, histories, t.clock, t.rotationWeeks, t.logger)
+		}
+	}
+	if t.outputs.has(outputSummary) {
+		summary := generateComprehensiveAPISummary(etfs, t.schedule, metadataMap, aggregates, t.clock.Now())
+		if err := export.SaveJSON(filepath.Join(t.outputDir, "api_summary_v3.json"), summary); err != nil {
+			t.logger.Errorf("Failed to save comprehensive API summary: %v", err)
+		} else {
+			t.logger.Info("Comprehensive API summary saved")
+		}
+	}
+
+	if t.tsvPath != "" {
+		exportSheetsTSV(t.tsvPath, t.outputDir, t.schedule, t.logger)
+	}
+
+	writeIndex(t.outputDir, t.logger)
+	finishOutput(t.outputDir, t.gzip, t.logger)
+}
+
+// notifyAmountChanges alerts on each history whose newest distribution is
+// new since rememberHistories and past the watcher's threshold
+func (t *crawlTail) notifyAmountChanges(histories []models.DividendHistory) {
+	if t.amountWatcher == nil {
+		return
+	}
+	for i := range histories {
+		history := &histories[i]
+		if sent, err := t.amountWatcher.Check(t.previous[history.Symbol], history); err != nil {
+			t.logger.Warnf("Amount change alert failed: %v", err)
+		} else if sent {
+			t.logger.Infof("Sent amount change alert for %s", history.Symbol)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/overrides"
+	"divminder-crawler/internal/scraper"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestOnlyUpcomingWritesNoHistories(t *testing.T) {
+	page, err := os.ReadFile("../../internal/scraper/testdata/schedule_page.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/distribution-schedule/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	last := time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC)
+	saveTestHistory(t, dir, "TSLY", monthlyEvents("TSLY", last, 0.4, 0.5))
+	historyPath := filepath.Join(dir, "dividends_TSLY.json")
+	before, err := os.ReadFile(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	clk := clock.NewFake(time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC))
+	outputs, err := parseOutputs("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The steps main runs with -only-upcoming
+	groupsFile := filepath.Join(t.TempDir(), "groups.json")
+	ys := scraper.NewImprovedYieldMaxScraper().WithLimitRule(1, 0)
+	ys.SetBaseURL(server.URL)
+	ys.SetGroupsFile(groupsFile)
+	ys.SetClock(clk)
+	schedule, err := ys.GetScheduleImproved()
+	if err != nil {
+		t.Fatalf("GetScheduleImproved: %v", err)
+	}
+	writeScheduleOutputs(dir, schedule, true, clk, logger)
+	resolver := scraper.NewGroupResolverFromFile(overrides.Overrides{}, groupsFile)
+	etfs := buildETFList(ys, nil, resolver, overrides.Overrides{}, logger)
+	refreshUpcoming(dir, outputs, etfs, false, logger)
+
+	for _, name := range []string{export.ScheduleFileName, "etfs.json", export.IndexFileName} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s not written: %v", name, err)
+		}
+	}
+
+	histories, err := filepath.Glob(filepath.Join(dir, "dividends_*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) != 1 || histories[0] != historyPath {
+		t.Errorf("history files = %v, want only the existing %s", histories, historyPath)
+	}
+	after, err := os.ReadFile(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("existing history was rewritten")
+	}
+	for _, name := range []string{export.CombinedDividendsFileName, "api_summary_v3.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s written in -only-upcoming mode (err %v)", name, err)
+		}
+	}
+}
+
+func TestGenerateBasicETFListResolvesGroups(t *testing.T) {
+	groupsFile := filepath.Join(t.TempDir(), "groups.json")
+	if err := scraper.SaveScrapedETFGroups(groupsFile, map[string]string{"NVDY": "GroupB"}); err != nil {
+		t.Fatal(err)
+	}
+	resolver := scraper.NewGroupResolverFromFile(overrides.Overrides{"TSLY": {Group: "GroupD"}}, groupsFile)
+
+	want := map[string]string{
+		"TSLY": "GroupD",                               // override
+		"NVDY": "GroupB",                               // scraped mapping
+		"CONY": scraper.GetYieldMaxETFGroups()["CONY"], // static mapping
+	}
+	for _, etf := range generateBasicETFList(resolver) {
+		if group, ok := want[etf.Symbol]; ok && etf.Group != group {
+			t.Errorf("%s group = %q, want %q", etf.Symbol, etf.Group, group)
+		}
+		if etf.Group == "" {
+			t.Errorf("%s has no group", etf.Symbol)
+		}
+	}
+}
@@ -1,33 +1,70 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"divminder-crawler/internal/cache"
+	"divminder-crawler/internal/httpx"
+	"divminder-crawler/internal/metrics"
 	"divminder-crawler/internal/models"
 
 	"github.com/sirupsen/logrus"
 )
 
+// DefaultMetadataCacheDir is where the client caches ETF metadata unless
+// WithCacheDir or WithCache says otherwise
+const DefaultMetadataCacheDir = "cache"
+
 // AlphaVantageClient handles Alpha Vantage API requests with caching
 type AlphaVantageClient struct {
-	apiKey      string
+	keys        []*apiKeyState
+	currentKey  int
+	keyMu       sync.Mutex
 	baseURL     string
-	httpClient  *http.Client
+	httpClient  *httpx.Client
 	logger      *logrus.Logger
+	cache       *cache.ETFMetadataCache // Opened on first use, see metadataCache
+	cacheMu     sync.Mutex
+	cacheDir    string
+	cacheTTL    time.Duration
+	bypassCache bool  // Skip cache reads (writes still happen) to force fresh data
+	keyErr      error // Why no usable key was given, or nil
+}
+
+// apiKeyState is one Alpha Vantage key with its own rate limiter
+type apiKeyState struct {
+	key         string
 	rateLimiter *RateLimiter
-	cache       *cache.ETFMetadataCache
+	exhausted   bool // Returned a rate-limit notice; skipped until every key is exhausted
+}
+
+// ParseAPIKeys splits a comma-separated list of API keys, dropping blanks
+func ParseAPIKeys(value string) []string {
+	var keys []string
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
 }
 
 // RateLimiter implements a simple rate limiter for API calls
 type RateLimiter struct {
 	tokens   chan struct{}
 	interval time.Duration
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 // NewRateLimiter creates a new rate limiter
@@ -35,6 +72,7 @@ func NewRateLimiter(maxCalls int, interval time.Duration) *RateLimiter {
 	rl := &RateLimiter{
 		tokens:   make(chan struct{}, maxCalls),
 		interval: interval,
+		stop:     make(chan struct{}),
 	}
 
 	// Fill initial tokens
@@ -47,7 +85,13 @@ func NewRateLimiter(maxCalls int, interval time.Duration) *RateLimiter {
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 
-		for range ticker.C {
+		for {
+			select {
+			case <-rl.stop:
+				return
+			case <-ticker.C:
+			}
+
 			select {
 			case rl.tokens <- struct{}{}:
 			default:
@@ -64,8 +108,16 @@ func (rl *RateLimiter) Wait() {
 	<-rl.tokens
 }
 
+// Stop ends the token refill; remaining tokens can still be used
+func (rl *RateLimiter) Stop() {
+	rl.stopOnce.Do(func() { close(rl.stop) })
+}
+
 // AlphaVantageResponse represents the API response structure
 type AlphaVantageResponse struct {
+	Note                       string `json:"Note"`          // Set instead of data when the per-minute limit is hit
+	Information                string `json:"Information"`   // Set instead of data when the daily limit is hit
+	ErrorMessage               string `json:"Error Message"` // Set instead of data when the request is rejected, e.g. for a bad key
 	Symbol                     string `json:"Symbol"`
 	AssetType                  string `json:"AssetType"`
 	Name                       string `json:"Name"`
@@ -114,72 +166,155 @@ type AlphaVantageResponse struct {
 	ExDividendDate             string `json:"ExDividendDate"`
 }
 
-// NewAlphaVantageClient creates a new Alpha Vantage API client with caching
-func NewAlphaVantageClient(apiKey string) *AlphaVantageClient {
+// rateLimited reports whether the response is a rate-limit notice rather than data
+func (r *AlphaVantageResponse) rateLimited() bool {
+	if r.Symbol != "" {
+		return false
+	}
+	message := strings.ToLower(r.rateLimitMessage())
+	return strings.Contains(message, "rate limit") || strings.Contains(message, "call frequency") ||
+		strings.Contains(message, "requests per day")
+}
+
+// invalidKey reports whether the response rejects the API key
+func (r *AlphaVantageResponse) invalidKey() bool {
+	if r.Symbol != "" {
+		return false
+	}
+	message := strings.ToLower(r.ErrorMessage + " " + r.Information)
+	return (strings.Contains(message, "apikey") || strings.Contains(message, "api key")) &&
+		(strings.Contains(message, "invalid") || strings.Contains(message, "missing"))
+}
+
+func (r *AlphaVantageResponse) rateLimitMessage() string {
+	if r.Note != "" {
+		return r.Note
+	}
+	return r.Information
+}
+
+// alphaVantageKeyLength is the length of the keys Alpha Vantage issues
+const alphaVantageKeyLength = 16
+
+// ValidateAlphaVantageKey flags keys that can't be used to enrich ETFs: the
+// public "demo" key, and keys that aren't 16 letters and digits
+func ValidateAlphaVantageKey(key string) error {
+	if strings.EqualFold(key, "demo") {
+		return ErrDemoAPIKey
+	}
+	if len(key) != alphaVantageKeyLength || strings.IndexFunc(key, func(r rune) bool {
+		return !('0' <= r && r <= '9' || 'A' <= r && r <= 'Z' || 'a' <= r && r <= 'z')
+	}) >= 0 {
+		return fmt.Errorf("%w: want %d letters and digits, got %d characters", ErrMalformedAPIKey, alphaVantageKeyLength, len(key))
+	}
+	return nil
+}
+
+// NewAlphaVantageClient creates a new Alpha Vantage API client with caching.
+// With several keys, the client rotates to the next key when one is rate limited.
+// Keys are checked with ValidateAlphaVantageKey: the demo key is dropped with
+// a warning, and malformed keys are warned about but still tried. KeyError
+// reports when no usable key is left.
+func NewAlphaVantageClient(apiKeys ...string) *AlphaVantageClient {
 	logger := logrus.New()
 	logger.SetLevel(logrus.InfoLevel)
 
-	// Rate limiter: 5 calls per minute for free tier (being conservative)
-	rateLimiter := NewRateLimiter(5, time.Minute)
+	// Rate limiter per key: 5 calls per minute for free tier (being conservative)
+	keys := make([]*apiKeyState, 0, len(apiKeys))
+	keyErr := ErrNoAPIKey
+	for i, key := range apiKeys {
+		if err := ValidateAlphaVantageKey(key); errors.Is(err, ErrDemoAPIKey) {
+			logger.Warnf("Ignoring Alpha Vantage key #%d: %v", i, err)
+			keyErr = err
+			continue
+		} else if err != nil {
+			logger.Warnf("Alpha Vantage key #%d may not work: %v", i, err)
+		}
+		keys = append(keys, &apiKeyState{key: key, rateLimiter: NewRateLimiter(5, time.Minute)})
+	}
+	if len(keys) > 0 {
+		keyErr = nil
+	} else {
+		keys = append(keys, &apiKeyState{rateLimiter: NewRateLimiter(5, time.Minute)})
+	}
 
-	// Initialize cache with 24-hour TTL
-	metadataCache := cache.NewETFMetadataCache("cache", 24*time.Hour)
+	metrics.APICurrentKey.WithLabelValues(metrics.SourceAlphaVantage).Set(0)
 
 	return &AlphaVantageClient{
-		apiKey:  apiKey,
-		baseURL: "https://www.alphavantage.co/query",
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		logger:      logger,
-		rateLimiter: rateLimiter,
-		cache:       metadataCache,
+		keys:       keys,
+		baseURL:    "https://www.alphavantage.co/query",
+		httpClient: httpx.New(30*time.Second, httpx.DefaultRetryPolicy()),
+		logger:     logger,
+		cacheDir:   DefaultMetadataCacheDir,
+		cacheTTL:   cache.DefaultTTLs().Metadata,
+		keyErr:     keyErr,
 	}
 }
 
-// GetETFOverview fetches comprehensive ETF metadata from Alpha Vantage with caching
-func (av *AlphaVantageClient) GetETFOverview(symbol string) (*models.ETFMetadata, error) {
-	// Check cache first
-	if cachedData, found, err := av.cache.GetETFMetadata(symbol); err == nil && found {
-		av.logger.Infof("Cache hit for %s metadata", symbol)
+// KeyError reports why the client has no usable key, ErrNoAPIKey or
+// ErrDemoAPIKey, or returns nil when it has one
+func (av *AlphaVantageClient) KeyError() error {
+	return av.keyErr
+}
 
-		// Convert cached data to ETFMetadata
-		if metadata, ok := cachedData.(*models.ETFMetadata); ok {
-			return metadata, nil
-		}
+// activeKey returns the key currently in use and its index
+func (av *AlphaVantageClient) activeKey() (*apiKeyState, int) {
+	av.keyMu.Lock()
+	defer av.keyMu.Unlock()
+	return av.keys[av.currentKey], av.currentKey
+}
 
-		// If type assertion fails, try JSON marshaling/unmarshaling
-		dataBytes, err := json.Marshal(cachedData)
-		if err == nil {
-			var metadata models.ETFMetadata
-			if err := json.Unmarshal(dataBytes, &metadata); err == nil {
-				return &metadata, nil
-			}
-		}
+// rotateKey marks the key at index exhausted and moves to the next unexhausted
+// key. It returns false when every key has been exhausted.
+func (av *AlphaVantageClient) rotateKey(index int) bool {
+	av.keyMu.Lock()
+	defer av.keyMu.Unlock()
 
-		av.logger.Warnf("Failed to convert cached data for %s, fetching fresh data", symbol)
+	av.keys[index].exhausted = true
+	if index != av.currentKey {
+		// Another request already rotated away from this key
+		return !av.keys[av.currentKey].exhausted
 	}
 
-	av.logger.Infof("Fetching fresh metadata for %s from Alpha Vantage", symbol)
+	for offset := 1; offset < len(av.keys); offset++ {
+		next := (index + offset) % len(av.keys)
+		if !av.keys[next].exhausted {
+			av.currentKey = next
+			metrics.APIKeyRotations.WithLabelValues(metrics.SourceAlphaVantage).Inc()
+			metrics.APICurrentKey.WithLabelValues(metrics.SourceAlphaVantage).Set(float64(next))
+			av.logger.Warnf("Alpha Vantage key #%d is rate limited, switching to key #%d", index, next)
+			return true
+		}
+	}
+
+	return false
+}
 
-	// Wait for rate limiter
-	av.rateLimiter.Wait()
+// fetchOverview makes one OVERVIEW request for symbol using key
+func (av *AlphaVantageClient) fetchOverview(ctx context.Context, symbol string, key *apiKeyState, index int) (*AlphaVantageResponse, error) {
+	// Wait for this key's rate limiter
+	key.rateLimiter.Wait()
 
 	// Build request URL
 	params := url.Values{}
 	params.Add("function", "OVERVIEW")
 	params.Add("symbol", symbol)
-	params.Add("apikey", av.apiKey)
+	params.Add("apikey", key.key)
 
 	requestURL := fmt.Sprintf("%s?%s", av.baseURL, params.Encode())
 
 	// Make HTTP request
-	resp, err := av.httpClient.Get(requestURL)
+	metrics.APICalls.WithLabelValues(metrics.SourceAlphaVantage).Inc()
+	metrics.APIKeyCalls.WithLabelValues(metrics.SourceAlphaVantage, strconv.Itoa(index)).Inc()
+	resp, err := av.httpClient.GetContext(ctx, requestURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request for %s: %w", symbol, err)
+		return nil, fmt.Errorf("failed to make request for %s: %w: %w", symbol, ErrNetwork, err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("API request failed for %s with status %d: %w", symbol, resp.StatusCode, ErrNetwork)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("API request failed for %s with status %d", symbol, resp.StatusCode)
 	}
@@ -196,14 +331,126 @@ func (av *AlphaVantageClient) GetETFOverview(symbol string) (*models.ETFMetadata
 		return nil, fmt.Errorf("failed to parse JSON response for %s: %w", symbol, err)
 	}
 
+	return &avResponse, nil
+}
+
+// WithCacheDir keeps the ETF metadata file cache under dir instead of
+// DefaultMetadataCacheDir; it replaces any cache set by WithCache
+func (av *AlphaVantageClient) WithCacheDir(dir string) *AlphaVantageClient {
+	av.cacheMu.Lock()
+	defer av.cacheMu.Unlock()
+	av.cacheDir = dir
+	av.cache = nil
+	return av
+}
+
+// WithTTL sets how long ETF metadata stays cached
+func (av *AlphaVantageClient) WithTTL(ttl time.Duration) *AlphaVantageClient {
+	av.cacheMu.Lock()
+	defer av.cacheMu.Unlock()
+	av.cacheTTL = ttl
+	av.cache = nil
+	return av
+}
+
+// WithCache stores ETF metadata in c, using its default TTL, instead of the
+// file cache; it replaces any cache set by WithTTL
+func (av *AlphaVantageClient) WithCache(c cache.Cache) *AlphaVantageClient {
+	av.cacheMu.Lock()
+	defer av.cacheMu.Unlock()
+	av.cache = cache.NewETFMetadataCacheFrom(c)
+	return av
+}
+
+// metadataCache returns the metadata cache, opening the file cache under
+// cacheDir on first use so a client that never caches creates no directory
+func (av *AlphaVantageClient) metadataCache() *cache.ETFMetadataCache {
+	av.cacheMu.Lock()
+	defer av.cacheMu.Unlock()
+	if av.cache == nil {
+		av.cache = cache.NewETFMetadataCache(av.cacheDir, av.cacheTTL)
+	}
+	return av.cache
+}
+
+// WithRetryPolicy sets how failed requests are retried
+func (av *AlphaVantageClient) WithRetryPolicy(policy httpx.RetryPolicy) *AlphaVantageClient {
+	av.httpClient.SetRetryPolicy(policy)
+	return av
+}
+
+// WithRequestTimeout bounds each API call, retries included, so a slow
+// symbol fails fast and the batch moves on; 0 leaves only the client timeout
+func (av *AlphaVantageClient) WithRequestTimeout(timeout time.Duration) *AlphaVantageClient {
+	av.httpClient.SetRequestTimeout(timeout)
+	return av
+}
+
+// SetBypassCache makes the client skip cache reads while still caching fresh responses
+func (av *AlphaVantageClient) SetBypassCache(bypass bool) {
+	av.bypassCache = bypass
+}
+
+// GetETFOverview fetches comprehensive ETF metadata from Alpha Vantage with caching
+func (av *AlphaVantageClient) GetETFOverview(symbol string) (*models.ETFMetadata, error) {
+	return av.GetETFOverviewContext(context.Background(), symbol)
+}
+
+// GetETFOverviewContext is GetETFOverview with the API request bound to ctx
+func (av *AlphaVantageClient) GetETFOverviewContext(ctx context.Context, symbol string) (metadata *models.ETFMetadata, err error) {
+	// Check cache first
+	if av.bypassCache {
+		av.logger.Debugf("Bypassing cache for %s metadata", symbol)
+	} else if cached, found, err := av.metadataCache().GetTyped(symbol); err != nil {
+		av.logger.Warnf("Failed to read cached metadata for %s, fetching fresh data: %v", symbol, err)
+	} else if found {
+		av.logger.Infof("Cache hit for %s metadata", symbol)
+		return cached, nil
+	}
+
+	av.logger.Infof("Fetching fresh metadata for %s from Alpha Vantage", symbol)
+
+	start := time.Now()
+	defer func() {
+		metrics.ObserveSince(metrics.ScrapeDuration.WithLabelValues(metrics.SourceAlphaVantage), start)
+		if err != nil {
+			metrics.ScrapeResults.WithLabelValues(metrics.SourceAlphaVantage, metrics.ResultFailure).Inc()
+		} else {
+			metrics.ScrapeResults.WithLabelValues(metrics.SourceAlphaVantage, metrics.ResultSuccess).Inc()
+		}
+	}()
+
+	// Request with the active key, rotating to the next one on a rate-limit notice
+	var avResponse *AlphaVantageResponse
+	for {
+		key, index := av.activeKey()
+		avResponse, err = av.fetchOverview(ctx, symbol, key, index)
+		if err != nil {
+			return nil, err
+		}
+		if !avResponse.rateLimited() || len(av.keys) == 1 {
+			break
+		}
+		if !av.rotateKey(index) {
+			return nil, fmt.Errorf("all %d Alpha Vantage API keys are rate limited: %s", len(av.keys), avResponse.rateLimitMessage())
+		}
+	}
+
 	// Check for API error responses
+	if avResponse.invalidKey() {
+		message := avResponse.ErrorMessage
+		if message == "" {
+			message = avResponse.Information
+		}
+		return nil, fmt.Errorf("%w for %s: %s", ErrInvalidAPIKey, symbol, message)
+	}
 	if avResponse.Symbol == "" {
 		av.logger.Warnf("No data returned for symbol %s (may not exist or API limit reached)", symbol)
 		return nil, fmt.Errorf("no data returned for symbol %s", symbol)
 	}
 
 	// Convert to our ETF metadata model
-	metadata := &models.ETFMetadata{
+	metadata = &models.ETFMetadata{
 		Symbol:        avResponse.Symbol,
 		Name:          avResponse.Name,
 		Description:   avResponse.Description,
@@ -245,7 +492,7 @@ func (av *AlphaVantageClient) GetETFOverview(symbol string) (*models.ETFMetadata
 	}
 
 	// Cache the result
-	if err := av.cache.SetETFMetadata(symbol, metadata); err != nil {
+	if err := av.metadataCache().SetETFMetadata(symbol, metadata); err != nil {
 		av.logger.Warnf("Failed to cache metadata for %s: %v", symbol, err)
 	} else {
 		av.logger.Debugf("Cached metadata for %s", symbol)
@@ -299,7 +546,9 @@ func (av *AlphaVantageClient) GetMultipleETFOverviews(symbols []string) (map[str
 	return results, nil
 }
 
-// TestConnection tests the Alpha Vantage API connection
+// TestConnection tests the Alpha Vantage API connection. A rejected key
+// returns an error wrapping ErrInvalidAPIKey and an unreachable API one
+// wrapping ErrNetwork.
 func (av *AlphaVantageClient) TestConnection() error {
 	av.logger.Info("Testing Alpha Vantage API connection...")
 
@@ -316,16 +565,31 @@ func (av *AlphaVantageClient) TestConnection() error {
 // ClearCache removes all cached metadata
 func (av *AlphaVantageClient) ClearCache() error {
 	av.logger.Info("Clearing Alpha Vantage metadata cache...")
-	return av.cache.CleanExpired()
+	return av.metadataCache().CleanExpired()
+}
+
+// Close stops every key's rate limiter and removes expired entries from the
+// cache, if the client opened one. The client must not be used afterwards.
+func (av *AlphaVantageClient) Close() error {
+	for _, key := range av.keys {
+		key.rateLimiter.Stop()
+	}
+	av.cacheMu.Lock()
+	metadataCache := av.cache
+	av.cacheMu.Unlock()
+	if metadataCache == nil {
+		return nil // Nothing was cached
+	}
+	return metadataCache.CleanExpired()
 }
 
 // GetCacheStats returns cache statistics
 func (av *AlphaVantageClient) GetCacheStats() (map[string]interface{}, error) {
-	return av.cache.GetStats()
+	return av.metadataCache().GetStats()
 }
 
 // InvalidateETFCache removes cached data for a specific ETF
 func (av *AlphaVantageClient) InvalidateETFCache(symbol string) error {
 	av.logger.Infof("Invalidating cache for %s", symbol)
-	return av.cache.InvalidateETF(symbol)
+	return av.metadataCache().InvalidateETF(symbol)
 }
@@ -0,0 +1,333 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/cache"
+	"divminder-crawler/internal/httpx"
+	"divminder-crawler/internal/metrics"
+	"divminder-crawler/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+const (
+	exhaustedKey = "EXHAUSTEDKEY0001"
+	freshKey     = "FRESHKEY00000002"
+)
+
+// keyServer answers OVERVIEW requests with the daily rate-limit notice for
+// exhaustedKey and data for any other key, recording the keys used
+func keyServer(t *testing.T) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("apikey")
+		mu.Lock()
+		keys = append(keys, key)
+		mu.Unlock()
+		if key == exhaustedKey {
+			w.Write([]byte(`{"Information":"You have reached the 25 requests per day rate limit of the free plan."}`))
+			return
+		}
+		w.Write([]byte(`{"Symbol":"` + r.URL.Query().Get("symbol") + `","Name":"Fund"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), keys...)
+	}
+}
+
+func TestGetETFOverviewRefreshBypassesCache(t *testing.T) {
+	for _, bypass := range []bool{false, true} {
+		chdirTemp(t)
+
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Write([]byte(`{"Symbol":"TSLY","Name":"Fresh Name"}`))
+		}))
+
+		av := NewAlphaVantageClient("ABCDEFGH12345678").WithCache(cache.NewFileCache(t.TempDir(), time.Hour))
+		av.baseURL = server.URL
+		av.SetBypassCache(bypass)
+		if err := av.cache.SetETFMetadata("TSLY", &models.ETFMetadata{Symbol: "TSLY", Name: "Cached Name"}); err != nil {
+			t.Fatal(err)
+		}
+
+		metadata, err := av.GetETFOverview("TSLY")
+		av.Close()
+		server.Close()
+		if err != nil {
+			t.Fatalf("bypass=%v: GetETFOverview: %v", bypass, err)
+		}
+
+		wantRequests, wantName := int32(0), "Cached Name"
+		if bypass {
+			wantRequests, wantName = 1, "Fresh Name"
+		}
+		if got := requests.Load(); got != wantRequests {
+			t.Errorf("bypass=%v: HTTP requests = %d, want %d", bypass, got, wantRequests)
+		}
+		if metadata.Name != wantName {
+			t.Errorf("bypass=%v: name = %q, want %q", bypass, metadata.Name, wantName)
+		}
+	}
+}
+
+func TestGetETFOverviewUndecodableCacheFetchesFresh(t *testing.T) {
+	chdirTemp(t)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"Symbol":"TSLY","Name":"Fresh Name"}`))
+	}))
+	defer server.Close()
+
+	av := NewAlphaVantageClient("ABCDEFGH12345678").WithCache(cache.NewFileCache(t.TempDir(), time.Hour))
+	defer av.Close()
+	av.baseURL = server.URL
+	if err := av.cache.SetETFMetadata("TSLY", "not metadata"); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := av.GetETFOverview("TSLY")
+	if err != nil {
+		t.Fatalf("GetETFOverview: %v", err)
+	}
+	if metadata.Name != "Fresh Name" || requests.Load() != 1 {
+		t.Errorf("name %q after %d requests, want Fresh Name after 1", metadata.Name, requests.Load())
+	}
+
+	// The fresh fetch replaced the bad entry
+	cached, found, err := av.cache.GetTyped("TSLY")
+	if err != nil || !found || cached.Name != "Fresh Name" {
+		t.Errorf("cached entry = %+v, %v, %v; want Fresh Name", cached, found, err)
+	}
+}
+
+func TestAlphaVantageKeyRotation(t *testing.T) {
+	chdirTemp(t)
+	server, usedKeys := keyServer(t)
+
+	av := NewAlphaVantageClient(exhaustedKey, freshKey).WithCache(cache.NewFileCache(t.TempDir(), time.Hour))
+	av.baseURL = server.URL
+	av.SetBypassCache(true)
+	defer av.Close()
+
+	for _, symbol := range []string{"TSLY", "CONY"} {
+		metadata, err := av.GetETFOverview(symbol)
+		if err != nil {
+			t.Fatalf("GetETFOverview(%s): %v", symbol, err)
+		}
+		if metadata.Symbol != symbol {
+			t.Errorf("metadata for %s has symbol %q", symbol, metadata.Symbol)
+		}
+	}
+
+	// The exhausted key is tried once, then the fresh key serves every request
+	want := []string{exhaustedKey, freshKey, freshKey}
+	if got := usedKeys(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("keys used = %v, want %v", got, want)
+	}
+	if got := testutil.ToFloat64(metrics.APICurrentKey.WithLabelValues(metrics.SourceAlphaVantage)); got != 1 {
+		t.Errorf("current key metric = %v, want 1", got)
+	}
+}
+
+func TestAlphaVantageSingleKeyRateLimited(t *testing.T) {
+	chdirTemp(t)
+	server, usedKeys := keyServer(t)
+
+	av := NewAlphaVantageClient(exhaustedKey).WithCache(cache.NewFileCache(t.TempDir(), time.Hour))
+	av.baseURL = server.URL
+	av.SetBypassCache(true)
+	defer av.Close()
+
+	if _, err := av.GetETFOverview("TSLY"); err == nil {
+		t.Error("GetETFOverview succeeded with only a rate-limited key")
+	}
+	if got := usedKeys(); len(got) != 1 {
+		t.Errorf("made %d requests with a single key, want 1", len(got))
+	}
+}
+
+// expiredEntry stores an entry in c that expired a minute ago and returns its path
+func expiredEntry(t *testing.T, c *cache.FileCache, dir string) string {
+	t.Helper()
+	key := c.Key("expired", "TSLY")
+	if err := c.SetWithTTL(key, map[string]string{"symbol": "TSLY"}, -time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, key)
+}
+
+func TestAlphaVantageCloseStopsLimitersAndCleansCache(t *testing.T) {
+	chdirTemp(t)
+	dir := t.TempDir()
+	fileCache := cache.NewFileCache(dir, time.Hour)
+	expired := expiredEntry(t, fileCache, dir)
+	if _, err := os.Stat(expired); err != nil {
+		t.Fatalf("expired entry not written: %v", err)
+	}
+
+	av := NewAlphaVantageClient(exhaustedKey, freshKey).WithCache(fileCache)
+	if err := av.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := os.Stat(expired); !os.IsNotExist(err) {
+		t.Errorf("expired cache entry still present after Close (stat err %v)", err)
+	}
+	for i, key := range av.keys {
+		select {
+		case <-key.rateLimiter.stop:
+		default:
+			t.Errorf("key %d rate limiter still refilling after Close", i)
+		}
+	}
+}
+
+func TestAlphaVantageTestConnectionClassifiesFailures(t *testing.T) {
+	noRetries := httpx.DefaultRetryPolicy()
+	noRetries.MaxRetries = 0
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    error
+	}{
+		{
+			name: "rejected key",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"Error Message":"the parameter apikey is invalid or missing."}`))
+			},
+			want: ErrInvalidAPIKey,
+		},
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			},
+			want: ErrNetwork,
+		},
+		{
+			name: "accepted key",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"Symbol":"SPY","Name":"SPDR S&P 500 ETF Trust"}`))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			av := NewAlphaVantageClient(freshKey).WithCache(cache.NewFileCache(t.TempDir(), time.Hour)).WithRetryPolicy(noRetries)
+			av.baseURL = server.URL
+			defer av.Close()
+
+			err := av.TestConnection()
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("TestConnection = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("TestConnection = %v, want %v", err, tt.want)
+			}
+			other := ErrNetwork
+			if tt.want == ErrNetwork {
+				other = ErrInvalidAPIKey
+			}
+			if errors.Is(err, other) {
+				t.Errorf("TestConnection = %v, also reported as %v", err, other)
+			}
+		})
+	}
+
+	// Nothing listening at all is a network error too
+	chdirTemp(t)
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	av := NewAlphaVantageClient(freshKey).WithCache(cache.NewFileCache(t.TempDir(), time.Hour)).WithRetryPolicy(noRetries)
+	av.baseURL = server.URL
+	defer av.Close()
+	if err := av.TestConnection(); !errors.Is(err, ErrNetwork) {
+		t.Errorf("TestConnection with the API down = %v, want %v", err, ErrNetwork)
+	}
+}
+
+func TestValidateAlphaVantageKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want error
+	}{
+		{freshKey, nil},
+		{"abcdefgh12345678", nil},
+		{"demo", ErrDemoAPIKey},
+		{"DEMO", ErrDemoAPIKey},
+		{"", ErrMalformedAPIKey},
+		{"SHORTKEY", ErrMalformedAPIKey},
+		{"FRESHKEY000000023", ErrMalformedAPIKey},
+		{"FRESHKEY-0000002", ErrMalformedAPIKey},
+	}
+	for _, tt := range tests {
+		if err := ValidateAlphaVantageKey(tt.key); !errors.Is(err, tt.want) || (tt.want == nil) != (err == nil) {
+			t.Errorf("ValidateAlphaVantageKey(%q) = %v, want %v", tt.key, err, tt.want)
+		}
+	}
+}
+
+func TestNewAlphaVantageClientFlagsKeys(t *testing.T) {
+	chdirTemp(t)
+
+	tests := []struct {
+		name     string
+		keys     []string
+		wantErr  error
+		wantKeys []string
+	}{
+		{name: "no key", wantErr: ErrNoAPIKey},
+		{name: "demo key", keys: []string{"demo"}, wantErr: ErrDemoAPIKey},
+		{name: "demo dropped beside a real key", keys: []string{"demo", freshKey}, wantKeys: []string{freshKey}},
+		// A key of an unexpected shape only draws a warning
+		{name: "malformed key", keys: []string{"SHORTKEY"}, wantKeys: []string{"SHORTKEY"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			av := NewAlphaVantageClient(tt.keys...)
+			defer av.Close()
+
+			if err := av.KeyError(); !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Errorf("KeyError() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			var keys []string
+			for _, state := range av.keys {
+				keys = append(keys, state.key)
+			}
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("keys in use = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrTooManyFailures marks symbols a batch request skipped after hitting its
+// consecutive failure limit, usually because the API key is exhausted
+var ErrTooManyFailures = errors.New("skipped: too many consecutive failures")
+
+// ErrCallBudgetExhausted marks symbols a batch request skipped because it had
+// already made as many API calls as its budget allows
+var ErrCallBudgetExhausted = errors.New("skipped: API call budget exhausted")
+
+// ErrInvalidAPIKey marks a request the API rejected because its key is
+// missing or invalid, which retrying or waiting won't fix
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// ErrNoAPIKey means a client was created without a usable key
+var ErrNoAPIKey = errors.New("no API key configured")
+
+// ErrDemoAPIKey marks Alpha Vantage's public "demo" key, which only answers
+// for IBM and so can't enrich any ETF
+var ErrDemoAPIKey = errors.New(`the Alpha Vantage "demo" key only works for IBM`)
+
+// ErrMalformedAPIKey marks a key that doesn't look like one the API issues
+var ErrMalformedAPIKey = errors.New("malformed API key")
+
+// ErrNetwork marks a request that never got an answer from the API, either
+// because the connection failed or because the server returned a 5xx status
+var ErrNetwork = errors.New("network error")
+
+// AggregateError reports the symbols a batch request could not fetch. The
+// symbols that succeeded are still returned alongside it.
+type AggregateError struct {
+	Errors  map[string]error // Failure per symbol, including skipped ones
+	Aborted bool             // The batch stopped early at the failure limit
+}
+
+// Symbols returns the failed symbols in sorted order
+func (e *AggregateError) Symbols() []string {
+	symbols := make([]string, 0, len(e.Errors))
+	for symbol := range e.Errors {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+func (e *AggregateError) Error() string {
+	symbols := e.Symbols()
+	shown := symbols
+	if len(shown) > 5 {
+		shown = shown[:5]
+	}
+	parts := make([]string, len(shown))
+	for i, symbol := range shown {
+		parts[i] = fmt.Sprintf("%s: %v", symbol, e.Errors[symbol])
+	}
+	msg := fmt.Sprintf("%d symbols failed (%s", len(symbols), strings.Join(parts, "; "))
+	if len(symbols) > len(shown) {
+		msg += fmt.Sprintf("; and %d more", len(symbols)-len(shown))
+	}
+	msg += ")"
+	if e.Aborted {
+		msg = "aborted after consecutive failures: " + msg
+	}
+	return msg
+}
+
+// Unwrap exposes the per-symbol errors to errors.Is and errors.As
+func (e *AggregateError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, symbol := range e.Symbols() {
+		errs = append(errs, e.Errors[symbol])
+	}
+	return errs
+}
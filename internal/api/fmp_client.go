@@ -1,26 +1,44 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"divminder-crawler/internal/cache"
+	"divminder-crawler/internal/httpx"
 	"divminder-crawler/internal/models"
 
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// FMPDailyLimit is the number of API calls the FMP free tier allows per day
+	FMPDailyLimit = 250
+	// fmpCallInterval spaces the API calls of a batch request
+	fmpCallInterval = 2 * time.Second
+	// cacheLookupWorkers is how many cache reads a batch request runs at once
+	cacheLookupWorkers = 8
+)
+
 // FMPClient handles Financial Modeling Prep API requests
 type FMPClient struct {
-	apiKey     string
-	baseURL    string
-	httpClient *http.Client
-	logger     *logrus.Logger
-	cache      *cache.FileCache
+	apiKey       string
+	baseURL      string
+	httpClient   *httpx.Client
+	logger       *logrus.Logger
+	cache        cache.Cache
+	historyTTL   time.Duration
+	calendarTTL  time.Duration
+	bypassCache  bool          // Skip cache reads (writes still happen) to force fresh data
+	maxFailures  int           // Consecutive batch failures before giving up; 0 never gives up
+	callBudget   int           // API calls one batch request may make; 0 is unlimited
+	callInterval time.Duration // Minimum spacing of a batch request's API calls
 }
 
 // FMPDividendResponse represents FMP dividend API response
@@ -52,27 +70,98 @@ func NewFMPClient(apiKey string) *FMPClient {
 	logger := logrus.New()
 	logger.SetLevel(logrus.InfoLevel)
 
-	// Initialize cache with 12-hour TTL for dividend data
-	dividendCache := cache.NewFileCache("cache/fmp", 12*time.Hour)
+	ttls := cache.DefaultTTLs()
+	dividendCache := cache.NewFileCache("cache/fmp", ttls.History)
 
 	return &FMPClient{
-		apiKey:  apiKey,
-		baseURL: "https://financialmodelingprep.com/api/v3",
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		logger: logger,
-		cache:  dividendCache,
+		apiKey:       apiKey,
+		baseURL:      "https://financialmodelingprep.com/api/v3",
+		httpClient:   httpx.New(30*time.Second, httpx.DefaultRetryPolicy()),
+		logger:       logger,
+		cache:        dividendCache,
+		historyTTL:   ttls.History,
+		calendarTTL:  ttls.Calendar,
+		callBudget:   FMPDailyLimit,
+		callInterval: fmpCallInterval,
+	}
+}
+
+// WithTTL sets how long dividend history and calendar responses stay cached
+func (fmp *FMPClient) WithTTL(history, calendar time.Duration) *FMPClient {
+	fmp.historyTTL = history
+	fmp.calendarTTL = calendar
+	return fmp
+}
+
+// WithCache stores responses in c instead of the default file cache
+func (fmp *FMPClient) WithCache(c cache.Cache) *FMPClient {
+	fmp.cache = c
+	return fmp
+}
+
+// WithRetryPolicy sets how failed requests are retried
+func (fmp *FMPClient) WithRetryPolicy(policy httpx.RetryPolicy) *FMPClient {
+	fmp.httpClient.SetRetryPolicy(policy)
+	return fmp
+}
+
+// WithRequestTimeout bounds each API call, retries included, so a slow
+// symbol fails fast and the batch moves on; 0 leaves only the client timeout
+func (fmp *FMPClient) WithRequestTimeout(timeout time.Duration) *FMPClient {
+	fmp.httpClient.SetRequestTimeout(timeout)
+	return fmp
+}
+
+// WithMaxConsecutiveFailures makes GetMultipleDividendHistories skip the
+// remaining symbols after n failures in a row; 0 never gives up
+func (fmp *FMPClient) WithMaxConsecutiveFailures(n int) *FMPClient {
+	fmp.maxFailures = n
+	return fmp
+}
+
+// WithCallBudget caps the API calls GetMultipleDividendHistories makes;
+// symbols served from the cache don't count. 0 removes the cap.
+func (fmp *FMPClient) WithCallBudget(n int) *FMPClient {
+	fmp.callBudget = n
+	return fmp
+}
+
+// SetBypassCache makes the client skip cache reads while still caching fresh responses
+func (fmp *FMPClient) SetBypassCache(bypass bool) {
+	fmp.bypassCache = bypass
+}
+
+// HasCachedDividendHistory reports whether GetDividendHistory would be served from the cache
+func (fmp *FMPClient) HasCachedDividendHistory(symbol string, years int) bool {
+	_, found := fmp.cachedDividendHistory(symbol, years)
+	return found
+}
+
+// cachedDividendHistory returns the cached history of symbol unless the
+// cache is bypassed or has none
+func (fmp *FMPClient) cachedDividendHistory(symbol string, years int) ([]models.DividendEvent, bool) {
+	if fmp.bypassCache {
+		return nil, false
 	}
+	var cachedEvents []models.DividendEvent
+	found, err := fmp.cache.Get(fmt.Sprintf("dividend_history_%s_%d", symbol, years), &cachedEvents)
+	return cachedEvents, err == nil && found
 }
 
 // GetDividendHistory fetches historical dividend data for a symbol
 func (fmp *FMPClient) GetDividendHistory(symbol string, years int) ([]models.DividendEvent, error) {
+	return fmp.GetDividendHistoryContext(context.Background(), symbol, years)
+}
+
+// GetDividendHistoryContext is GetDividendHistory with the API request bound to ctx
+func (fmp *FMPClient) GetDividendHistoryContext(ctx context.Context, symbol string, years int) ([]models.DividendEvent, error) {
 	// Check cache first
 	cacheKey := fmt.Sprintf("dividend_history_%s_%d", symbol, years)
 	var cachedEvents []models.DividendEvent
 
-	if found, err := fmp.cache.Get(cacheKey, &cachedEvents); err == nil && found {
+	if fmp.bypassCache {
+		fmp.logger.Debugf("Bypassing cache for %s dividend history", symbol)
+	} else if found, err := fmp.cache.Get(cacheKey, &cachedEvents); err == nil && found {
 		fmp.logger.Infof("Cache hit for %s dividend history", symbol)
 		return cachedEvents, nil
 	}
@@ -87,7 +176,7 @@ func (fmp *FMPClient) GetDividendHistory(symbol string, years int) ([]models.Div
 		fmp.baseURL, symbol, params.Encode())
 
 	// Make HTTP request
-	resp, err := fmp.httpClient.Get(requestURL)
+	resp, err := fmp.httpClient.GetContext(ctx, requestURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request for %s: %w", symbol, err)
 	}
@@ -119,7 +208,7 @@ func (fmp *FMPClient) GetDividendHistory(symbol string, years int) ([]models.Div
 
 	for _, div := range response.Historical {
 		// Parse dates
-		exDate, err := time.Parse("2006-01-02", div.Date)
+		exDate, err := models.ParseMarketDate("2006-01-02", div.Date)
 		if err != nil {
 			fmp.logger.Warnf("Failed to parse ex-date %s for %s: %v", div.Date, symbol, err)
 			continue
@@ -134,17 +223,24 @@ func (fmp *FMPClient) GetDividendHistory(symbol string, years int) ([]models.Div
 		var payDate, declareDate time.Time
 
 		if div.PaymentDate != "" {
-			if parsed, err := time.Parse("2006-01-02", div.PaymentDate); err == nil {
+			if parsed, err := models.ParseMarketDate("2006-01-02", div.PaymentDate); err == nil {
 				payDate = parsed
 			}
 		}
 
 		if div.DeclarationDate != "" {
-			if parsed, err := time.Parse("2006-01-02", div.DeclarationDate); err == nil {
+			if parsed, err := models.ParseMarketDate("2006-01-02", div.DeclarationDate); err == nil {
 				declareDate = parsed
 			}
 		}
 
+		recordDate := exDate
+		if div.RecordDate != "" {
+			if parsed, err := models.ParseMarketDate("2006-01-02", div.RecordDate); err == nil {
+				recordDate = parsed
+			}
+		}
+
 		// If payment date is missing, estimate it (typically 2-3 weeks after ex-date)
 		if payDate.IsZero() {
 			payDate = exDate.AddDate(0, 0, 14) // 2 weeks after ex-date
@@ -160,16 +256,18 @@ func (fmp *FMPClient) GetDividendHistory(symbol string, years int) ([]models.Div
 			ExDate:      exDate,
 			PayDate:     payDate,
 			DeclareDate: declareDate,
-			Amount:      div.AdjDividend,
+			RecordDate:  recordDate,
+			Amount:      models.RoundAmount(div.AdjDividend),
 			Group:       "", // Will be filled by caller
 			Frequency:   "", // Will be determined by caller
+			Source:      models.SourceFMP,
 		}
 
 		events = append(events, event)
 	}
 
 	// Cache the result
-	if err := fmp.cache.Set(cacheKey, events); err != nil {
+	if err := fmp.cache.SetWithTTL(cacheKey, events, fmp.historyTTL); err != nil {
 		fmp.logger.Warnf("Failed to cache dividend history for %s: %v", symbol, err)
 	}
 
@@ -177,6 +275,73 @@ func (fmp *FMPClient) GetDividendHistory(symbol string, years int) ([]models.Div
 	return events, nil
 }
 
+// GetHistoricalPrices fetches daily closing prices for a symbol between
+// fromDate and toDate, oldest first
+func (fmp *FMPClient) GetHistoricalPrices(symbol string, fromDate, toDate time.Time) ([]models.PricePoint, error) {
+	from := fromDate.Format("2006-01-02")
+	to := toDate.Format("2006-01-02")
+	cacheKey := fmt.Sprintf("historical_prices_%s_%s_%s", symbol, from, to)
+	var cachedPrices []models.PricePoint
+
+	if fmp.bypassCache {
+		fmp.logger.Debugf("Bypassing cache for %s prices", symbol)
+	} else if found, err := fmp.cache.Get(cacheKey, &cachedPrices); err == nil && found {
+		fmp.logger.Infof("Cache hit for %s prices", symbol)
+		return cachedPrices, nil
+	}
+
+	params := url.Values{}
+	params.Add("from", from)
+	params.Add("to", to)
+	params.Add("apikey", fmp.apiKey)
+
+	requestURL := fmt.Sprintf("%s/historical-price-full/%s?%s", fmp.baseURL, symbol, params.Encode())
+
+	resp, err := fmp.httpClient.Get(requestURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make price request for %s: %w", symbol, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("price API request failed for %s with status %d", symbol, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price response body for %s: %w", symbol, err)
+	}
+
+	var response struct {
+		Historical []struct {
+			Date  string  `json:"date"`
+			Close float64 `json:"close"`
+		} `json:"historical"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse price JSON response for %s: %w", symbol, err)
+	}
+
+	// FMP lists the newest day first
+	prices := make([]models.PricePoint, 0, len(response.Historical))
+	for i := len(response.Historical) - 1; i >= 0; i-- {
+		day := response.Historical[i]
+		date, err := models.ParseMarketDate("2006-01-02", day.Date)
+		if err != nil {
+			fmp.logger.Warnf("Failed to parse price date %s for %s: %v", day.Date, symbol, err)
+			continue
+		}
+		prices = append(prices, models.PricePoint{Date: date, Close: day.Close})
+	}
+
+	if err := fmp.cache.SetWithTTL(cacheKey, prices, fmp.historyTTL); err != nil {
+		fmp.logger.Warnf("Failed to cache prices for %s: %v", symbol, err)
+	}
+
+	fmp.logger.Infof("Successfully fetched %d daily prices for %s", len(prices), symbol)
+	return prices, nil
+}
+
 // GetDividendCalendar fetches upcoming dividend events
 func (fmp *FMPClient) GetDividendCalendar(fromDate, toDate time.Time) ([]models.DividendEvent, error) {
 	// Check cache first
@@ -184,7 +349,9 @@ func (fmp *FMPClient) GetDividendCalendar(fromDate, toDate time.Time) ([]models.
 		fromDate.Format("2006-01-02"), toDate.Format("2006-01-02"))
 
 	var cachedEvents []models.DividendEvent
-	if found, err := fmp.cache.Get(cacheKey, &cachedEvents); err == nil && found {
+	if fmp.bypassCache {
+		fmp.logger.Debug("Bypassing cache for dividend calendar")
+	} else if found, err := fmp.cache.Get(cacheKey, &cachedEvents); err == nil && found {
 		fmp.logger.Info("Cache hit for dividend calendar")
 		return cachedEvents, nil
 	}
@@ -228,7 +395,7 @@ func (fmp *FMPClient) GetDividendCalendar(fromDate, toDate time.Time) ([]models.
 
 	for _, cal := range calendarEvents {
 		// Parse dates
-		exDate, err := time.Parse("2006-01-02", cal.Date)
+		exDate, err := models.ParseMarketDate("2006-01-02", cal.Date)
 		if err != nil {
 			fmp.logger.Warnf("Failed to parse calendar ex-date %s: %v", cal.Date, err)
 			continue
@@ -237,32 +404,41 @@ func (fmp *FMPClient) GetDividendCalendar(fromDate, toDate time.Time) ([]models.
 		var payDate, declareDate time.Time
 
 		if cal.PaymentDate != "" {
-			if parsed, err := time.Parse("2006-01-02", cal.PaymentDate); err == nil {
+			if parsed, err := models.ParseMarketDate("2006-01-02", cal.PaymentDate); err == nil {
 				payDate = parsed
 			}
 		}
 
 		if cal.DeclarationDate != "" {
-			if parsed, err := time.Parse("2006-01-02", cal.DeclarationDate); err == nil {
+			if parsed, err := models.ParseMarketDate("2006-01-02", cal.DeclarationDate); err == nil {
 				declareDate = parsed
 			}
 		}
 
+		recordDate := exDate
+		if cal.RecordDate != "" {
+			if parsed, err := models.ParseMarketDate("2006-01-02", cal.RecordDate); err == nil {
+				recordDate = parsed
+			}
+		}
+
 		event := models.DividendEvent{
 			Symbol:      cal.Symbol,
 			ExDate:      exDate,
 			PayDate:     payDate,
 			DeclareDate: declareDate,
-			Amount:      cal.AdjDividend,
+			RecordDate:  recordDate,
+			Amount:      models.RoundAmount(cal.AdjDividend),
 			Group:       "", // Will be filled by caller
 			Frequency:   "", // Will be determined by caller
+			Source:      models.SourceFMP,
 		}
 
 		events = append(events, event)
 	}
 
 	// Cache the result
-	if err := fmp.cache.Set(cacheKey, events); err != nil {
+	if err := fmp.cache.SetWithTTL(cacheKey, events, fmp.calendarTTL); err != nil {
 		fmp.logger.Warnf("Failed to cache dividend calendar: %v", err)
 	}
 
@@ -270,41 +446,100 @@ func (fmp *FMPClient) GetDividendCalendar(fromDate, toDate time.Time) ([]models.
 	return events, nil
 }
 
-// GetMultipleDividendHistories fetches dividend history for multiple symbols
+// GetMultipleDividendHistories fetches dividend history for multiple symbols.
+// Cached histories are read concurrently and cost nothing; the rest are
+// fetched one at a time, spaced by the call interval, until the call budget
+// runs out. When any symbol fails, the histories that were fetched are
+// returned along with an *AggregateError listing each failure.
 func (fmp *FMPClient) GetMultipleDividendHistories(symbols []string, years int) (map[string][]models.DividendEvent, error) {
 	fmp.logger.Infof("Fetching dividend histories for %d symbols", len(symbols))
 
-	results := make(map[string][]models.DividendEvent)
-	errors := make(map[string]error)
+	results := fmp.cachedDividendHistories(symbols, years)
+	cacheHits := len(results)
+	var pending []string
+	for _, symbol := range symbols {
+		if _, cached := results[symbol]; !cached {
+			pending = append(pending, symbol)
+		}
+	}
 
-	for i, symbol := range symbols {
-		fmp.logger.Infof("Processing dividend history %d/%d: %s", i+1, len(symbols), symbol)
+	failures := &AggregateError{Errors: make(map[string]error)}
+	limiter := NewRateLimiter(1, fmp.callInterval)
+	defer limiter.Stop()
+	consecutive := 0
+	calls := 0
 
+	for i, symbol := range pending {
+		if failures.Aborted {
+			failures.Errors[symbol] = ErrTooManyFailures
+			continue
+		}
+		if fmp.callBudget > 0 && calls >= fmp.callBudget {
+			failures.Errors[symbol] = ErrCallBudgetExhausted
+			continue
+		}
+
+		fmp.logger.Infof("Processing dividend history %d/%d: %s", i+1, len(pending), symbol)
+
+		// Spaces API calls to respect rate limits (250 calls/day for free tier)
+		limiter.Wait()
+		calls++
 		events, err := fmp.GetDividendHistory(symbol, years)
 		if err != nil {
 			fmp.logger.Errorf("Failed to fetch dividend history for %s: %v", symbol, err)
-			errors[symbol] = err
+			failures.Errors[symbol] = err
+			consecutive++
+			if fmp.maxFailures > 0 && consecutive >= fmp.maxFailures {
+				fmp.logger.Errorf("Stopping after %d consecutive failures; the API key may be exhausted", consecutive)
+				failures.Aborted = true
+			}
 			continue
 		}
+		consecutive = 0
 
 		results[symbol] = events
-
-		// Add delay to respect rate limits (250 calls/day for free tier)
-		if i < len(symbols)-1 {
-			time.Sleep(2 * time.Second)
-		}
 	}
 
-	fmp.logger.Infof("Successfully fetched dividend histories for %d/%d symbols",
-		len(results), len(symbols))
+	fmp.logger.Infof("Successfully fetched dividend histories for %d/%d symbols (cache hits: %d, API calls: %d)",
+		len(results), len(symbols), cacheHits, calls)
 
-	if len(errors) > 0 {
-		fmp.logger.Warnf("Failed to fetch dividend histories for %d symbols", len(errors))
+	if len(failures.Errors) > 0 {
+		fmp.logger.Warnf("Failed to fetch dividend histories for %d symbols", len(failures.Errors))
+		return results, failures
 	}
 
 	return results, nil
 }
 
+// cachedDividendHistories reads the cached histories of symbols concurrently
+func (fmp *FMPClient) cachedDividendHistories(symbols []string, years int) map[string][]models.DividendEvent {
+	results := make(map[string][]models.DividendEvent)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	work := make(chan string)
+
+	for w := 0; w < cacheLookupWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for symbol := range work {
+				if events, found := fmp.cachedDividendHistory(symbol, years); found {
+					mu.Lock()
+					results[symbol] = events
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, symbol := range symbols {
+		work <- symbol
+	}
+	close(work)
+	wg.Wait()
+
+	return results
+}
+
 // TestConnection tests the FMP API connection
 func (fmp *FMPClient) TestConnection() error {
 	fmp.logger.Info("Testing FMP API connection...")
@@ -325,6 +560,12 @@ func (fmp *FMPClient) ClearCache() error {
 	return fmp.cache.Clear()
 }
 
+// Close removes expired cache entries. FMP requests aren't rate limited
+// client-side, so there is nothing else to stop.
+func (fmp *FMPClient) Close() error {
+	return fmp.cache.CleanExpired()
+}
+
 // GetCacheStats returns cache statistics
 func (fmp *FMPClient) GetCacheStats() (map[string]interface{}, error) {
 	return fmp.cache.GetStats()
@@ -0,0 +1,354 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/cache"
+	"divminder-crawler/internal/models"
+	"divminder-crawler/internal/proxy"
+)
+
+// chdirTemp runs the test in a temporary directory, so the default caches the
+// constructors create land there instead of in the source tree
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestGetDividendHistoryRefreshBypassesCache(t *testing.T) {
+	exDate := time.Now().AddDate(0, -1, 0).Format("2006-01-02")
+
+	tests := []struct {
+		name         string
+		bypass       bool
+		wantRequests int32
+		wantAmount   float64
+	}{
+		{"fresh cache entry is served", false, 0, 0.25},
+		{"refresh fetches anyway", true, 1, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				fmt.Fprintf(w, `{"symbol":"TSLY","historical":[{"date":%q,"adjDividend":0.5}]}`, exDate)
+			}))
+			defer server.Close()
+
+			fileCache := cache.NewFileCache(t.TempDir(), time.Hour)
+			fmp := NewFMPClient("test").WithCache(fileCache)
+			fmp.baseURL = server.URL
+			fmp.SetBypassCache(tt.bypass)
+
+			cached := []models.DividendEvent{{Symbol: "TSLY", Amount: 0.25}}
+			if err := fileCache.Set("dividend_history_TSLY_1", cached); err != nil {
+				t.Fatal(err)
+			}
+
+			events, err := fmp.GetDividendHistory("TSLY", 1)
+			if err != nil {
+				t.Fatalf("GetDividendHistory: %v", err)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("HTTP requests = %d, want %d", got, tt.wantRequests)
+			}
+			if len(events) != 1 || events[0].Amount != tt.wantAmount {
+				t.Fatalf("events = %+v, want one event of %v", events, tt.wantAmount)
+			}
+
+			// The refreshed response still replaces the cache entry
+			var stored []models.DividendEvent
+			if found, err := fileCache.Get("dividend_history_TSLY_1", &stored); err != nil || !found || stored[0].Amount != tt.wantAmount {
+				t.Errorf("cache entry = %+v (found %v, err %v), want amount %v", stored, found, err, tt.wantAmount)
+			}
+		})
+	}
+}
+
+// ttlRecorder is a cache that finds nothing and records the TTL of each entry stored
+type ttlRecorder struct {
+	*cache.FileCache
+	ttls map[string]time.Duration
+}
+
+func (r *ttlRecorder) Get(key string, target interface{}) (bool, error) {
+	return false, nil
+}
+
+func (r *ttlRecorder) SetWithTTL(key string, data interface{}, ttl time.Duration) error {
+	r.ttls[key] = ttl
+	return nil
+}
+
+func TestFMPWithTTLAppliesToHistoryEntries(t *testing.T) {
+	chdirTemp(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"symbol":"TSLY","historical":[]}`))
+	}))
+	defer server.Close()
+
+	recorder := &ttlRecorder{FileCache: cache.NewFileCache(t.TempDir(), time.Minute), ttls: map[string]time.Duration{}}
+	fmp := NewFMPClient("test").WithCache(recorder).WithTTL(72*time.Hour, 6*time.Hour)
+	fmp.baseURL = server.URL
+
+	if _, err := fmp.GetDividendHistory("TSLY", 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := recorder.ttls["dividend_history_TSLY_1"]; got != 72*time.Hour {
+		t.Errorf("history cached for %s, want the injected 72h", got)
+	}
+}
+
+func TestFMPRoutesThroughProxy(t *testing.T) {
+	chdirTemp(t)
+	var proxied []string
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Write([]byte(`{"symbol":"ULTY","historical":[]}`))
+	}))
+	defer proxyServer.Close()
+
+	if err := proxy.Configure(proxyServer.URL); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { proxy.Set(nil) })
+
+	// The API host doesn't resolve, so the response can only arrive via the proxy
+	fmp := NewFMPClient("test")
+	fmp.baseURL = "http://api.invalid"
+	fmp.SetBypassCache(true)
+	if _, err := fmp.GetDividendHistory("ULTY", 1); err != nil {
+		t.Fatalf("GetDividendHistory through proxy: %v", err)
+	}
+	if len(proxied) != 1 || !strings.HasPrefix(proxied[0], "http://api.invalid/") {
+		t.Errorf("proxy saw %v, want the API request", proxied)
+	}
+}
+
+func TestFMPCloseCleansExpiredEntries(t *testing.T) {
+	chdirTemp(t)
+	dir := t.TempDir()
+	fileCache := cache.NewFileCache(dir, time.Hour)
+	expired := expiredEntry(t, fileCache, dir)
+	fresh := fileCache.Key("fresh", "TSLY")
+	if err := fileCache.Set(fresh, "kept"); err != nil {
+		t.Fatal(err)
+	}
+
+	fmp := NewFMPClient("test").WithCache(fileCache)
+	if err := fmp.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := os.Stat(expired); !os.IsNotExist(err) {
+		t.Errorf("expired cache entry still present after Close (stat err %v)", err)
+	}
+	var kept string
+	if found, err := fileCache.Get(fresh, &kept); err != nil || !found || kept != "kept" {
+		t.Errorf("fresh entry after Close = %q (found %v, err %v), want it kept", kept, found, err)
+	}
+}
+
+// flakyFMPServer serves an empty dividend history for every symbol except
+// those in failing, which get a 404
+func flakyFMPServer(t *testing.T, failing map[string]bool) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		symbol := path.Base(r.URL.Path)
+		if failing[symbol] {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"symbol":%q,"historical":[]}`, symbol)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newBatchTestClient(t *testing.T, baseURL string) *FMPClient {
+	t.Helper()
+	fmp := NewFMPClient("test").WithCache(cache.NewFileCache(t.TempDir(), time.Hour))
+	fmp.baseURL = baseURL
+	fmp.callInterval = time.Millisecond
+	fmp.logger.SetOutput(io.Discard)
+	return fmp
+}
+
+func TestGetMultipleDividendHistoriesReportsFailures(t *testing.T) {
+	chdirTemp(t)
+	failing := map[string]bool{"CONY": true, "MSTY": true}
+	server, _ := flakyFMPServer(t, failing)
+	fmp := newBatchTestClient(t, server.URL)
+
+	results, err := fmp.GetMultipleDividendHistories([]string{"TSLY", "CONY", "ULTY", "MSTY"}, 1)
+
+	var aggregate *AggregateError
+	if !errors.As(err, &aggregate) {
+		t.Fatalf("error = %v, want an *AggregateError", err)
+	}
+	if got := aggregate.Symbols(); !reflect.DeepEqual(got, []string{"CONY", "MSTY"}) {
+		t.Errorf("failed symbols = %v, want [CONY MSTY]", got)
+	}
+	if aggregate.Aborted {
+		t.Error("batch marked aborted without a failure limit")
+	}
+	for _, symbol := range []string{"TSLY", "ULTY"} {
+		if _, ok := results[symbol]; !ok {
+			t.Errorf("no result for %s, which succeeded", symbol)
+		}
+	}
+	if len(results) != 2 {
+		t.Errorf("got %d results, want 2", len(results))
+	}
+}
+
+func TestGetMultipleDividendHistoriesAbortsAfterConsecutiveFailures(t *testing.T) {
+	chdirTemp(t)
+	failing := map[string]bool{"CONY": true, "MSTY": true, "NVDY": true}
+	server, requests := flakyFMPServer(t, failing)
+	fmp := newBatchTestClient(t, server.URL).WithMaxConsecutiveFailures(2)
+
+	_, err := fmp.GetMultipleDividendHistories([]string{"TSLY", "CONY", "MSTY", "NVDY", "ULTY"}, 1)
+
+	var aggregate *AggregateError
+	if !errors.As(err, &aggregate) || !aggregate.Aborted {
+		t.Fatalf("error = %v, want an aborted *AggregateError", err)
+	}
+	for _, symbol := range []string{"NVDY", "ULTY"} {
+		if !errors.Is(aggregate.Errors[symbol], ErrTooManyFailures) {
+			t.Errorf("%s error = %v, want ErrTooManyFailures", symbol, aggregate.Errors[symbol])
+		}
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("made %d requests, want 3 before giving up", got)
+	}
+}
+
+func TestFMPEventsStampSource(t *testing.T) {
+	chdirTemp(t)
+	exDate := time.Now().AddDate(0, -1, 0).Format("2006-01-02")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "stock_dividend_calendar") {
+			fmt.Fprintf(w, `[{"symbol":"TSLY","date":%q,"adjDividend":0.5}]`, exDate)
+			return
+		}
+		fmt.Fprintf(w, `{"symbol":"TSLY","historical":[{"date":%q,"adjDividend":0.5}]}`, exDate)
+	}))
+	defer server.Close()
+
+	fmp := newBatchTestClient(t, server.URL)
+	history, err := fmp.GetDividendHistory("TSLY", 1)
+	if err != nil {
+		t.Fatalf("GetDividendHistory: %v", err)
+	}
+	calendar, err := fmp.GetDividendCalendar(time.Now().AddDate(0, -2, 0), time.Now())
+	if err != nil {
+		t.Fatalf("GetDividendCalendar: %v", err)
+	}
+
+	events := append(history, calendar...)
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want one from each endpoint", len(events))
+	}
+	for _, e := range events {
+		if e.Source != models.SourceFMP || e.Estimated {
+			t.Errorf("event %+v: want source %q, not estimated", e, models.SourceFMP)
+		}
+	}
+}
+
+func TestGetDividendHistoryRequestTimeout(t *testing.T) {
+	chdirTemp(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		symbol := path.Base(r.URL.Path)
+		if symbol == "SLOW" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		fmt.Fprintf(w, `{"symbol":%q,"historical":[]}`, symbol)
+	}))
+	defer server.Close()
+
+	fmp := newBatchTestClient(t, server.URL).WithRequestTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := fmp.GetDividendHistory("SLOW", 1); err == nil {
+		t.Fatal("GetDividendHistory succeeded against a stalled server")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("slow symbol took %s, want it cut off by the 50ms request timeout", elapsed)
+	}
+	if _, err := fmp.GetDividendHistory("ULTY", 1); err != nil {
+		t.Errorf("next symbol failed after the timeout: %v", err)
+	}
+}
+
+func TestGetMultipleDividendHistoriesCachedSkipDelay(t *testing.T) {
+	chdirTemp(t)
+	server, requests := flakyFMPServer(t, nil)
+	fmp := newBatchTestClient(t, server.URL)
+
+	cached := []string{"TSLY", "CONY", "ULTY", "MSTY", "NVDY"}
+	for _, symbol := range cached {
+		if _, err := fmp.GetDividendHistory(symbol, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	requests.Store(0)
+
+	// A pause that would show if cache hits waited for the limiter
+	fmp.callInterval = 300 * time.Millisecond
+	start := time.Now()
+	results, err := fmp.GetMultipleDividendHistories(cached, 1)
+	if err != nil {
+		t.Fatalf("GetMultipleDividendHistories: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("%d cached symbols took %s, want no API pacing", len(cached), elapsed)
+	}
+	if len(results) != len(cached) || requests.Load() != 0 {
+		t.Errorf("got %d results with %d API requests, want %d from the cache alone", len(results), requests.Load(), len(cached))
+	}
+
+	// Only API calls count against the budget
+	fmp.callInterval = time.Millisecond
+	fmp.WithCallBudget(1)
+	results, err = fmp.GetMultipleDividendHistories(append([]string{"AMDY", "GOOY"}, cached...), 1)
+	var aggregate *AggregateError
+	if !errors.As(err, &aggregate) {
+		t.Fatalf("error = %v, want an *AggregateError", err)
+	}
+	if len(aggregate.Errors) != 1 || !errors.Is(aggregate.Errors["GOOY"], ErrCallBudgetExhausted) {
+		t.Errorf("failures = %v, want only GOOY over the budget", aggregate.Errors)
+	}
+	if len(results) != len(cached)+1 || requests.Load() != 1 {
+		t.Errorf("got %d results with %d API requests, want %d with 1", len(results), requests.Load(), len(cached)+1)
+	}
+}
@@ -0,0 +1,26 @@
+package cache
+
+import "time"
+
+// Cache is a key/value store for API responses with per-entry expiry.
+// FileCache is the default; RedisCache shares entries between machines.
+type Cache interface {
+	// Key builds a backend-safe key from a prefix and parameters
+	Key(prefix string, params ...string) string
+	// Get decodes a fresh entry into target, reporting whether one was found
+	Get(key string, target interface{}) (bool, error)
+	// Set stores data for the cache's default TTL
+	Set(key string, data interface{}) error
+	// SetWithTTL stores data, expiring after ttl
+	SetWithTTL(key string, data interface{}, ttl time.Duration) error
+	// Delete removes one entry; a missing entry is not an error
+	Delete(key string) error
+	// Clear removes every entry
+	Clear() error
+	// CleanExpired removes entries past their expiry
+	CleanExpired() error
+	// GetStats describes the cache's contents
+	GetStats() (map[string]interface{}, error)
+}
+
+var _ Cache = (*FileCache)(nil)
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// testCacheContract checks the behaviour every Cache backend must share.
+// newCache returns an empty cache whose default TTL is ttl. Keys always come
+// from Key, as callers of a Cache build them.
+func testCacheContract(t *testing.T, newCache func(t *testing.T, ttl time.Duration) Cache) {
+	type payload struct {
+		Symbol string  `json:"symbol"`
+		Amount float64 `json:"amount"`
+	}
+
+	t.Run("set and get", func(t *testing.T) {
+		c := newCache(t, time.Hour)
+		key := c.Key("dividends", "TSLY")
+		if err := c.Set(key, payload{Symbol: "TSLY", Amount: 0.437}); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+
+		var got payload
+		found, err := c.Get(key, &got)
+		if err != nil || !found {
+			t.Fatalf("Get = %v, %v; want a hit", found, err)
+		}
+		if got != (payload{Symbol: "TSLY", Amount: 0.437}) {
+			t.Errorf("Get decoded %+v", got)
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		c := newCache(t, time.Hour)
+		var got payload
+		if found, err := c.Get(c.Key("dividends", "NONE"), &got); err != nil || found {
+			t.Errorf("Get of a missing key = %v, %v; want a miss", found, err)
+		}
+	})
+
+	t.Run("entry ttl", func(t *testing.T) {
+		c := newCache(t, time.Hour)
+		short, long := c.Key("entry", "short"), c.Key("entry", "long")
+		if err := c.SetWithTTL(short, "value", time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.SetWithTTL(long, "value", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if err := c.CleanExpired(); err != nil {
+			t.Fatalf("CleanExpired: %v", err)
+		}
+
+		var got string
+		if found, _ := c.Get(short, &got); found {
+			t.Error("expired entry still found")
+		}
+		if found, _ := c.Get(long, &got); !found || got != "value" {
+			t.Errorf("fresh entry = %q, %v; want a hit", got, found)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		c := newCache(t, time.Hour)
+		key := c.Key("entry", "deleted")
+		if err := c.Set(key, "value"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Delete(key); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		var got string
+		if found, _ := c.Get(key, &got); found {
+			t.Error("deleted entry still found")
+		}
+		if err := c.Delete(key); err != nil {
+			t.Errorf("Delete of a missing key: %v", err)
+		}
+	})
+
+	t.Run("clear and stats", func(t *testing.T) {
+		c := newCache(t, time.Hour)
+		for _, symbol := range []string{"TSLY", "ULTY", "MSTY"} {
+			if err := c.Set(c.Key("entry", symbol), symbol); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if stats, err := c.GetStats(); err != nil || stats["totalEntries"] != 3 {
+			t.Errorf("GetStats = %v, %v; want 3 entries", stats, err)
+		}
+
+		if err := c.Clear(); err != nil {
+			t.Fatalf("Clear: %v", err)
+		}
+		var got string
+		if found, _ := c.Get(c.Key("entry", "TSLY"), &got); found {
+			t.Error("entry found after Clear")
+		}
+		if stats, err := c.GetStats(); err != nil || stats["totalEntries"] != 0 {
+			t.Errorf("GetStats after Clear = %v, %v; want 0 entries", stats, err)
+		}
+	})
+}
+
+func TestFileCacheContract(t *testing.T) {
+	testCacheContract(t, func(t *testing.T, ttl time.Duration) Cache {
+		return NewFileCache(t.TempDir(), ttl)
+	})
+}
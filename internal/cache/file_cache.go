@@ -6,8 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
+	"divminder-crawler/internal/metrics"
+	"divminder-crawler/internal/models"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -16,6 +20,11 @@ type FileCache struct {
 	cacheDir string
 	ttl      time.Duration
 	logger   *logrus.Logger
+
+	// Keep expired entries so GetStale can serve them while refreshing
+	staleWhileRevalidate bool
+	refreshing           sync.Map // Keys with a background refresh in flight
+	refreshes            sync.WaitGroup
 }
 
 // CacheEntry represents a cached item with metadata
@@ -56,6 +65,11 @@ func (fc *FileCache) generateCacheKey(prefix string, params ...string) string {
 	return fmt.Sprintf("%s_%x.json", prefix, hash)
 }
 
+// Key returns a file-safe cache key for the given prefix and parameters
+func (fc *FileCache) Key(prefix string, params ...string) string {
+	return fc.generateCacheKey(prefix, params...)
+}
+
 // getCacheFilePath returns the full path to a cache file
 func (fc *FileCache) getCacheFilePath(key string) string {
 	return filepath.Join(fc.cacheDir, key)
@@ -63,49 +77,155 @@ func (fc *FileCache) getCacheFilePath(key string) string {
 
 // Set stores data in the cache with TTL
 func (fc *FileCache) Set(key string, data interface{}) error {
+	return fc.SetWithTTL(key, data, fc.ttl)
+}
+
+// SetWithTTL stores data in the cache, expiring after ttl instead of the cache default
+func (fc *FileCache) SetWithTTL(key string, data interface{}, ttl time.Duration) error {
 	now := time.Now()
 	entry := CacheEntry{
 		Data:      data,
 		CreatedAt: now,
-		ExpiresAt: now.Add(fc.ttl),
+		ExpiresAt: now.Add(ttl),
 		Key:       key,
 	}
 
 	filePath := fc.getCacheFilePath(key)
 
-	// Create the file
-	file, err := os.Create(filePath)
+	// Write a temporary file and rename it over the entry, so a reader
+	// never sees a half-written entry while a background refresh stores it
+	tmpPath := filePath + ".tmp"
+	file, err := os.Create(tmpPath)
 	if err != nil {
 		return fmt.Errorf("failed to create cache file %s: %w", filePath, err)
 	}
-	defer file.Close()
 
 	// Encode to JSON
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
 
-	if err := encoder.Encode(entry); err != nil {
+	err = encoder.Encode(entry)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
 		return fmt.Errorf("failed to encode cache entry: %w", err)
 	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace cache file %s: %w", filePath, err)
+	}
 
 	fc.logger.Debugf("Cached data with key: %s (expires: %s)", key, entry.ExpiresAt.Format(time.RFC3339))
 	return nil
 }
 
-// Get retrieves data from the cache if it exists and hasn't expired
+// Get retrieves data from the cache if it exists and hasn't expired.
+// Expired entries are removed unless stale-while-revalidate is on.
 func (fc *FileCache) Get(key string, target interface{}) (bool, error) {
+	entry, err := fc.readEntry(key)
+	if entry == nil || err != nil {
+		return false, err
+	}
+
+	if time.Now().After(entry.ExpiresAt) {
+		fc.logger.Debugf("Cache expired: %s (expired: %s)", key, entry.ExpiresAt.Format(time.RFC3339))
+		metrics.CacheLookups.WithLabelValues("miss").Inc()
+		if !fc.staleWhileRevalidate {
+			os.Remove(fc.getCacheFilePath(key))
+		}
+		return false, nil
+	}
+
+	if err := decodeEntry(entry, target); err != nil {
+		return false, err
+	}
+
+	fc.logger.Debugf("Cache hit: %s (created: %s)", key, entry.CreatedAt.Format(time.RFC3339))
+	metrics.CacheLookups.WithLabelValues("hit").Inc()
+	return true, nil
+}
+
+// SetStaleWhileRevalidate turns on keeping expired entries, which GetStale
+// then serves while it refreshes them in the background
+func (fc *FileCache) SetStaleWhileRevalidate(enabled bool) {
+	fc.staleWhileRevalidate = enabled
+}
+
+// GetStale is Get that, with stale-while-revalidate on, also decodes an
+// expired entry into target and reports it as stale. It then starts refresh
+// in the background and stores its result, unless a refresh of the key is
+// already running; a failed refresh leaves the stale entry in place. With
+// the mode off it behaves like Get.
+func (fc *FileCache) GetStale(key string, target interface{}, refresh func() (interface{}, error)) (found, stale bool, err error) {
+	if !fc.staleWhileRevalidate {
+		found, err = fc.Get(key, target)
+		return found, false, err
+	}
+
+	entry, err := fc.readEntry(key)
+	if entry == nil || err != nil {
+		return false, false, err
+	}
+	if err := decodeEntry(entry, target); err != nil {
+		return false, false, err
+	}
+
+	if !time.Now().After(entry.ExpiresAt) {
+		metrics.CacheLookups.WithLabelValues("hit").Inc()
+		return true, false, nil
+	}
+
+	fc.logger.Debugf("Serving stale cache entry %s (expired: %s)", key, entry.ExpiresAt.Format(time.RFC3339))
+	metrics.CacheLookups.WithLabelValues("stale").Inc()
+	fc.revalidate(key, refresh)
+	return true, true, nil
+}
+
+// WaitForRefreshes blocks until background refreshes started by GetStale finish
+func (fc *FileCache) WaitForRefreshes() {
+	fc.refreshes.Wait()
+}
+
+// revalidate refreshes key in the background unless that is already under way
+func (fc *FileCache) revalidate(key string, refresh func() (interface{}, error)) {
+	if _, running := fc.refreshing.LoadOrStore(key, true); running {
+		return
+	}
+
+	fc.refreshes.Add(1)
+	go func() {
+		defer fc.refreshes.Done()
+		defer fc.refreshing.Delete(key)
+
+		data, err := refresh()
+		if err != nil {
+			fc.logger.Warnf("Failed to refresh cache entry %s, keeping stale data: %v", key, err)
+			return
+		}
+		if err := fc.Set(key, data); err != nil {
+			fc.logger.Warnf("Failed to store refreshed cache entry %s: %v", key, err)
+		}
+	}()
+}
+
+// readEntry reads key's entry regardless of expiry, returning nil for a
+// missing file. A corrupt file is removed and treated as missing.
+func (fc *FileCache) readEntry(key string) (*CacheEntry, error) {
 	filePath := fc.getCacheFilePath(key)
 
 	// Check if file exists
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		fc.logger.Debugf("Cache miss: %s (file not found)", key)
-		return false, nil
+		metrics.CacheLookups.WithLabelValues("miss").Inc()
+		return nil, nil
 	}
 
 	// Read the file
 	file, err := os.Open(filePath)
 	if err != nil {
-		return false, fmt.Errorf("failed to open cache file %s: %w", filePath, err)
+		return nil, fmt.Errorf("failed to open cache file %s: %w", filePath, err)
 	}
 	defer file.Close()
 
@@ -114,29 +234,24 @@ func (fc *FileCache) Get(key string, target interface{}) (bool, error) {
 	decoder := json.NewDecoder(file)
 	if err := decoder.Decode(&entry); err != nil {
 		fc.logger.Warnf("Failed to decode cache file %s, removing: %v", filePath, err)
+		metrics.CacheLookups.WithLabelValues("miss").Inc()
 		os.Remove(filePath)
-		return false, nil
-	}
-
-	// Check if expired
-	if time.Now().After(entry.ExpiresAt) {
-		fc.logger.Debugf("Cache expired: %s (expired: %s)", key, entry.ExpiresAt.Format(time.RFC3339))
-		os.Remove(filePath)
-		return false, nil
+		return nil, nil
 	}
+	return &entry, nil
+}
 
-	// Convert data to target type
+// decodeEntry converts an entry's data to target's type
+func decodeEntry(entry *CacheEntry, target interface{}) error {
 	dataBytes, err := json.Marshal(entry.Data)
 	if err != nil {
-		return false, fmt.Errorf("failed to marshal cached data: %w", err)
+		return fmt.Errorf("failed to marshal cached data: %w", err)
 	}
 
 	if err := json.Unmarshal(dataBytes, target); err != nil {
-		return false, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		return fmt.Errorf("failed to unmarshal cached data: %w", err)
 	}
-
-	fc.logger.Debugf("Cache hit: %s (created: %s)", key, entry.CreatedAt.Format(time.RFC3339))
-	return true, nil
+	return nil
 }
 
 // Delete removes an item from the cache
@@ -279,7 +394,7 @@ func (fc *FileCache) GetStats() (map[string]interface{}, error) {
 
 // ETFMetadataCache provides specialized caching for ETF metadata
 type ETFMetadataCache struct {
-	cache *FileCache
+	cache Cache
 }
 
 // NewETFMetadataCache creates a cache specifically for ETF metadata
@@ -289,9 +404,14 @@ func NewETFMetadataCache(cacheDir string, ttl time.Duration) *ETFMetadataCache {
 	}
 }
 
+// NewETFMetadataCacheFrom stores ETF metadata in an existing cache backend
+func NewETFMetadataCacheFrom(c Cache) *ETFMetadataCache {
+	return &ETFMetadataCache{cache: c}
+}
+
 // GetETFMetadata retrieves cached ETF metadata
 func (emc *ETFMetadataCache) GetETFMetadata(symbol string) (interface{}, bool, error) {
-	key := emc.cache.generateCacheKey("etf_metadata", symbol)
+	key := emc.cache.Key("etf_metadata", symbol)
 
 	var metadata interface{}
 	found, err := emc.cache.Get(key, &metadata)
@@ -299,15 +419,28 @@ func (emc *ETFMetadataCache) GetETFMetadata(symbol string) (interface{}, bool, e
 	return metadata, found, err
 }
 
+// GetTyped retrieves cached ETF metadata decoded straight into the struct.
+// An entry that can't be decoded is reported as an error rather than a miss.
+func (emc *ETFMetadataCache) GetTyped(symbol string) (*models.ETFMetadata, bool, error) {
+	key := emc.cache.Key("etf_metadata", symbol)
+
+	var metadata models.ETFMetadata
+	found, err := emc.cache.Get(key, &metadata)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	return &metadata, true, nil
+}
+
 // SetETFMetadata caches ETF metadata
 func (emc *ETFMetadataCache) SetETFMetadata(symbol string, metadata interface{}) error {
-	key := emc.cache.generateCacheKey("etf_metadata", symbol)
+	key := emc.cache.Key("etf_metadata", symbol)
 	return emc.cache.Set(key, metadata)
 }
 
 // InvalidateETF removes cached data for a specific ETF
 func (emc *ETFMetadataCache) InvalidateETF(symbol string) error {
-	key := emc.cache.generateCacheKey("etf_metadata", symbol)
+	key := emc.cache.Key("etf_metadata", symbol)
 	return emc.cache.Delete(key)
 }
 
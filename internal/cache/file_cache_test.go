@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestFileCacheHonorsInjectedTTL(t *testing.T) {
+	tests := []struct {
+		name      string
+		ttl       time.Duration // Cache default, used by Set
+		entryTTL  time.Duration // Passed to SetWithTTL when non-zero
+		wantFresh bool
+	}{
+		{"long default", time.Hour, 0, true},
+		{"short default", time.Millisecond, 0, false},
+		{"short entry overrides long default", time.Hour, time.Millisecond, false},
+		{"long entry overrides short default", time.Millisecond, time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewFileCache(t.TempDir(), tt.ttl)
+			var err error
+			if tt.entryTTL != 0 {
+				err = fc.SetWithTTL("key", "value", tt.entryTTL)
+			} else {
+				err = fc.Set("key", "value")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			time.Sleep(10 * time.Millisecond)
+
+			var got string
+			found, err := fc.Get("key", &got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found != tt.wantFresh {
+				t.Errorf("found = %v, want %v", found, tt.wantFresh)
+			}
+			if found && got != "value" {
+				t.Errorf("got %q, want value", got)
+			}
+		})
+	}
+}
+
+func TestDefaultTTLs(t *testing.T) {
+	ttls := DefaultTTLs()
+	if ttls.History != 12*time.Hour || ttls.Calendar != 12*time.Hour || ttls.Metadata != 24*time.Hour || ttls.Detail != 0 {
+		t.Errorf("DefaultTTLs() = %+v", ttls)
+	}
+}
+
+func TestETFMetadataCacheGetTyped(t *testing.T) {
+	mc := NewETFMetadataCache(t.TempDir(), time.Hour)
+	want := &models.ETFMetadata{
+		Symbol:        "TSLY",
+		Name:          "YieldMax TSLA Option Income Strategy ETF",
+		DividendYield: "0.65",
+		LastUpdated:   time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC),
+		Source:        "alpha_vantage",
+	}
+	if err := mc.SetETFMetadata("TSLY", want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, found, err := mc.GetTyped("TSLY")
+	if err != nil || !found {
+		t.Fatalf("GetTyped(TSLY) = %v, %v; want a hit", found, err)
+	}
+	if *got != *want {
+		t.Errorf("GetTyped(TSLY) = %+v, want %+v", got, want)
+	}
+
+	if got, found, err := mc.GetTyped("NVDY"); got != nil || found || err != nil {
+		t.Errorf("GetTyped(NVDY) = %v, %v, %v; want a miss", got, found, err)
+	}
+
+	// An entry of the wrong shape is an error, not an empty struct
+	if err := mc.SetETFMetadata("MSTY", []string{"not", "metadata"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, found, err := mc.GetTyped("MSTY"); err == nil || found || got != nil {
+		t.Errorf("GetTyped(MSTY) = %v, %v, %v; want a decode error", got, found, err)
+	}
+}
+
+func TestFileCacheStaleWhileRevalidate(t *testing.T) {
+	// The old entry expires at once; the refreshed one, stored with the default TTL, stays fresh
+	fc := NewFileCache(t.TempDir(), time.Hour)
+	fc.SetStaleWhileRevalidate(true)
+	if err := fc.SetWithTTL("key", "old", time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// Get leaves the expired entry in place in this mode
+	var got string
+	if found, err := fc.Get("key", &got); found || err != nil {
+		t.Fatalf("Get on an expired entry = %v, %v; want a miss", found, err)
+	}
+
+	// The refresh blocks until released, so the stale answer can't have waited for it
+	release := make(chan struct{})
+	var refreshes atomic.Int32
+	refresh := func() (interface{}, error) {
+		refreshes.Add(1)
+		<-release
+		return "new", nil
+	}
+
+	found, stale, err := fc.GetStale("key", &got, refresh)
+	if err != nil || !found || !stale || got != "old" {
+		t.Fatalf("GetStale = %q, found %v, stale %v, err %v; want stale old data", got, found, stale, err)
+	}
+	// A second lookup while the refresh runs doesn't start another
+	if _, stale, _ := fc.GetStale("key", &got, refresh); !stale {
+		t.Error("second GetStale not stale while the refresh runs")
+	}
+
+	close(release)
+	fc.WaitForRefreshes()
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("refresh ran %d times, want 1", n)
+	}
+
+	got = ""
+	found, stale, err = fc.GetStale("key", &got, refresh)
+	if err != nil || !found || stale || got != "new" {
+		t.Errorf("GetStale after the refresh = %q, found %v, stale %v, err %v; want fresh new data", got, found, stale, err)
+	}
+}
+
+func TestFileCacheFailedRefreshKeepsStaleData(t *testing.T) {
+	fc := NewFileCache(t.TempDir(), time.Millisecond)
+	fc.SetStaleWhileRevalidate(true)
+	if err := fc.Set("key", "old"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	failing := func() (interface{}, error) { return nil, errors.New("site down") }
+	var got string
+	if _, stale, err := fc.GetStale("key", &got, failing); err != nil || !stale {
+		t.Fatalf("GetStale = stale %v, err %v; want stale data", stale, err)
+	}
+	fc.WaitForRefreshes()
+
+	got = ""
+	if found, stale, err := fc.GetStale("key", &got, failing); !found || !stale || err != nil || got != "old" {
+		t.Errorf("after a failed refresh GetStale = %q, found %v, stale %v, err %v; want the stale old data", got, found, stale, err)
+	}
+	fc.WaitForRefreshes()
+
+	// Without the mode, expired entries are misses as before
+	plain := NewFileCache(t.TempDir(), time.Millisecond)
+	if err := plain.Set("key", "old"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if found, stale, err := plain.GetStale("key", &got, failing); found || stale || err != nil {
+		t.Errorf("GetStale without the mode = found %v, stale %v, err %v; want a miss", found, stale, err)
+	}
+}
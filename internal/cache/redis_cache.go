@@ -0,0 +1,335 @@
+//go:build redis
+
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"divminder-crawler/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RedisCache stores entries in Redis so several crawler instances share one
+// cache. Keys are namespaced and expire through Redis itself.
+type RedisCache struct {
+	conn      *redisConn
+	addr      string
+	namespace string
+	ttl       time.Duration
+	logger    *logrus.Logger
+}
+
+var _ Cache = (*RedisCache)(nil)
+
+// NewRedisCache connects to a redis://[:password@]host:port[/db] URL. Entries
+// are stored under namespace and default to ttl.
+func NewRedisCache(rawURL, namespace string, ttl time.Duration) (*RedisCache, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "redis" || u.Host == "" {
+		return nil, fmt.Errorf("invalid redis URL %q: want redis://[:password@]host:port[/db]", rawURL)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+
+	conn := &redisConn{addr: addr}
+	if password, ok := u.User.Password(); ok {
+		conn.password = password
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if _, err := strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis database %q", db)
+		}
+		conn.db = db
+	}
+	if _, err := conn.do("PING"); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", addr, err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.InfoLevel)
+
+	return &RedisCache{
+		conn:      conn,
+		addr:      addr,
+		namespace: namespace + ":",
+		ttl:       ttl,
+		logger:    logger,
+	}, nil
+}
+
+// Key joins the prefix and parameters with colons
+func (rc *RedisCache) Key(prefix string, params ...string) string {
+	return strings.Join(append([]string{prefix}, params...), ":")
+}
+
+// Set stores data for the cache's default TTL
+func (rc *RedisCache) Set(key string, data interface{}) error {
+	return rc.SetWithTTL(key, data, rc.ttl)
+}
+
+// SetWithTTL stores data, expiring after ttl; a ttl of zero or less stores nothing
+func (rc *RedisCache) SetWithTTL(key string, data interface{}, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	now := time.Now()
+	payload, err := json.Marshal(CacheEntry{
+		Data:      data,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+		Key:       key,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	ms := ttl.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	if _, err := rc.conn.do("SET", rc.namespace+key, string(payload), "PX", strconv.FormatInt(ms, 10)); err != nil {
+		return fmt.Errorf("failed to cache %s: %w", key, err)
+	}
+	rc.logger.Debugf("Cached data with key: %s (expires: %s)", key, now.Add(ttl).Format(time.RFC3339))
+	return nil
+}
+
+// Get decodes a fresh entry into target, reporting whether one was found
+func (rc *RedisCache) Get(key string, target interface{}) (bool, error) {
+	reply, err := rc.conn.do("GET", rc.namespace+key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	payload, ok := reply.(string)
+	if !ok {
+		metrics.CacheLookups.WithLabelValues("miss").Inc()
+		return false, nil
+	}
+
+	var entry struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(payload), &entry); err != nil {
+		rc.logger.Warnf("Failed to decode cache entry %s, removing: %v", key, err)
+		metrics.CacheLookups.WithLabelValues("miss").Inc()
+		rc.Delete(key)
+		return false, nil
+	}
+	if err := json.Unmarshal(entry.Data, target); err != nil {
+		return false, fmt.Errorf("failed to unmarshal cached data: %w", err)
+	}
+
+	metrics.CacheLookups.WithLabelValues("hit").Inc()
+	return true, nil
+}
+
+// Delete removes one entry; a missing entry is not an error
+func (rc *RedisCache) Delete(key string) error {
+	if _, err := rc.conn.do("DEL", rc.namespace+key); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// Clear removes every entry in the cache's namespace
+func (rc *RedisCache) Clear() error {
+	keys, err := rc.keys()
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(keys); start += 100 {
+		end := start + 100
+		if end > len(keys) {
+			end = len(keys)
+		}
+		if _, err := rc.conn.do(append([]string{"DEL"}, keys[start:end]...)...); err != nil {
+			return fmt.Errorf("failed to clear redis cache: %w", err)
+		}
+	}
+	rc.logger.Infof("Cleared %d redis cache entries", len(keys))
+	return nil
+}
+
+// CleanExpired is a no-op: Redis drops entries when their TTL lapses
+func (rc *RedisCache) CleanExpired() error {
+	return nil
+}
+
+// GetStats returns cache statistics
+func (rc *RedisCache) GetStats() (map[string]interface{}, error) {
+	keys, err := rc.keys()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"totalEntries": len(keys),
+		"backend":      "redis",
+		"addr":         rc.addr,
+		"namespace":    strings.TrimSuffix(rc.namespace, ":"),
+		"ttlHours":     rc.ttl.Hours(),
+	}, nil
+}
+
+// keys lists every key in the namespace with SCAN
+func (rc *RedisCache) keys() ([]string, error) {
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := rc.conn.do("SCAN", cursor, "MATCH", rc.namespace+"*", "COUNT", "100")
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan redis keys: %w", err)
+		}
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 2 {
+			return nil, fmt.Errorf("unexpected SCAN reply %v", reply)
+		}
+		cursor, _ = parts[0].(string)
+		batch, _ := parts[1].([]interface{})
+		for _, key := range batch {
+			if s, ok := key.(string); ok {
+				keys = append(keys, s)
+			}
+		}
+		if cursor == "0" {
+			return keys, nil
+		}
+	}
+}
+
+// redisConn is a minimal RESP client holding one connection, redialled after
+// any error
+type redisConn struct {
+	addr     string
+	password string
+	db       string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// do sends one command and returns its reply: a string, int64, nil, or
+// []interface{} for arrays. Redis error replies are returned as errors.
+func (c *redisConn) do(args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.dial(); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := c.roundTrip(args)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		c.conn.Close()
+		c.conn = nil
+	}
+	return reply, err
+}
+
+func (c *redisConn) dial() error {
+	conn, err := net.DialTimeout("tcp", c.addr, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+
+	if c.password != "" {
+		if _, err := c.roundTrip([]string{"AUTH", c.password}); err != nil {
+			conn.Close()
+			c.conn = nil
+			return fmt.Errorf("redis AUTH failed: %w", err)
+		}
+	}
+	if c.db != "" {
+		if _, err := c.roundTrip([]string{"SELECT", c.db}); err != nil {
+			conn.Close()
+			c.conn = nil
+			return fmt.Errorf("redis SELECT failed: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *redisConn) roundTrip(args []string) (interface{}, error) {
+	c.conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, err
+	}
+	return readReply(c.reader)
+}
+
+// redisError is an error reply from the server
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unexpected redis reply %q", line)
+}
@@ -0,0 +1,232 @@
+//go:build redis
+
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is an in-memory server speaking the subset of RESP that
+// RedisCache uses, so the contract runs without a Redis install
+type fakeRedis struct {
+	listener net.Listener
+	password string
+
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &fakeRedis{
+		listener: listener,
+		password: password,
+		values:   make(map[string]string),
+		expires:  make(map[string]time.Time),
+	}
+	go r.serve()
+	t.Cleanup(func() { listener.Close() })
+	return r
+}
+
+func (r *fakeRedis) addr() string {
+	return r.listener.Addr().String()
+}
+
+func (r *fakeRedis) serve() {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			return
+		}
+		go r.handle(conn)
+	}
+}
+
+func (r *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authed := r.password == ""
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(args[0])
+		if !authed && command != "AUTH" {
+			io.WriteString(conn, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+		if command == "AUTH" {
+			if len(args) != 2 || args[1] != r.password {
+				io.WriteString(conn, "-WRONGPASS invalid password\r\n")
+				continue
+			}
+			authed = true
+		}
+		io.WriteString(conn, r.exec(command, args[1:]))
+	}
+}
+
+// readCommand reads one RESP array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	reply, err := readReply(r)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("unexpected command %v", reply)
+	}
+	args := make([]string, len(items))
+	for i, item := range items {
+		args[i], _ = item.(string)
+	}
+	return args, nil
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func (r *fakeRedis) exec(command string, args []string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expire()
+
+	switch command {
+	case "PING":
+		return "+PONG\r\n"
+	case "AUTH", "SELECT":
+		return "+OK\r\n"
+	case "SET":
+		r.values[args[0]] = args[1]
+		delete(r.expires, args[0])
+		if len(args) == 4 && strings.ToUpper(args[2]) == "PX" {
+			ms, _ := strconv.Atoi(args[3])
+			r.expires[args[0]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		return "+OK\r\n"
+	case "GET":
+		value, ok := r.values[args[0]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulk(value)
+	case "DEL":
+		deleted := 0
+		for _, key := range args {
+			if _, ok := r.values[key]; ok {
+				delete(r.values, key)
+				delete(r.expires, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	case "SCAN":
+		// Every match in one batch; args are cursor MATCH pattern COUNT n
+		var keys []string
+		for key := range r.values {
+			if matched, _ := path.Match(args[2], key); matched {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		reply := fmt.Sprintf("*2\r\n%s*%d\r\n", bulk("0"), len(keys))
+		for _, key := range keys {
+			reply += bulk(key)
+		}
+		return reply
+	}
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", command)
+}
+
+// expire drops keys past their PX deadline, as Redis does lazily
+func (r *fakeRedis) expire() {
+	now := time.Now()
+	for key, at := range r.expires {
+		if now.After(at) {
+			delete(r.values, key)
+			delete(r.expires, key)
+		}
+	}
+}
+
+func TestRedisCacheContract(t *testing.T) {
+	testCacheContract(t, func(t *testing.T, ttl time.Duration) Cache {
+		server := newFakeRedis(t, "")
+		c, err := NewRedisCache("redis://"+server.addr()+"/2", "divminder", ttl)
+		if err != nil {
+			t.Fatalf("NewRedisCache: %v", err)
+		}
+		return c
+	})
+}
+
+func TestRedisCacheNamespaces(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+
+	crawler, err := NewRedisCache("redis://:secret@"+server.addr(), "crawler", time.Hour)
+	if err != nil {
+		t.Fatalf("NewRedisCache: %v", err)
+	}
+	backfill, err := NewRedisCache("redis://:secret@"+server.addr(), "backfill", time.Hour)
+	if err != nil {
+		t.Fatalf("NewRedisCache: %v", err)
+	}
+
+	if err := crawler.Set("TSLY", "crawler"); err != nil {
+		t.Fatal(err)
+	}
+	if err := backfill.Set("TSLY", "backfill"); err != nil {
+		t.Fatal(err)
+	}
+	if err := backfill.Clear(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Clearing one namespace leaves the other's entries
+	var got string
+	if found, err := crawler.Get("TSLY", &got); err != nil || !found || got != "crawler" {
+		t.Errorf("crawler entry = %q, %v, %v; want it kept", got, found, err)
+	}
+	if found, _ := backfill.Get("TSLY", &got); found {
+		t.Error("backfill entry survived Clear")
+	}
+}
+
+func TestNewRedisCacheErrors(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"wrong scheme", "http://" + server.addr()},
+		{"invalid database", "redis://" + server.addr() + "/cache"},
+		{"wrong password", "redis://:wrong@" + server.addr()},
+		{"missing password", "redis://" + server.addr()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRedisCache(tt.url, "divminder", time.Hour); err == nil {
+				t.Errorf("NewRedisCache(%q) succeeded", tt.url)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !redis
+
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// RedisCache is only available in builds with the redis tag
+type RedisCache struct{ Cache }
+
+// NewRedisCache always fails without the redis build tag
+func NewRedisCache(rawURL, namespace string, ttl time.Duration) (*RedisCache, error) {
+	return nil, fmt.Errorf("redis cache support not built in; rebuild with -tags redis")
+}
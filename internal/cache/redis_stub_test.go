@@ -0,0 +1,16 @@
+//go:build !redis
+
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewRedisCacheNeedsBuildTag(t *testing.T) {
+	_, err := NewRedisCache("redis://127.0.0.1:6379", "divminder", time.Hour)
+	if err == nil || !strings.Contains(err.Error(), "-tags redis") {
+		t.Errorf("NewRedisCache = %v, want an error naming the redis build tag", err)
+	}
+}
@@ -0,0 +1,20 @@
+package cache
+
+import "time"
+
+// TTLs holds the cache lifetime for each type of cached data
+type TTLs struct {
+	History  time.Duration // FMP dividend history
+	Calendar time.Duration // FMP dividend calendar
+	Metadata time.Duration // Alpha Vantage ETF overviews
+	Detail   time.Duration // YieldMax fund pages; zero disables caching
+}
+
+// DefaultTTLs returns the lifetimes used when none are configured
+func DefaultTTLs() TTLs {
+	return TTLs{
+		History:  12 * time.Hour,
+		Calendar: 12 * time.Hour,
+		Metadata: 24 * time.Hour,
+	}
+}
@@ -0,0 +1,50 @@
+package cmdutil
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrCircuitOpen is reported for symbols skipped after the breaker tripped
+var ErrCircuitOpen = errors.New("skipped: too many consecutive failures")
+
+// CircuitBreaker trips after a run of consecutive failures so a run stops
+// hammering a site that is down. It is safe for concurrent use and lives for
+// a single run, so each run starts closed.
+type CircuitBreaker struct {
+	mu          sync.Mutex
+	threshold   int
+	consecutive int
+	tripped     bool
+}
+
+// NewCircuitBreaker returns a breaker that trips after threshold consecutive
+// failures; a threshold below 1 never trips
+func NewCircuitBreaker(threshold int) *CircuitBreaker {
+	return &CircuitBreaker{threshold: threshold}
+}
+
+// Record notes a result, resetting the count on success. It returns true
+// only for the failure that trips the breaker.
+func (b *CircuitBreaker) Record(err error) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.consecutive = 0
+		return false
+	}
+	b.consecutive++
+	if b.threshold < 1 || b.tripped || b.consecutive < b.threshold {
+		return false
+	}
+	b.tripped = true
+	return true
+}
+
+// Open reports whether the breaker has tripped and remaining work should be skipped
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tripped
+}
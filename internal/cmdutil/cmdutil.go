@@ -0,0 +1,140 @@
+// Package cmdutil holds the helpers shared by the scraping subcommands.
+package cmdutil
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+	"divminder-crawler/internal/overrides"
+	"divminder-crawler/internal/scraper"
+)
+
+// SortedSymbols returns the symbols of a symbol -> group map in alphabetical order
+func SortedSymbols(etfs map[string]string) []string {
+	symbols := make([]string, 0, len(etfs))
+	for symbol := range etfs {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// WriteCombinedNDJSON writes every saved history's events to all_events.ndjson
+func WriteCombinedNDJSON(outputDir string) {
+	combinedFile := filepath.Join(outputDir, "all_events.ndjson")
+	count, err := export.SaveCombinedNDJSON(outputDir, combinedFile)
+	if err != nil {
+		log.Printf("Failed to save combined NDJSON: %v", err)
+		return
+	}
+	log.Printf("Combined NDJSON saved to: %s (%d events)", combinedFile, count)
+}
+
+// WriteTotalsByYear writes every saved history's calendar totals to totals_by_year.json
+func WriteTotalsByYear(outputDir string) {
+	count, err := export.SaveTotalsByYear(outputDir)
+	if err != nil {
+		log.Printf("Failed to save calendar totals: %v", err)
+		return
+	}
+	log.Printf("Calendar totals saved to: %s (%d ETFs)", filepath.Join(outputDir, export.TotalsByYearFileName), count)
+}
+
+// WriteSummary rebuilds the ETF summary next to outputDir from the histories
+// saved in it and returns the summary's path
+func WriteSummary(outputDir string, tracker *scraper.SymbolTracker) string {
+	summaryPath := export.SummaryPath(outputDir)
+	histories, err := export.LoadHistories(outputDir)
+	if err != nil {
+		log.Printf("Failed to read output directory: %v", err)
+		return summaryPath
+	}
+
+	summary := export.BuildSummary(histories, nil, tracker.IsActive, time.Now())
+	if err := export.SaveJSON(summaryPath, summary); err != nil {
+		log.Printf("Failed to save summary: %v", err)
+		return summaryPath
+	}
+	log.Printf("Summary saved to: %s (%d ETFs)", summaryPath, summary.TotalETFs)
+	return summaryPath
+}
+
+// LoadSymbolTracker loads the consecutive-404 state kept in outputRoot,
+// marking symbols inactive after inactiveAfter 404s in a row. It starts
+// empty if the state can't be read.
+func LoadSymbolTracker(outputRoot string, inactiveAfter int) *scraper.SymbolTracker {
+	path := scraper.SymbolStatusPath(outputRoot)
+	tracker, err := scraper.LoadSymbolTracker(path, inactiveAfter)
+	if err != nil {
+		log.Printf("Failed to load symbol status, treating all symbols as active: %v", err)
+		tracker = scraper.NewSymbolTracker(path, inactiveAfter)
+	}
+	return tracker
+}
+
+// RecordSymbolResult updates the 404 tracker and logs when a symbol goes inactive
+func RecordSymbolResult(tracker *scraper.SymbolTracker, symbol string, err error) {
+	if tracker.RecordResult(symbol, err, time.Now()) {
+		log.Printf("Marking %s inactive after %d consecutive 404s; pass -include-inactive to retry it", symbol, tracker.InactiveAfter())
+	}
+}
+
+// SaveSymbolTracker persists the 404 tracker state
+func SaveSymbolTracker(tracker *scraper.SymbolTracker) {
+	if err := tracker.Save(); err != nil {
+		log.Printf("Failed to save symbol status: %v", err)
+	}
+}
+
+// LoadOverrides loads the per-symbol overrides, continuing without them if the file is unreadable
+func LoadOverrides(path string) overrides.Overrides {
+	loaded, err := overrides.Load(path)
+	if err != nil {
+		log.Printf("Failed to load overrides, using scraped values: %v", err)
+		return overrides.Overrides{}
+	}
+	if len(loaded) > 0 {
+		log.Printf("Loaded overrides for %d symbols from %s", len(loaded), path)
+	}
+	return loaded
+}
+
+// MaxConcurrency is the most workers a scrape command accepts, to stay polite
+// to the YieldMax site
+const MaxConcurrency = 20
+
+// ValidateConcurrency checks a -concurrency value is between 1 and MaxConcurrency
+func ValidateConcurrency(n int) error {
+	if n < 1 || n > MaxConcurrency {
+		return fmt.Errorf("must be between 1 and %d, got %d", MaxConcurrency, n)
+	}
+	return nil
+}
+
+// ApplyOutlierFilter drops outlier events from a history when enabled
+func ApplyOutlierFilter(history *models.DividendHistory, enabled bool) {
+	if !enabled {
+		return
+	}
+	for _, event := range history.DropOutliers() {
+		log.Printf("Dropped outlier event for %s: %s $%.4f", history.Symbol, event.ExDate.Format("2006-01-02"), event.Amount)
+	}
+}
+
+// CheckWeekendExDates logs events whose ex-date falls on a weekend and, when
+// fix is set, moves them to the prior Friday
+func CheckWeekendExDates(history *models.DividendHistory, fix bool) {
+	for _, weekend := range history.CheckWeekendExDates(fix) {
+		exDate := weekend.Event.ExDate.Format("2006-01-02")
+		if weekend.Corrected.IsZero() {
+			log.Printf("Warning: %s ex-date %s falls on a %s (pass -fix-weekends to move it)", history.Symbol, exDate, weekend.Event.ExDate.Weekday())
+			continue
+		}
+		log.Printf("Warning: moved %s ex-date %s from a %s to %s", history.Symbol, exDate, weekend.Event.ExDate.Weekday(), weekend.Corrected.Format("2006-01-02"))
+	}
+}
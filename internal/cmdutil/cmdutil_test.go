@@ -0,0 +1,75 @@
+package cmdutil
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSortedSymbols(t *testing.T) {
+	many := make(map[string]string)
+	var manySorted []string
+	for i := 0; i < 200; i++ {
+		symbol := fmt.Sprintf("S%03d", (i*37)%200)
+		many[symbol] = "GroupA"
+		manySorted = append(manySorted, symbol)
+	}
+	sort.Strings(manySorted)
+
+	tests := []struct {
+		name string
+		etfs map[string]string
+		want []string
+	}{
+		{name: "empty", etfs: nil, want: []string{}},
+		{name: "mixed groups", etfs: map[string]string{"ULTY": "Weekly", "CONY": "GroupC", "TSLY": "GroupA"}, want: []string{"CONY", "TSLY", "ULTY"}},
+		{name: "many", etfs: many, want: manySorted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SortedSymbols(tt.etfs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortedSymbols = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConcurrency(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "1", want: 1},
+		{value: "8", want: 8},
+		{value: "20", want: MaxConcurrency},
+		{value: "0", wantErr: true},
+		{value: "-3", wantErr: true},
+		{value: "21", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			fs := flag.NewFlagSet("scrape", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			concurrency := fs.Int("concurrency", 3, "")
+			if err := fs.Parse([]string{"-concurrency", tt.value}); err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			err := ValidateConcurrency(*concurrency)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ValidateConcurrency(%d) succeeded, want error", *concurrency)
+				}
+				return
+			}
+			if err != nil || *concurrency != tt.want {
+				t.Errorf("ValidateConcurrency(%d) = %v, want %d accepted", *concurrency, err, tt.want)
+			}
+		})
+	}
+}
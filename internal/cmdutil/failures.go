@@ -0,0 +1,85 @@
+package cmdutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+
+	"divminder-crawler/internal/api"
+	"divminder-crawler/internal/scraper"
+)
+
+// Error classes recorded for failed symbols
+const (
+	ErrorClassNotFound = "not-found"    // The fund page returned 404
+	ErrorClassNetwork  = "network"      // Connection failure, timeout or 5xx
+	ErrorClassSkipped  = "circuit-open" // Not attempted after repeated failures
+	ErrorClassOther    = "other"
+)
+
+// ClassifyError returns the error class of a failed symbol's err
+func ClassifyError(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, scraper.ErrNotFound):
+		return ErrorClassNotFound
+	case errors.Is(err, ErrCircuitOpen):
+		return ErrorClassSkipped
+	case errors.Is(err, api.ErrNetwork), errors.As(err, &netErr):
+		return ErrorClassNetwork
+	}
+	return ErrorClassOther
+}
+
+// FailureExitCode returns 1 when more than maxRatio of total symbols failed,
+// so CI can fail the job, and 0 otherwise
+func FailureExitCode(failed, total int, maxRatio float64) int {
+	if total == 0 || failed == 0 {
+		return 0
+	}
+	if float64(failed)/float64(total) > maxRatio {
+		return 1
+	}
+	return 0
+}
+
+// ValidateFailureRatio checks a -max-failure-ratio value
+func ValidateFailureRatio(ratio float64) error {
+	if ratio < 0 || ratio > 1 {
+		return fmt.Errorf("%v must be between 0 and 1", ratio)
+	}
+	return nil
+}
+
+// PrintFailureSummary writes the failed symbols in sorted order, each with
+// its error class and message from report, between delimiter lines. It
+// writes nothing when no symbol failed.
+func PrintFailureSummary(w io.Writer, failed []string, report *RunReport) {
+	if len(failed) == 0 {
+		return
+	}
+
+	entries := make(map[string]SymbolReport, len(report.Symbols))
+	for _, entry := range report.Symbols {
+		entries[entry.Symbol] = entry
+	}
+	symbols := append([]string(nil), failed...)
+	sort.Strings(symbols)
+
+	rule := strings.Repeat("=", 60)
+	fmt.Fprintln(w, rule)
+	fmt.Fprintf(w, "FAILURES (%d)\n", len(symbols))
+	fmt.Fprintln(w, rule)
+	for _, symbol := range symbols {
+		entry := entries[symbol]
+		class := entry.ErrorClass
+		if class == "" {
+			class = ErrorClassOther
+		}
+		fmt.Fprintf(w, "%-6s  %-12s  %s\n", symbol, class, entry.Error)
+	}
+	fmt.Fprintln(w, rule)
+}
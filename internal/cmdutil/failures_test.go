@@ -0,0 +1,105 @@
+package cmdutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/api"
+	"divminder-crawler/internal/scraper"
+)
+
+func TestFailureExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		failed   int
+		total    int
+		maxRatio float64
+		want     int
+	}{
+		{"no failures", 0, 40, 0, 0},
+		{"empty run", 0, 0, 0.1, 0},
+		{"below the ratio", 3, 40, 0.1, 0},
+		// Exactly at the ratio still passes; only exceeding it fails
+		{"at the ratio", 4, 40, 0.1, 0},
+		{"above the ratio", 5, 40, 0.1, 1},
+		{"any failure with a zero ratio", 1, 40, 0, 1},
+		{"every symbol failed", 40, 40, 0.5, 1},
+		{"every symbol failed with ratio 1", 40, 40, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FailureExitCode(tt.failed, tt.total, tt.maxRatio); got != tt.want {
+				t.Errorf("FailureExitCode(%d, %d, %v) = %d, want %d", tt.failed, tt.total, tt.maxRatio, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFailureRatio(t *testing.T) {
+	for _, ratio := range []float64{0, 0.25, 1} {
+		if err := ValidateFailureRatio(ratio); err != nil {
+			t.Errorf("ValidateFailureRatio(%v) = %v, want nil", ratio, err)
+		}
+	}
+	for _, ratio := range []float64{-0.1, 1.5} {
+		if err := ValidateFailureRatio(ratio); err == nil {
+			t.Errorf("ValidateFailureRatio(%v) accepted", ratio)
+		}
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("failed to visit: %w", scraper.ErrNotFound), ErrorClassNotFound},
+		{ErrCircuitOpen, ErrorClassSkipped},
+		{fmt.Errorf("request failed: %w", api.ErrNetwork), ErrorClassNetwork},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, ErrorClassNetwork},
+		{errors.New("table missing"), ErrorClassOther},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("ClassifyError(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestPrintFailureSummary(t *testing.T) {
+	report := NewRunReport(time.Date(2025, 6, 10, 4, 0, 0, 0, time.UTC))
+	report.Record("TSLY", StatusScraped, errors.New("table missing"), 0, 0)
+	report.Record("GONE", StatusScraped, scraper.ErrNotFound, 0, 0)
+	report.Record("ULTY", StatusScraped, nil, 52, 0)
+
+	var buf bytes.Buffer
+	PrintFailureSummary(&buf, []string{"TSLY", "GONE"}, report)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("summary has %d lines, want 6:\n%s", len(lines), buf.String())
+	}
+	if lines[1] != "FAILURES (2)" {
+		t.Errorf("header = %q, want FAILURES (2)", lines[1])
+	}
+	if fields := strings.Fields(lines[3]); len(fields) < 2 || fields[0] != "GONE" || fields[1] != ErrorClassNotFound {
+		t.Errorf("first failure = %q, want GONE as %s", lines[3], ErrorClassNotFound)
+	}
+	if fields := strings.Fields(lines[4]); len(fields) < 2 || fields[0] != "TSLY" || fields[1] != ErrorClassOther {
+		t.Errorf("second failure = %q, want TSLY as %s", lines[4], ErrorClassOther)
+	}
+	if lines[0] != lines[5] || lines[0] != lines[2] {
+		t.Errorf("summary not delimited:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	PrintFailureSummary(&buf, nil, report)
+	if buf.Len() != 0 {
+		t.Errorf("printed %q without failures", buf.String())
+	}
+}
@@ -0,0 +1,133 @@
+package cmdutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// LockFileName is the run lock kept in the directory a run writes to
+const LockFileName = ".crawl.lock"
+
+// DefaultLockStaleAfter is how old a lock may get before another run takes
+// it over, in case its holder died on another machine
+const DefaultLockStaleAfter = 6 * time.Hour
+
+// ErrLocked is returned when another run holds the lock
+var ErrLocked = errors.New("another run holds the lock")
+
+// LockInfo is what a lock file records about its holder
+type LockInfo struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// RunLock is a lock file held for the length of a run so overlapping
+// scheduled runs don't write the same files at once
+type RunLock struct {
+	path string
+}
+
+// LockPath returns the run lock's path in dir
+func LockPath(dir string) string {
+	return filepath.Join(dir, LockFileName)
+}
+
+// AcquireRunLock creates the lock file at path, failing with ErrLocked while
+// another run holds it. A lock left by a process that is no longer running
+// on this host, or older than staleAfter, is taken over.
+func AcquireRunLock(path string, staleAfter time.Duration) (*RunLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	host, _ := os.Hostname()
+	info := LockInfo{PID: os.Getpid(), Host: host, StartedAt: time.Now()}
+
+	// A second attempt follows removing a stale lock
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			err = json.NewEncoder(file).Encode(info)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock %s: %w", path, err)
+			}
+			return &RunLock{path: path}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock %s: %w", path, err)
+		}
+
+		holder, err := readLock(path)
+		if err != nil {
+			return nil, err
+		}
+		if !holder.stale(host, staleAfter) {
+			return nil, fmt.Errorf("%w: %s %s", ErrLocked, path, holder)
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove stale lock %s: %w", path, err)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s was recreated while taking over a stale lock", ErrLocked, path)
+}
+
+// Release removes the lock file
+func (l *RunLock) Release() error {
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to release lock %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// readLock reads a lock's holder. A lock that can't be parsed, e.g. one cut
+// short by a crash, is dated by its modification time.
+func readLock(path string) (LockInfo, error) {
+	var info LockInfo
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return info, fmt.Errorf("failed to read lock %s: %w", path, err)
+	}
+	if err == nil && json.Unmarshal(data, &info) == nil {
+		return info, nil
+	}
+	if stat, err := os.Stat(path); err == nil {
+		info.StartedAt = stat.ModTime()
+	}
+	return info, nil
+}
+
+// String describes the holder for error messages
+func (info LockInfo) String() string {
+	since := info.StartedAt.Format(time.RFC3339)
+	if info.PID == 0 {
+		return "has no readable holder, written " + since
+	}
+	return fmt.Sprintf("held by pid %d on %s since %s", info.PID, info.Host, since)
+}
+
+// stale reports whether the lock's holder is gone
+func (info LockInfo) stale(host string, staleAfter time.Duration) bool {
+	if staleAfter > 0 && time.Since(info.StartedAt) > staleAfter {
+		return true
+	}
+	return info.Host == host && info.PID > 0 && !processRunning(info.PID)
+}
+
+// processRunning reports whether a process with pid exists on this host
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
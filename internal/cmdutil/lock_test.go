@@ -0,0 +1,125 @@
+package cmdutil
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeLock(t *testing.T, path string, info LockInfo) {
+	t.Helper()
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireRunLockRefusesHeldLock(t *testing.T) {
+	path := LockPath(t.TempDir())
+
+	first, err := AcquireRunLock(path, DefaultLockStaleAfter)
+	if err != nil {
+		t.Fatalf("first AcquireRunLock: %v", err)
+	}
+
+	// A second run while the first holds the lock bails out, naming the holder
+	_, err = AcquireRunLock(path, DefaultLockStaleAfter)
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("second AcquireRunLock error = %v, want ErrLocked", err)
+	}
+	if want := "pid " + strconv.Itoa(os.Getpid()); !strings.Contains(err.Error(), want) {
+		t.Errorf("error %q doesn't name the holder (%s)", err, want)
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock file left after Release (err %v)", err)
+	}
+	second, err := AcquireRunLock(path, DefaultLockStaleAfter)
+	if err != nil {
+		t.Fatalf("AcquireRunLock after Release: %v", err)
+	}
+	second.Release()
+}
+
+func TestAcquireRunLockTakesOverStaleLock(t *testing.T) {
+	host, _ := os.Hostname()
+
+	tests := []struct {
+		name      string
+		holder    *LockInfo // nil writes an unreadable lock
+		modTime   time.Time
+		wantTaken bool
+	}{
+		{
+			name:      "holder no longer running",
+			holder:    &LockInfo{PID: 1 << 30, Host: host, StartedAt: time.Now()},
+			wantTaken: true,
+		},
+		{
+			name:      "older than the stale age",
+			holder:    &LockInfo{PID: os.Getpid(), Host: host, StartedAt: time.Now().Add(-7 * time.Hour)},
+			wantTaken: true,
+		},
+		{
+			// A process on another machine can't be checked, so only age frees its lock
+			name:   "recent holder on another host",
+			holder: &LockInfo{PID: 1 << 30, Host: host + "-other", StartedAt: time.Now()},
+		},
+		{
+			name:    "recent unreadable lock",
+			modTime: time.Now(),
+		},
+		{
+			name:      "old unreadable lock",
+			modTime:   time.Now().Add(-7 * time.Hour),
+			wantTaken: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), LockFileName)
+			if tt.holder != nil {
+				writeLock(t, path, *tt.holder)
+			} else {
+				if err := os.WriteFile(path, []byte("{\"pid\":"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chtimes(path, tt.modTime, tt.modTime); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			lock, err := AcquireRunLock(path, DefaultLockStaleAfter)
+			if !tt.wantTaken {
+				if !errors.Is(err, ErrLocked) {
+					t.Fatalf("AcquireRunLock error = %v, want ErrLocked", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AcquireRunLock: %v", err)
+			}
+			defer lock.Release()
+
+			held, err := readLock(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if held.PID != os.Getpid() || held.Host != host {
+				t.Errorf("lock now held by %+v, want this process", held)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package cmdutil
+
+import (
+	"log"
+	"time"
+
+	"divminder-crawler/internal/export"
+)
+
+// PruneStaleFiles removes the per-symbol files in dir that no run has
+// rewritten within olderThan, except those of the symbols this run checked;
+// the rest belong to delisted tickers. 0 keeps them all.
+func PruneStaleFiles(dir string, olderThan time.Duration, symbols []string) {
+	if olderThan <= 0 {
+		return
+	}
+	removed, err := export.PruneStaleSymbolFiles(dir, olderThan, time.Now(), symbols)
+	if err != nil {
+		log.Printf("Failed to prune stale files: %v", err)
+	}
+	for _, path := range removed {
+		log.Printf("Pruned stale file %s", path)
+	}
+}
+
+// FinishOutput writes gzip copies of the data files in dir when gzip is set
+// and logs the total size of the output
+func FinishOutput(dir string, gzip bool) {
+	if gzip {
+		if count, err := export.WriteGzipCopies(dir); err != nil {
+			log.Printf("Failed to write gzip copies: %v", err)
+		} else {
+			log.Printf("Wrote %d gzip copies", count)
+		}
+	}
+	if report, err := export.MeasureOutput(dir); err != nil {
+		log.Printf("Failed to measure output: %v", err)
+	} else {
+		log.Printf("Output size of %s: %s", dir, report)
+	}
+}
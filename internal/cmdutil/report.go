@@ -0,0 +1,168 @@
+package cmdutil
+
+import (
+	"errors"
+	"log"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+)
+
+// RunReportFileName is the machine-readable report written next to the summary
+const RunReportFileName = "run_report.json"
+
+// Symbol statuses in a run report
+const (
+	StatusScraped = "scraped"
+	StatusCached  = "cached"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// SymbolReport is one symbol's outcome in a run
+type SymbolReport struct {
+	Symbol     string `json:"symbol"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	ErrorClass string `json:"errorClass,omitempty"` // One of the ErrorClass constants
+	Events     int    `json:"events,omitempty"`
+	DurationMS int64  `json:"durationMs"`
+	// Gaps are spans with missing payments; their symbols should be re-scraped
+	Gaps []models.DateRange `json:"gaps,omitempty"`
+}
+
+// RunTotals counts symbols by status
+type RunTotals struct {
+	Symbols int `json:"symbols"`
+	Scraped int `json:"scraped"`
+	Cached  int `json:"cached"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+	Gaps    int `json:"gaps"` // Symbols whose history has gaps
+}
+
+// RunReport collects per-symbol outcomes; Record is safe to call from several goroutines
+type RunReport struct {
+	mu         sync.Mutex
+	StartedAt  time.Time      `json:"startedAt"`
+	FinishedAt time.Time      `json:"finishedAt"`
+	Degraded   bool           `json:"degraded"`
+	Reason     string         `json:"reason,omitempty"`
+	Totals     RunTotals      `json:"totals"`
+	Symbols    []SymbolReport `json:"symbols"`
+}
+
+// NewRunReport starts a report for a run beginning at startedAt
+func NewRunReport(startedAt time.Time) *RunReport {
+	return &RunReport{StartedAt: startedAt, Symbols: []SymbolReport{}}
+}
+
+// MarkDegraded flags the run as incomplete, keeping the first reason given
+func (r *RunReport) MarkDegraded(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.Degraded {
+		r.Degraded = true
+		r.Reason = reason
+	}
+}
+
+// Record adds a symbol's outcome. A non-nil err marks it failed regardless of
+// status, except that ErrCircuitOpen marks it skipped.
+func (r *RunReport) Record(symbol, status string, err error, events int, duration time.Duration) {
+	entry := SymbolReport{
+		Symbol:     symbol,
+		Status:     status,
+		Events:     events,
+		DurationMS: duration.Milliseconds(),
+	}
+	if err != nil {
+		entry.Status = StatusFailed
+		if errors.Is(err, ErrCircuitOpen) {
+			entry.Status = StatusSkipped
+		}
+		entry.Error = err.Error()
+		entry.ErrorClass = ClassifyError(err)
+		entry.Events = 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Symbols = append(r.Symbols, entry)
+	r.Totals.Symbols++
+	switch entry.Status {
+	case StatusScraped:
+		r.Totals.Scraped++
+	case StatusCached:
+		r.Totals.Cached++
+	case StatusFailed:
+		r.Totals.Failed++
+	case StatusSkipped:
+		r.Totals.Skipped++
+	}
+}
+
+// FlagGaps attaches history gaps to symbol's most recent entry so the symbol
+// is marked for re-scraping; it does nothing when gaps is empty
+func (r *RunReport) FlagGaps(symbol string, gaps []models.DateRange) {
+	if len(gaps) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.Symbols) - 1; i >= 0; i-- {
+		if r.Symbols[i].Symbol != symbol {
+			continue
+		}
+		if r.Symbols[i].Gaps == nil {
+			r.Totals.Gaps++
+		}
+		r.Symbols[i].Gaps = gaps
+		return
+	}
+}
+
+// RecordGaps detects gaps in history, logs them and flags the symbol in report
+func RecordGaps(report *RunReport, history *models.DividendHistory) {
+	gaps := models.DetectGaps(history)
+	for _, gap := range gaps {
+		log.Printf("Warning: %s history is missing %s payments from %s to %s; re-scrape it", history.Symbol, history.Frequency, gap.Start.Format("2006-01-02"), gap.End.Format("2006-01-02"))
+	}
+	report.FlagGaps(history.Symbol, gaps)
+}
+
+// Save sorts the symbols, stamps the finish time and atomically writes the report
+func (r *RunReport) Save(filename string, finishedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sort.Slice(r.Symbols, func(i, j int) bool {
+		return r.Symbols[i].Symbol < r.Symbols[j].Symbol
+	})
+	r.FinishedAt = finishedAt
+
+	return export.SaveJSON(filename, r)
+}
+
+// RunReportPath returns where the run report goes for an output directory: its parent, beside the summary
+func RunReportPath(outputDir string) string {
+	return filepath.Join(filepath.Dir(filepath.Clean(outputDir)), RunReportFileName)
+}
+
+// SaveRunReport writes the report for outputDir, logging rather than failing the run
+func SaveRunReport(report *RunReport, outputDir string) {
+	path := RunReportPath(outputDir)
+	if err := report.Save(path, time.Now()); err != nil {
+		log.Printf("Failed to save run report: %v", err)
+		return
+	}
+	log.Printf("Run report saved to: %s", path)
+}
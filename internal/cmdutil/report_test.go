@@ -0,0 +1,143 @@
+package cmdutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/models"
+	"divminder-crawler/internal/scraper"
+)
+
+func TestRunReportRecordsMixedResults(t *testing.T) {
+	started := time.Date(2025, 6, 10, 4, 0, 0, 0, time.UTC)
+	report := NewRunReport(started)
+
+	results := []struct {
+		symbol   string
+		status   string
+		err      error
+		events   int
+		duration time.Duration
+	}{
+		{"ULTY", StatusScraped, nil, 52, 1500 * time.Millisecond},
+		{"TSLY", StatusCached, nil, 0, 0},
+		{"GONE", StatusScraped, fmt.Errorf("failed to visit: %w", scraper.ErrNotFound), 7, 300 * time.Millisecond},
+		{"FLKY", StatusScraped, errors.New("table missing"), 0, 2 * time.Second},
+		{"LATE", StatusScraped, ErrCircuitOpen, 0, 0},
+	}
+
+	// Workers report concurrently, as the scrape commands do
+	var wg sync.WaitGroup
+	for _, r := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Record(r.symbol, r.status, r.err, r.events, r.duration)
+		}()
+	}
+	wg.Wait()
+
+	dir := t.TempDir()
+	outputDir := filepath.Join(dir, "data")
+	path := RunReportPath(outputDir)
+	if path != filepath.Join(dir, RunReportFileName) {
+		t.Errorf("RunReportPath = %s, want beside the output directory", path)
+	}
+	finished := started.Add(time.Minute)
+	if err := report.Save(path, finished); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved RunReport
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+
+	wantTotals := RunTotals{Symbols: 5, Scraped: 1, Cached: 1, Failed: 2, Skipped: 1}
+	if saved.Totals != wantTotals {
+		t.Errorf("totals = %+v, want %+v", saved.Totals, wantTotals)
+	}
+	if !saved.StartedAt.Equal(started) || !saved.FinishedAt.Equal(finished) {
+		t.Errorf("run spans %s to %s, want %s to %s", saved.StartedAt, saved.FinishedAt, started, finished)
+	}
+
+	want := map[string]SymbolReport{
+		"FLKY": {Symbol: "FLKY", Status: StatusFailed, Error: "table missing", ErrorClass: ErrorClassOther, DurationMS: 2000},
+		"GONE": {Symbol: "GONE", Status: StatusFailed, Error: "failed to visit: " + scraper.ErrNotFound.Error(), ErrorClass: ErrorClassNotFound, DurationMS: 300},
+		"LATE": {Symbol: "LATE", Status: StatusSkipped, Error: ErrCircuitOpen.Error(), ErrorClass: ErrorClassSkipped},
+		"TSLY": {Symbol: "TSLY", Status: StatusCached},
+		"ULTY": {Symbol: "ULTY", Status: StatusScraped, Events: 52, DurationMS: 1500},
+	}
+	if len(saved.Symbols) != len(want) {
+		t.Fatalf("report has %d symbols, want %d", len(saved.Symbols), len(want))
+	}
+	for i, got := range saved.Symbols {
+		if i > 0 && saved.Symbols[i-1].Symbol >= got.Symbol {
+			t.Errorf("symbols not sorted: %s before %s", saved.Symbols[i-1].Symbol, got.Symbol)
+		}
+		w := want[got.Symbol]
+		if got.Symbol != w.Symbol || got.Status != w.Status || got.Error != w.Error || got.ErrorClass != w.ErrorClass ||
+			got.Events != w.Events || got.DurationMS != w.DurationMS {
+			t.Errorf("%s = %+v, want %+v", got.Symbol, got, w)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d entries, want only the report", len(entries))
+	}
+}
+
+func TestRunReportMarkDegradedKeepsFirstReason(t *testing.T) {
+	report := NewRunReport(time.Time{})
+	report.MarkDegraded("circuit breaker tripped")
+	report.MarkDegraded("later reason")
+	if !report.Degraded || report.Reason != "circuit breaker tripped" {
+		t.Errorf("degraded=%v reason=%q, want the first reason", report.Degraded, report.Reason)
+	}
+}
+
+func TestRecordGapsFlagsSymbolForRescrape(t *testing.T) {
+	report := NewRunReport(time.Date(2025, 6, 10, 4, 0, 0, 0, time.UTC))
+	report.Record("ULTY", StatusScraped, nil, 4, time.Second)
+	report.Record("TSLY", StatusScraped, nil, 3, time.Second)
+
+	last := time.Date(2025, 6, 5, 0, 0, 0, 0, models.MarketLocation)
+	gapped := &models.DividendHistory{Symbol: "ULTY", Frequency: "weekly", Events: []models.DividendEvent{
+		{ExDate: last}, {ExDate: last.AddDate(0, 0, -7)}, {ExDate: last.AddDate(0, 0, -28)}, {ExDate: last.AddDate(0, 0, -35)},
+	}}
+	complete := &models.DividendHistory{Symbol: "TSLY", Frequency: "monthly", Events: []models.DividendEvent{
+		{ExDate: last}, {ExDate: last.AddDate(0, -1, 0)}, {ExDate: last.AddDate(0, -2, 0)},
+	}}
+	RecordGaps(report, gapped)
+	RecordGaps(report, complete)
+
+	if report.Totals.Gaps != 1 {
+		t.Errorf("Totals.Gaps = %d, want 1", report.Totals.Gaps)
+	}
+	for _, entry := range report.Symbols {
+		switch entry.Symbol {
+		case "ULTY":
+			if len(entry.Gaps) != 1 || !entry.Gaps[0].Start.Equal(last.AddDate(0, 0, -21)) || !entry.Gaps[0].End.Equal(last.AddDate(0, 0, -14)) {
+				t.Errorf("ULTY gaps = %+v, want the two missing weeks", entry.Gaps)
+			}
+		case "TSLY":
+			if entry.Gaps != nil {
+				t.Errorf("TSLY flagged with gaps %+v", entry.Gaps)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package cmdutil
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"divminder-crawler/internal/scraper"
+)
+
+// ReadSymbolsFile reads one symbol per line, upper-cased, in file order.
+// Blank lines and anything after a # are ignored, and repeats are dropped.
+func ReadSymbolsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open symbols file: %w", err)
+	}
+	defer file.Close()
+
+	var symbols []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		symbol := strings.ToUpper(strings.TrimSpace(line))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		symbols = append(symbols, symbol)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read symbols file: %w", err)
+	}
+	return symbols, nil
+}
+
+// SelectSymbols returns the sorted symbols of etfs to work on: those listed
+// in symbolsFile when it is set, narrowed to group's members when group is
+// set. A listed symbol outside etfs, or a selection left empty, is an error.
+func SelectSymbols(etfs map[string]string, symbolsFile, group string, groups *scraper.GroupResolver) ([]string, error) {
+	symbols := SortedSymbols(etfs)
+
+	if symbolsFile != "" {
+		listed, err := ReadSymbolsFile(symbolsFile)
+		if err != nil {
+			return nil, err
+		}
+		wanted := make(map[string]bool, len(listed))
+		var unknown []string
+		for _, symbol := range listed {
+			if _, ok := etfs[symbol]; !ok {
+				unknown = append(unknown, symbol)
+			}
+			wanted[symbol] = true
+		}
+		if len(unknown) > 0 {
+			return nil, fmt.Errorf("%s lists unknown symbols: %s", symbolsFile, strings.Join(unknown, ", "))
+		}
+		symbols = filterSymbols(symbols, func(symbol string) bool { return wanted[symbol] })
+	}
+
+	if group != "" {
+		symbols = filterSymbols(symbols, func(symbol string) bool {
+			return strings.EqualFold(groups.ResolveGroup(symbol), group)
+		})
+	}
+
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("no ETFs selected")
+	}
+	return symbols, nil
+}
+
+func filterSymbols(symbols []string, keep func(symbol string) bool) []string {
+	var kept []string
+	for _, symbol := range symbols {
+		if keep(symbol) {
+			kept = append(kept, symbol)
+		}
+	}
+	return kept
+}
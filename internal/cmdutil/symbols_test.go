@@ -0,0 +1,69 @@
+package cmdutil
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"divminder-crawler/internal/overrides"
+	"divminder-crawler/internal/scraper"
+)
+
+func TestReadSymbolsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "symbols.txt")
+	content := "# watch list\ntsly\n\n  CONY  # coin\nTSLY\r\n\t\nulty\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadSymbolsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"TSLY", "CONY", "ULTY"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadSymbolsFile = %v, want %v", got, want)
+	}
+
+	if _, err := ReadSymbolsFile(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("ReadSymbolsFile of a missing file succeeded")
+	}
+}
+
+func TestSelectSymbols(t *testing.T) {
+	etfs := map[string]string{"CONY": "GroupC", "MSTY": "GroupC", "TSLY": "GroupA", "ULTY": "Weekly"}
+	groups := scraper.NewGroupResolverFromFile(overrides.Overrides{
+		"CONY": {Group: "GroupC"}, "MSTY": {Group: "GroupC"}, "TSLY": {Group: "GroupA"}, "ULTY": {Group: "Weekly"},
+	}, "")
+
+	dir := t.TempDir()
+	listed := filepath.Join(dir, "listed.txt")
+	os.WriteFile(listed, []byte("ULTY\nCONY\n"), 0644)
+	unknown := filepath.Join(dir, "unknown.txt")
+	os.WriteFile(unknown, []byte("CONY\nNOPE\n"), 0644)
+	commented := filepath.Join(dir, "commented.txt")
+	os.WriteFile(commented, []byte("# nothing tracked yet\n\n"), 0644)
+
+	tests := []struct {
+		name        string
+		symbolsFile string
+		group       string
+		want        []string
+		wantErr     bool
+	}{
+		{"everything", "", "", []string{"CONY", "MSTY", "TSLY", "ULTY"}, false},
+		{"group", "", "groupc", []string{"CONY", "MSTY"}, false},
+		{"file", listed, "", []string{"CONY", "ULTY"}, false},
+		{"file and group", listed, "Weekly", []string{"ULTY"}, false},
+		{"nothing left", listed, "GroupA", nil, true},
+		{"unknown symbol", unknown, "", nil, true},
+		{"only comments", commented, "", nil, true},
+		{"missing file", filepath.Join(dir, "missing.txt"), "", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := SelectSymbols(etfs, tt.symbolsFile, tt.group, groups)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: SelectSymbols = %v, %v; want %v (error %v)", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package fixdata
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+	"divminder-crawler/internal/overrides"
+	"divminder-crawler/internal/scraper"
+)
+
+// Run regenerates the ETF list and sample histories from data/etf_correct_data.json
+func Run(args []string) {
+	fs := flag.NewFlagSet("fix", flag.ExitOnError)
+	outputDir := fs.String("out", "data", "Directory to write the fixed ETF list and histories to")
+	overridesPath := fs.String("overrides", overrides.DefaultFile, "Per-symbol name/group/frequency/description overrides applied over the defaults")
+	compact := fs.Bool("compact", false, "Write JSON output without indentation to shrink published files")
+	pretty := fs.Bool("pretty", false, "Write indented JSON output (the default); overrides -compact")
+	fs.Parse(args)
+
+	export.SetCompactJSON(*compact && !*pretty)
+
+	if err := export.EnsureOutputDir(*outputDir); err != nil {
+		log.Fatal(err)
+	}
+
+	// Load correct ETF data
+	symbolOverrides, err := overrides.Load(*overridesPath)
+	if err != nil {
+		log.Fatalf("Failed to load overrides: %v", err)
+	}
+
+	// Get all ETF groups
+	etfGroups := scraper.GetYieldMaxETFGroups()
+
+	// Create corrected ETF list
+	var etfs []models.ETF
+
+	// Create ETFs with correct information
+	for symbol, group := range etfGroups {
+		etf := models.ETF{
+			Symbol: symbol,
+			Group:  group,
+			Active: true,
+		}
+
+		// Default based on group
+		switch group {
+		case "Target12":
+			etf.Frequency = "monthly"
+			etf.Description = fmt.Sprintf("YieldMax %s Target 12 ETF", symbol)
+		case "Weekly":
+			etf.Frequency = "weekly"
+			etf.Description = fmt.Sprintf("YieldMax %s Weekly ETF", symbol)
+		default:
+			etf.Frequency = "weekly"
+			etf.Description = fmt.Sprintf("YieldMax %s Option Income Strategy ETF", symbol)
+		}
+
+		// Set name based on group
+		switch group {
+		case "Target12":
+			etf.Name = fmt.Sprintf("YieldMax %s Target 12 ETF", symbol)
+		case "Weekly":
+			etf.Name = fmt.Sprintf("YieldMax %s Weekly ETF", symbol)
+		default:
+			etf.Name = fmt.Sprintf("YieldMax %s Option Income Strategy ETF", symbol)
+		}
+
+		// Apply correct data if available
+		symbolOverrides.ApplyETF(&etf)
+
+		// Set placeholder dates (these should be updated from schedule)
+		nextDate := getNextDividendDate(etf.Group, clock.Real{})
+		etf.NextExDate = nextDate.Format("2006-01-02")
+		etf.NextPayDate = nextDate.AddDate(0, 0, 1).Format("2006-01-02")
+
+		etfs = append(etfs, etf)
+	}
+
+	// Save corrected ETF list
+	if err := export.SaveJSON(filepath.Join(*outputDir, "etfs_fixed.json"), etfs); err != nil {
+		log.Fatalf("Failed to write ETFs: %v", err)
+	}
+
+	fmt.Printf("Created fixed ETF data with %d ETFs\n", len(etfs))
+
+	// Create sample dividend history for CONY with correct dates
+	createSampleDividendHistory(*outputDir, "CONY", "monthly", "GroupC")
+	createSampleDividendHistory(*outputDir, "TSLY", "weekly", "GroupA")
+	createSampleDividendHistory(*outputDir, "NVDY", "weekly", "GroupA")
+}
+
+func getNextDividendDate(group string, clk clock.Clock) time.Time {
+	now := clk.Now()
+
+	// Group schedules (simplified)
+	switch group {
+	case "GroupA":
+		// Every Wednesday
+		for d := now; ; d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == time.Wednesday {
+				return d
+			}
+		}
+	case "GroupB":
+		// Every Wednesday
+		for d := now; ; d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == time.Wednesday {
+				return d
+			}
+		}
+	case "GroupC":
+		// Every Wednesday
+		for d := now; ; d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == time.Wednesday {
+				return d
+			}
+		}
+	case "GroupD":
+		// Every Wednesday
+		for d := now; ; d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == time.Wednesday {
+				return d
+			}
+		}
+	case "Weekly":
+		// Every Thursday
+		for d := now; ; d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == time.Thursday {
+				return d
+			}
+		}
+	case "Target12":
+		// First Wednesday of month
+		nextMonth := now.AddDate(0, 1, 0)
+		for d := time.Date(nextMonth.Year(), nextMonth.Month(), 1, 0, 0, 0, 0, now.Location()); d.Month() == nextMonth.Month(); d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == time.Wednesday {
+				return d
+			}
+		}
+	}
+
+	return now.AddDate(0, 0, 7) // Default to next week
+}
+
+func createSampleDividendHistory(outputDir, symbol, frequency, group string) {
+	var events []models.DividendEvent
+	now := time.Now()
+
+	// Generate historical dividend events
+	numEvents := 12
+	if frequency == "weekly" {
+		numEvents = 52
+	}
+
+	for i := 0; i < numEvents; i++ {
+		var exDate time.Time
+
+		if frequency == "monthly" {
+			// Monthly: last Wednesday of each month going back
+			exDate = now.AddDate(0, -i, 0)
+			// Find last Wednesday of that month
+			lastDay := time.Date(exDate.Year(), exDate.Month()+1, 0, 0, 0, 0, 0, exDate.Location())
+			for d := lastDay; d.Month() == exDate.Month(); d = d.AddDate(0, 0, -1) {
+				if d.Weekday() == time.Wednesday {
+					exDate = d
+					break
+				}
+			}
+		} else {
+			// Weekly: every Wednesday going back
+			exDate = now.AddDate(0, 0, -i*7)
+			for exDate.Weekday() != time.Wednesday {
+				exDate = exDate.AddDate(0, 0, -1)
+			}
+		}
+
+		// Generate realistic dividend amount (varies between 0.10 and 0.80)
+		baseAmount := 0.30
+		variation := float64(i%5)*0.1 - 0.2
+		amount := baseAmount + variation
+		if amount < 0.10 {
+			amount = 0.10
+		}
+		if amount > 0.80 {
+			amount = 0.80
+		}
+
+		event := models.DividendEvent{
+			Symbol:      symbol,
+			ExDate:      exDate,
+			PayDate:     exDate.AddDate(0, 0, 1),
+			DeclareDate: exDate.AddDate(0, 0, -3),
+			Amount:      models.RoundAmount(amount),
+			Group:       group,
+			Frequency:   frequency,
+			Estimated:   true,
+			Source:      models.SourceSynthetic,
+		}
+
+		events = append(events, event)
+	}
+
+	// Calculate stats
+	models.SortEventsDescending(events)
+	var totalMicros int64
+	for _, event := range events {
+		totalMicros += models.AmountToMicros(event.Amount)
+	}
+	totalAmount := models.MicrosToAmount(totalMicros)
+
+	history := models.DividendHistory{
+		Symbol:    symbol,
+		Name:      fmt.Sprintf("YieldMax %s Option Income Strategy ETF", symbol),
+		Group:     group,
+		Frequency: frequency,
+		Events:    events,
+		Stats: models.DividendStats{
+			TotalPayments:     len(events),
+			AverageAmount:     models.RoundAmount(totalAmount / float64(len(events))),
+			LastAmount:        events[0].Amount,
+			YearToDateTotal:   models.RoundAmount(totalAmount * 0.5), // Approximate
+			TrailingYearTotal: models.RoundAmount(totalAmount),
+		},
+		UpdatedAt: now,
+	}
+
+	// Save to file
+	filename := filepath.Join(outputDir, fmt.Sprintf("dividends_%s_fixed.json", symbol))
+	if err := export.SaveJSON(filename, history); err != nil {
+		log.Printf("Failed to write history for %s: %v", symbol, err)
+		return
+	}
+
+	fmt.Printf("Created fixed dividend history for %s with %d events\n", symbol, len(events))
+}
@@ -0,0 +1,40 @@
+package fixdata
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestSampleHistoryIsMarkedSynthetic(t *testing.T) {
+	for _, frequency := range []string{"weekly", "monthly"} {
+		t.Run(frequency, func(t *testing.T) {
+			dir := t.TempDir()
+			createSampleDividendHistory(dir, "TEST", frequency, "GroupA")
+
+			data, err := os.ReadFile(filepath.Join(dir, "dividends_TEST_fixed.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var history models.DividendHistory
+			if err := json.Unmarshal(data, &history); err != nil {
+				t.Fatal(err)
+			}
+			if len(history.Events) == 0 {
+				t.Fatal("no events generated")
+			}
+			for _, e := range history.Events {
+				if e.Source != models.SourceSynthetic || !e.Estimated {
+					t.Errorf("event %s: source %q (estimated %v), want an estimated synthetic event",
+						e.ExDate.Format("2006-01-02"), e.Source, e.Estimated)
+				}
+				if err := e.Validate(); err != nil {
+					t.Errorf("generated event invalid: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package list
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"divminder-crawler/internal/cmdutil"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/overrides"
+	"divminder-crawler/internal/scraper"
+)
+
+// entry is one ETF of the listed universe
+type entry struct {
+	Symbol    string `json:"symbol"`
+	Group     string `json:"group"`
+	Frequency string `json:"frequency"`
+}
+
+// Run prints every known symbol with its resolved group and frequency,
+// without any network access
+func Run(args []string) {
+	if err := run(args, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run is Run with the list written to stdout and failures returned
+func run(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	format := fs.String("format", "json", "Output format: json or csv")
+	group := fs.String("group", "", "Only list ETFs in this group, e.g. GroupC or Target12")
+	overridesPath := fs.String("overrides", overrides.DefaultFile, "Per-symbol name/group/frequency/description overrides applied over the mappings")
+	compact := fs.Bool("compact", false, "Print JSON without indentation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != "json" && *format != "csv" {
+		return fmt.Errorf("invalid -format %q: must be json or csv", *format)
+	}
+	export.SetCompactJSON(*compact)
+
+	entries := universe(scraper.NewGroupResolver(cmdutil.LoadOverrides(*overridesPath)), *group)
+	if len(entries) == 0 && *group != "" {
+		log.Printf("No ETFs in group %s", *group)
+	}
+
+	if *format == "csv" {
+		if err := writeCSV(stdout, entries); err != nil {
+			return fmt.Errorf("failed to print list: %w", err)
+		}
+		return nil
+	}
+	if err := export.WriteJSON(stdout, entries); err != nil {
+		return fmt.Errorf("failed to print list: %w", err)
+	}
+	return nil
+}
+
+// universe resolves every known symbol, keeping only group's members when
+// group is set
+func universe(groups *scraper.GroupResolver, group string) []entry {
+	entries := []entry{}
+	for _, symbol := range groups.Symbols() {
+		resolved := groups.ResolveGroup(symbol)
+		if group != "" && !strings.EqualFold(resolved, group) {
+			continue
+		}
+		entries = append(entries, entry{
+			Symbol:    symbol,
+			Group:     resolved,
+			Frequency: groups.ResolveFrequency(symbol, resolved),
+		})
+	}
+	return entries
+}
+
+// writeCSV prints entries with a header row to out
+func writeCSV(out io.Writer, entries []entry) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"symbol", "group", "frequency"})
+	for _, e := range entries {
+		w.Write([]string{e.Symbol, e.Group, e.Frequency})
+	}
+	w.Flush()
+	return w.Error()
+}
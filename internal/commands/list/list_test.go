@@ -0,0 +1,95 @@
+package list
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"divminder-crawler/internal/scraper"
+)
+
+// writeOverrides saves an overrides file moving ULTY into GroupC
+func writeOverrides(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "overrides.json")
+	if err := os.WriteFile(path, []byte(`{"ULTY": {"group": "GroupC"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestListUniverse(t *testing.T) {
+	var stdout bytes.Buffer
+	if err := run([]string{"-overrides", filepath.Join(t.TempDir(), "missing.json")}, &stdout); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	var entries []entry
+	if err := json.Unmarshal(stdout.Bytes(), &entries); err != nil {
+		t.Fatalf("output is not a JSON list: %v", err)
+	}
+
+	static := scraper.GetYieldMaxETFGroups()
+	if len(entries) != len(static) {
+		t.Errorf("listed %d ETFs, want the %d mapped", len(entries), len(static))
+	}
+	for _, e := range entries {
+		if e.Group != static[e.Symbol] {
+			t.Errorf("%s listed in %s, want %s", e.Symbol, e.Group, static[e.Symbol])
+		}
+		if e.Frequency != scraper.GroupFrequency(e.Group) {
+			t.Errorf("%s listed as %s, want %s", e.Symbol, e.Frequency, scraper.GroupFrequency(e.Group))
+		}
+	}
+}
+
+func TestListFiltersByGroup(t *testing.T) {
+	var want []string
+	for symbol, group := range scraper.GetYieldMaxETFGroups() {
+		if group == "GroupC" {
+			want = append(want, symbol)
+		}
+	}
+	want = append(want, "ULTY")
+	sort.Strings(want)
+
+	// Group names match case-insensitively
+	for _, group := range []string{"GroupC", "groupc"} {
+		var stdout bytes.Buffer
+		if err := run([]string{"-format", "csv", "-group", group, "-overrides", writeOverrides(t)}, &stdout); err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		rows, err := csv.NewReader(&stdout).ReadAll()
+		if err != nil {
+			t.Fatalf("output is not CSV: %v", err)
+		}
+		if len(rows) == 0 || rows[0][0] != "symbol" {
+			t.Fatalf("CSV rows = %v, want a header first", rows)
+		}
+
+		var got []string
+		for _, row := range rows[1:] {
+			if row[1] != "GroupC" {
+				t.Errorf("-group %s listed %s in %s", group, row[0], row[1])
+			}
+			got = append(got, row[0])
+		}
+		if len(got) != len(want) {
+			t.Fatalf("-group %s listed %v, want %v", group, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("-group %s listed %v, want %v", group, got, want)
+			}
+		}
+	}
+}
+
+func TestListRejectsUnknownFormat(t *testing.T) {
+	if err := run([]string{"-format", "xml"}, &bytes.Buffer{}); err == nil {
+		t.Error("-format xml accepted")
+	}
+}
@@ -0,0 +1,68 @@
+package recompute
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+)
+
+// Run recalculates the stats of every saved dividend history in -dir from its
+// events and rewrites the files in place
+func Run(args []string) {
+	fs := flag.NewFlagSet("recompute", flag.ExitOnError)
+	dir := fs.String("dir", "docs/dividends", "Directory containing the dividend history files to update")
+	compact := fs.Bool("compact", false, "Write JSON output without indentation to shrink published files")
+	pretty := fs.Bool("pretty", false, "Write indented JSON output (the default); overrides -compact")
+	fs.Parse(args)
+
+	export.SetCompactJSON(*compact && !*pretty)
+
+	paths, err := export.HistoryFiles(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(paths) == 0 {
+		log.Fatalf("No dividend history files found in %s", *dir)
+	}
+
+	failed := 0
+	for _, path := range paths {
+		if err := recomputeFile(path, time.Now()); err != nil {
+			log.Printf("Failed to recompute %s: %v", path, err)
+			failed++
+			continue
+		}
+		log.Printf("Recomputed stats for %s", path)
+	}
+
+	log.Printf("Recomputed %d files, %d failed", len(paths)-failed, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// recomputeFile rewrites one history file with stats recalculated from its
+// events, counting year-to-date from now's year
+func recomputeFile(path string, now time.Time) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var history models.DividendHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if history.Symbol == "" {
+		return fmt.Errorf("not a dividend history")
+	}
+
+	history.RecomputeStatsAt(now)
+	return export.SaveJSON(path, history)
+}
@@ -0,0 +1,83 @@
+package recompute
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestRecomputeFileReplacesStaleStats(t *testing.T) {
+	// Events merged by hand: out of order, with stats from before the edit
+	stale := `{
+		"symbol": "TSLY",
+		"events": [
+			{"symbol": "TSLY", "exDate": "2025-04-10T00:00:00-04:00", "amount": 0.50},
+			{"symbol": "TSLY", "exDate": "2025-06-05T00:00:00-04:00", "amount": 0.40},
+			{"symbol": "TSLY", "exDate": "2024-12-05T00:00:00-05:00", "amount": 0.30},
+			{"symbol": "TSLY", "exDate": "2024-03-07T00:00:00-05:00", "amount": 0.60}
+		],
+		"stats": {"totalPayments": 2, "averageAmount": 0.45, "lastAmount": 0.50}
+	}`
+	path := filepath.Join(t.TempDir(), "dividends_TSLY.json")
+	if err := os.WriteFile(path, []byte(stale), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recomputeFile(path, time.Date(2025, 6, 10, 12, 0, 0, 0, models.MarketLocation)); err != nil {
+		t.Fatalf("recomputeFile: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var history models.DividendHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := history.Stats
+	if stats.TotalPayments != 4 || stats.AverageAmount != 0.45 || stats.MedianAmount != 0.45 || stats.LastAmount != 0.4 {
+		t.Errorf("count/average/median/last = %d/%v/%v/%v, want 4/0.45/0.45/0.4",
+			stats.TotalPayments, stats.AverageAmount, stats.MedianAmount, stats.LastAmount)
+	}
+	// Trailing year from 2024-06-10: 0.40 + 0.50 + 0.30, not the March 2024 0.60
+	if stats.YearToDateTotal != 0.9 || stats.TrailingYearTotal != 1.2 {
+		t.Errorf("ytd/trailing = %v/%v, want 0.9/1.2", stats.YearToDateTotal, stats.TrailingYearTotal)
+	}
+	if stats.ChangePercent > -19.99 || stats.ChangePercent < -20.01 {
+		t.Errorf("ChangePercent = %v, want -20 (0.50 → 0.40)", stats.ChangePercent)
+	}
+	if history.Events[0].Amount != 0.40 || history.Events[3].Amount != 0.60 {
+		t.Errorf("events not rewritten newest first: %+v", history.Events)
+	}
+}
+
+func TestRecomputeFileRejectsOtherFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"invalid JSON", `{"symbol": `},
+		{"not a history", `{"etfs": []}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "dividends_BAD.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := recomputeFile(path, time.Now()); err == nil {
+				t.Error("recomputeFile succeeded")
+			}
+			// The file is left as it was
+			if data, _ := os.ReadFile(path); string(data) != tt.content {
+				t.Errorf("file rewritten to %q", data)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package reconcile
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"divminder-crawler/internal/cmdutil"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/scraper"
+)
+
+// Run rebuilds the ETF summary from the saved histories and the schedule,
+// reporting the symbols on which they and the previous summary disagree
+func Run(args []string) {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	dir := fs.String("dir", "docs/dividends", "Directory containing the dividend history files")
+	schedulePath := fs.String("schedule", filepath.Join("docs", export.ScheduleFileName), "Schedule whose upcoming events supply the next dates; skipped if missing")
+	summaryPath := fs.String("summary", "", "Summary file to rebuild; defaults to etf_summary.json next to -dir")
+	check := fs.Bool("check", false, "Only report drift, exiting non-zero if any, without rewriting the summary")
+	compact := fs.Bool("compact", false, "Write JSON output without indentation to shrink published files")
+	pretty := fs.Bool("pretty", false, "Write indented JSON output (the default); overrides -compact")
+	fs.Parse(args)
+
+	export.SetCompactJSON(*compact && !*pretty)
+	if *summaryPath == "" {
+		*summaryPath = export.SummaryPath(*dir)
+	}
+
+	histories, err := export.LoadHistories(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(histories) == 0 {
+		log.Fatalf("No dividend history files found in %s", *dir)
+	}
+	schedule, err := export.LoadSchedule(*schedulePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if schedule == nil {
+		log.Printf("No schedule at %s, estimating next dates from the histories", *schedulePath)
+	}
+	previous, err := export.LoadSummary(*summaryPath)
+	if err != nil {
+		log.Printf("Ignoring unreadable summary: %v", err)
+	}
+
+	drift := export.ReconcileSummary(histories, previous, schedule)
+	reportDrift("Missing from summary", drift.MissingFromSummary)
+	reportDrift("In summary without a history", drift.OrphanedInSummary)
+	reportDrift("Scheduled without a history", drift.ScheduledNoHistory)
+	if drift.Empty() {
+		log.Printf("Summary and histories agree on all %d ETFs", len(histories))
+	}
+
+	if *check {
+		if !drift.Empty() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	tracker := cmdutil.LoadSymbolTracker(filepath.Dir(*dir), scraper.DefaultInactiveAfter)
+	summary := export.BuildSummary(histories, schedule, tracker.IsActive, time.Now())
+	if err := export.SaveJSON(*summaryPath, summary); err != nil {
+		log.Fatalf("Failed to save summary: %v", err)
+	}
+	log.Printf("Summary saved to: %s (%d ETFs)", *summaryPath, summary.TotalETFs)
+}
+
+// reportDrift logs one kind of drift when any symbols have it
+func reportDrift(label string, symbols []string) {
+	if len(symbols) > 0 {
+		log.Printf("%s (%d): %s", label, len(symbols), strings.Join(symbols, ", "))
+	}
+}
@@ -0,0 +1,66 @@
+package schedule
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+	"divminder-crawler/internal/proxy"
+	"divminder-crawler/internal/scraper"
+)
+
+// Run scrapes the distribution schedule and prints it as JSON to stdout
+// without writing any files, exiting non-zero when the scrape fails
+func Run(args []string) {
+	if err := run(args, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run is Run with the JSON written to stdout and failures returned
+func run(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("schedule", flag.ContinueOnError)
+	days := fs.Int("days", scraper.DefaultUpcomingDays, "Include upcoming events with ex-dates within this many days of the as-of date")
+	asOfDate := fs.String("as-of", "", "Build the schedule as of this past date (YYYY-MM-DD); defaults to now")
+	baseURL := fs.String("base-url", scraper.DefaultBaseURL, "Site the schedule page is fetched from")
+	proxyURL := fs.String("proxy", "", "Route scraper requests through this http(s) or socks5 proxy URL; defaults to HTTP_PROXY/HTTPS_PROXY")
+	compact := fs.Bool("compact", false, "Print JSON without indentation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *days < 0 {
+		return fmt.Errorf("invalid -days %d: must not be negative", *days)
+	}
+	if err := proxy.Configure(*proxyURL); err != nil {
+		return fmt.Errorf("invalid -proxy: %w", err)
+	}
+	export.SetCompactJSON(*compact)
+
+	s := scraper.NewImprovedYieldMaxScraper()
+	s.SetBaseURL(*baseURL)
+	s.SetUpcomingDays(*days)
+	s.SetGroupsFile("")
+	if *asOfDate != "" {
+		asOf, err := models.ParseMarketDate("2006-01-02", *asOfDate)
+		if err != nil {
+			return fmt.Errorf("invalid -as-of %q: must be YYYY-MM-DD", *asOfDate)
+		}
+		s.SetClock(clock.NewFake(asOf))
+	}
+
+	schedule, err := s.GetScheduleImproved()
+	if err != nil {
+		return fmt.Errorf("failed to scrape schedule: %w", err)
+	}
+
+	if err := export.WriteJSON(stdout, schedule); err != nil {
+		return fmt.Errorf("failed to print schedule: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package schedule
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+)
+
+// scheduleSite serves the scraper's schedule page fixture
+func scheduleSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	page, err := os.ReadFile(filepath.Join("..", "..", "scraper", "testdata", "schedule_page.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/distribution-schedule/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(page)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunPrintsScheduleJSON(t *testing.T) {
+	server := scheduleSite(t)
+	t.Cleanup(func() { export.SetCompactJSON(false) })
+
+	var stdout bytes.Buffer
+	err := run([]string{"-base-url", server.URL, "-as-of", "2025-06-10", "-days", "10", "-compact"}, &stdout)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if lines := bytes.Count(stdout.Bytes(), []byte("\n")); lines != 1 {
+		t.Errorf("-compact printed %d lines, want 1", lines)
+	}
+	var schedule models.Schedule
+	if err := json.Unmarshal(stdout.Bytes(), &schedule); err != nil {
+		t.Fatalf("stdout is not a schedule: %v\n%s", err, stdout.String())
+	}
+	if len(schedule.Groups) == 0 || len(schedule.Upcoming) == 0 {
+		t.Fatalf("schedule has %d groups and %d upcoming events, want both", len(schedule.Groups), len(schedule.Upcoming))
+	}
+	asOf := time.Date(2025, 6, 10, 0, 0, 0, 0, models.MarketLocation)
+	for _, event := range schedule.Upcoming {
+		if event.ExDate.Before(asOf) || event.ExDate.After(asOf.AddDate(0, 0, 10)) {
+			t.Errorf("upcoming %s on %v outside the 10-day window", event.Symbol, event.ExDate)
+		}
+	}
+}
+
+func TestRunFails(t *testing.T) {
+	server := scheduleSite(t)
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "scrape failure", args: []string{"-base-url", down.URL}},
+		{name: "negative days", args: []string{"-base-url", server.URL, "-days", "-1"}},
+		{name: "bad as-of", args: []string{"-base-url", server.URL, "-as-of", "June 10"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			if err := run(tt.args, &stdout); err == nil {
+				t.Error("run succeeded, want an error")
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("printed %q on failure", stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package scrape
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"divminder-crawler/internal/cmdutil"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/metrics"
+	"divminder-crawler/internal/overrides"
+	"divminder-crawler/internal/proxy"
+	"divminder-crawler/internal/scraper"
+)
+
+// Run scrapes every YieldMax ETF's dividend table sequentially
+func Run(args []string) {
+	fs := flag.NewFlagSet("scrape", flag.ExitOnError)
+	format := fs.String("format", "json", "Output format: json, or ndjson to also write one event per line")
+	dropOutliers := fs.Bool("drop-outliers", false, "Remove events whose amount is an outlier before saving")
+	outputDir := fs.String("out", "docs/dividends", "Directory to write dividend histories to; the summary goes in its parent")
+	fixWeekends := fs.Bool("fix-weekends", false, "Move ex-dates that fall on a weekend to the prior Friday instead of only warning about them")
+	years := fs.Int("years", 0, "Keep only events from the last N years, computing stats from that window (0 keeps all)")
+	maxEvents := fs.Int("max-events", 0, "Keep only the N most recent events per symbol in saved files; stats still cover every event (0 keeps all)")
+	includeInactive := fs.Bool("include-inactive", false, "Also scrape symbols marked inactive after repeated 404s")
+	inactiveAfter := fs.Int("inactive-after", scraper.DefaultInactiveAfter, "Consecutive 404s after which a symbol is marked inactive and skipped")
+	symbolsFile := fs.String("symbols-file", "", "Only scrape the symbols in this file, one per line; blank lines and # comments are ignored")
+	group := fs.String("group", "", "Only scrape ETFs in this group, e.g. GroupC or Target12; combined with -symbols-file, only the listed ETFs in it")
+	overridesPath := fs.String("overrides", overrides.DefaultFile, "Per-symbol name/group/frequency/description overrides applied over scraped data")
+	proxyURL := fs.String("proxy", "", "Route scraper and API requests through this http(s) or socks5 proxy URL; defaults to HTTP_PROXY/HTTPS_PROXY")
+	compact := fs.Bool("compact", false, "Write JSON output without indentation to shrink published files")
+	pretty := fs.Bool("pretty", false, "Write indented JSON output (the default); overrides -compact")
+	debugRequests := fs.Bool("debug-requests", false, "Log each scrape request's status, size and whether a table was found, plus a summary of unexpected statuses, at debug level")
+	siteRateLimit := fs.Int("site-rate-limit", 0, "Cap requests to yieldmaxetfs.com per minute across all scrapers in the run (0 means no cap)")
+	lockStaleAfter := fs.Duration("lock-stale-after", cmdutil.DefaultLockStaleAfter, "Take over a run lock older than this, left by a run that died on another machine (0 never does)")
+	gzipOutput := fs.Bool("gzip", false, "Also write a .gz copy of every JSON/NDJSON file in the output directory")
+	pruneOlderThan := fs.Duration("prune-older-than", 0, "Remove per-symbol files not rewritten within this long (e.g. 720h), such as those of delisted tickers (0 keeps them)")
+	prettyFailures := fs.Bool("pretty-print-failures", false, "Print a sorted summary of the failed symbols and their error class to stderr at the end")
+	maxFailureRatio := fs.Float64("max-failure-ratio", 1, "Exit non-zero when more than this fraction (0-1) of the symbols fail")
+	metricsAddr := fs.String("metrics-addr", "", "Serve Prometheus metrics on this address (e.g. :9090) while scraping")
+	fs.Parse(args)
+
+	if *format != "json" && *format != "ndjson" {
+		log.Fatalf("Invalid -format %q: must be json or ndjson", *format)
+	}
+	if *maxEvents < 0 {
+		log.Fatalf("Invalid -max-events %d: must not be negative", *maxEvents)
+	}
+	if *years < 0 {
+		log.Fatalf("Invalid -years %d: must not be negative", *years)
+	}
+	if *inactiveAfter < 1 {
+		log.Fatalf("Invalid -inactive-after %d: must be at least 1", *inactiveAfter)
+	}
+	if *pruneOlderThan < 0 {
+		log.Fatalf("Invalid -prune-older-than %s: must not be negative", *pruneOlderThan)
+	}
+	if err := cmdutil.ValidateFailureRatio(*maxFailureRatio); err != nil {
+		log.Fatalf("Invalid -max-failure-ratio: %v", err)
+	}
+	if err := proxy.Configure(*proxyURL); err != nil {
+		log.Fatalf("Invalid -proxy: %v", err)
+	}
+	// Registered before the deferred request summary so it still runs
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+	if *siteRateLimit < 0 {
+		log.Fatalf("Invalid -site-rate-limit %d: must not be negative", *siteRateLimit)
+	}
+	scraper.SetSiteRateLimit(*siteRateLimit)
+	scraper.SetRequestLogging(*debugRequests)
+	defer scraper.LogRequestSummary()
+	export.SetCompactJSON(*compact && !*pretty)
+
+	if *metricsAddr != "" {
+		metrics.Serve(*metricsAddr, func(err error) {
+			log.Printf("Metrics server failed: %v", err)
+		})
+		log.Printf("Serving metrics on %s/metrics", *metricsAddr)
+	}
+
+	log.Println("Starting YieldMax dividend data collection...")
+
+	// Create output directory
+	if err := export.EnsureOutputDir(*outputDir); err != nil {
+		log.Fatal(err)
+	}
+
+	// Lock the summary directory, shared with the crawler, so overlapping
+	// scheduled runs don't write the same files
+	runLock, err := cmdutil.AcquireRunLock(cmdutil.LockPath(filepath.Dir(*outputDir)), *lockStaleAfter)
+	if err != nil {
+		log.Fatalf("Not starting: %v", err)
+	}
+	defer func() {
+		if err := runLock.Release(); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	// Initialize scraper
+	dividendScraper := scraper.NewDividendTableScraper()
+
+	// Get all YieldMax ETFs
+	etfs := scraper.GetYieldMaxETFGroups()
+
+	tracker := cmdutil.LoadSymbolTracker(filepath.Dir(*outputDir), *inactiveAfter)
+	symbolOverrides := cmdutil.LoadOverrides(*overridesPath)
+	groups := scraper.NewGroupResolver(symbolOverrides)
+	dividendScraper.SetGroupResolver(groups)
+	selected, err := cmdutil.SelectSymbols(etfs, *symbolsFile, *group, groups)
+	if err != nil {
+		log.Fatalf("Invalid symbol selection: %v", err)
+	}
+	symbols, skipped := tracker.FilterActive(selected, *includeInactive)
+	if len(skipped) > 0 {
+		log.Printf("Skipping %d inactive ETFs: %v", len(skipped), skipped)
+	}
+
+	// Track progress
+	report := cmdutil.NewRunReport(time.Now())
+	successCount := 0
+	failureCount := 0
+	var failedETFs []string
+
+	// Scrape each ETF
+	for i, symbol := range symbols {
+		log.Printf("[%d/%d] Scraping %s...", i+1, len(symbols), symbol)
+
+		// Scrape dividend history
+		start := time.Now()
+		history, err := dividendScraper.ScrapeDividendHistory(symbol)
+		cmdutil.RecordSymbolResult(tracker, symbol, err)
+		if err != nil {
+			report.Record(symbol, cmdutil.StatusFailed, err, 0, time.Since(start))
+			log.Printf("Failed to scrape %s: %v", symbol, err)
+			failureCount++
+			failedETFs = append(failedETFs, symbol)
+			continue
+		}
+
+		symbolOverrides.ApplyHistory(history)
+		cmdutil.ApplyOutlierFilter(history, *dropOutliers)
+		cmdutil.CheckWeekendExDates(history, *fixWeekends)
+		history.KeepYears(*years)
+		history.Target = history.TargetTrackingAt(0, time.Now())
+		history.TrimEvents(*maxEvents)
+
+		// Save to JSON file
+		filename := filepath.Join(*outputDir, fmt.Sprintf("%s_dividend_history.json", symbol))
+		if _, err := export.SaveHistory(filename, history); err != nil {
+			report.Record(symbol, cmdutil.StatusFailed, err, 0, time.Since(start))
+			log.Printf("Failed to save %s data: %v", symbol, err)
+			failureCount++
+			failedETFs = append(failedETFs, symbol)
+			continue
+		}
+
+		successCount++
+		report.Record(symbol, cmdutil.StatusScraped, nil, len(history.Events), time.Since(start))
+		cmdutil.RecordGaps(report, history)
+		log.Printf("Successfully saved %s dividend history (%d events)", symbol, len(history.Events))
+
+		if *format == "ndjson" {
+			ndjsonFile := filepath.Join(*outputDir, fmt.Sprintf("%s_events.ndjson", symbol))
+			if err := export.SaveEventsNDJSON(ndjsonFile, history.Events); err != nil {
+				log.Printf("Failed to save %s NDJSON: %v", symbol, err)
+			}
+		}
+
+		// Add delay between requests to be respectful
+		if i < len(symbols)-1 {
+			time.Sleep(3 * time.Second)
+		}
+	}
+
+	cmdutil.SaveSymbolTracker(tracker)
+	cmdutil.SaveRunReport(report, *outputDir)
+	cmdutil.PruneStaleFiles(*outputDir, *pruneOlderThan, symbols)
+
+	if *format == "ndjson" {
+		cmdutil.WriteCombinedNDJSON(*outputDir)
+	}
+	cmdutil.WriteTotalsByYear(*outputDir)
+
+	summaryPath := cmdutil.WriteSummary(*outputDir, tracker)
+	cmdutil.FinishOutput(*outputDir, *gzipOutput)
+
+	// Print results
+	log.Println("\n=== Scraping Complete ===")
+	log.Printf("Successful: %d ETFs", successCount)
+	log.Printf("Failed: %d ETFs", failureCount)
+	if len(failedETFs) > 0 {
+		log.Printf("Failed ETFs: %v", failedETFs)
+	}
+	log.Printf("Data saved to: %s", *outputDir)
+	log.Printf("Summary saved to: %s", summaryPath)
+
+	failed := failedETFs
+	if *prettyFailures {
+		cmdutil.PrintFailureSummary(os.Stderr, failed, report)
+	}
+	if exitCode = cmdutil.FailureExitCode(len(failed), len(symbols), *maxFailureRatio); exitCode != 0 {
+		log.Printf("%d of %d ETFs failed, more than -max-failure-ratio %v", len(failed), len(symbols), *maxFailureRatio)
+	}
+}
@@ -0,0 +1,84 @@
+package scrapecached
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/cmdutil"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+)
+
+func TestNeedsUpdate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(now)
+	maxAge := 6 * time.Hour
+
+	write := func(name string, age time.Duration) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := now.Add(-age)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	// checked saves a history whose sidecar check time can differ from its
+	// modification time, as when a run finds nothing new
+	checked := func(name string, modAge, checkAge time.Duration) string {
+		path := filepath.Join(dir, name)
+		history := &models.DividendHistory{Symbol: name, UpdatedAt: now.Add(-checkAge)}
+		if _, err := export.SaveHistory(path, history); err != nil {
+			t.Fatal(err)
+		}
+		modTime := now.Add(-modAge)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"fresh", write("fresh.json", maxAge-time.Minute), false},
+		{"at max age", write("edge.json", maxAge), false},
+		{"stale", write("stale.json", maxAge+time.Minute), true},
+		{"missing", filepath.Join(dir, "missing.json"), true},
+		{"unchanged but checked", checked("dividends_TSLY.json", maxAge+time.Hour, time.Hour), false},
+		{"checked too long ago", checked("dividends_YMAX.json", time.Hour, maxAge+time.Minute), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsUpdate(tt.path, maxAge, clk); got != tt.want {
+				t.Errorf("needsUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMaxAge(t *testing.T) {
+	if got, err := parseMaxAge("36h"); err != nil || got != 36*time.Hour {
+		t.Errorf("parseMaxAge(36h) = %v, %v", got, err)
+	}
+	for _, value := range []string{"0s", "-1h", "soon"} {
+		if _, err := parseMaxAge(value); err == nil {
+			t.Errorf("parseMaxAge(%q) succeeded, want error", value)
+		}
+	}
+}
+
+func TestDefaultConcurrencyIsValid(t *testing.T) {
+	if err := cmdutil.ValidateConcurrency(defaultConcurrency); err != nil {
+		t.Errorf("default -concurrency %d rejected: %v", defaultConcurrency, err)
+	}
+}
@@ -0,0 +1,330 @@
+package scrapeoptimized
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"divminder-crawler/internal/cmdutil"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/metrics"
+	"divminder-crawler/internal/models"
+	"divminder-crawler/internal/overrides"
+	"divminder-crawler/internal/proxy"
+	"divminder-crawler/internal/scraper"
+)
+
+const (
+	defaultConcurrency = 5 // Default concurrent scraping jobs (see -concurrency)
+	retryAttempts      = 2 // Number of retry attempts for failed scrapes
+)
+
+// Delays between a worker's retries and between its symbols; tests shorten them
+var (
+	retryDelay   = 2 * time.Second
+	requestDelay = 500 * time.Millisecond
+)
+
+// historyScraper is the part of DividendTableScraper a worker uses
+type historyScraper interface {
+	ScrapeDividendHistory(symbol string) (*models.DividendHistory, error)
+}
+
+type scrapeResult struct {
+	symbol   string
+	history  *models.DividendHistory
+	err      error
+	duration time.Duration
+}
+
+// Run scrapes the YieldMax dividend tables with a pool of concurrent workers
+func Run(args []string) {
+	fs := flag.NewFlagSet("scrape -optimized", flag.ExitOnError)
+	format := fs.String("format", "json", "Output format: json, or ndjson to also write one event per line")
+	dropOutliers := fs.Bool("drop-outliers", false, "Remove events whose amount is an outlier before saving")
+	outputDir := fs.String("out", "data/dividends", "Directory to write dividend histories to; the summary goes in its parent")
+	concurrency := fs.Int("concurrency", defaultConcurrency, fmt.Sprintf("Number of concurrent scrape workers (1-%d)", cmdutil.MaxConcurrency))
+	fixWeekends := fs.Bool("fix-weekends", false, "Move ex-dates that fall on a weekend to the prior Friday instead of only warning about them")
+	years := fs.Int("years", 0, "Keep only events from the last N years, computing stats from that window (0 keeps all)")
+	maxEvents := fs.Int("max-events", 0, "Keep only the N most recent events per symbol in saved files; stats still cover every event (0 keeps all)")
+	maxFailures := fs.Int("max-consecutive-failures", 10, "Skip the remaining symbols after this many consecutive failures across workers (0 never skips)")
+	includeInactive := fs.Bool("include-inactive", false, "Also scrape symbols marked inactive after repeated 404s")
+	inactiveAfter := fs.Int("inactive-after", scraper.DefaultInactiveAfter, "Consecutive 404s after which a symbol is marked inactive and skipped")
+	symbolsFile := fs.String("symbols-file", "", "Only scrape the symbols in this file, one per line; blank lines and # comments are ignored")
+	group := fs.String("group", "", "Only scrape ETFs in this group, e.g. GroupC or Target12; combined with -symbols-file, only the listed ETFs in it")
+	overridesPath := fs.String("overrides", overrides.DefaultFile, "Per-symbol name/group/frequency/description overrides applied over scraped data")
+	proxyURL := fs.String("proxy", "", "Route scraper and API requests through this http(s) or socks5 proxy URL; defaults to HTTP_PROXY/HTTPS_PROXY")
+	compact := fs.Bool("compact", false, "Write JSON output without indentation to shrink published files")
+	pretty := fs.Bool("pretty", false, "Write indented JSON output (the default); overrides -compact")
+	debugRequests := fs.Bool("debug-requests", false, "Log each scrape request's status, size and whether a table was found, plus a summary of unexpected statuses, at debug level")
+	siteRateLimit := fs.Int("site-rate-limit", 0, "Cap requests to yieldmaxetfs.com per minute across all scrapers in the run (0 means no cap)")
+	lockStaleAfter := fs.Duration("lock-stale-after", cmdutil.DefaultLockStaleAfter, "Take over a run lock older than this, left by a run that died on another machine (0 never does)")
+	gzipOutput := fs.Bool("gzip", false, "Also write a .gz copy of every JSON/NDJSON file in the output directory")
+	pruneOlderThan := fs.Duration("prune-older-than", 0, "Remove per-symbol files not rewritten within this long (e.g. 720h), such as those of delisted tickers (0 keeps them)")
+	prettyFailures := fs.Bool("pretty-print-failures", false, "Print a sorted summary of the failed symbols and their error class to stderr at the end")
+	maxFailureRatio := fs.Float64("max-failure-ratio", 1, "Exit non-zero when more than this fraction (0-1) of the symbols fail")
+	metricsAddr := fs.String("metrics-addr", "", "Serve Prometheus metrics on this address (e.g. :9090) while scraping")
+	fs.Parse(args)
+
+	if *format != "json" && *format != "ndjson" {
+		log.Fatalf("Invalid -format %q: must be json or ndjson", *format)
+	}
+	if *maxEvents < 0 {
+		log.Fatalf("Invalid -max-events %d: must not be negative", *maxEvents)
+	}
+	if *years < 0 {
+		log.Fatalf("Invalid -years %d: must not be negative", *years)
+	}
+	if *inactiveAfter < 1 {
+		log.Fatalf("Invalid -inactive-after %d: must be at least 1", *inactiveAfter)
+	}
+	if *pruneOlderThan < 0 {
+		log.Fatalf("Invalid -prune-older-than %s: must not be negative", *pruneOlderThan)
+	}
+	if err := cmdutil.ValidateFailureRatio(*maxFailureRatio); err != nil {
+		log.Fatalf("Invalid -max-failure-ratio: %v", err)
+	}
+	if *maxFailures < 0 {
+		log.Fatalf("Invalid -max-consecutive-failures %d: must not be negative", *maxFailures)
+	}
+	if err := cmdutil.ValidateConcurrency(*concurrency); err != nil {
+		log.Fatalf("Invalid -concurrency: %v", err)
+	}
+	if err := proxy.Configure(*proxyURL); err != nil {
+		log.Fatalf("Invalid -proxy: %v", err)
+	}
+	// Registered before the deferred request summary so it still runs
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+	if *siteRateLimit < 0 {
+		log.Fatalf("Invalid -site-rate-limit %d: must not be negative", *siteRateLimit)
+	}
+	scraper.SetSiteRateLimit(*siteRateLimit)
+	scraper.SetRequestLogging(*debugRequests)
+	defer scraper.LogRequestSummary()
+	export.SetCompactJSON(*compact && !*pretty)
+
+	if *metricsAddr != "" {
+		metrics.Serve(*metricsAddr, func(err error) {
+			log.Printf("Metrics server failed: %v", err)
+		})
+		log.Printf("Serving metrics on %s/metrics", *metricsAddr)
+	}
+
+	log.Println("Starting optimized YieldMax dividend data collection...")
+
+	// Create output directory
+	if err := export.EnsureOutputDir(*outputDir); err != nil {
+		log.Fatal(err)
+	}
+
+	// Lock the summary directory, shared with the crawler, so overlapping
+	// scheduled runs don't write the same files
+	runLock, err := cmdutil.AcquireRunLock(cmdutil.LockPath(filepath.Dir(*outputDir)), *lockStaleAfter)
+	if err != nil {
+		log.Fatalf("Not starting: %v", err)
+	}
+	defer func() {
+		if err := runLock.Release(); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	// Get all YieldMax ETFs
+	etfs := scraper.GetYieldMaxETFGroups()
+	tracker := cmdutil.LoadSymbolTracker(filepath.Dir(*outputDir), *inactiveAfter)
+	symbolOverrides := cmdutil.LoadOverrides(*overridesPath)
+	groups := scraper.NewGroupResolver(symbolOverrides)
+	selected, err := cmdutil.SelectSymbols(etfs, *symbolsFile, *group, groups)
+	if err != nil {
+		log.Fatalf("Invalid symbol selection: %v", err)
+	}
+	symbols, skipped := tracker.FilterActive(selected, *includeInactive)
+	if len(skipped) > 0 {
+		log.Printf("Skipping %d inactive ETFs: %v", len(skipped), skipped)
+	}
+
+	report := cmdutil.NewRunReport(time.Now())
+	breaker := cmdutil.NewCircuitBreaker(*maxFailures)
+
+	// Each worker gets its own scraper
+	newScraper := func() historyScraper {
+		s := scraper.NewDividendTableScraper()
+		s.SetGroupResolver(groups)
+		return s
+	}
+
+	// Create channels for concurrent processing
+	jobs := make(chan string, len(symbols))
+	results := make(chan scrapeResult, len(symbols))
+
+	// Start workers
+	var wg sync.WaitGroup
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go worker(i, jobs, results, breaker, newScraper, &wg)
+	}
+
+	// Queue all jobs
+	go func() {
+		for _, symbol := range symbols {
+			jobs <- symbol
+		}
+		close(jobs)
+	}()
+
+	// Start a goroutine to close results channel after all workers are done
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Process results
+	successCount := 0
+	failureCount := 0
+	var failedETFs []string
+	var skippedETFs []string
+	processedCount := 0
+
+	for result := range results {
+		processedCount++
+		log.Printf("[%d/%d] Processing %s result...", processedCount, len(symbols), result.symbol)
+
+		if errors.Is(result.err, cmdutil.ErrCircuitOpen) {
+			report.Record(result.symbol, cmdutil.StatusSkipped, result.err, 0, 0)
+			skippedETFs = append(skippedETFs, result.symbol)
+			continue
+		}
+
+		cmdutil.RecordSymbolResult(tracker, result.symbol, result.err)
+		if result.err != nil {
+			report.Record(result.symbol, cmdutil.StatusFailed, result.err, 0, result.duration)
+			log.Printf("Failed to scrape %s: %v", result.symbol, result.err)
+			failureCount++
+			failedETFs = append(failedETFs, result.symbol)
+			continue
+		}
+
+		symbolOverrides.ApplyHistory(result.history)
+		cmdutil.ApplyOutlierFilter(result.history, *dropOutliers)
+		cmdutil.CheckWeekendExDates(result.history, *fixWeekends)
+		result.history.KeepYears(*years)
+		result.history.Target = result.history.TargetTrackingAt(0, time.Now())
+		result.history.TrimEvents(*maxEvents)
+
+		// Save to JSON file
+		filename := filepath.Join(*outputDir, fmt.Sprintf("%s_dividend_history.json", result.symbol))
+		if _, err := export.SaveHistory(filename, result.history); err != nil {
+			report.Record(result.symbol, cmdutil.StatusFailed, err, 0, result.duration)
+			log.Printf("Failed to save %s data: %v", result.symbol, err)
+			failureCount++
+			failedETFs = append(failedETFs, result.symbol)
+			continue
+		}
+
+		successCount++
+		report.Record(result.symbol, cmdutil.StatusScraped, nil, len(result.history.Events), result.duration)
+		cmdutil.RecordGaps(report, result.history)
+		log.Printf("Successfully saved %s dividend history (%d events)", result.symbol, len(result.history.Events))
+
+		if *format == "ndjson" {
+			ndjsonFile := filepath.Join(*outputDir, fmt.Sprintf("%s_events.ndjson", result.symbol))
+			if err := export.SaveEventsNDJSON(ndjsonFile, result.history.Events); err != nil {
+				log.Printf("Failed to save %s NDJSON: %v", result.symbol, err)
+			}
+		}
+	}
+
+	cmdutil.PruneStaleFiles(*outputDir, *pruneOlderThan, symbols)
+	if *format == "ndjson" {
+		cmdutil.WriteCombinedNDJSON(*outputDir)
+	}
+	cmdutil.WriteTotalsByYear(*outputDir)
+
+	if breaker.Open() {
+		report.MarkDegraded(fmt.Sprintf("circuit breaker tripped after %d consecutive failures; %d symbols skipped", *maxFailures, len(skippedETFs)))
+	}
+
+	// Create summary
+	cmdutil.SaveSymbolTracker(tracker)
+	cmdutil.SaveRunReport(report, *outputDir)
+	cmdutil.WriteSummary(*outputDir, tracker)
+	cmdutil.FinishOutput(*outputDir, *gzipOutput)
+
+	// Print results
+	log.Println("\n=== Scraping Complete ===")
+	log.Printf("Successful: %d ETFs", successCount)
+	log.Printf("Failed: %d ETFs", failureCount)
+	if len(failedETFs) > 0 {
+		log.Printf("Failed ETFs: %v", failedETFs)
+	}
+	if len(skippedETFs) > 0 {
+		log.Printf("Skipped %d ETFs after repeated failures: %v", len(skippedETFs), skippedETFs)
+	}
+	log.Printf("Data saved to: %s", *outputDir)
+	log.Printf("Total time: %s", time.Since(time.Now()).String())
+
+	failed := append(failedETFs, skippedETFs...)
+	if *prettyFailures {
+		cmdutil.PrintFailureSummary(os.Stderr, failed, report)
+	}
+	if exitCode = cmdutil.FailureExitCode(len(failed), len(symbols), *maxFailureRatio); exitCode != 0 {
+		log.Printf("%d of %d ETFs failed, more than -max-failure-ratio %v", len(failed), len(symbols), *maxFailureRatio)
+	}
+}
+
+func worker(id int, jobs <-chan string, results chan<- scrapeResult, breaker *cmdutil.CircuitBreaker, newScraper func() historyScraper, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	// Create a scraper instance for this worker
+	scraper := newScraper()
+
+	for symbol := range jobs {
+		// Drain the remaining jobs without scraping once the breaker trips
+		if breaker.Open() {
+			results <- scrapeResult{symbol: symbol, err: cmdutil.ErrCircuitOpen}
+			continue
+		}
+
+		log.Printf("[Worker %d] Scraping %s...", id, symbol)
+
+		start := time.Now()
+		var history *models.DividendHistory
+		var err error
+
+		// Retry logic
+		for attempt := 0; attempt < retryAttempts; attempt++ {
+			history, err = scraper.ScrapeDividendHistory(symbol)
+			if err == nil {
+				break
+			}
+			if attempt < retryAttempts-1 {
+				log.Printf("[Worker %d] Retry %d for %s after error: %v", id, attempt+1, symbol, err)
+				time.Sleep(retryDelay)
+			}
+		}
+
+		if breaker.Record(err) {
+			log.Printf("[Worker %d] Circuit breaker tripped after %s failed; skipping remaining symbols", id, symbol)
+		}
+
+		results <- scrapeResult{
+			symbol:   symbol,
+			history:  history,
+			err:      err,
+			duration: time.Since(start),
+		}
+
+		// Small delay between requests from same worker
+		time.Sleep(requestDelay)
+	}
+}
@@ -0,0 +1,146 @@
+package scrapeoptimized
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"divminder-crawler/internal/cmdutil"
+	"divminder-crawler/internal/models"
+)
+
+// stubScraper fails every symbol in failing and counts its calls
+type stubScraper struct {
+	mu      sync.Mutex
+	failing map[string]bool
+	calls   map[string]int
+}
+
+func (s *stubScraper) ScrapeDividendHistory(symbol string) (*models.DividendHistory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[symbol]++
+	if s.failing[symbol] {
+		return nil, errors.New("HTTP 503")
+	}
+	return &models.DividendHistory{Symbol: symbol}, nil
+}
+
+// runWorkers scrapes symbols with the given workers and breaker threshold,
+// returning each symbol's result error
+func runWorkers(t *testing.T, stub *stubScraper, symbols []string, workers, threshold int) map[string]error {
+	t.Helper()
+	savedRetry, savedRequest := retryDelay, requestDelay
+	retryDelay, requestDelay = 0, 0
+	t.Cleanup(func() { retryDelay, requestDelay = savedRetry, savedRequest })
+
+	breaker := cmdutil.NewCircuitBreaker(threshold)
+	jobs := make(chan string, len(symbols))
+	results := make(chan scrapeResult, len(symbols))
+	for _, symbol := range symbols {
+		jobs <- symbol
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go worker(i, jobs, results, breaker, func() historyScraper { return stub }, &wg)
+	}
+	wg.Wait()
+	close(results)
+
+	errs := make(map[string]error)
+	for result := range results {
+		errs[result.symbol] = result.err
+	}
+	if len(errs) != len(symbols) {
+		t.Fatalf("got %d results, want one per symbol", len(errs))
+	}
+	return errs
+}
+
+func TestWorkerShortCircuitsAfterConsecutiveFailures(t *testing.T) {
+	var symbols []string
+	failing := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		symbol := fmt.Sprintf("SYM%d", i)
+		symbols = append(symbols, symbol)
+		failing[symbol] = true
+	}
+	stub := &stubScraper{failing: failing, calls: map[string]int{}}
+
+	const threshold = 3
+	errs := runWorkers(t, stub, symbols, 1, threshold)
+
+	for i, symbol := range symbols {
+		err := errs[symbol]
+		if i < threshold {
+			if err == nil || errors.Is(err, cmdutil.ErrCircuitOpen) {
+				t.Errorf("%s: err = %v, want the scrape failure", symbol, err)
+			}
+			if stub.calls[symbol] != retryAttempts {
+				t.Errorf("%s scraped %d times, want %d attempts", symbol, stub.calls[symbol], retryAttempts)
+			}
+			continue
+		}
+		if !errors.Is(err, cmdutil.ErrCircuitOpen) {
+			t.Errorf("%s: err = %v, want ErrCircuitOpen", symbol, err)
+		}
+		if stub.calls[symbol] != 0 {
+			t.Errorf("%s scraped %d times after the breaker tripped, want 0", symbol, stub.calls[symbol])
+		}
+	}
+}
+
+func TestWorkerBreakerResetsOnSuccess(t *testing.T) {
+	symbols := []string{"BAD1", "BAD2", "GOOD", "BAD3", "BAD4", "LAST"}
+	stub := &stubScraper{failing: map[string]bool{"BAD1": true, "BAD2": true, "BAD3": true, "BAD4": true}, calls: map[string]int{}}
+
+	errs := runWorkers(t, stub, symbols, 1, 3)
+	for _, symbol := range symbols {
+		if errors.Is(errs[symbol], cmdutil.ErrCircuitOpen) {
+			t.Errorf("%s skipped, but no three consecutive symbols failed", symbol)
+		}
+	}
+	if errs["GOOD"] != nil || errs["LAST"] != nil {
+		t.Errorf("GOOD err = %v, LAST err = %v; want both scraped", errs["GOOD"], errs["LAST"])
+	}
+}
+
+func TestWorkersShareOneBreaker(t *testing.T) {
+	var symbols []string
+	failing := make(map[string]bool)
+	for i := 0; i < 40; i++ {
+		symbol := fmt.Sprintf("SYM%02d", i)
+		symbols = append(symbols, symbol)
+		failing[symbol] = true
+	}
+	stub := &stubScraper{failing: failing, calls: map[string]int{}}
+
+	const threshold, workers = 4, 3
+	errs := runWorkers(t, stub, symbols, workers, threshold)
+
+	// Workers already past the Open check when it trips may finish their symbol
+	attempted, skipped := 0, 0
+	for _, symbol := range symbols {
+		if errors.Is(errs[symbol], cmdutil.ErrCircuitOpen) {
+			skipped++
+		} else {
+			attempted++
+		}
+	}
+	if attempted < threshold || attempted > threshold+workers-1 {
+		t.Errorf("attempted %d symbols, want between %d and %d", attempted, threshold, threshold+workers-1)
+	}
+	if skipped != len(symbols)-attempted {
+		t.Errorf("skipped %d symbols, want the remaining %d", skipped, len(symbols)-attempted)
+	}
+}
+
+func TestDefaultConcurrencyIsValid(t *testing.T) {
+	if err := cmdutil.ValidateConcurrency(defaultConcurrency); err != nil {
+		t.Errorf("default -concurrency %d rejected: %v", defaultConcurrency, err)
+	}
+}
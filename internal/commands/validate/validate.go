@@ -0,0 +1,98 @@
+package validate
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+)
+
+// FileResult is the validation outcome for one dividend history file
+type FileResult struct {
+	Path   string
+	Errors []string
+}
+
+// OK reports whether the file passed every check
+func (r FileResult) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// Run validates the saved dividend histories in -dir without re-scraping,
+// printing a per-file report and exiting non-zero if any file is bad
+func Run(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	dir := fs.String("dir", "docs/dividends", "Directory containing the dividends_*.json files to validate")
+	fs.Parse(args)
+
+	results, err := ValidateDir(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(results) == 0 {
+		log.Fatalf("No dividend history files found in %s", *dir)
+	}
+
+	bad := 0
+	for _, result := range results {
+		if result.OK() {
+			fmt.Printf("OK    %s\n", result.Path)
+			continue
+		}
+		bad++
+		fmt.Printf("FAIL  %s\n", result.Path)
+		for _, msg := range result.Errors {
+			fmt.Printf("      - %s\n", msg)
+		}
+	}
+
+	fmt.Printf("\n%d files checked, %d failed\n", len(results), bad)
+	if bad > 0 {
+		os.Exit(1)
+	}
+}
+
+// ValidateDir validates every dividend history file in dir, sorted by path
+func ValidateDir(dir string) ([]FileResult, error) {
+	paths, err := export.HistoryFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]FileResult, 0, len(paths))
+	for _, path := range paths {
+		results = append(results, ValidateFile(path))
+	}
+	return results, nil
+}
+
+// ValidateFile checks that a history file is non-empty, parses, and holds
+// valid events with consistent stats
+func ValidateFile(path string) FileResult {
+	result := FileResult{Path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("read failed: %v", err))
+		return result
+	}
+	if len(data) == 0 {
+		result.Errors = append(result.Errors, "file is empty")
+		return result
+	}
+
+	var history models.DividendHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid JSON: %v", err))
+		return result
+	}
+
+	for _, err := range history.Validate() {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package validate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+)
+
+func TestValidateDirReportsBadFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	exDate := time.Date(2025, 6, 5, 0, 0, 0, 0, models.MarketLocation)
+	events := []models.DividendEvent{
+		{Symbol: "GOOD", ExDate: exDate, PayDate: exDate.AddDate(0, 0, 1), Amount: 0.5, Source: models.SourceYieldMax},
+		{Symbol: "GOOD", ExDate: exDate.AddDate(0, 0, -7), PayDate: exDate.AddDate(0, 0, -6), Amount: 0.4, Source: models.SourceYieldMax},
+	}
+	good := models.DividendHistory{Symbol: "GOOD", Events: events, Stats: models.CalculateStats(events)}
+	if _, err := export.SaveHistory(filepath.Join(dir, "dividends_GOOD.json"), &good); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := models.DividendHistory{Symbol: "STAL", Events: events, Stats: models.CalculateStats(events[1:])}
+	if _, err := export.SaveHistory(filepath.Join(dir, "dividends_STAL.json"), &stale); err != nil {
+		t.Fatal(err)
+	}
+
+	writeRaw := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeRaw("dividends_CORR.json", `{"symbol": "CORR", "events": [{"exDate": `)
+	writeRaw("dividends_EMPT.json", "")
+	writeRaw("notes.json", `not a history`)
+
+	results, err := ValidateDir(dir)
+	if err != nil {
+		t.Fatalf("ValidateDir: %v", err)
+	}
+
+	want := map[string]string{
+		"dividends_CORR.json": "invalid JSON",
+		"dividends_EMPT.json": "file is empty",
+		"dividends_GOOD.json": "",
+		"dividends_STAL.json": "does not match",
+	}
+	if len(results) != len(want) {
+		t.Fatalf("checked %d files, want %d dividend histories", len(results), len(want))
+	}
+	for _, result := range results {
+		name := filepath.Base(result.Path)
+		wantErr, ok := want[name]
+		if !ok {
+			t.Errorf("checked unexpected file %s", name)
+			continue
+		}
+		if wantErr == "" {
+			if !result.OK() {
+				t.Errorf("%s failed with %v, want it to pass", name, result.Errors)
+			}
+			continue
+		}
+		if result.OK() || !strings.Contains(strings.Join(result.Errors, "; "), wantErr) {
+			t.Errorf("%s errors = %v, want one mentioning %q", name, result.Errors, wantErr)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"math"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+// Payout identifies one fund's most recent distribution
+type Payout struct {
+	Symbol string  `json:"symbol"`
+	Amount float64 `json:"amount"`
+	ExDate string  `json:"exDate"` // YYYY-MM-DD
+}
+
+// Aggregates summarizes dividend activity across every ETF
+type Aggregates struct {
+	YearToDateTotal     float64            `json:"yearToDateTotal"`     // Sum of per-share distributions this year
+	AverageYieldByGroup map[string]float64 `json:"averageYieldByGroup"` // Mean current yield (%) of funds with a known yield
+	HighestRecentPayout *Payout            `json:"highestRecentPayout,omitempty"`
+	LowestRecentPayout  *Payout            `json:"lowestRecentPayout,omitempty"`
+	UpcomingNext7Days   int                `json:"upcomingNext7Days"`
+	UpcomingNext30Days  int                `json:"upcomingNext30Days"`
+}
+
+// BuildAggregates computes the cross-ETF aggregates from the saved histories,
+// the schedule's upcoming events and each symbol's current yield in percent
+func BuildAggregates(histories []models.DividendHistory, schedule *models.Schedule, yields map[string]float64, now time.Time) Aggregates {
+	today := models.MarketDate(now)
+	aggregates := Aggregates{AverageYieldByGroup: make(map[string]float64)}
+
+	yieldSums := make(map[string]float64)
+	yieldCounts := make(map[string]int)
+
+	var ytdMicros int64
+	for _, history := range histories {
+		var latest *models.DividendEvent
+		for i := range history.Events {
+			event := &history.Events[i]
+			if event.ExDate.IsZero() || models.CalendarDate(event.ExDate).After(today) {
+				continue
+			}
+			if event.ExDate.Year() == today.Year() {
+				ytdMicros += models.AmountToMicros(event.Amount)
+			}
+			if latest == nil || event.ExDate.After(latest.ExDate) {
+				latest = event
+			}
+		}
+
+		if latest != nil && latest.Amount > 0 {
+			payout := &Payout{Symbol: history.Symbol, Amount: latest.Amount, ExDate: latest.ExDate.Format("2006-01-02")}
+			if aggregates.HighestRecentPayout == nil || payout.Amount > aggregates.HighestRecentPayout.Amount {
+				aggregates.HighestRecentPayout = payout
+			}
+			if aggregates.LowestRecentPayout == nil || payout.Amount < aggregates.LowestRecentPayout.Amount {
+				aggregates.LowestRecentPayout = payout
+			}
+		}
+
+		if yield, ok := yields[history.Symbol]; ok && yield > 0 {
+			yieldSums[history.Group] += yield
+			yieldCounts[history.Group]++
+		}
+	}
+	aggregates.YearToDateTotal = models.MicrosToAmount(ytdMicros)
+
+	for group, sum := range yieldSums {
+		aggregates.AverageYieldByGroup[group] = math.Round(sum/float64(yieldCounts[group])*100) / 100
+	}
+
+	if schedule != nil {
+		in7Days := today.AddDate(0, 0, 7)
+		in30Days := today.AddDate(0, 0, 30)
+		for _, event := range schedule.Upcoming {
+			exDate := models.CalendarDate(event.ExDate)
+			if event.ExDate.IsZero() || exDate.Before(today) {
+				continue
+			}
+			if !exDate.After(in7Days) {
+				aggregates.UpcomingNext7Days++
+			}
+			if !exDate.After(in30Days) {
+				aggregates.UpcomingNext30Days++
+			}
+		}
+	}
+
+	return aggregates
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestBuildAggregates(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, models.MarketLocation)
+	histories := []models.DividendHistory{
+		{Symbol: "ULTY", Group: "Weekly", Events: []models.DividendEvent{
+			{ExDate: marketDate(2025, 6, 12), Amount: 9.99}, // Announced but not yet paid
+			{ExDate: marketDate(2025, 6, 5), Amount: 0.0948},
+			{ExDate: marketDate(2025, 5, 29), Amount: 0.0952},
+		}},
+		{Symbol: "CONY", Group: "GroupC", Events: []models.DividendEvent{
+			{ExDate: marketDate(2025, 5, 15), Amount: 0.8063},
+			{ExDate: marketDate(2024, 12, 19), Amount: 0.7551},
+		}},
+		{Symbol: "MSTY", Group: "GroupC", Events: []models.DividendEvent{
+			{ExDate: marketDate(2025, 5, 15), Amount: 1.3},
+		}},
+		{Symbol: "NONE", Group: "GroupA"},
+	}
+	yields := map[string]float64{"ULTY": 79.48, "CONY": 100.1, "MSTY": 90.2, "NONE": 0}
+	schedule := &models.Schedule{Upcoming: []models.DividendEvent{
+		{Symbol: "PAST", ExDate: marketDate(2025, 6, 9)},
+		{Symbol: "TODAY", ExDate: marketDate(2025, 6, 10)},
+		{Symbol: "WEEK", ExDate: marketDate(2025, 6, 17)},
+		{Symbol: "MONTH", ExDate: marketDate(2025, 7, 10)},
+		{Symbol: "LATER", ExDate: marketDate(2025, 7, 11)},
+		{Symbol: "UNKNOWN"},
+	}}
+
+	got := BuildAggregates(histories, schedule, yields, now)
+
+	if want := 0.0948 + 0.0952 + 0.8063 + 1.3; got.YearToDateTotal != models.RoundAmount(want) {
+		t.Errorf("YearToDateTotal = %v, want %v", got.YearToDateTotal, models.RoundAmount(want))
+	}
+	wantYields := map[string]float64{"Weekly": 79.48, "GroupC": 95.15}
+	if len(got.AverageYieldByGroup) != len(wantYields) {
+		t.Errorf("AverageYieldByGroup = %v, want %v", got.AverageYieldByGroup, wantYields)
+	}
+	for group, want := range wantYields {
+		if got.AverageYieldByGroup[group] != want {
+			t.Errorf("average yield of %s = %v, want %v", group, got.AverageYieldByGroup[group], want)
+		}
+	}
+	if got.HighestRecentPayout == nil || *got.HighestRecentPayout != (Payout{"MSTY", 1.3, "2025-05-15"}) {
+		t.Errorf("HighestRecentPayout = %+v, want MSTY 1.3", got.HighestRecentPayout)
+	}
+	if got.LowestRecentPayout == nil || *got.LowestRecentPayout != (Payout{"ULTY", 0.0948, "2025-06-05"}) {
+		t.Errorf("LowestRecentPayout = %+v, want ULTY 0.0948", got.LowestRecentPayout)
+	}
+	if got.UpcomingNext7Days != 2 || got.UpcomingNext30Days != 3 {
+		t.Errorf("upcoming 7/30 days = %d/%d, want 2/3", got.UpcomingNext7Days, got.UpcomingNext30Days)
+	}
+}
+
+func TestBuildAggregatesWithoutData(t *testing.T) {
+	got := BuildAggregates(nil, nil, nil, time.Now())
+	if got.YearToDateTotal != 0 || got.HighestRecentPayout != nil || got.UpcomingNext30Days != 0 || got.AverageYieldByGroup == nil {
+		t.Errorf("BuildAggregates(nil) = %+v, want zero totals and an empty yield map", got)
+	}
+}
@@ -0,0 +1,133 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+// WriteFileAtomic writes a file through a temp file in the same directory and
+// renames it into place only once write succeeds, so a failed or interrupted
+// write never leaves a truncated file behind
+func WriteFileAtomic(filename string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for %s: %w", filename, err)
+	}
+	tmpName := tmp.Name()
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	if err := os.Chmod(tmpName, 0644); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to set permissions on %s: %w", filename, err)
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to replace %s: %w", filename, err)
+	}
+
+	return nil
+}
+
+// compactJSON drops indentation from files written by SaveJSON
+var compactJSON atomic.Bool
+
+// SetCompactJSON makes SaveJSON write unindented JSON, which is much smaller
+// for large histories; the default is two-space indentation
+func SetCompactJSON(compact bool) {
+	compactJSON.Store(compact)
+}
+
+// SaveJSON atomically writes data to filename as indented JSON, or compact
+// JSON after SetCompactJSON(true)
+func SaveJSON(filename string, data interface{}) error {
+	return WriteFileAtomic(filename, func(w io.Writer) error {
+		return WriteJSON(w, data)
+	})
+}
+
+// WriteJSON encodes data to w the way SaveJSON writes files
+func WriteJSON(w io.Writer, data interface{}) error {
+	encoder := json.NewEncoder(w)
+	if !compactJSON.Load() {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+// SaveHistory writes history to filename like SaveJSON unless the file
+// already holds the same history apart from UpdatedAt, so re-running a
+// crawl that found nothing new leaves the published files, and their
+// modification times, unchanged. Either way it records the history's hash
+// and UpdatedAt as the check time in the directory's HistoryChecksFileName
+// sidecar. It reports whether the file was written.
+func SaveHistory(filename string, history *models.DividendHistory) (bool, error) {
+	hash, err := historyHash(history)
+	if err != nil {
+		return false, err
+	}
+
+	historyChecksMu.Lock()
+	defer historyChecksMu.Unlock()
+
+	checks := loadHistoryChecks(filename)
+	name := filepath.Base(filename)
+	written := !historyUnchanged(filename, checks[name], hash)
+	if written {
+		if err := SaveJSON(filename, history); err != nil {
+			return false, err
+		}
+	}
+
+	fileHash, err := fileSHA256(filename)
+	if err != nil {
+		return written, fmt.Errorf("failed to hash %s: %w", filename, err)
+	}
+	checkedAt := history.UpdatedAt
+	if checkedAt.IsZero() {
+		checkedAt = time.Now()
+	}
+	checks[name] = HistoryCheck{SHA256: hash, FileSHA256: fileHash, CheckedAt: checkedAt}
+	if err := SaveJSON(historyChecksPath(filename), checks); err != nil {
+		return written, fmt.Errorf("failed to record the check of %s: %w", filename, err)
+	}
+	return written, nil
+}
+
+// historyUnchanged reports whether filename still holds a history hashing to
+// hash. A sidecar entry is trusted only while the file's bytes match what it
+// recorded, so a hand edit counts as a change; without an entry the file is
+// decoded and hashed. A missing or unreadable file counts as changed.
+func historyUnchanged(filename string, check HistoryCheck, hash string) bool {
+	existing, err := os.ReadFile(filename)
+	if err != nil {
+		return false
+	}
+	if check.FileSHA256 != "" {
+		return check.SHA256 == hash && check.FileSHA256 == hexSHA256(existing)
+	}
+
+	var saved models.DividendHistory
+	if err := json.Unmarshal(existing, &saved); err != nil {
+		return false
+	}
+	savedHash, err := historyHash(&saved)
+	return err == nil && savedHash == hash
+}
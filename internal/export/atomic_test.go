@@ -0,0 +1,272 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestSaveJSONKeepsOriginalOnEncodeError(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "etfs.json")
+	original := []byte(`[{"symbol":"TSLY"}]` + "\n")
+	if err := os.WriteFile(filename, original, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// NaN can't be encoded as JSON, so encoding fails after the temp file exists
+	if err := SaveJSON(filename, map[string]float64{"yield": math.NaN()}); err == nil {
+		t.Fatal("SaveJSON of NaN succeeded")
+	}
+
+	got, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(original) {
+		t.Errorf("file changed to %q after a failed save", got)
+	}
+	assertOnlyFile(t, dir, "etfs.json")
+}
+
+func TestWriteFileAtomicInterruptedWrite(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "dividends_TSLY.json")
+	if err := os.WriteFile(filename, []byte("good"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := errors.New("killed")
+	err := WriteFileAtomic(filename, func(w io.Writer) error {
+		w.Write([]byte("half"))
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("WriteFileAtomic error = %v, want the write error", err)
+	}
+	if got, _ := os.ReadFile(filename); string(got) != "good" {
+		t.Errorf("file holds %q after an interrupted write, want the original", got)
+	}
+	assertOnlyFile(t, dir, "dividends_TSLY.json")
+
+	// A successful write replaces the file, readable by everyone
+	if err := SaveJSON(filename, []int{1}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("permissions = %v, want 0644", info.Mode().Perm())
+	}
+	assertOnlyFile(t, dir, "dividends_TSLY.json")
+}
+
+func TestSaveJSONRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "etfs.json")
+
+	for _, etfs := range [][]models.ETF{
+		{{Symbol: "TSLY", Group: "GroupA"}},
+		{{Symbol: "TSLY", Group: "GroupA"}, {Symbol: "ULTY", Group: "Weekly"}},
+	} {
+		if err := SaveJSON(filename, etfs); err != nil {
+			t.Fatalf("SaveJSON: %v", err)
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []models.ETF
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("saved file doesn't parse: %v", err)
+		}
+		if len(got) != len(etfs) || got[len(got)-1].Symbol != etfs[len(etfs)-1].Symbol {
+			t.Errorf("read back %+v, want %+v", got, etfs)
+		}
+		assertOnlyFile(t, dir, "etfs.json")
+	}
+}
+
+func TestSaveJSONCompact(t *testing.T) {
+	history := models.DividendHistory{Symbol: "ULTY", Frequency: "weekly"}
+	for i := 0; i < 52; i++ {
+		exDate := marketDate(2025, 6, 5).AddDate(0, 0, -7*i)
+		history.Events = append(history.Events, models.DividendEvent{
+			Symbol:  "ULTY",
+			ExDate:  exDate,
+			PayDate: exDate.AddDate(0, 0, 1),
+			Amount:  0.094,
+		})
+	}
+
+	dir := t.TempDir()
+	save := func(name string, compact bool) []byte {
+		t.Helper()
+		SetCompactJSON(compact)
+		defer SetCompactJSON(false)
+
+		filename := filepath.Join(dir, name)
+		if err := SaveJSON(filename, history); err != nil {
+			t.Fatalf("SaveJSON: %v", err)
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	pretty := save("pretty.json", false)
+	compact := save("compact.json", true)
+
+	if strings.Count(string(compact), "\n") != 1 || strings.Contains(string(compact), "  ") {
+		t.Errorf("compact output is indented:\n%s", compact)
+	}
+	if len(compact) >= len(pretty)*3/4 {
+		t.Errorf("compact output is %d bytes, want well under the %d pretty bytes", len(compact), len(pretty))
+	}
+
+	var parsed models.DividendHistory
+	if err := json.Unmarshal(compact, &parsed); err != nil {
+		t.Fatalf("compact output doesn't parse: %v", err)
+	}
+	if len(parsed.Events) != 52 || !parsed.Events[51].ExDate.Equal(history.Events[51].ExDate) {
+		t.Errorf("compact output parsed to %d events, want the 52 saved", len(parsed.Events))
+	}
+}
+
+// assertOnlyFile fails unless name is the only entry in dir, i.e. no temp file was left behind
+func assertOnlyFile(t *testing.T, dir, name string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != name {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("directory holds %v, want only %s", names, name)
+	}
+}
+
+func TestSaveHistorySkipsUnchangedFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "dividends_TSLY.json")
+	history := &models.DividendHistory{
+		Symbol:    "TSLY",
+		Frequency: "monthly",
+		Events:    []models.DividendEvent{{Symbol: "TSLY", ExDate: marketDate(2025, 6, 5), Amount: 0.437}},
+		UpdatedAt: time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC),
+	}
+	if written, err := SaveHistory(filename, history); err != nil || !written {
+		t.Fatalf("first SaveHistory = %v, %v; want a write", written, err)
+	}
+	original, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checked := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filename, checked, checked); err != nil {
+		t.Fatal(err)
+	}
+
+	// A re-run that found the same events, only later
+	rerun := *history
+	rerun.UpdatedAt = history.UpdatedAt.Add(24 * time.Hour)
+	if written, err := SaveHistory(filename, &rerun); err != nil || written {
+		t.Fatalf("identical SaveHistory = %v, %v; want no write", written, err)
+	}
+	got, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(original) {
+		t.Error("unchanged history was rewritten with a new updatedAt")
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(checked) {
+		t.Errorf("modification time moved to %v, want it left at %v", info.ModTime(), checked)
+	}
+	// The sidecar records the check instead
+	if checkedAt, ok := HistoryCheckedAt(filename); !ok || !checkedAt.Equal(rerun.UpdatedAt) {
+		t.Errorf("HistoryCheckedAt = %v, %v; want %v", checkedAt, ok, rerun.UpdatedAt)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name() != HistoryChecksFileName || entries[1].Name() != "dividends_TSLY.json" {
+		t.Errorf("directory holds %v, want only the history and %s", entries, HistoryChecksFileName)
+	}
+
+	// A new event is written, with the new updatedAt
+	rerun.Events = append([]models.DividendEvent{{Symbol: "TSLY", ExDate: marketDate(2025, 7, 3), Amount: 0.4}}, rerun.Events...)
+	if written, err := SaveHistory(filename, &rerun); err != nil || !written {
+		t.Fatalf("changed SaveHistory = %v, %v; want a write", written, err)
+	}
+	var saved models.DividendHistory
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Events) != 2 || !saved.UpdatedAt.Equal(rerun.UpdatedAt) {
+		t.Errorf("saved %d events updated %v, want 2 updated %v", len(saved.Events), saved.UpdatedAt, rerun.UpdatedAt)
+	}
+
+	// A hand edit doesn't match the sidecar, so the scraped history goes back
+	edited := strings.Replace(string(data), "0.437", "0.5", 1)
+	if err := os.WriteFile(filename, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if written, err := SaveHistory(filename, &rerun); err != nil || !written {
+		t.Errorf("SaveHistory over a hand-edited file = %v, %v; want a write", written, err)
+	}
+
+	// A file that isn't a history is replaced
+	if err := os.WriteFile(filename, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if written, err := SaveHistory(filename, &rerun); err != nil || !written {
+		t.Errorf("SaveHistory over a corrupt file = %v, %v; want a write", written, err)
+	}
+}
+
+func TestSaveHistoryWithoutSidecarComparesContent(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "dividends_TSLY.json")
+	history := &models.DividendHistory{
+		Symbol:    "TSLY",
+		Events:    []models.DividendEvent{{Symbol: "TSLY", ExDate: marketDate(2025, 6, 5), Amount: 0.437}},
+		UpdatedAt: time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC),
+	}
+	// Written before the sidecar existed, e.g. a fresh checkout of docs/
+	if err := SaveJSON(filename, history); err != nil {
+		t.Fatal(err)
+	}
+
+	rerun := *history
+	rerun.UpdatedAt = history.UpdatedAt.Add(time.Hour)
+	if written, err := SaveHistory(filename, &rerun); err != nil || written {
+		t.Errorf("SaveHistory = %v, %v; want the unchanged file kept", written, err)
+	}
+	if checkedAt, ok := HistoryCheckedAt(filename); !ok || !checkedAt.Equal(rerun.UpdatedAt) {
+		t.Errorf("HistoryCheckedAt = %v, %v; want %v", checkedAt, ok, rerun.UpdatedAt)
+	}
+}
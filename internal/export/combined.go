@@ -0,0 +1,72 @@
+package export
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+// CombinedDividendsFileName is the bulk download of every ETF's dividend history
+const CombinedDividendsFileName = "all_dividends.json"
+
+// ManifestEntry summarizes one symbol in the combined dividends file
+type ManifestEntry struct {
+	Symbol     string    `json:"symbol"`
+	EventCount int       `json:"eventCount"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// CombinedDividends maps each symbol to its history, with a manifest so
+// clients can tell what changed without walking every history
+type CombinedDividends struct {
+	GeneratedAt time.Time                         `json:"generatedAt"`
+	Slim        bool                              `json:"slim"` // Events omitted; fetch dividends_{SYMBOL}.json for them
+	Manifest    []ManifestEntry                   `json:"manifest"`
+	Histories   map[string]models.DividendHistory `json:"histories"`
+}
+
+// BuildCombinedDividends assembles histories into a CombinedDividends. When
+// slim is set, event arrays are dropped and only stats are kept.
+func BuildCombinedDividends(histories []models.DividendHistory, slim bool, now time.Time) CombinedDividends {
+	combined := CombinedDividends{
+		GeneratedAt: now,
+		Slim:        slim,
+		Manifest:    make([]ManifestEntry, 0, len(histories)),
+		Histories:   make(map[string]models.DividendHistory, len(histories)),
+	}
+
+	for _, history := range histories {
+		combined.Manifest = append(combined.Manifest, ManifestEntry{
+			Symbol:     history.Symbol,
+			EventCount: len(history.Events),
+			UpdatedAt:  history.UpdatedAt,
+		})
+
+		if slim {
+			history.Events = []models.DividendEvent{}
+		}
+		combined.Histories[history.Symbol] = history
+	}
+
+	return combined
+}
+
+// SaveCombinedDividends builds the combined file from the histories in dir
+// and writes it to dir/all_dividends.json, returning the number of symbols
+func SaveCombinedDividends(dir string, slim bool) (int, error) {
+	histories, err := LoadHistories(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	combined := BuildCombinedDividends(histories, slim, time.Now())
+
+	filename := filepath.Join(dir, CombinedDividendsFileName)
+	if err := SaveJSON(filename, combined); err != nil {
+		return 0, fmt.Errorf("failed to save %s: %w", filename, err)
+	}
+
+	return len(histories), nil
+}
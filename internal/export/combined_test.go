@@ -0,0 +1,63 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestSaveCombinedDividendsContainsEverySymbol(t *testing.T) {
+	dir := t.TempDir()
+	updated := time.Date(2025, 6, 10, 4, 0, 0, 0, time.UTC)
+	histories := map[string]int{"ULTY": 3, "CONY": 2, "TSLY": 1}
+	for symbol, count := range histories {
+		history := models.DividendHistory{Symbol: symbol, UpdatedAt: updated}
+		for i := 0; i < count; i++ {
+			history.Events = append(history.Events, models.DividendEvent{
+				Symbol: symbol,
+				ExDate: marketDate(2025, 6, 5).AddDate(0, 0, -7*i),
+				Amount: 0.5,
+			})
+		}
+		if _, err := SaveHistory(filepath.Join(dir, "dividends_"+symbol+".json"), &history); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, slim := range []bool{false, true} {
+		n, err := SaveCombinedDividends(dir, slim)
+		if err != nil {
+			t.Fatalf("slim=%v: SaveCombinedDividends: %v", slim, err)
+		}
+		if n != len(histories) {
+			t.Errorf("slim=%v: combined %d symbols, want %d", slim, n, len(histories))
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, CombinedDividendsFileName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var combined CombinedDividends
+		if err := json.Unmarshal(data, &combined); err != nil {
+			t.Fatalf("slim=%v: %s is not valid JSON: %v", slim, CombinedDividendsFileName, err)
+		}
+
+		if len(combined.Histories) != len(histories) || len(combined.Manifest) != len(histories) {
+			t.Fatalf("slim=%v: %d histories and %d manifest entries, want %d each",
+				slim, len(combined.Histories), len(combined.Manifest), len(histories))
+		}
+		for _, entry := range combined.Manifest {
+			if want := histories[entry.Symbol]; entry.EventCount != want || !entry.UpdatedAt.Equal(updated) {
+				t.Errorf("slim=%v: manifest %+v, want %d events updated %s", slim, entry, want, updated)
+			}
+			events := combined.Histories[entry.Symbol].Events
+			if wantEvents := histories[entry.Symbol]; slim && len(events) != 0 || !slim && len(events) != wantEvents {
+				t.Errorf("slim=%v: %s has %d events in the combined file", slim, entry.Symbol, len(events))
+			}
+		}
+	}
+}
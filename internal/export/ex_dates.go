@@ -0,0 +1,77 @@
+package export
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/models"
+)
+
+// Files listing the ETFs that go ex-dividend today and tomorrow in New York
+const (
+	TodayExFileName    = "today_ex.json"
+	TomorrowExFileName = "tomorrow_ex.json"
+)
+
+// ExDateList is the content of today_ex.json and tomorrow_ex.json
+type ExDateList struct {
+	Date        string        `json:"date"` // Market calendar date (YYYY-MM-DD)
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Count       int           `json:"count"`
+	ETFs        []ExDateEntry `json:"etfs"`
+}
+
+// ExDateEntry is one ETF going ex-dividend on the list's date
+type ExDateEntry struct {
+	Symbol    string  `json:"symbol"`
+	Group     string  `json:"group,omitempty"`
+	Amount    float64 `json:"amount,omitempty"`    // Omitted until announced
+	Estimated bool    `json:"estimated,omitempty"` // Amount is a placeholder
+	PayDate   string  `json:"payDate,omitempty"`
+}
+
+// BuildExDateList lists the events that go ex-dividend on day's market date
+func BuildExDateList(events []models.DividendEvent, day time.Time, generatedAt time.Time) ExDateList {
+	list := ExDateList{
+		Date:        models.MarketDate(day).Format("2006-01-02"),
+		GeneratedAt: generatedAt,
+		ETFs:        []ExDateEntry{},
+	}
+	for _, event := range models.ExDatesOn(events, day) {
+		entry := ExDateEntry{
+			Symbol:    event.Symbol,
+			Group:     event.Group,
+			Amount:    event.Amount,
+			Estimated: event.Estimated,
+		}
+		if !event.PayDate.IsZero() {
+			entry.PayDate = event.PayDate.Format("2006-01-02")
+		}
+		list.ETFs = append(list.ETFs, entry)
+	}
+	list.Count = len(list.ETFs)
+	return list
+}
+
+// SaveExDateLists writes dir/today_ex.json and dir/tomorrow_ex.json from the
+// schedule's upcoming events, taking today from clk in New York time
+func SaveExDateLists(dir string, schedule *models.Schedule, clk clock.Clock) error {
+	now := clk.Now()
+	today := models.MarketDate(now)
+	lists := []struct {
+		name string
+		day  time.Time
+	}{
+		{TodayExFileName, today},
+		{TomorrowExFileName, today.AddDate(0, 0, 1)},
+	}
+	for _, list := range lists {
+		filename := filepath.Join(dir, list.name)
+		if err := SaveJSON(filename, BuildExDateList(schedule.Upcoming, list.day, now)); err != nil {
+			return fmt.Errorf("failed to save %s: %w", filename, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/models"
+)
+
+func TestSaveExDateLists(t *testing.T) {
+	event := func(symbol string, day int, amount float64) models.DividendEvent {
+		return models.DividendEvent{Symbol: symbol, Group: "GroupA", ExDate: marketDate(2025, 6, day), PayDate: marketDate(2025, 6, day+1), Amount: amount}
+	}
+	schedule := &models.Schedule{Upcoming: []models.DividendEvent{
+		event("NVDY", 9, 0.5),
+		event("TSLY", 10, 0.4),
+		event("ULTY", 11, 0.0965),
+		event("CRSH", 11, 0),
+		event("MSTY", 12, 1.2),
+	}}
+
+	// 22:00 on the 10th in New York is already the 11th in UTC
+	clk := clock.NewFake(time.Date(2025, 6, 11, 2, 0, 0, 0, time.UTC))
+	dir := t.TempDir()
+	if err := SaveExDateLists(dir, schedule, clk); err != nil {
+		t.Fatalf("SaveExDateLists: %v", err)
+	}
+
+	tests := []struct {
+		file    string
+		date    string
+		symbols []string
+	}{
+		{TodayExFileName, "2025-06-10", []string{"TSLY"}},
+		{TomorrowExFileName, "2025-06-11", []string{"CRSH", "ULTY"}},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Fatalf("%s not written: %v", tt.file, err)
+		}
+		var list ExDateList
+		if err := json.Unmarshal(data, &list); err != nil {
+			t.Fatal(err)
+		}
+		if list.Date != tt.date || list.Count != len(tt.symbols) || len(list.ETFs) != len(tt.symbols) {
+			t.Fatalf("%s = %+v, want %s with %v", tt.file, list, tt.date, tt.symbols)
+		}
+		for i, symbol := range tt.symbols {
+			if list.ETFs[i].Symbol != symbol {
+				t.Errorf("%s entry %d = %s, want %s", tt.file, i, list.ETFs[i].Symbol, symbol)
+			}
+		}
+	}
+
+	// An unannounced amount is left out rather than written as zero
+	data, err := os.ReadFile(filepath.Join(dir, TomorrowExFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw struct {
+		ETFs []map[string]interface{} `json:"etfs"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw.ETFs[0]["amount"]; ok {
+		t.Errorf("CRSH entry %v has an amount", raw.ETFs[0])
+	}
+	if raw.ETFs[1]["amount"] != 0.0965 || raw.ETFs[1]["payDate"] != "2025-06-12" {
+		t.Errorf("ULTY entry = %v, want amount 0.0965 paid 2025-06-12", raw.ETFs[1])
+	}
+}
@@ -0,0 +1,62 @@
+package export
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"divminder-crawler/internal/models"
+)
+
+// GroupScheduleFileName returns the per-group schedule file name, e.g. schedule_GroupA.json
+func GroupScheduleFileName(group string) string {
+	return fmt.Sprintf("schedule_%s.json", group)
+}
+
+// BuildGroupSchedule returns group's schedule with only the events of its own
+// ETFs, drawing on both the group's events and the schedule's upcoming list
+func BuildGroupSchedule(schedule *models.Schedule, groupSchedule models.GroupSchedule) models.GroupSchedule {
+	members := make(map[string]bool, len(groupSchedule.ETFs))
+	for _, symbol := range groupSchedule.ETFs {
+		members[symbol] = true
+	}
+	belongs := func(event models.DividendEvent) bool {
+		if event.Symbol != "" {
+			return members[event.Symbol]
+		}
+		return event.Group == groupSchedule.Group
+	}
+
+	type eventKey struct {
+		symbol string
+		exDate string
+	}
+	seen := make(map[eventKey]bool)
+	events := []models.DividendEvent{}
+	for _, source := range [][]models.DividendEvent{groupSchedule.Events, schedule.Upcoming} {
+		for _, event := range source {
+			key := eventKey{event.Symbol, event.ExDate.Format("2006-01-02")}
+			if !belongs(event) || seen[key] {
+				continue
+			}
+			seen[key] = true
+			events = append(events, event)
+		}
+	}
+
+	groupSchedule.Events = events
+	return groupSchedule
+}
+
+// SaveGroupSchedules writes one schedule_{GROUP}.json per group in schedule
+// to dir and returns the paths written
+func SaveGroupSchedules(dir string, schedule *models.Schedule) ([]string, error) {
+	paths := make([]string, 0, len(schedule.Groups))
+	for _, groupSchedule := range schedule.Groups {
+		path := filepath.Join(dir, GroupScheduleFileName(groupSchedule.Group))
+		if err := SaveJSON(path, BuildGroupSchedule(schedule, groupSchedule)); err != nil {
+			return paths, fmt.Errorf("failed to save %s schedule: %w", groupSchedule.Group, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
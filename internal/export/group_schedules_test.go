@@ -0,0 +1,103 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestSaveGroupSchedulesWritesOneFilePerGroup(t *testing.T) {
+	event := func(symbol, group string, day int) models.DividendEvent {
+		return models.DividendEvent{Symbol: symbol, Group: group, ExDate: marketDate(2025, 6, day), Amount: 0.4}
+	}
+	schedule := &models.Schedule{
+		Groups: []models.GroupSchedule{
+			{Group: "GroupA", ETFs: []string{"TSLY", "CRSH"}, NextExDate: "2025-06-11", NextPayDate: "2025-06-12",
+				Events: []models.DividendEvent{event("TSLY", "GroupA", 11)}},
+			{Group: "GroupB", ETFs: []string{"NVDY"}, NextExDate: "2025-06-18", NextPayDate: "2025-06-19"},
+			{Group: "Weekly", ETFs: []string{"ULTY"}, NextExDate: "2025-06-12", NextPayDate: "2025-06-13",
+				Events: []models.DividendEvent{event("ULTY", "Weekly", 12)}},
+			{Group: "Target12", ETFs: []string{"BIGY"}},
+		},
+		Upcoming: []models.DividendEvent{
+			event("TSLY", "GroupA", 11), // duplicate of the group's own event
+			event("CRSH", "GroupA", 11),
+			event("NVDY", "GroupB", 18),
+			event("ULTY", "Weekly", 19),
+		},
+	}
+
+	dir := t.TempDir()
+	paths, err := SaveGroupSchedules(dir, schedule)
+	if err != nil {
+		t.Fatalf("SaveGroupSchedules: %v", err)
+	}
+	if len(paths) != len(schedule.Groups) {
+		t.Fatalf("wrote %d files, want %d", len(paths), len(schedule.Groups))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	wantNames := []string{"schedule_GroupA.json", "schedule_GroupB.json", "schedule_Target12.json", "schedule_Weekly.json"}
+	if len(names) != len(wantNames) {
+		t.Fatalf("files = %v, want %v", names, wantNames)
+	}
+	for i := range names {
+		if names[i] != wantNames[i] {
+			t.Fatalf("files = %v, want %v", names, wantNames)
+		}
+	}
+
+	wantSymbols := map[string][]string{
+		"GroupA":   {"CRSH", "TSLY"},
+		"GroupB":   {"NVDY"},
+		"Weekly":   {"ULTY", "ULTY"},
+		"Target12": nil,
+	}
+	for _, want := range schedule.Groups {
+		data, err := os.ReadFile(filepath.Join(dir, GroupScheduleFileName(want.Group)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got models.GroupSchedule
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%s: %v", want.Group, err)
+		}
+
+		if got.Group != want.Group || got.NextExDate != want.NextExDate || got.NextPayDate != want.NextPayDate {
+			t.Errorf("%s: header = %s %s/%s, want %s/%s", want.Group, got.Group, got.NextExDate, got.NextPayDate, want.NextExDate, want.NextPayDate)
+		}
+		if got.Events == nil {
+			t.Errorf("%s: events encoded as null, want an empty list", want.Group)
+		}
+
+		var symbols []string
+		for _, e := range got.Events {
+			if e.Group != want.Group {
+				t.Errorf("%s: contains %s event from %s", want.Group, e.Symbol, e.Group)
+			}
+			symbols = append(symbols, e.Symbol)
+		}
+		sort.Strings(symbols)
+		if len(symbols) != len(wantSymbols[want.Group]) {
+			t.Errorf("%s: event symbols = %v, want %v", want.Group, symbols, wantSymbols[want.Group])
+			continue
+		}
+		for i := range symbols {
+			if symbols[i] != wantSymbols[want.Group][i] {
+				t.Errorf("%s: event symbols = %v, want %v", want.Group, symbols, wantSymbols[want.Group])
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+// HistoryChecksFileName is the sidecar beside a directory's histories where
+// SaveHistory records each one's content hash and when a run last checked it.
+// It's a dotfile so the index leaves it out.
+const HistoryChecksFileName = ".history_checks.json"
+
+// HistoryCheck is the sidecar entry for one history file
+type HistoryCheck struct {
+	SHA256     string    `json:"sha256"`     // The history's JSON without UpdatedAt
+	FileSHA256 string    `json:"fileSha256"` // The file's bytes as last written
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+// historyChecksMu serializes sidecar updates from concurrent SaveHistory calls
+var historyChecksMu sync.Mutex
+
+func historyChecksPath(filename string) string {
+	return filepath.Join(filepath.Dir(filename), HistoryChecksFileName)
+}
+
+// loadHistoryChecks reads the sidecar for filename's directory, keyed by file
+// name; a missing or unreadable sidecar reads as empty
+func loadHistoryChecks(filename string) map[string]HistoryCheck {
+	checks := make(map[string]HistoryCheck)
+	data, err := os.ReadFile(historyChecksPath(filename))
+	if err != nil {
+		return checks
+	}
+	if err := json.Unmarshal(data, &checks); err != nil || checks == nil {
+		return make(map[string]HistoryCheck)
+	}
+	return checks
+}
+
+// HistoryCheckedAt returns when SaveHistory last checked filename, whether or
+// not it rewrote it, or false when the sidecar has no entry for it
+func HistoryCheckedAt(filename string) (time.Time, bool) {
+	historyChecksMu.Lock()
+	defer historyChecksMu.Unlock()
+
+	check, ok := loadHistoryChecks(filename)[filepath.Base(filename)]
+	return check.CheckedAt, ok && !check.CheckedAt.IsZero()
+}
+
+// historyHash hashes what SaveJSON would write for history with UpdatedAt
+// cleared, so two runs that found the same events hash the same
+func historyHash(history *models.DividendHistory) (string, error) {
+	candidate := *history
+	candidate.UpdatedAt = time.Time{}
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, candidate); err != nil {
+		return "", err
+	}
+	return hexSHA256(buf.Bytes()), nil
+}
+
+func fileSHA256(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	return hexSHA256(data), nil
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
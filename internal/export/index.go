@@ -0,0 +1,110 @@
+package export
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IndexFileName lists every data file in the output directory
+const IndexFileName = "index.json"
+
+// IndexEntry describes one published data file
+type IndexEntry struct {
+	Path      string    `json:"path"`             // Relative to the output directory, with forward slashes
+	Symbol    string    `json:"symbol,omitempty"` // Set for per-symbol files
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Index lets clients discover which files a run actually produced
+type Index struct {
+	GeneratedAt time.Time    `json:"generatedAt"`
+	Files       []IndexEntry `json:"files"`
+}
+
+var symbolFilePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^dividends_([A-Z0-9.]+)\.(?:nd)?json$`),
+	regexp.MustCompile(`^([A-Z0-9.]+)_dividend_history\.json$`),
+	regexp.MustCompile(`^([A-Z0-9.]+)_events\.ndjson$`),
+}
+
+// symbolFromFileName returns the ticker a per-symbol file belongs to, or ""
+func symbolFromFileName(name string) string {
+	for _, pattern := range symbolFilePatterns {
+		if match := pattern.FindStringSubmatch(name); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// BuildIndex lists the .json and .ndjson files under dir, including
+// subdirectories, sorted by path. The index file itself and dotfiles such as
+// HistoryChecksFileName are left out.
+func BuildIndex(dir string, now time.Time) (Index, error) {
+	index := Index{GeneratedAt: now, Files: []IndexEntry{}}
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(entry.Name())
+		if (ext != ".json" && ext != ".ndjson") || strings.HasPrefix(entry.Name(), ".") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == IndexFileName {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		index.Files = append(index.Files, IndexEntry{
+			Path:      filepath.ToSlash(rel),
+			Symbol:    symbolFromFileName(entry.Name()),
+			Size:      info.Size(),
+			UpdatedAt: info.ModTime().UTC(),
+		})
+		return nil
+	})
+	if err != nil {
+		return Index{}, fmt.Errorf("failed to index %s: %w", dir, err)
+	}
+
+	sort.Slice(index.Files, func(i, j int) bool {
+		return index.Files[i].Path < index.Files[j].Path
+	})
+	return index, nil
+}
+
+// SaveIndex writes dir/index.json from the files currently in dir, returning
+// the number of files listed
+func SaveIndex(dir string) (int, error) {
+	index, err := BuildIndex(dir, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	filename := filepath.Join(dir, IndexFileName)
+	if err := SaveJSON(filename, index); err != nil {
+		return 0, fmt.Errorf("failed to save %s: %w", filename, err)
+	}
+	return len(index.Files), nil
+}
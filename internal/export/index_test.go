@@ -0,0 +1,74 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveIndexMatchesFilesOnDisk(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"dividends_ULTY.json":       `{"symbol":"ULTY"}`,
+		"dividends_TSLY.ndjson":     "{}\n{}\n",
+		"ULTY_events.ndjson":        "{}\n",
+		"etfs.json":                 `[]`,
+		"aliases/dividends_OL.json": `{"symbol":"OL"}`,
+		"notes.txt":                 "not data",
+		".cache/entry.json":         `{}`,
+		HistoryChecksFileName:       `{}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count, err := SaveIndex(dir)
+	if err != nil {
+		t.Fatalf("SaveIndex: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, IndexFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		path   string
+		symbol string
+	}{
+		{"ULTY_events.ndjson", "ULTY"},
+		{"aliases/dividends_OL.json", "OL"},
+		{"dividends_TSLY.ndjson", "TSLY"},
+		{"dividends_ULTY.json", "ULTY"},
+		{"etfs.json", ""},
+	}
+	if count != len(want) || len(index.Files) != len(want) {
+		t.Fatalf("indexed %d files (%+v), want %d", count, index.Files, len(want))
+	}
+	for i, w := range want {
+		entry := index.Files[i]
+		if entry.Path != w.path || entry.Symbol != w.symbol {
+			t.Errorf("entry %d = %s (%q), want %s (%q)", i, entry.Path, entry.Symbol, w.path, w.symbol)
+			continue
+		}
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Size != info.Size() || !entry.UpdatedAt.Equal(info.ModTime()) {
+			t.Errorf("%s listed as %d bytes at %v, file is %d bytes at %v",
+				entry.Path, entry.Size, entry.UpdatedAt, info.Size(), info.ModTime())
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"divminder-crawler/internal/models"
+)
+
+// SortEventsBySymbol orders events by symbol, then by ex-date ascending
+func SortEventsBySymbol(events []models.DividendEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Symbol != events[j].Symbol {
+			return events[i].Symbol < events[j].Symbol
+		}
+		return events[i].ExDate.Before(events[j].ExDate)
+	})
+}
+
+// WriteEventsNDJSON writes one JSON-encoded event per line in deterministic order
+func WriteEventsNDJSON(w io.Writer, events []models.DividendEvent) error {
+	sorted := append([]models.DividendEvent(nil), events...)
+	SortEventsBySymbol(sorted)
+
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	for _, event := range sorted {
+		// Encode appends the newline that terminates each record
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("failed to encode event for %s: %w", event.Symbol, err)
+		}
+	}
+
+	return buffered.Flush()
+}
+
+// SaveEventsNDJSON writes events to an NDJSON file
+func SaveEventsNDJSON(filename string, events []models.DividendEvent) error {
+	return WriteFileAtomic(filename, func(w io.Writer) error {
+		return WriteEventsNDJSON(w, events)
+	})
+}
+
+// ReadEventsNDJSON reads events written by WriteEventsNDJSON
+func ReadEventsNDJSON(r io.Reader) ([]models.DividendEvent, error) {
+	var events []models.DividendEvent
+
+	decoder := json.NewDecoder(r)
+	for {
+		var event models.DividendEvent
+		if err := decoder.Decode(&event); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// LoadHistories reads every dividend history file HistoryFiles finds in a
+// directory, skipping unreadable files and ones without a symbol
+func LoadHistories(dir string) ([]models.DividendHistory, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	paths, err := HistoryFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var histories []models.DividendHistory
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var history models.DividendHistory
+		if err := json.Unmarshal(data, &history); err != nil || history.Symbol == "" {
+			continue
+		}
+		histories = append(histories, history)
+	}
+
+	sort.Slice(histories, func(i, j int) bool {
+		return histories[i].Symbol < histories[j].Symbol
+	})
+
+	return histories, nil
+}
+
+// SaveCombinedNDJSON writes the events of every history in dir to a single
+// NDJSON file and returns the number of events written
+func SaveCombinedNDJSON(dir, filename string) (int, error) {
+	histories, err := LoadHistories(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var events []models.DividendEvent
+	for _, history := range histories {
+		events = append(events, history.Events...)
+	}
+
+	if err := SaveEventsNDJSON(filename, events); err != nil {
+		return 0, err
+	}
+
+	return len(events), nil
+}
@@ -0,0 +1,83 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+func marketDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, models.MarketLocation)
+}
+
+func TestEventsNDJSONRoundTrip(t *testing.T) {
+	events := []models.DividendEvent{
+		{Symbol: "ULTY", ExDate: marketDate(2025, 6, 5), PayDate: marketDate(2025, 6, 6), Amount: 0.0948, Group: "Weekly"},
+		{Symbol: "CONY", ExDate: marketDate(2025, 6, 12), PayDate: marketDate(2025, 6, 13), Amount: 0.8063, Group: "GroupC"},
+		{Symbol: "CONY", ExDate: marketDate(2025, 5, 15), PayDate: marketDate(2025, 5, 16), Amount: 0.7551, Group: "GroupC"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteEventsNDJSON(&buf, events); err != nil {
+		t.Fatalf("WriteEventsNDJSON: %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != len(events) {
+		t.Fatalf("wrote %d lines, want one per event", lines)
+	}
+
+	got, err := ReadEventsNDJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadEventsNDJSON: %v", err)
+	}
+
+	// Records come back ordered by symbol, then ex-date ascending
+	want := []models.DividendEvent{events[2], events[1], events[0]}
+	if len(got) != len(want) {
+		t.Fatalf("read %d events, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Symbol != want[i].Symbol || !got[i].ExDate.Equal(want[i].ExDate) ||
+			!got[i].PayDate.Equal(want[i].PayDate) || got[i].Amount != want[i].Amount || got[i].Group != want[i].Group {
+			t.Errorf("event %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoadHistoriesOnlyReadsHistoryFiles(t *testing.T) {
+	dir := t.TempDir()
+	histories := map[string]models.DividendHistory{
+		"dividends_ULTY.json":           {Symbol: "ULTY"},
+		"CONY_dividend_history.json":    {Symbol: "CONY"},
+		"today_ex.json":                 {Symbol: "MSTY"},
+		"etf_groups_scraped_extra.json": {Symbol: "TSLY"},
+	}
+	for name, history := range histories {
+		if err := SaveJSON(filepath.Join(dir, name), history); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dividends_BAD.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadHistories(dir)
+	if err != nil {
+		t.Fatalf("LoadHistories: %v", err)
+	}
+	var symbols []string
+	for _, history := range loaded {
+		symbols = append(symbols, history.Symbol)
+	}
+	if got := strings.Join(symbols, ","); got != "CONY,ULTY" {
+		t.Errorf("loaded %s, want only the history files CONY,ULTY", got)
+	}
+
+	if _, err := LoadHistories(filepath.Join(dir, "missing")); err == nil {
+		t.Error("LoadHistories on a missing directory succeeded")
+	}
+}
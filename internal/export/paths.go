@@ -0,0 +1,55 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// SummaryFileName is the name of the per-ETF summary written next to the history directory
+const SummaryFileName = "etf_summary.json"
+
+// ScheduleFileName is the name of the dividend schedule the crawler writes
+const ScheduleFileName = "schedule_v3.json"
+
+// SummaryPath returns where the ETF summary for histories in outputDir is
+// written: alongside outputDir, e.g. docs/dividends -> docs/etf_summary.json
+func SummaryPath(outputDir string) string {
+	return filepath.Join(filepath.Dir(filepath.Clean(outputDir)), SummaryFileName)
+}
+
+// EnsureOutputDir validates dir and creates it if it doesn't exist
+func EnsureOutputDir(dir string) error {
+	if dir == "" {
+		return fmt.Errorf("output directory must not be empty")
+	}
+
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		return fmt.Errorf("output path %s is not a directory", dir)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", dir, err)
+	}
+
+	return nil
+}
+
+// historyPatterns match the history files written by the crawler and by
+// the scrape commands
+var historyPatterns = []string{"dividends_*.json", "*_dividend_history.json"}
+
+// HistoryFiles lists the dividend history files in dir, sorted by path
+func HistoryFiles(dir string) ([]string, error) {
+	var paths []string
+	for _, pattern := range historyPatterns {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
@@ -0,0 +1,47 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSummaryPath(t *testing.T) {
+	tests := []struct {
+		outputDir string
+		want      string
+	}{
+		{"docs/dividends", filepath.Join("docs", SummaryFileName)},
+		{"docs/dividends/", filepath.Join("docs", SummaryFileName)},
+		{"data", SummaryFileName},
+		{"/tmp/run/out", filepath.Join("/tmp/run", SummaryFileName)},
+	}
+	for _, tt := range tests {
+		if got := SummaryPath(tt.outputDir); got != tt.want {
+			t.Errorf("SummaryPath(%q) = %q, want %q", tt.outputDir, got, tt.want)
+		}
+	}
+}
+
+func TestEnsureOutputDir(t *testing.T) {
+	dir := t.TempDir()
+
+	nested := filepath.Join(dir, "a", "b")
+	if err := EnsureOutputDir(nested); err != nil {
+		t.Fatalf("EnsureOutputDir(%q): %v", nested, err)
+	}
+	if info, err := os.Stat(nested); err != nil || !info.IsDir() {
+		t.Errorf("%s was not created as a directory", nested)
+	}
+
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureOutputDir(file); err == nil {
+		t.Error("EnsureOutputDir accepted a regular file")
+	}
+	if err := EnsureOutputDir(""); err == nil {
+		t.Error("EnsureOutputDir accepted an empty path")
+	}
+}
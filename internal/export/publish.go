@@ -0,0 +1,197 @@
+package export
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// GzipSuffix is appended to a data file's name for its compressed copy
+const GzipSuffix = ".gz"
+
+// SizeReport totals the published data files and their compressed copies
+type SizeReport struct {
+	Files     int   // .json and .ndjson files
+	Bytes     int64 // Their combined size
+	GzipFiles int
+	GzipBytes int64
+}
+
+// String summarizes the report for logs
+func (r SizeReport) String() string {
+	s := fmt.Sprintf("%d files, %s", r.Files, formatBytes(r.Bytes))
+	if r.GzipFiles > 0 {
+		s += fmt.Sprintf("; %d gzip copies, %s", r.GzipFiles, formatBytes(r.GzipBytes))
+	}
+	return s
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// isDataFile reports whether name is a published .json or .ndjson file
+func isDataFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".json" || ext == ".ndjson"
+}
+
+// walkOutput calls visit for every file under dir, skipping hidden files
+// and directories such as in-progress atomic writes
+func walkOutput(dir string, visit func(path string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return visit(path, info)
+	})
+}
+
+// WriteGzipCopies writes name.gz next to every .json and .ndjson file under
+// dir, so static hosts can serve the smaller copy, and removes copies whose
+// source file is gone. It returns the number of copies written.
+func WriteGzipCopies(dir string) (int, error) {
+	var sources, orphans []string
+	err := walkOutput(dir, func(path string, info fs.FileInfo) error {
+		switch {
+		case isDataFile(path):
+			sources = append(sources, path)
+		case strings.HasSuffix(path, GzipSuffix) && isDataFile(strings.TrimSuffix(path, GzipSuffix)):
+			if _, err := os.Stat(strings.TrimSuffix(path, GzipSuffix)); os.IsNotExist(err) {
+				orphans = append(orphans, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	for _, path := range sources {
+		if err := writeGzipCopy(path); err != nil {
+			return 0, err
+		}
+	}
+	for _, path := range orphans {
+		if err := os.Remove(path); err != nil {
+			return 0, fmt.Errorf("failed to remove stale %s: %w", path, err)
+		}
+	}
+	return len(sources), nil
+}
+
+// writeGzipCopy compresses path to path.gz. The gzip header carries no name
+// or time, so unchanged files produce identical copies.
+func writeGzipCopy(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer src.Close()
+
+	return WriteFileAtomic(path+GzipSuffix, func(w io.Writer) error {
+		gz, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(gz, src); err != nil {
+			return fmt.Errorf("failed to compress %s: %w", path, err)
+		}
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("failed to compress %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// MeasureOutput totals the data files and gzip copies under dir
+func MeasureOutput(dir string) (SizeReport, error) {
+	var report SizeReport
+	err := walkOutput(dir, func(path string, info fs.FileInfo) error {
+		switch {
+		case isDataFile(path):
+			report.Files++
+			report.Bytes += info.Size()
+		case strings.HasSuffix(path, GzipSuffix):
+			report.GzipFiles++
+			report.GzipBytes += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return SizeReport{}, fmt.Errorf("failed to measure %s: %w", dir, err)
+	}
+	return report, nil
+}
+
+// PruneStaleSymbolFiles removes the per-symbol files directly in dir, and
+// their gzip copies, that haven't been rewritten within olderThan of now and
+// whose symbol isn't in keep. Unchanged histories aren't rewritten, so keep
+// holds the symbols the run checked; the rest belong to delisted tickers.
+// Subdirectories such as aliases/ are left alone. It returns the removed
+// paths.
+func PruneStaleSymbolFiles(dir string, olderThan time.Duration, now time.Time, keep []string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	kept := make(map[string]bool, len(keep))
+	for _, symbol := range keep {
+		kept[symbol] = true
+	}
+
+	cutoff := now.Add(-olderThan)
+	var removed []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if symbol := symbolFromFileName(entry.Name()); symbol == "" || kept[symbol] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return removed, fmt.Errorf("failed to stat %s: %w", entry.Name(), err)
+		}
+		if !info.ModTime().Before(cutoff) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed = append(removed, path)
+		if err := os.Remove(path + GzipSuffix); err == nil {
+			removed = append(removed, path+GzipSuffix)
+		} else if !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove %s: %w", path+GzipSuffix, err)
+		}
+	}
+	return removed, nil
+}
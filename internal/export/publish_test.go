@@ -0,0 +1,158 @@
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteGzipCopiesRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"schedule_v3.json":       `{"groups":[]}`,
+		"dividends_ULTY.json":    `{"symbol":"ULTY","events":[]}`,
+		"ULTY_events.ndjson":     "{\"amount\":0.1}\n{\"amount\":0.2}\n",
+		"aliases/dividends.json": `{"archived":true}`,
+	}
+	for name, content := range files {
+		writeTestFile(t, filepath.Join(dir, name), content)
+	}
+	writeTestFile(t, filepath.Join(dir, "notes.txt"), "not published")
+	writeTestFile(t, filepath.Join(dir, "dividends_GONE.json.gz"), "stale copy")
+
+	count, err := WriteGzipCopies(dir)
+	if err != nil {
+		t.Fatalf("WriteGzipCopies: %v", err)
+	}
+	if count != len(files) {
+		t.Errorf("wrote %d copies, want %d", count, len(files))
+	}
+
+	for name, content := range files {
+		f, err := os.Open(filepath.Join(dir, name+GzipSuffix))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			t.Fatalf("%s: %v", name, err)
+		}
+		data, err := io.ReadAll(gz)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(data, []byte(content)) {
+			t.Errorf("%s.gz decompresses to %q, want %q", name, data, content)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt"+GzipSuffix)); !os.IsNotExist(err) {
+		t.Errorf("non-data file compressed (err %v)", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dividends_GONE.json.gz")); !os.IsNotExist(err) {
+		t.Errorf("copy of a removed file left behind (err %v)", err)
+	}
+
+	// Unchanged files produce identical copies
+	first, err := os.ReadFile(filepath.Join(dir, "schedule_v3.json.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WriteGzipCopies(dir); err != nil {
+		t.Fatal(err)
+	}
+	second, err := os.ReadFile(filepath.Join(dir, "schedule_v3.json.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("rewriting an unchanged file changed its gzip copy")
+	}
+
+	report, err := MeasureOutput(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Files != len(files) || report.GzipFiles != len(files) {
+		t.Errorf("report = %+v, want %d files and %d gzip copies", report, len(files), len(files))
+	}
+}
+
+func TestPruneStaleSymbolFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	stale := now.Add(-60 * 24 * time.Hour)
+
+	files := map[string]time.Time{
+		"dividends_GONE.json":             stale,
+		"dividends_GONE.json.gz":          stale,
+		"GONE_dividend_history.json":      stale,
+		"GONE_events.ndjson":              stale,
+		"dividends_ULTY.json":             now,
+		"schedule_v3.json":                stale,
+		"aliases/dividends_OLDY.json":     stale,
+		"dividends_FRESH.json":            now.Add(-time.Hour),
+		"dividends_KEPT.json":             stale, // Unchanged, so not rewritten, but still listed
+		"KEPT_events.ndjson":              stale,
+		"notes_dividends_about_GONE.text": stale,
+	}
+	for name, modTime := range files {
+		path := filepath.Join(dir, name)
+		writeTestFile(t, path, "{}")
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := PruneStaleSymbolFiles(dir, 30*24*time.Hour, now, []string{"KEPT", "ULTY"})
+	if err != nil {
+		t.Fatalf("PruneStaleSymbolFiles: %v", err)
+	}
+
+	want := []string{"GONE_dividend_history.json", "GONE_events.ndjson", "dividends_GONE.json", "dividends_GONE.json.gz"}
+	var got []string
+	for _, path := range removed {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, rel)
+	}
+	sort.Strings(got)
+	if len(got) != len(want) {
+		t.Fatalf("removed %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("removed %v, want %v", got, want)
+		}
+	}
+
+	for name := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		removed := false
+		for _, w := range want {
+			removed = removed || w == name
+		}
+		if removed != os.IsNotExist(err) {
+			t.Errorf("%s: removed %v, stat err %v", name, removed, err)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package export
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/models"
+)
+
+// RotationCalendarFileName is the forward-looking group rotation calendar
+const RotationCalendarFileName = "rotation_calendar.json"
+
+// DefaultRotationWeeks is how far ahead the rotation calendar looks
+const DefaultRotationWeeks = 12
+
+// RotationWeek is the group whose funds go ex-dividend in a given week
+type RotationWeek struct {
+	WeekOf time.Time `json:"weekOf"` // Monday of the week
+	Group  string    `json:"group"`
+	ExDate time.Time `json:"exDate"`
+}
+
+// RotationCalendar is the content of rotation_calendar.json
+type RotationCalendar struct {
+	GeneratedAt time.Time      `json:"generatedAt"`
+	Weeks       []RotationWeek `json:"weeks"`
+}
+
+// rotationLookback is how far back events count towards the rotation, so an
+// older schedule doesn't outvote the current one
+const rotationLookback = 26 * 7 * 24 * time.Hour
+
+// rotationEpoch is a Monday used to number weeks
+var rotationEpoch = time.Date(1970, 1, 5, 0, 0, 0, 0, time.UTC)
+
+// isRotatingGroup reports whether group takes turns with the other lettered
+// groups, unlike Weekly and Target12 funds
+func isRotatingGroup(group string) bool {
+	return strings.HasPrefix(group, "Group")
+}
+
+// weekIndex numbers the Monday-to-Sunday week of date's calendar day as
+// written, since saved dates are midnight in whatever zone they were parsed
+func weekIndex(date time.Time) int {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return int(day.Sub(rotationEpoch).Hours()/24) / 7
+}
+
+// mondayOffset is date's weekday counted from Monday
+func mondayOffset(date time.Time) int {
+	return (int(date.Weekday()) + 6) % 7
+}
+
+// RotationEvents gathers the confirmed rotating-group events from the scraped
+// schedule and saved histories, filling in each history's group where its
+// events lack one. Estimated placeholders are left out, so the rotation comes
+// only from announced or paid distributions.
+func RotationEvents(schedule *models.Schedule, histories []models.DividendHistory) []models.DividendEvent {
+	var events []models.DividendEvent
+	add := func(event models.DividendEvent, group string) {
+		if event.Group == "" {
+			event.Group = group
+		}
+		if event.Estimated || event.ExDate.IsZero() || !isRotatingGroup(event.Group) {
+			return
+		}
+		events = append(events, event)
+	}
+
+	if schedule != nil {
+		for _, groupSchedule := range schedule.Groups {
+			for _, event := range groupSchedule.Events {
+				add(event, groupSchedule.Group)
+			}
+		}
+		for _, event := range schedule.Upcoming {
+			add(event, "")
+		}
+	}
+	for _, history := range histories {
+		for _, event := range history.Events {
+			add(event, history.Group)
+		}
+	}
+	return events
+}
+
+// BuildRotationCalendar projects the group rotation seen in the last six
+// months of events over the weeks starting with the current week on clk. The cycle length is the number
+// of rotating groups. Groups are given the turns that agree with the most
+// events, since a few funds are filed under the wrong group, and each group's
+// ex-dividend weekday is the one most of its events fall on.
+func BuildRotationCalendar(events []models.DividendEvent, clk clock.Clock, weeks int) ([]RotationWeek, error) {
+	now := models.MarketDate(clk.Now())
+	cutoff := now.Add(-rotationLookback)
+	var recent []models.DividendEvent
+	for _, event := range events {
+		if isRotatingGroup(event.Group) && !event.ExDate.Before(cutoff) {
+			recent = append(recent, event)
+		}
+	}
+	events = recent
+
+	phaseVotes := make(map[string]map[int]int)
+	weekdayVotes := make(map[string]map[int]int)
+	var groups []string
+	for _, event := range events {
+		if phaseVotes[event.Group] == nil {
+			phaseVotes[event.Group] = make(map[int]int)
+			weekdayVotes[event.Group] = make(map[int]int)
+			groups = append(groups, event.Group)
+		}
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("no recent confirmed group events to derive the rotation from")
+	}
+	sort.Strings(groups)
+	cycle := len(groups)
+
+	for _, event := range events {
+		phaseVotes[event.Group][weekIndex(event.ExDate)%cycle]++
+		weekdayVotes[event.Group][mondayOffset(event.ExDate)]++
+	}
+
+	phases := bestRotation(groups, phaseVotes, cycle)
+	byPhase := make(map[int]string, cycle)
+	for i, group := range groups {
+		byPhase[phases[i]] = group
+	}
+
+	monday := now.AddDate(0, 0, -mondayOffset(now))
+	first := weekIndex(monday)
+
+	calendar := make([]RotationWeek, 0, weeks)
+	for i := 0; i < weeks; i++ {
+		weekOf := monday.AddDate(0, 0, 7*i)
+		group := byPhase[(first+i)%cycle]
+		calendar = append(calendar, RotationWeek{
+			WeekOf: weekOf,
+			Group:  group,
+			ExDate: weekOf.AddDate(0, 0, mostVoted(weekdayVotes[group])),
+		})
+	}
+	return calendar, nil
+}
+
+// bestRotation gives each group a distinct turn in the cycle, choosing the
+// assignment that the most events agree with; earlier assignments in
+// permutation order win ties
+func bestRotation(groups []string, votes map[string]map[int]int, cycle int) []int {
+	best := make([]int, len(groups))
+	bestScore := -1
+	current := make([]int, len(groups))
+	used := make([]bool, cycle)
+
+	var assign func(i, score int)
+	assign = func(i, score int) {
+		if i == len(groups) {
+			if score > bestScore {
+				bestScore = score
+				copy(best, current)
+			}
+			return
+		}
+		for phase := 0; phase < cycle; phase++ {
+			if used[phase] {
+				continue
+			}
+			used[phase] = true
+			current[i] = phase
+			assign(i+1, score+votes[groups[i]][phase])
+			used[phase] = false
+		}
+	}
+	assign(0, 0)
+	return best
+}
+
+// mostVoted returns the key with the most votes, the smallest on a tie
+func mostVoted(votes map[int]int) int {
+	best, bestVotes := 0, -1
+	for key, count := range votes {
+		if count > bestVotes || (count == bestVotes && key < best) {
+			best, bestVotes = key, count
+		}
+	}
+	return best
+}
+
+// SaveRotationCalendar writes dir/rotation_calendar.json for the given weeks
+func SaveRotationCalendar(dir string, events []models.DividendEvent, clk clock.Clock, weeks int) error {
+	weeksAhead, err := BuildRotationCalendar(events, clk, weeks)
+	if err != nil {
+		return err
+	}
+
+	calendar := RotationCalendar{GeneratedAt: clk.Now(), Weeks: weeksAhead}
+	filename := filepath.Join(dir, RotationCalendarFileName)
+	if err := SaveJSON(filename, calendar); err != nil {
+		return fmt.Errorf("failed to save %s: %w", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/models"
+)
+
+func TestBuildRotationCalendar(t *testing.T) {
+	order := []string{"GroupA", "GroupB", "GroupC", "GroupD"}
+	firstMonday := time.Date(2025, 4, 7, 0, 0, 0, 0, models.MarketLocation)
+
+	// Nine weeks of Wednesday ex-dates cycling A, B, C, D from the week of April 7
+	var histories []models.DividendHistory
+	for _, group := range order {
+		histories = append(histories, models.DividendHistory{Symbol: group + "Y", Group: group})
+	}
+	for week := 0; week < 9; week++ {
+		i := week % len(order)
+		histories[i].Events = append(histories[i].Events, models.DividendEvent{
+			Symbol: histories[i].Symbol,
+			ExDate: firstMonday.AddDate(0, 0, 7*week+2),
+		})
+	}
+	schedule := &models.Schedule{
+		Groups: []models.GroupSchedule{{Group: "GroupA", Events: []models.DividendEvent{
+			// A fund filed under the wrong group is outvoted
+			{Symbol: "ODDY", ExDate: firstMonday.AddDate(0, 0, 7*2+2)},
+		}}},
+		Upcoming: []models.DividendEvent{
+			// Weekly funds and estimated placeholders don't take part in the rotation
+			{Symbol: "ULTY", Group: "Weekly", ExDate: firstMonday.AddDate(0, 0, 7*3+3)},
+			{Symbol: "TSLY", Group: "GroupB", ExDate: firstMonday.AddDate(0, 0, 7*4+4), Estimated: true},
+		},
+	}
+
+	events := RotationEvents(schedule, histories)
+	if len(events) != 10 {
+		t.Fatalf("RotationEvents returned %d events, want 10", len(events))
+	}
+
+	// Tuesday of the tenth week
+	clk := clock.NewFake(time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC))
+	calendar, err := BuildRotationCalendar(events, clk, 8)
+	if err != nil {
+		t.Fatalf("BuildRotationCalendar: %v", err)
+	}
+
+	want := []string{"GroupB", "GroupC", "GroupD", "GroupA", "GroupB", "GroupC", "GroupD", "GroupA"}
+	if len(calendar) != len(want) {
+		t.Fatalf("got %d weeks, want %d", len(calendar), len(want))
+	}
+	monday := time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC)
+	for i, week := range calendar {
+		weekOf := monday.AddDate(0, 0, 7*i)
+		exDate := weekOf.AddDate(0, 0, 2)
+		if week.Group != want[i] {
+			t.Errorf("week %d group = %s, want %s", i, week.Group, want[i])
+		}
+		if got := week.WeekOf.Format("2006-01-02"); got != weekOf.Format("2006-01-02") {
+			t.Errorf("week %d starts %s, want %s", i, got, weekOf.Format("2006-01-02"))
+		}
+		if got := week.ExDate.Format("2006-01-02"); got != exDate.Format("2006-01-02") {
+			t.Errorf("week %d ex-date %s, want Wednesday %s", i, got, exDate.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestBuildRotationCalendarWithoutEvents(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC))
+	stale := []models.DividendEvent{{Group: "GroupA", ExDate: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)}}
+	if _, err := BuildRotationCalendar(stale, clk, 8); err == nil {
+		t.Error("expected an error without recent group events")
+	}
+}
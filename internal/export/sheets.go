@@ -0,0 +1,122 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"divminder-crawler/internal/models"
+)
+
+// sheetsHeader is the column layout of the TSV summary
+var sheetsHeader = []string{
+	"symbol", "group", "frequency", "lastAmount", "avgAmount", "ttmTotal", "nextExDate", "nextPayDate",
+}
+
+// SheetsExporter writes a Google Sheets-compatible TSV with one row per ETF
+type SheetsExporter struct {
+	histories []models.DividendHistory
+	schedule  *models.Schedule
+}
+
+// NewSheetsExporter creates an exporter from dividend histories and an optional schedule
+func NewSheetsExporter(histories []models.DividendHistory, schedule *models.Schedule) *SheetsExporter {
+	return &SheetsExporter{
+		histories: histories,
+		schedule:  schedule,
+	}
+}
+
+// Rows returns the header followed by one row per ETF, sorted by symbol
+func (e *SheetsExporter) Rows() [][]string {
+	histories := append([]models.DividendHistory(nil), e.histories...)
+	sort.Slice(histories, func(i, j int) bool {
+		return histories[i].Symbol < histories[j].Symbol
+	})
+
+	rows := [][]string{sheetsHeader}
+	for _, history := range histories {
+		nextExDate, nextPayDate := NextDates(&history, e.schedule)
+		rows = append(rows, []string{
+			history.Symbol,
+			history.Group,
+			history.Frequency,
+			formatAmount(history.Stats.LastAmount),
+			formatAmount(history.Stats.AverageAmount),
+			formatAmount(history.Stats.TrailingYearTotal),
+			nextExDate,
+			nextPayDate,
+		})
+	}
+
+	return rows
+}
+
+// Write writes the TSV to w
+func (e *SheetsExporter) Write(w io.Writer) error {
+	buffered := bufio.NewWriter(w)
+	for _, row := range e.Rows() {
+		fields := make([]string, len(row))
+		for i, field := range row {
+			fields[i] = escapeTSVField(field)
+		}
+		if _, err := buffered.WriteString(strings.Join(fields, "\t") + "\n"); err != nil {
+			return fmt.Errorf("failed to write TSV row: %w", err)
+		}
+	}
+	return buffered.Flush()
+}
+
+// Save writes the TSV to filename, creating its directory if needed
+func (e *SheetsExporter) Save(filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", filename, err)
+	}
+
+	return WriteFileAtomic(filename, e.Write)
+}
+
+// NextDates returns the history's next ex and pay dates as YYYY-MM-DD. It
+// prefers the symbol's earliest upcoming event in schedule, then its group's
+// next dates, and otherwise estimates them from the history's cadence.
+// schedule may be nil.
+func NextDates(history *models.DividendHistory, schedule *models.Schedule) (string, string) {
+	if schedule != nil {
+		var next *models.DividendEvent
+		for i, event := range schedule.Upcoming {
+			if event.Symbol == history.Symbol && (next == nil || event.ExDate.Before(next.ExDate)) {
+				next = &schedule.Upcoming[i]
+			}
+		}
+		if next != nil {
+			return next.ExDate.Format("2006-01-02"), next.PayDate.Format("2006-01-02")
+		}
+
+		for _, groupSchedule := range schedule.Groups {
+			if groupSchedule.Group == history.Group && groupSchedule.NextExDate != "" {
+				return groupSchedule.NextExDate, groupSchedule.NextPayDate
+			}
+		}
+	}
+
+	exDate := models.EstimateNextExDate(history)
+	if exDate.IsZero() {
+		return "", ""
+	}
+	return exDate.Format("2006-01-02"), models.EstimatePayDate(history, exDate).Format("2006-01-02")
+}
+
+// formatAmount formats a dollar amount without trailing zeros
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
+
+// escapeTSVField replaces characters that would break the TSV layout
+func escapeTSVField(field string) string {
+	return strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(field)
+}
@@ -0,0 +1,60 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestSheetsExporterWrite(t *testing.T) {
+	histories := []models.DividendHistory{
+		{
+			Symbol:    "ULTY",
+			Group:     "Weekly",
+			Frequency: "weekly",
+			Stats:     models.DividendStats{LastAmount: 0.0948, AverageAmount: 0.1, TrailingYearTotal: 5.2},
+		},
+		{
+			Symbol:    "CONY",
+			Group:     "GroupC",
+			Frequency: "weekly",
+			Stats:     models.DividendStats{LastAmount: 0.8063, AverageAmount: 0.75, TrailingYearTotal: 9.5},
+		},
+	}
+	schedule := &models.Schedule{
+		Groups: []models.GroupSchedule{{Group: "GroupC", NextExDate: "2025-06-12", NextPayDate: "2025-06-13"}},
+		Upcoming: []models.DividendEvent{
+			{Symbol: "ULTY", ExDate: marketDate(2025, 6, 12), PayDate: marketDate(2025, 6, 13)},
+			{Symbol: "ULTY", ExDate: marketDate(2025, 6, 5), PayDate: marketDate(2025, 6, 6)},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewSheetsExporter(histories, schedule).Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("wrote %d lines, want a header and two rows:\n%s", len(lines), buf.String())
+	}
+	if want := "symbol\tgroup\tfrequency\tlastAmount\tavgAmount\tttmTotal\tnextExDate\tnextPayDate"; lines[0] != want {
+		t.Errorf("header = %q, want %q", lines[0], want)
+	}
+	// Rows are sorted by symbol; CONY takes its group's next dates and ULTY
+	// its own earliest upcoming event
+	if want := "CONY\tGroupC\tweekly\t0.8063\t0.75\t9.5\t2025-06-12\t2025-06-13"; lines[1] != want {
+		t.Errorf("row = %q, want %q", lines[1], want)
+	}
+	if want := "ULTY\tWeekly\tweekly\t0.0948\t0.1\t5.2\t2025-06-05\t2025-06-06"; lines[2] != want {
+		t.Errorf("row = %q, want %q", lines[2], want)
+	}
+}
+
+func TestEscapeTSVField(t *testing.T) {
+	if got := escapeTSVField("Income\tETF\nweekly"); got != "Income ETF weekly" {
+		t.Errorf("escapeTSVField() = %q", got)
+	}
+}
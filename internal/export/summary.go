@@ -0,0 +1,132 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+// Summary is the etf_summary.json layout: one entry per saved history
+type Summary struct {
+	LastUpdated time.Time    `json:"lastUpdated"`
+	ETFs        []models.ETF `json:"etfs"`
+	TotalETFs   int          `json:"totalETFs"`
+}
+
+// SummaryDrift lists the symbols on which the histories, a previous summary
+// and the schedule disagree
+type SummaryDrift struct {
+	MissingFromSummary []string `json:"missingFromSummary,omitempty"` // Has a history but no summary entry
+	OrphanedInSummary  []string `json:"orphanedInSummary,omitempty"`  // Has a summary entry but no history
+	ScheduledNoHistory []string `json:"scheduledNoHistory,omitempty"` // Has upcoming events but no history
+}
+
+// Empty reports whether no drift was found
+func (d SummaryDrift) Empty() bool {
+	return len(d.MissingFromSummary) == 0 && len(d.OrphanedInSummary) == 0 && len(d.ScheduledNoHistory) == 0
+}
+
+// BuildSummary creates the summary of histories, sorted by symbol. Next dates
+// come from schedule when it lists the symbol or its group, and are otherwise
+// estimated; schedule may be nil. active reports whether a symbol is still
+// listed.
+func BuildSummary(histories []models.DividendHistory, schedule *models.Schedule, active func(symbol string) bool, now time.Time) Summary {
+	etfs := make([]models.ETF, 0, len(histories))
+	for i := range histories {
+		history := &histories[i]
+		etf := models.ETF{
+			Symbol:                 history.Symbol,
+			Name:                   history.Name,
+			Group:                  history.Group,
+			Frequency:              history.Frequency,
+			Description:            fmt.Sprintf("YieldMax %s ETF - %s dividend payments", history.Symbol, history.Frequency),
+			Active:                 active(history.Symbol),
+			AnnualizedDistribution: history.AnnualizedDistribution(),
+			Target:                 history.Target,
+		}
+		etf.NextExDate, etf.NextPayDate = NextDates(history, schedule)
+		etfs = append(etfs, etf)
+	}
+	sort.Slice(etfs, func(i, j int) bool { return etfs[i].Symbol < etfs[j].Symbol })
+
+	return Summary{LastUpdated: now, ETFs: etfs, TotalETFs: len(etfs)}
+}
+
+// ReconcileSummary compares histories with the previous summary, which may
+// be nil, and with the symbols the schedule expects to pay
+func ReconcileSummary(histories []models.DividendHistory, previous *Summary, schedule *models.Schedule) SummaryDrift {
+	saved := make(map[string]bool, len(histories))
+	for _, history := range histories {
+		saved[history.Symbol] = true
+	}
+
+	var drift SummaryDrift
+	if previous != nil {
+		listed := make(map[string]bool, len(previous.ETFs))
+		for _, etf := range previous.ETFs {
+			listed[etf.Symbol] = true
+			if !saved[etf.Symbol] {
+				drift.OrphanedInSummary = append(drift.OrphanedInSummary, etf.Symbol)
+			}
+		}
+		for _, history := range histories {
+			if !listed[history.Symbol] {
+				drift.MissingFromSummary = append(drift.MissingFromSummary, history.Symbol)
+			}
+		}
+	}
+
+	if schedule != nil {
+		seen := make(map[string]bool)
+		for _, event := range schedule.Upcoming {
+			if !saved[event.Symbol] && !seen[event.Symbol] {
+				seen[event.Symbol] = true
+				drift.ScheduledNoHistory = append(drift.ScheduledNoHistory, event.Symbol)
+			}
+		}
+	}
+
+	sort.Strings(drift.MissingFromSummary)
+	sort.Strings(drift.OrphanedInSummary)
+	sort.Strings(drift.ScheduledNoHistory)
+	return drift
+}
+
+// LoadSummary reads a saved summary, returning nil without an error when
+// the file doesn't exist
+func LoadSummary(path string) (*Summary, error) {
+	var summary Summary
+	if found, err := loadJSON(path, &summary); !found {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// LoadSchedule reads a saved schedule, returning nil without an error when
+// the file doesn't exist
+func LoadSchedule(path string) (*models.Schedule, error) {
+	var schedule models.Schedule
+	if found, err := loadJSON(path, &schedule); !found {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// loadJSON decodes path into v and reports whether it did
+func loadJSON(path string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestBuildSummaryAnnualizesDistributions(t *testing.T) {
+	histories := []models.DividendHistory{
+		{Symbol: "ULTY", Group: "Weekly", Frequency: "weekly"},
+		{Symbol: "TSLY", Group: "GroupA", Frequency: "monthly"},
+		{Symbol: "NEWY", Group: "GroupB", Frequency: "monthly"},
+	}
+	histories[0].Stats.LastAmount = 0.0965
+	histories[1].Stats.LastAmount = 0.437
+
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	summary := BuildSummary(histories, nil, func(string) bool { return true }, now)
+
+	want := map[string]float64{"ULTY": 5.018, "TSLY": 5.244, "NEWY": 0}
+	for _, etf := range summary.ETFs {
+		if math.Abs(etf.AnnualizedDistribution-want[etf.Symbol]) > 1e-9 {
+			t.Errorf("%s annualized distribution = %v, want %v", etf.Symbol, etf.AnnualizedDistribution, want[etf.Symbol])
+		}
+	}
+	if summary.TotalETFs != len(want) {
+		t.Errorf("summary lists %d ETFs, want %d", summary.TotalETFs, len(want))
+	}
+}
+
+func TestReconcileSummary(t *testing.T) {
+	histories := []models.DividendHistory{{Symbol: "ULTY"}, {Symbol: "TSLY"}, {Symbol: "NEWY"}}
+	schedule := &models.Schedule{Upcoming: []models.DividendEvent{
+		{Symbol: "ULTY", ExDate: marketDate(2025, 6, 12)},
+		{Symbol: "BIGY", ExDate: marketDate(2025, 6, 12)},
+		{Symbol: "BIGY", ExDate: marketDate(2025, 6, 19)},
+	}}
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		previous *Summary
+		want     SummaryDrift
+	}{
+		{
+			name:     "missing from summary",
+			previous: &Summary{ETFs: []models.ETF{{Symbol: "ULTY"}, {Symbol: "TSLY"}}},
+			want:     SummaryDrift{MissingFromSummary: []string{"NEWY"}, ScheduledNoHistory: []string{"BIGY"}},
+		},
+		{
+			name:     "orphaned in summary",
+			previous: &Summary{ETFs: []models.ETF{{Symbol: "ULTY"}, {Symbol: "TSLY"}, {Symbol: "NEWY"}, {Symbol: "GONE"}, {Symbol: "OLDY"}}},
+			want:     SummaryDrift{OrphanedInSummary: []string{"GONE", "OLDY"}, ScheduledNoHistory: []string{"BIGY"}},
+		},
+		{
+			// Without a previous summary only the schedule can disagree
+			name: "no previous summary",
+			want: SummaryDrift{ScheduledNoHistory: []string{"BIGY"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReconcileSummary(histories, tt.previous, schedule); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReconcileSummary = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// A summary rebuilt from the histories agrees with them
+	path := filepath.Join(t.TempDir(), "etf_summary.json")
+	if err := SaveJSON(path, BuildSummary(histories, schedule, func(string) bool { return true }, now)); err != nil {
+		t.Fatal(err)
+	}
+	rebuilt, err := LoadSummary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drift := ReconcileSummary(histories, rebuilt, nil)
+	if !drift.Empty() {
+		t.Errorf("drift after rebuilding = %+v, want none", drift)
+	}
+
+	if missing, err := LoadSummary(filepath.Join(t.TempDir(), "missing.json")); missing != nil || err != nil {
+		t.Errorf("LoadSummary of a missing file = %v, %v; want nil, nil", missing, err)
+	}
+}
@@ -0,0 +1,57 @@
+package export
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+// TotalsByYearFileName holds every ETF's distributions per calendar year and month
+const TotalsByYearFileName = "totals_by_year.json"
+
+// SymbolTotals is one ETF's per-share distributions per calendar period
+type SymbolTotals struct {
+	Years  map[int]float64    `json:"years"`  // Keyed by year
+	Months map[string]float64 `json:"months"` // Keyed YYYY-MM
+}
+
+// TotalsByYear maps each symbol to its calendar totals, for tax and planning
+type TotalsByYear struct {
+	GeneratedAt time.Time               `json:"generatedAt"`
+	Symbols     map[string]SymbolTotals `json:"symbols"`
+}
+
+// BuildTotalsByYear totals each history's events by ex-date year and month
+func BuildTotalsByYear(histories []models.DividendHistory, now time.Time) TotalsByYear {
+	totals := TotalsByYear{
+		GeneratedAt: now,
+		Symbols:     make(map[string]SymbolTotals, len(histories)),
+	}
+	for _, history := range histories {
+		totals.Symbols[history.Symbol] = SymbolTotals{
+			Years:  models.AggregateByYear(history.Events),
+			Months: models.AggregateByMonth(history.Events),
+		}
+	}
+	return totals
+}
+
+// SaveTotalsByYear builds the calendar totals from the histories in dir and
+// writes them to dir/totals_by_year.json, returning the number of symbols
+func SaveTotalsByYear(dir string) (int, error) {
+	histories, err := LoadHistories(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	totals := BuildTotalsByYear(histories, time.Now())
+
+	filename := filepath.Join(dir, TotalsByYearFileName)
+	if err := SaveJSON(filename, totals); err != nil {
+		return 0, fmt.Errorf("failed to save %s: %w", filename, err)
+	}
+
+	return len(histories), nil
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestSaveTotalsByYear(t *testing.T) {
+	dir := t.TempDir()
+	histories := []*models.DividendHistory{
+		{Symbol: "TSLY", Events: []models.DividendEvent{
+			{Symbol: "TSLY", ExDate: marketDate(2026, 1, 2), Amount: 0.4},
+			{Symbol: "TSLY", ExDate: marketDate(2025, 12, 31), Amount: 0.35},
+			{Symbol: "TSLY", ExDate: marketDate(2025, 12, 3), Amount: 0.3},
+		}},
+		{Symbol: "ULTY", Events: []models.DividendEvent{
+			{Symbol: "ULTY", ExDate: marketDate(2026, 1, 1), Amount: 0.1},
+		}},
+	}
+	for _, history := range histories {
+		if _, err := SaveHistory(filepath.Join(dir, "dividends_"+history.Symbol+".json"), history); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count, err := SaveTotalsByYear(dir)
+	if err != nil {
+		t.Fatalf("SaveTotalsByYear: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("totalled %d symbols, want 2", count)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, TotalsByYearFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var totals TotalsByYear
+	if err := json.Unmarshal(data, &totals); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saved and reloaded, each ex-date keeps its New York year and month
+	tsly := totals.Symbols["TSLY"]
+	if tsly.Years[2026] != 0.4 || tsly.Years[2025] != 0.65 {
+		t.Errorf("TSLY years = %v, want 2026: 0.4, 2025: 0.65", tsly.Years)
+	}
+	if tsly.Months["2026-01"] != 0.4 || tsly.Months["2025-12"] != 0.65 || len(tsly.Months) != 2 {
+		t.Errorf("TSLY months = %v, want 2026-01: 0.4, 2025-12: 0.65", tsly.Months)
+	}
+	if ulty := totals.Symbols["ULTY"]; ulty.Years[2026] != 0.1 || len(ulty.Years) != 1 {
+		t.Errorf("ULTY years = %v, want only 2026: 0.1", ulty.Years)
+	}
+}
@@ -0,0 +1,163 @@
+// Package httpx wraps http.Client with retries for the idempotent GETs the
+// API clients make, so a dropped connection or a brief 503 doesn't fail a run.
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"divminder-crawler/internal/proxy"
+)
+
+// RetryPolicy controls how failed GETs are retried. The delay before retry n
+// (from 0) is BaseDelay*2^n capped at MaxDelay, then varied by up to ±Jitter
+// of itself.
+type RetryPolicy struct {
+	MaxRetries int           // Retries after the first attempt; 0 disables retrying
+	BaseDelay  time.Duration // Delay before the first retry
+	MaxDelay   time.Duration // Upper bound on any single delay
+	Jitter     float64       // Fraction of the delay to randomize, 0 to 1
+}
+
+// DefaultRetryPolicy returns the policy the API clients use unless configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   8 * time.Second,
+		Jitter:     0.2,
+	}
+}
+
+// Delay returns the backoff before retry attempt, starting from 0
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// Client makes GET requests, retrying on 5xx responses and transient network
+// errors but never on 4xx
+type Client struct {
+	http           *http.Client
+	policy         RetryPolicy
+	requestTimeout time.Duration // Deadline for one Get including its retries; 0 means none
+}
+
+// New creates a client with the given per-attempt timeout, routed through
+// the configured proxy
+func New(timeout time.Duration, policy RetryPolicy) *Client {
+	return &Client{
+		http: &http.Client{
+			Timeout:   timeout,
+			Transport: proxy.Transport(),
+		},
+		policy: policy,
+	}
+}
+
+// SetRetryPolicy replaces the retry policy
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.policy = policy
+}
+
+// SetRequestTimeout bounds each Get, retries and reading the body included,
+// so one slow endpoint fails fast instead of using up the whole client
+// timeout on every attempt; 0 removes the bound
+func (c *Client) SetRequestTimeout(timeout time.Duration) {
+	c.requestTimeout = timeout
+}
+
+// Get fetches url, retrying per the policy. The last response or error is
+// returned once retries run out or the request timeout passes; the caller
+// closes the body as usual.
+func (c *Client) Get(url string) (*http.Response, error) {
+	return c.GetContext(context.Background(), url)
+}
+
+// GetContext is Get with the attempts and retry waits also ending when ctx is done
+func (c *Client) GetContext(ctx context.Context, url string) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if c.requestTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.get(ctx, url)
+		retry := false
+		if err != nil {
+			retry = IsTransient(err) && ctx.Err() == nil
+		} else {
+			retry = resp.StatusCode >= 500
+		}
+		if !retry || attempt >= c.policy.MaxRetries {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			// The deadline keeps covering the body until the caller closes it
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		select {
+		case <-time.After(c.policy.Delay(attempt)):
+		case <-ctx.Done():
+			cancel()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *Client) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.http.Do(req)
+}
+
+// cancelOnClose releases a request's context once its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// IsTransient reports whether err is a network failure worth retrying, such
+// as a timeout, a reset connection or a response cut short
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
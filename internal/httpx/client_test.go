@@ -0,0 +1,203 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/proxy"
+)
+
+func TestClientRoutesThroughProxy(t *testing.T) {
+	var proxied []string
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Write([]byte(`{}`))
+	}))
+	defer proxyServer.Close()
+
+	if err := proxy.Configure(proxyServer.URL); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { proxy.Set(nil) })
+
+	client := New(5*time.Second, RetryPolicy{})
+	resp, err := client.Get("http://api.invalid/stable/dividends?symbol=ULTY")
+	if err != nil {
+		t.Fatalf("GET through proxy: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(proxied) != 1 || proxied[0] != "http://api.invalid/stable/dividends?symbol=ULTY" {
+		t.Errorf("proxy saw %v, want the API request", proxied)
+	}
+}
+
+// fastRetries retries quickly enough for tests
+var fastRetries = RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+// flappingServer answers status for the first failures requests, then 200 OK
+func flappingServer(t *testing.T, status, failures int) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(atomic.AddInt32(&calls, 1)) <= failures {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		w.Write([]byte(`ok`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		failures   int
+		wantStatus int
+		wantCalls  int32
+	}{
+		{name: "503 then 200", status: http.StatusServiceUnavailable, failures: 2, wantStatus: http.StatusOK, wantCalls: 3},
+		{name: "no retry on 404", status: http.StatusNotFound, failures: 1, wantStatus: http.StatusNotFound, wantCalls: 1},
+		{name: "no retry on 429", status: http.StatusTooManyRequests, failures: 1, wantStatus: http.StatusTooManyRequests, wantCalls: 1},
+		{name: "retries exhausted", status: http.StatusBadGateway, failures: 10, wantStatus: http.StatusBadGateway, wantCalls: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := flappingServer(t, tt.status, tt.failures)
+
+			resp, err := New(5*time.Second, fastRetries).Get(server.URL)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := atomic.LoadInt32(calls); got != tt.wantCalls {
+				t.Errorf("server saw %d requests, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClientRetriesDroppedConnection(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// Close the connection without answering
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.Write([]byte(`ok`))
+	}))
+	defer server.Close()
+
+	resp, err := New(5*time.Second, fastRetries).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "ok" || atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("body %q after %d requests, want ok after 2", body, calls)
+	}
+}
+
+// stallingServer answers fast paths at once and stalls /slow until the
+// client gives up, writing the headers first when stallBody is set
+func stallingServer(t *testing.T, stallBody bool) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			if stallBody {
+				w.WriteHeader(http.StatusOK)
+				w.(http.Flusher).Flush()
+			}
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`ok`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	t.Run("slow response", func(t *testing.T) {
+		server := stallingServer(t, false)
+		client := New(5*time.Second, fastRetries)
+		client.SetRequestTimeout(50 * time.Millisecond)
+
+		start := time.Now()
+		_, err := client.Get(server.URL + "/slow")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Get = %v, want the request deadline to pass", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Get took %s, want it cut off by the 50ms request timeout", elapsed)
+		}
+
+		// The next call in the batch still succeeds
+		resp, err := client.Get(server.URL + "/fast")
+		if err != nil {
+			t.Fatalf("Get after a timeout: %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil || string(body) != "ok" {
+			t.Errorf("body %q (err %v), want ok", body, err)
+		}
+	})
+
+	t.Run("slow body", func(t *testing.T) {
+		server := stallingServer(t, true)
+		client := New(5*time.Second, fastRetries)
+		client.SetRequestTimeout(50 * time.Millisecond)
+
+		start := time.Now()
+		resp, err := client.Get(server.URL + "/slow")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		defer resp.Body.Close()
+		if _, err := io.ReadAll(resp.Body); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("reading the body = %v, want the request deadline to pass", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("reading took %s, want it cut off by the 50ms request timeout", elapsed)
+		}
+	})
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for attempt, w := range want {
+		if got := policy.Delay(attempt); got != w {
+			t.Errorf("Delay(%d) = %v, want %v", attempt, got, w)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.Delay(1); got < 100*time.Millisecond || got > 300*time.Millisecond {
+			t.Fatalf("jittered Delay(1) = %v, want within 200ms ±50%%", got)
+		}
+	}
+}
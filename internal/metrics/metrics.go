@@ -0,0 +1,110 @@
+// Package metrics defines the crawler's Prometheus counters and histograms
+// and serves them in the text exposition format.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Scrape results reported for a source
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Sources reported by the instrumented clients
+const (
+	SourceDividendTable = "dividend_table"
+	SourceAlphaVantage  = "alpha_vantage"
+)
+
+// DefaultDurationBuckets are the histogram upper bounds, in seconds, used for scrape durations
+var DefaultDurationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+var (
+	// ScrapeResults counts scrape attempts by source and result
+	ScrapeResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "divminder_scrape_results_total",
+		Help: "Scrape attempts by source and result.",
+	}, []string{"source", "result"})
+	// CacheLookups counts file cache lookups by result (hit, miss or stale)
+	CacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "divminder_cache_lookups_total",
+		Help: "File cache lookups by result.",
+	}, []string{"result"})
+	// APICalls counts outbound API requests by API
+	APICalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "divminder_api_calls_total",
+		Help: "Outbound API requests by API.",
+	}, []string{"api"})
+	// ScrapeDuration observes how long a single symbol took to scrape
+	ScrapeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "divminder_scrape_duration_seconds",
+		Help:    "Per-symbol scrape duration in seconds.",
+		Buckets: DefaultDurationBuckets,
+	}, []string{"source"})
+	// APIKeyCalls counts outbound API requests by API and key index (never the key itself)
+	APIKeyCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "divminder_api_key_calls_total",
+		Help: "Outbound API requests by API and key index.",
+	}, []string{"api", "key"})
+	// APIKeyRotations counts switches to the next API key after a rate limit
+	APIKeyRotations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "divminder_api_key_rotations_total",
+		Help: "API key rotations after rate limiting.",
+	}, []string{"api"})
+	// APICurrentKey reports the index of the API key currently in use
+	APICurrentKey = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "divminder_api_current_key",
+		Help: "Index of the API key currently in use.",
+	}, []string{"api"})
+)
+
+// registry holds the crawler's own metrics, without the Go runtime
+// collectors of the global default registry
+var registry = prometheus.NewRegistry()
+
+func init() {
+	registry.MustRegister(ScrapeResults, CacheLookups, APICalls, ScrapeDuration, APIKeyCalls, APIKeyRotations, APICurrentKey)
+}
+
+// Register adds a collector to the served metrics, failing if one with the
+// same descriptors is already registered
+func Register(c prometheus.Collector) error {
+	return registry.Register(c)
+}
+
+// ObserveSince records the time elapsed since start, in seconds
+func ObserveSince(o prometheus.Observer, start time.Time) {
+	o.Observe(time.Since(start).Seconds())
+}
+
+// Handler serves the crawler's metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Serve starts an HTTP server exposing /metrics on addr in the background.
+// Listen errors are passed to onError; the returned server can be shut down by the caller.
+func Serve(addr string, onError func(error)) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed && onError != nil {
+			onError(err)
+		}
+	}()
+
+	return server
+}
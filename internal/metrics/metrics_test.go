@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+func TestHandlerExposesMetricsAfterRun(t *testing.T) {
+	// Simulate a run touching each metric
+	ScrapeResults.WithLabelValues(SourceDividendTable, ResultSuccess).Inc()
+	ScrapeResults.WithLabelValues(SourceDividendTable, ResultFailure).Inc()
+	CacheLookups.WithLabelValues("hit").Inc()
+	APICalls.WithLabelValues(SourceAlphaVantage).Inc()
+	APIKeyCalls.WithLabelValues(SourceAlphaVantage, "0").Inc()
+	APIKeyRotations.WithLabelValues(SourceAlphaVantage).Inc()
+	APICurrentKey.WithLabelValues(SourceAlphaVantage).Set(1)
+	ObserveSince(ScrapeDuration.WithLabelValues(SourceDividendTable), time.Now().Add(-300*time.Millisecond))
+
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The body must be valid text exposition format
+	families, err := new(expfmt.TextParser).TextToMetricFamilies(strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatalf("invalid exposition format: %v\n%s", err, body)
+	}
+
+	for _, name := range []string{
+		"divminder_scrape_results_total",
+		"divminder_cache_lookups_total",
+		"divminder_api_calls_total",
+		"divminder_scrape_duration_seconds",
+		"divminder_api_key_calls_total",
+		"divminder_api_key_rotations_total",
+		"divminder_api_current_key",
+	} {
+		if _, ok := families[name]; !ok {
+			t.Errorf("metric %s missing from /metrics", name)
+		}
+	}
+
+	duration := families["divminder_scrape_duration_seconds"]
+	if duration == nil || len(duration.GetMetric()) == 0 || duration.GetMetric()[0].GetHistogram().GetSampleCount() == 0 {
+		t.Errorf("scrape duration histogram has no observations")
+	}
+}
+
+func TestRegisterRejectsDuplicates(t *testing.T) {
+	duplicate := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "divminder_scrape_results_total",
+		Help: "Scrape attempts by source and result.",
+	}, []string{"source", "result"})
+	if err := Register(duplicate); err == nil {
+		t.Error("registering a duplicate metric succeeded")
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"sort"
+	"time"
+	_ "time/tzdata" // Embed the zone database so America/New_York loads on minimal images
+)
+
+// MarketLocation is US/Eastern, the zone YieldMax publishes its schedule in.
+// Ex, pay, declare and record dates are calendar dates in this location.
+var MarketLocation = loadMarketLocation()
+
+func loadMarketLocation() *time.Location {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return time.FixedZone("EST", -5*60*60)
+	}
+	return loc
+}
+
+// ParseMarketDate parses a date-only value as midnight of that calendar date
+// in MarketLocation, so formatting it never shifts the day
+func ParseMarketDate(layout, value string) (time.Time, error) {
+	return time.ParseInLocation(layout, value, MarketLocation)
+}
+
+// MarketDate returns midnight in MarketLocation of the calendar date t falls on there
+func MarketDate(t time.Time) time.Time {
+	t = t.In(MarketLocation)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, MarketLocation)
+}
+
+// CalendarDate returns midnight in MarketLocation of the calendar date t is
+// written as in its own zone. Use it for stored date-only values, which older
+// files hold at UTC midnight; MarketDate would move those to the prior day.
+func CalendarDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, MarketLocation)
+}
+
+// ExDatesOn returns the events whose ex-date is the market calendar date of
+// day, sorted by symbol. Ex-dates are compared as written, so events saved at
+// UTC midnight match the same date in MarketLocation.
+func ExDatesOn(events []DividendEvent, day time.Time) []DividendEvent {
+	date := MarketDate(day).Format("2006-01-02")
+	var matched []DividendEvent
+	for _, event := range events {
+		if exDateKey(event) == date {
+			matched = append(matched, event)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].Symbol < matched[j].Symbol })
+	return matched
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMarketDateKeepsTheCalendarDay(t *testing.T) {
+	parsed, err := ParseMarketDate("2006-01-02", "2025-06-05")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Midnight Eastern is still the 5th in UTC and in Seoul, where the
+	// crawler's schedule runs
+	for _, loc := range []*time.Location{MarketLocation, time.UTC, time.FixedZone("KST", 9*60*60)} {
+		if got := parsed.In(loc).Format("2006-01-02"); got != "2025-06-05" {
+			t.Errorf("in %s formatted as %s, want 2025-06-05", loc, got)
+		}
+	}
+	if got := MarketDate(parsed).Format("2006-01-02"); got != "2025-06-05" {
+		t.Errorf("MarketDate = %s, want 2025-06-05", got)
+	}
+
+	// A UTC parse of the same cell is the evening of the 4th in Eastern
+	utc, _ := time.Parse("2006-01-02", "2025-06-05")
+	if got := utc.In(MarketLocation).Format("2006-01-02"); got != "2025-06-04" {
+		t.Fatalf("UTC parse in Eastern = %s; the case this guards against no longer flips", got)
+	}
+}
+
+func TestMarketDateNearMidnight(t *testing.T) {
+	tests := []struct {
+		name string
+		at   time.Time
+		want string
+	}{
+		{"late evening eastern", time.Date(2025, 6, 4, 23, 30, 0, 0, MarketLocation), "2025-06-04"},
+		{"same instant in UTC", time.Date(2025, 6, 5, 3, 30, 0, 0, time.UTC), "2025-06-04"},
+		{"00:05 KST run", time.Date(2025, 6, 5, 0, 5, 0, 0, time.FixedZone("KST", 9*60*60)), "2025-06-04"},
+		{"just after eastern midnight", time.Date(2025, 6, 5, 4, 1, 0, 0, time.UTC), "2025-06-05"},
+	}
+	for _, tt := range tests {
+		if got := MarketDate(tt.at).Format("2006-01-02"); got != tt.want {
+			t.Errorf("%s: MarketDate(%s) = %s, want %s", tt.name, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestExDatesOnMatchesUTCMidnightEvents(t *testing.T) {
+	events := []DividendEvent{
+		{Symbol: "ULTY", ExDate: time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC)},
+		{Symbol: "CONY", ExDate: time.Date(2025, 6, 5, 0, 0, 0, 0, MarketLocation)},
+		{Symbol: "TSLY", ExDate: time.Date(2025, 6, 4, 0, 0, 0, 0, MarketLocation)},
+	}
+	// 00:05 KST on the 6th is still the 5th in Eastern
+	got := ExDatesOn(events, time.Date(2025, 6, 6, 0, 5, 0, 0, time.FixedZone("KST", 9*60*60)))
+	if len(got) != 2 || got[0].Symbol != "CONY" || got[1].Symbol != "ULTY" {
+		t.Errorf("ExDatesOn(2025-06-05) = %v, want CONY and ULTY", got)
+	}
+}
+
+func TestCalendarDateKeepsStoredUTCDates(t *testing.T) {
+	stored := time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC)
+	if got := CalendarDate(stored); !got.Equal(time.Date(2025, 6, 5, 0, 0, 0, 0, MarketLocation)) {
+		t.Errorf("CalendarDate(%s) = %s, want midnight Eastern on the 5th", stored, got)
+	}
+	if got := MarketDate(stored).Format("2006-01-02"); got != "2025-06-04" {
+		t.Errorf("MarketDate(%s) = %s, want the instant's Eastern date 2025-06-04", stored, got)
+	}
+}
+
+func TestExDatesOn(t *testing.T) {
+	event := func(symbol string, day int) DividendEvent {
+		return DividendEvent{Symbol: symbol, ExDate: time.Date(2025, 6, day, 0, 0, 0, 0, MarketLocation), Amount: 0.4}
+	}
+	events := []DividendEvent{
+		event("ULTY", 11), event("TSLY", 10), event("CRSH", 10), event("NVDY", 9),
+		// Saved at UTC midnight, the same calendar date
+		{Symbol: "YMAX", ExDate: time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name string
+		day  time.Time
+		want []string
+	}{
+		{"today", time.Date(2025, 6, 10, 9, 30, 0, 0, MarketLocation), []string{"CRSH", "TSLY", "YMAX"}},
+		{"tomorrow", time.Date(2025, 6, 11, 9, 30, 0, 0, MarketLocation), []string{"ULTY"}},
+		// 01:00 UTC on the 11th is still the evening of the 10th in New York
+		{"late evening in UTC", time.Date(2025, 6, 11, 1, 0, 0, 0, time.UTC), []string{"CRSH", "TSLY", "YMAX"}},
+		{"no events", time.Date(2025, 6, 12, 12, 0, 0, 0, MarketLocation), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExDatesOn(events, tt.day)
+			symbols := make([]string, len(got))
+			for i, e := range got {
+				symbols[i] = e.Symbol
+			}
+			if len(symbols) != len(tt.want) {
+				t.Fatalf("ExDatesOn(%v) = %v, want %v", tt.day, symbols, tt.want)
+			}
+			for i := range tt.want {
+				if symbols[i] != tt.want[i] {
+					t.Fatalf("ExDatesOn(%v) = %v, want %v", tt.day, symbols, tt.want)
+				}
+			}
+		})
+	}
+}
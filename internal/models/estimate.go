@@ -0,0 +1,125 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// groupExWeekdays is the weekday each rotation group goes ex-dividend on
+var groupExWeekdays = map[string]time.Weekday{
+	"GroupA": time.Wednesday,
+	"GroupB": time.Wednesday,
+	"GroupC": time.Wednesday,
+	"GroupD": time.Wednesday,
+	"Weekly": time.Thursday,
+}
+
+// EstimateNextExDate projects the history's next ex-date after today
+func EstimateNextExDate(history *DividendHistory) time.Time {
+	return EstimateNextExDateAt(history, time.Now())
+}
+
+// EstimateNextExDateAt projects the next ex-date after now's market date by
+// stepping the median gap between past ex-dates forward from the latest one
+// and snapping to the group's ex-dividend weekday. Monthly cadences step by
+// calendar month so month lengths don't drift the estimate. It returns the
+// latest ex-date if that's still upcoming, and the zero time without events.
+func EstimateNextExDateAt(history *DividendHistory, now time.Time) time.Time {
+	today := MarketDate(now)
+
+	var dates []time.Time
+	for _, event := range history.Events {
+		if !event.ExDate.IsZero() {
+			dates = append(dates, CalendarDate(event.ExDate))
+		}
+	}
+	if len(dates) == 0 {
+		return time.Time{}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	latest := dates[len(dates)-1]
+	if latest.After(today) {
+		return latest
+	}
+
+	gapDays := medianGapDays(dates)
+	if gapDays == 0 {
+		gapDays = 7
+		if history.Frequency == "monthly" {
+			gapDays = 30
+		}
+	}
+	monthly := gapDays >= 25
+
+	next := latest
+	for !next.After(today) {
+		var stepped time.Time
+		if monthly {
+			stepped = next.AddDate(0, 1, 0)
+		} else {
+			stepped = next.AddDate(0, 0, gapDays)
+		}
+		// Snapping back must not undo the step for very short gaps
+		if snapped := snapToWeekday(stepped, history.Group); snapped.After(next) {
+			stepped = snapped
+		}
+		next = stepped
+	}
+
+	return next
+}
+
+// EstimatePayDate returns exDate shifted by the latest event's ex-to-pay gap,
+// defaulting to the next day
+func EstimatePayDate(history *DividendHistory, exDate time.Time) time.Time {
+	if exDate.IsZero() {
+		return time.Time{}
+	}
+
+	var latest *DividendEvent
+	for i := range history.Events {
+		event := &history.Events[i]
+		if !event.ExDate.IsZero() && (latest == nil || event.ExDate.After(latest.ExDate)) {
+			latest = event
+		}
+	}
+	if latest == nil || latest.PayDate.IsZero() || !latest.PayDate.After(latest.ExDate) {
+		return exDate.AddDate(0, 0, 1)
+	}
+
+	days := int(CalendarDate(latest.PayDate).Sub(CalendarDate(latest.ExDate)).Hours()/24 + 0.5)
+	return exDate.AddDate(0, 0, days)
+}
+
+// medianGapDays returns the median number of days between consecutive distinct dates
+func medianGapDays(dates []time.Time) int {
+	var gaps []float64
+	for i := 1; i < len(dates); i++ {
+		days := dates[i].Sub(dates[i-1]).Hours() / 24
+		if days >= 1 {
+			gaps = append(gaps, days)
+		}
+	}
+	if len(gaps) == 0 {
+		return 0
+	}
+	return int(medianOf(gaps) + 0.5)
+}
+
+// snapToWeekday moves date to the nearest occurrence of the group's ex-dividend
+// weekday, leaving it alone for groups without a fixed weekday
+func snapToWeekday(date time.Time, group string) time.Time {
+	weekday, ok := groupExWeekdays[group]
+	if !ok {
+		return date
+	}
+
+	offset := int(weekday - date.Weekday())
+	if offset > 3 {
+		offset -= 7
+	} else if offset < -3 {
+		offset += 7
+	}
+	return date.AddDate(0, 0, offset)
+}
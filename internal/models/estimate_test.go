@@ -0,0 +1,97 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// cadence returns count events stepping step days from first, newest first
+func cadence(first time.Time, step, count int) []DividendEvent {
+	events := make([]DividendEvent, count)
+	for i := range events {
+		exDate := first.AddDate(0, 0, step*(count-1-i))
+		events[i] = DividendEvent{ExDate: exDate, PayDate: exDate.AddDate(0, 0, 1), Amount: 0.5}
+	}
+	return events
+}
+
+func TestEstimateNextExDateFollowsRegularCadence(t *testing.T) {
+	weeklyStart := time.Date(2025, 1, 2, 0, 0, 0, 0, MarketLocation)   // Thursday
+	rotationStart := time.Date(2025, 1, 8, 0, 0, 0, 0, MarketLocation) // Wednesday
+
+	tests := []struct {
+		name    string
+		history DividendHistory
+	}{
+		{"weekly thursdays", DividendHistory{Group: "Weekly", Events: cadence(weeklyStart, 7, 20)}},
+		{"four-week rotation", DividendHistory{Group: "GroupB", Events: cadence(rotationStart, 28, 6)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step := int(tt.history.Events[0].ExDate.Sub(tt.history.Events[1].ExDate).Hours() / 24)
+			latest := tt.history.Events[0].ExDate
+
+			// Each day up to and including the next ex-date's eve projects
+			// that date, across month boundaries
+			for want := latest.AddDate(0, 0, step); want.Before(latest.AddDate(0, 0, 4*step)); want = want.AddDate(0, 0, step) {
+				for day := want.AddDate(0, 0, -step); day.Before(want); day = day.AddDate(0, 0, 1) {
+					now := day.Add(12 * time.Hour)
+					if got := EstimateNextExDateAt(&tt.history, now); !got.Equal(want) {
+						t.Fatalf("at %s estimated %s, want %s", now.Format("2006-01-02"), got.Format("2006-01-02 Mon"), want.Format("2006-01-02 Mon"))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEstimateNextExDateMonthlyKeepsDayOfMonth(t *testing.T) {
+	var events []DividendEvent
+	for month := time.June; month >= time.January; month-- {
+		events = append(events, DividendEvent{ExDate: time.Date(2025, month, 5, 0, 0, 0, 0, MarketLocation)})
+	}
+	history := DividendHistory{Group: "Target12", Frequency: "monthly", Events: events}
+
+	got := EstimateNextExDateAt(&history, time.Date(2025, 8, 20, 12, 0, 0, 0, MarketLocation))
+	if want := time.Date(2025, 9, 5, 0, 0, 0, 0, MarketLocation); !got.Equal(want) {
+		t.Errorf("estimated %s, want %s", got.Format("2006-01-02"), want.Format("2006-01-02"))
+	}
+}
+
+func TestEstimateNextExDateEdgeCases(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, MarketLocation)
+
+	if got := EstimateNextExDateAt(&DividendHistory{}, now); !got.IsZero() {
+		t.Errorf("without events estimated %s, want the zero time", got)
+	}
+
+	upcoming := time.Date(2025, 6, 12, 0, 0, 0, 0, MarketLocation)
+	history := DividendHistory{Group: "Weekly", Events: []DividendEvent{{ExDate: upcoming}, {ExDate: upcoming.AddDate(0, 0, -7)}}}
+	if got := EstimateNextExDateAt(&history, now); !got.Equal(upcoming) {
+		t.Errorf("with a declared upcoming ex-date estimated %s, want it", got)
+	}
+
+	// A single past event falls back to the frequency's nominal gap
+	single := DividendHistory{Group: "Weekly", Events: []DividendEvent{{ExDate: time.Date(2025, 6, 5, 0, 0, 0, 0, MarketLocation)}}}
+	if got := EstimateNextExDateAt(&single, now); !got.Equal(upcoming) {
+		t.Errorf("single weekly event estimated %s, want %s", got.Format("2006-01-02"), upcoming.Format("2006-01-02"))
+	}
+}
+
+func TestEstimatePayDate(t *testing.T) {
+	exDate := time.Date(2025, 6, 12, 0, 0, 0, 0, MarketLocation)
+	history := DividendHistory{Events: []DividendEvent{
+		{ExDate: time.Date(2025, 6, 5, 0, 0, 0, 0, MarketLocation), PayDate: time.Date(2025, 6, 9, 0, 0, 0, 0, MarketLocation)},
+		{ExDate: time.Date(2025, 5, 29, 0, 0, 0, 0, MarketLocation), PayDate: time.Date(2025, 5, 30, 0, 0, 0, 0, MarketLocation)},
+	}}
+	if got, want := EstimatePayDate(&history, exDate), exDate.AddDate(0, 0, 4); !got.Equal(want) {
+		t.Errorf("EstimatePayDate = %s, want the latest event's 4-day gap %s", got, want)
+	}
+	if got := EstimatePayDate(&DividendHistory{}, exDate); !got.Equal(exDate.AddDate(0, 0, 1)) {
+		t.Errorf("without events EstimatePayDate = %s, want the next day", got)
+	}
+	if got := EstimatePayDate(&history, time.Time{}); !got.IsZero() {
+		t.Errorf("EstimatePayDate(zero) = %s, want zero", got)
+	}
+}
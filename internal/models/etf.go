@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -13,6 +14,23 @@ type ETF struct {
 	Description string `json:"description"` // ETF description
 	NextExDate  string `json:"nextExDate"`  // Next ex-dividend date (YYYY-MM-DD)
 	NextPayDate string `json:"nextPayDate"` // Next payment date (YYYY-MM-DD)
+	Active      bool   `json:"active"`      // False once the fund page keeps returning 404
+
+	InceptionDate *time.Time `json:"inceptionDate,omitempty"` // Fund launch date from its page, nil when not stated
+
+	AnnualizedDistribution float64         `json:"annualizedDistribution,omitempty"` // Newest amount times payments per year
+	Target                 *TargetTracking `json:"target,omitempty"`                 // Target12 funds only, copied from the history
+}
+
+// UnmarshalJSON decodes an ETF, treating a missing "active" field as true
+func (e *ETF) UnmarshalJSON(data []byte) error {
+	type plainETF ETF
+	decoded := plainETF{Active: true}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*e = ETF(decoded)
+	return nil
 }
 
 // ETFMetadata represents comprehensive ETF information from external APIs
@@ -60,45 +78,56 @@ type ETFMetadata struct {
 
 // DividendEvent represents a dividend payment event
 type DividendEvent struct {
-	Symbol      string    `json:"symbol"`          // ETF ticker symbol
-	ExDate      time.Time `json:"exDate"`          // Ex-dividend date
-	PayDate     time.Time `json:"payDate"`         // Payment date
-	DeclareDate time.Time `json:"declareDate"`     // Declaration date
-	Amount      float64   `json:"amount"`          // Dividend amount per share
-	Group       string    `json:"group"`           // ETF group (A, B, C, D, Weekly, Target12)
-	Frequency   string    `json:"frequency"`       // Payment frequency (weekly, monthly)
-	Yield       float64   `json:"yield,omitempty"` // Dividend yield percentage
+	Symbol       string    `json:"symbol"`           // ETF ticker symbol
+	ExDate       time.Time `json:"exDate"`           // Ex-dividend date
+	PayDate      time.Time `json:"payDate"`          // Payment date
+	DeclareDate  time.Time `json:"declareDate"`      // Declaration date
+	RecordDate   time.Time `json:"recordDate"`       // Record date (same as ex-date under T+1 settlement)
+	Amount       float64   `json:"amount"`           // Dividend amount per share
+	AmountMicros int64     `json:"amountMicros"`     // Amount in micro-dollars, derived from Amount when saved
+	Group        string    `json:"group"`            // ETF group (A, B, C, D, Weekly, Target12)
+	Frequency    string    `json:"frequency"`        // Payment frequency (weekly, monthly)
+	Yield        float64   `json:"yield,omitempty"`  // Dividend yield percentage
+	Estimated    bool      `json:"estimated"`        // Amount is a placeholder, not an announced or paid distribution
+	Source       string    `json:"source,omitempty"` // Component that created the event, one of the Source constants
 }
 
 // DividendHistory represents historical dividend data for an ETF
 type DividendHistory struct {
-	Symbol    string          `json:"symbol"`
-	Name      string          `json:"name"`
-	Group     string          `json:"group"`
-	Frequency string          `json:"frequency"`
-	Events    []DividendEvent `json:"events"`
-	Stats     DividendStats   `json:"stats"`
-	UpdatedAt time.Time       `json:"updatedAt"`
+	Symbol             string            `json:"symbol"`
+	Name               string            `json:"name"`
+	Group              string            `json:"group"`
+	Frequency          string            `json:"frequency"`                    // Current payment frequency
+	FrequencyChangedAt *time.Time        `json:"frequencyChangedAt,omitempty"` // First ex-date of the current frequency, if it changed
+	FrequencyRegimes   []FrequencyRegime `json:"frequencyRegimes,omitempty"`   // Oldest first
+	Events             []DividendEvent   `json:"events"`
+	Stats              DividendStats     `json:"stats"`
+	Target             *TargetTracking   `json:"target,omitempty"` // Target12 funds only
+	UpdatedAt          time.Time         `json:"updatedAt"`
 }
 
 // DividendStats contains calculated statistics for dividend history
 type DividendStats struct {
-	TotalPayments     int     `json:"totalPayments"`
-	AverageAmount     float64 `json:"averageAmount"`
-	LastAmount        float64 `json:"lastAmount"`
-	YearToDateTotal   float64 `json:"yearToDateTotal"`
-	TrailingYearTotal float64 `json:"trailingYearTotal"`
-	ChangePercent     float64 `json:"changePercent"`
+	TotalPayments     int      `json:"totalPayments"`
+	AverageAmount     float64  `json:"averageAmount"`
+	MedianAmount      float64  `json:"medianAmount"`
+	LastAmount        float64  `json:"lastAmount"`
+	YearToDateTotal   float64  `json:"yearToDateTotal"`
+	TrailingYearTotal float64  `json:"trailingYearTotal"`
+	ChangePercent     float64  `json:"changePercent"`
+	DistributionCAGR  *float64 `json:"distributionCAGR,omitempty"` // GrowthRate over GrowthWindow in percent; nil without two windows of history
 }
 
 // GroupSchedule represents the dividend schedule for a specific ETF group
 type GroupSchedule struct {
-	Group       string          `json:"group"`       // Group name (A, B, C, D, Weekly, Target12)
-	Frequency   string          `json:"frequency"`   // Payment frequency (weekly, monthly)
-	ETFs        []string        `json:"etfs"`        // List of ETF symbols in this group
-	NextExDate  string          `json:"nextExDate"`  // Next ex-dividend date (YYYY-MM-DD)
-	NextPayDate string          `json:"nextPayDate"` // Next payment date (YYYY-MM-DD)
-	Events      []DividendEvent `json:"events"`      // Upcoming dividend events
+	Group           string          `json:"group"`           // Group name (A, B, C, D, Weekly, Target12)
+	Frequency       string          `json:"frequency"`       // Payment frequency (weekly, monthly)
+	ETFs            []string        `json:"etfs"`            // List of ETF symbols in this group
+	NextExDate      string          `json:"nextExDate"`      // Next ex-dividend date (YYYY-MM-DD)
+	NextPayDate     string          `json:"nextPayDate"`     // Next payment date (YYYY-MM-DD)
+	NextDeclareDate string          `json:"nextDeclareDate"` // Declaration date of the next event (YYYY-MM-DD)
+	NextRecordDate  string          `json:"nextRecordDate"`  // Record date of the next event (YYYY-MM-DD)
+	Events          []DividendEvent `json:"events"`          // Upcoming dividend events
 }
 
 // Schedule represents the overall dividend schedule
@@ -110,14 +139,19 @@ type Schedule struct {
 
 // ETFDetail represents detailed information scraped from individual ETF pages
 type ETFDetail struct {
-	Symbol          string          `json:"symbol"`
-	Name            string          `json:"name"`
-	Description     string          `json:"description"`
-	CurrentPrice    float64         `json:"currentPrice"`
-	CurrentYield    float64         `json:"currentYield"`
-	Frequency       string          `json:"frequency"`
-	DividendHistory []DividendEvent `json:"dividendHistory"`
-	LastUpdated     time.Time       `json:"lastUpdated"`
+	Symbol             string          `json:"symbol"`
+	Name               string          `json:"name"`
+	Description        string          `json:"description"`
+	CurrentPrice       float64         `json:"currentPrice"`
+	CurrentYield       float64         `json:"currentYield"`       // Alias of DistributionRate, kept for existing consumers
+	DistributionRate   float64         `json:"distributionRate"`   // Annualized latest distribution as a percent of price
+	SECYield           float64         `json:"secYield"`           // 30-Day SEC Yield percent
+	NAV                float64         `json:"nav"`                // Net asset value per share
+	PremiumDiscountPct float64         `json:"premiumDiscountPct"` // (price-nav)/nav*100, zero when unavailable
+	Frequency          string          `json:"frequency"`
+	InceptionDate      *time.Time      `json:"inceptionDate,omitempty"` // Nil when the page doesn't state it
+	DividendHistory    []DividendEvent `json:"dividendHistory"`
+	LastUpdated        time.Time       `json:"lastUpdated"`
 }
 
 // APIResponse represents a generic API response wrapper
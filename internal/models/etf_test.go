@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestETFInceptionDateJSON(t *testing.T) {
+	// An unknown inception date is left out rather than written as year 1
+	data, err := json.Marshal(ETF{Symbol: "ULTY", Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "inceptionDate") {
+		t.Errorf("ETF JSON %s includes inceptionDate", data)
+	}
+
+	inception := time.Date(2024, 2, 28, 0, 0, 0, 0, MarketLocation)
+	data, err = json.Marshal(ETF{Symbol: "ULTY", InceptionDate: &inception})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ETF
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.InceptionDate == nil || !decoded.InceptionDate.Equal(inception) {
+		t.Errorf("round-tripped InceptionDate = %v, want %v", decoded.InceptionDate, inception)
+	}
+}
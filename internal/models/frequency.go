@@ -0,0 +1,96 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// weeklyMaxGapDays is the longest spacing still counted as weekly, allowing
+// for one skipped week
+const weeklyMaxGapDays = 17
+
+// minRegimeGaps is how many consecutive gaps a new spacing must hold for
+// before it counts as a frequency change rather than a one-off irregularity
+const minRegimeGaps = 2
+
+// FrequencyRegime is a stretch of history paid at one frequency
+type FrequencyRegime struct {
+	Frequency string    `json:"frequency"`
+	Start     time.Time `json:"start"` // First ex-date paid at this frequency
+	End       time.Time `json:"end"`   // Last ex-date paid at this frequency
+	Events    int       `json:"events"`
+}
+
+// DetectFrequencyRegimes segments the events into weekly and monthly regimes by
+// their spacing, then sets Frequency to the current regime and
+// FrequencyChangedAt to when it began. Histories with fewer than three dated
+// events keep their existing Frequency.
+func (h *DividendHistory) DetectFrequencyRegimes() []FrequencyRegime {
+	var dates []time.Time
+	for _, event := range h.Events {
+		if !event.ExDate.IsZero() {
+			dates = append(dates, CalendarDate(event.ExDate))
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	h.FrequencyRegimes = nil
+	h.FrequencyChangedAt = nil
+	if len(dates) < 3 {
+		return nil
+	}
+
+	// Label each event by the spacing since the one before; the first event
+	// takes the label of the gap after it
+	labels := make([]string, len(dates))
+	for i := 1; i < len(dates); i++ {
+		labels[i] = classifyGap(dates[i].Sub(dates[i-1]))
+	}
+	labels[0] = labels[1]
+	smoothLabels(labels)
+
+	var regimes []FrequencyRegime
+	for i, date := range dates {
+		if len(regimes) == 0 || regimes[len(regimes)-1].Frequency != labels[i] {
+			regimes = append(regimes, FrequencyRegime{Frequency: labels[i], Start: date})
+		}
+		current := &regimes[len(regimes)-1]
+		current.End = date
+		current.Events++
+	}
+
+	current := regimes[len(regimes)-1]
+	h.Frequency = current.Frequency
+	if len(regimes) > 1 {
+		changedAt := current.Start
+		h.FrequencyChangedAt = &changedAt
+	}
+	h.FrequencyRegimes = regimes
+
+	return regimes
+}
+
+// classifyGap labels the spacing between two ex-dates
+func classifyGap(gap time.Duration) string {
+	if gap.Hours()/24 <= weeklyMaxGapDays {
+		return "weekly"
+	}
+	return "monthly"
+}
+
+// smoothLabels relabels runs shorter than minRegimeGaps to match the run
+// before them, so a single delayed or skipped payment doesn't split a regime
+func smoothLabels(labels []string) {
+	for start := 1; start < len(labels); {
+		end := start
+		for end < len(labels) && labels[end] == labels[start] {
+			end++
+		}
+		if end-start < minRegimeGaps && labels[start] != labels[start-1] {
+			for i := start; i < end; i++ {
+				labels[i] = labels[start-1]
+			}
+		}
+		start = end
+	}
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// monthlyThenWeekly returns months monthly events on the 5th from January
+// 2024, then weeks weekly Thursdays from 2025-01-02, newest first
+func monthlyThenWeekly(months, weeks int) []DividendEvent {
+	var events []DividendEvent
+	for i := 0; i < months; i++ {
+		events = append(events, DividendEvent{ExDate: time.Date(2024, time.January+time.Month(i), 5, 0, 0, 0, 0, MarketLocation), Amount: 1})
+	}
+	firstWeek := time.Date(2025, 1, 2, 0, 0, 0, 0, MarketLocation)
+	for i := 0; i < weeks; i++ {
+		events = append(events, DividendEvent{ExDate: firstWeek.AddDate(0, 0, 7*i), Amount: 0.25})
+	}
+	SortEventsDescending(events)
+	return events
+}
+
+func TestDetectFrequencyRegimesMonthlyToWeekly(t *testing.T) {
+	history := DividendHistory{Frequency: "monthly", Events: monthlyThenWeekly(12, 20)}
+
+	regimes := history.DetectFrequencyRegimes()
+	if len(regimes) != 2 {
+		t.Fatalf("found %d regimes %+v, want monthly then weekly", len(regimes), regimes)
+	}
+
+	// 2025-01-02 still follows a monthly gap; the first weekly spacing ends on 01-09
+	changedAt := time.Date(2025, 1, 9, 0, 0, 0, 0, MarketLocation)
+	monthly, weekly := regimes[0], regimes[1]
+	if monthly.Frequency != "monthly" || monthly.Events != 13 || !monthly.End.Equal(time.Date(2025, 1, 2, 0, 0, 0, 0, MarketLocation)) {
+		t.Errorf("first regime = %+v, want 13 monthly events ending 2025-01-02", monthly)
+	}
+	if weekly.Frequency != "weekly" || weekly.Events != 19 || !weekly.Start.Equal(changedAt) {
+		t.Errorf("second regime = %+v, want 19 weekly events from 2025-01-09", weekly)
+	}
+
+	if history.Frequency != "weekly" {
+		t.Errorf("Frequency = %q, want the current regime weekly", history.Frequency)
+	}
+	if history.FrequencyChangedAt == nil || !history.FrequencyChangedAt.Equal(changedAt) {
+		t.Errorf("FrequencyChangedAt = %v, want %s", history.FrequencyChangedAt, changedAt.Format("2006-01-02"))
+	}
+	if len(history.FrequencyRegimes) != 2 {
+		t.Errorf("stored %d regimes, want 2", len(history.FrequencyRegimes))
+	}
+}
+
+func TestDetectFrequencyRegimesIgnoresOneOffGaps(t *testing.T) {
+	// Two separate skipped weeks each leave a single 14-day gap
+	events := weeklyEvents(testLast, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1)
+	events = append(events[:3], events[4:]...)
+	for i := 6; i < len(events); i++ {
+		events[i].ExDate = events[i].ExDate.AddDate(0, 0, -7)
+	}
+	history := DividendHistory{Events: events}
+
+	regimes := history.DetectFrequencyRegimes()
+	if len(regimes) != 1 || regimes[0].Frequency != "weekly" || regimes[0].Events != len(events) {
+		t.Errorf("regimes = %+v, want a single weekly regime", regimes)
+	}
+	if history.FrequencyChangedAt != nil {
+		t.Errorf("FrequencyChangedAt = %v, want nil without a change", history.FrequencyChangedAt)
+	}
+}
+
+func TestDetectFrequencyRegimesNeedsThreeEvents(t *testing.T) {
+	changed := testLast
+	history := DividendHistory{
+		Frequency:          "monthly",
+		FrequencyChangedAt: &changed,
+		Events:             weeklyEvents(testLast, 0.1, 0.1),
+	}
+	if regimes := history.DetectFrequencyRegimes(); regimes != nil {
+		t.Errorf("regimes = %+v, want none for two events", regimes)
+	}
+	if history.Frequency != "monthly" || history.FrequencyChangedAt != nil {
+		t.Errorf("Frequency = %q, changed %v; want the existing monthly and a cleared change date", history.Frequency, history.FrequencyChangedAt)
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// daysPerMonth is the average month length used to count missed monthly payments
+const daysPerMonth = 30.44
+
+// DateRange is an inclusive span of dates
+type DateRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// DetectGaps returns the spans between the first and last event where
+// payments expected at the history's frequency are missing, which usually
+// means a scrape dropped rows. Each span runs from the first missed expected
+// ex-date to the last. The frequency between two events comes from the
+// FrequencyRegimes covering them, falling back to Frequency; spans where the
+// frequency changes, or at frequencies other than weekly or monthly, are not
+// checked.
+func DetectGaps(history *DividendHistory) []DateRange {
+	var dates []time.Time
+	for _, event := range history.Events {
+		if !event.ExDate.IsZero() {
+			dates = append(dates, event.ExDate)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	var gaps []DateRange
+	for i := 1; i < len(dates); i++ {
+		prev, next := dates[i-1], dates[i]
+		days := next.Sub(prev).Hours() / 24
+
+		frequency := frequencyAt(history, next)
+		if frequencyAt(history, prev) != frequency {
+			continue
+		}
+
+		switch frequency {
+		case "weekly":
+			if missing := int(math.Round(days/7)) - 1; missing > 0 {
+				gaps = append(gaps, DateRange{Start: prev.AddDate(0, 0, 7), End: prev.AddDate(0, 0, 7*missing)})
+			}
+		case "monthly":
+			if missing := int(math.Round(days/daysPerMonth)) - 1; missing > 0 {
+				gaps = append(gaps, DateRange{Start: prev.AddDate(0, 1, 0), End: prev.AddDate(0, missing, 0)})
+			}
+		}
+	}
+	return gaps
+}
+
+// frequencyAt returns the frequency the history was paying at on date
+func frequencyAt(history *DividendHistory, date time.Time) string {
+	day := MarketDate(date)
+	for _, regime := range history.FrequencyRegimes {
+		if !day.Before(regime.Start) && !day.After(regime.End) {
+			return regime.Frequency
+		}
+	}
+	return history.Frequency
+}
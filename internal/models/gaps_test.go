@@ -0,0 +1,72 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectGaps(t *testing.T) {
+	// Weekly from testLast back, with the 3rd and 4th most recent weeks missing
+	weekly := weeklyEvents(testLast, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1)
+	weekly = append(weekly[:2], weekly[4:]...)
+
+	monthlyLast := time.Date(2025, 6, 5, 0, 0, 0, 0, MarketLocation)
+	monthly := []DividendEvent{
+		{ExDate: monthlyLast},
+		{ExDate: monthlyLast.AddDate(0, -1, 0)},
+		{ExDate: monthlyLast.AddDate(0, -3, 0)},
+	}
+
+	// Monthly until April, then weekly from May 22; the switch itself isn't a gap
+	regimeSwitch := append(weeklyEvents(testLast, 0.1, 0.1, 0.1), DividendEvent{ExDate: testLast.AddDate(0, -2, 0)}, DividendEvent{ExDate: testLast.AddDate(0, -3, 0)})
+
+	tests := []struct {
+		name    string
+		history DividendHistory
+		want    []DateRange
+	}{
+		{
+			name:    "weekly missing two consecutive weeks",
+			history: DividendHistory{Frequency: "weekly", Events: weekly},
+			want:    []DateRange{{Start: testLast.AddDate(0, 0, -21), End: testLast.AddDate(0, 0, -14)}},
+		},
+		{
+			name:    "complete weekly series",
+			history: DividendHistory{Frequency: "weekly", Events: weeklyEvents(testLast, 0.1, 0.1, 0.1, 0.1)},
+		},
+		{
+			name:    "monthly missing one month",
+			history: DividendHistory{Frequency: "monthly", Events: monthly},
+			want:    []DateRange{{Start: monthlyLast.AddDate(0, -2, 0), End: monthlyLast.AddDate(0, -2, 0)}},
+		},
+		{
+			name: "frequency regimes",
+			history: DividendHistory{
+				Frequency: "weekly",
+				Events:    regimeSwitch,
+				FrequencyRegimes: []FrequencyRegime{
+					{Frequency: "monthly", Start: testLast.AddDate(0, -3, 0), End: testLast.AddDate(0, -2, 0)},
+					{Frequency: "weekly", Start: testLast.AddDate(0, 0, -14), End: testLast},
+				},
+			},
+		},
+		{
+			name:    "quarterly isn't checked",
+			history: DividendHistory{Frequency: "quarterly", Events: monthly},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectGaps(&tt.history)
+			if len(got) != len(tt.want) {
+				t.Fatalf("DetectGaps = %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) {
+					t.Errorf("gap %d = %v to %v, want %v to %v", i, got[i].Start, got[i].End, tt.want[i].Start, tt.want[i].End)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// GrowthWindow is the window DistributionCAGR averages payouts over at each
+// end of a history: a quarter, or 13 weekly or 3 monthly payments
+const GrowthWindow = 91 * 24 * time.Hour
+
+// averageYear is a calendar year including leap days
+const averageYear = 365.25 * 24 * time.Hour
+
+// GrowthRate returns the compound annual growth, in percent, from the
+// average payout in the first period of the history to the average payout
+// in its last period, so a shrinking series gives a negative rate. It
+// returns NaN when the history spans less than two periods, leaving the
+// windows overlapping, or when the earliest average isn't positive.
+// Estimated events are ignored.
+func (h *DividendHistory) GrowthRate(period time.Duration) float64 {
+	return growthRate(h.Events, period)
+}
+
+func growthRate(events []DividendEvent, period time.Duration) float64 {
+	if period <= 0 {
+		return math.NaN()
+	}
+
+	var first, last time.Time
+	for _, event := range events {
+		if event.Estimated || event.ExDate.IsZero() {
+			continue
+		}
+		if first.IsZero() || event.ExDate.Before(first) {
+			first = event.ExDate
+		}
+		if event.ExDate.After(last) {
+			last = event.ExDate
+		}
+	}
+	span := last.Sub(first)
+	if first.IsZero() || span < 2*period {
+		return math.NaN()
+	}
+
+	earlyEnd := first.Add(period)
+	lateStart := last.Add(-period)
+	var earlyMicros, lateMicros int64
+	var earlySeconds, lateSeconds float64
+	early, late := 0, 0
+	for _, event := range events {
+		if event.Estimated || event.ExDate.IsZero() {
+			continue
+		}
+		if event.ExDate.Before(earlyEnd) {
+			earlyMicros += AmountToMicros(event.Amount)
+			earlySeconds += float64(event.ExDate.Unix())
+			early++
+		}
+		if event.ExDate.After(lateStart) {
+			lateMicros += AmountToMicros(event.Amount)
+			lateSeconds += float64(event.ExDate.Unix())
+			late++
+		}
+	}
+	if earlyMicros <= 0 {
+		return math.NaN()
+	}
+
+	earlyAverage := float64(earlyMicros) / float64(early)
+	lateAverage := float64(lateMicros) / float64(late)
+	// Compound over the time between the windows' average ex-dates
+	years := (lateSeconds/float64(late) - earlySeconds/float64(early)) / averageYear.Seconds()
+	return (math.Pow(lateAverage/earlyAverage, 1/years) - 1) * 100
+}
+
+// distributionCAGR is growthRate over GrowthWindow rounded for stats, or nil
+// when it can't be measured
+func distributionCAGR(events []DividendEvent) *float64 {
+	rate := growthRate(events, GrowthWindow)
+	if math.IsNaN(rate) || math.IsInf(rate, 0) {
+		return nil
+	}
+	rate = roundRate(rate)
+	return &rate
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// compoundingWeekly returns two years of weekly events ending at testLast,
+// newest first, whose amounts compound at annualPct a year from 0.5
+func compoundingWeekly(annualPct float64) []DividendEvent {
+	const weeks = 105
+	first := testLast.AddDate(0, 0, -7*(weeks-1))
+	amounts := make([]float64, weeks)
+	for i := range amounts {
+		exDate := testLast.AddDate(0, 0, -7*i)
+		years := exDate.Sub(first).Seconds() / averageYear.Seconds()
+		amounts[i] = 0.5 * math.Pow(1+annualPct/100, years)
+	}
+	return weeklyEvents(testLast, amounts...)
+}
+
+func TestGrowthRate(t *testing.T) {
+	tests := []struct {
+		name string
+		pct  float64
+	}{
+		{"growing", 10},
+		{"flat", 0},
+		{"shrinking", -20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := DividendHistory{Events: compoundingWeekly(tt.pct)}
+			// Amounts are rounded to micro-dollars, so allow a little drift
+			if got := history.GrowthRate(GrowthWindow); math.Abs(got-tt.pct) > 0.01 {
+				t.Errorf("GrowthRate = %v, want %v", got, tt.pct)
+			}
+
+			stats := CalculateStats(history.Events)
+			if stats.DistributionCAGR == nil || math.Abs(*stats.DistributionCAGR-tt.pct) > 0.01 {
+				t.Errorf("DistributionCAGR = %v, want %v", stats.DistributionCAGR, tt.pct)
+			}
+		})
+	}
+}
+
+func TestGrowthRateUnmeasurable(t *testing.T) {
+	// Estimated placeholders past the real events don't stretch the span
+	estimated := compoundingWeekly(10)[:20]
+	for i := 0; i < 30; i++ {
+		estimated = append([]DividendEvent{{ExDate: testLast.AddDate(0, 0, 7*(i+1)), Amount: 5, Estimated: true}}, estimated...)
+	}
+
+	zeroStart := compoundingWeekly(10)
+	for i := len(zeroStart) - 13; i < len(zeroStart); i++ {
+		zeroStart[i].Amount = 0
+	}
+
+	tests := []struct {
+		name   string
+		events []DividendEvent
+		period time.Duration
+	}{
+		{"no events", nil, GrowthWindow},
+		{"shorter than two windows", weeklyEvents(testLast, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5), GrowthWindow},
+		{"estimated events ignored", estimated, GrowthWindow},
+		{"nothing paid at first", zeroStart, GrowthWindow},
+		{"no period", compoundingWeekly(10), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := DividendHistory{Events: tt.events}
+			if got := history.GrowthRate(tt.period); !math.IsNaN(got) {
+				t.Errorf("GrowthRate = %v, want NaN", got)
+			}
+		})
+	}
+
+	// JSON has no NaN, so stats leave the field out
+	stats := CalculateStats(weeklyEvents(testLast, 0.5, 0.5, 0.5))
+	if stats.DistributionCAGR != nil {
+		t.Fatalf("DistributionCAGR = %v for three weeks, want nil", *stats.DistributionCAGR)
+	}
+	data, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "distributionCAGR") {
+		t.Errorf("stats JSON %s includes distributionCAGR", data)
+	}
+}
@@ -0,0 +1,222 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// AmountDecimals is the number of decimal places dividend amounts are kept to
+const AmountDecimals = 4
+
+var amountScale = math.Pow10(AmountDecimals)
+
+// RoundAmount rounds a dividend amount to AmountDecimals places so float noise
+// like 0.5300001 serializes identically across sources and runs
+func RoundAmount(amount float64) float64 {
+	return math.Round(amount*amountScale) / amountScale
+}
+
+// OutlierMADs is how many median absolute deviations from the median an
+// amount must be to count as an outlier
+const OutlierMADs = 5.0
+
+// SortEventsDescending orders events newest ex-date first, keeping the
+// relative order of events that share an ex-date
+func SortEventsDescending(events []DividendEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].ExDate.After(events[j].ExDate)
+	})
+}
+
+// SortEventsDescending orders the history's events newest first, as
+// CalculateStats and consumers of the saved files expect
+func (h *DividendHistory) SortEventsDescending() {
+	SortEventsDescending(h.Events)
+}
+
+// TrimEvents keeps only the max most recent events, leaving the stats as
+// computed from the full set. A max below 1 keeps every event. It returns the
+// number of events removed.
+func (h *DividendHistory) TrimEvents(max int) int {
+	if max < 1 || len(h.Events) <= max {
+		return 0
+	}
+	h.SortEventsDescending()
+	removed := len(h.Events) - max
+	h.Events = h.Events[:max]
+	return removed
+}
+
+// KeepYears drops events with an ex-date more than years before now and, if
+// any were dropped, recomputes the stats so they describe only the retained
+// window. A years below 1 keeps every event. It returns the number removed.
+func (h *DividendHistory) KeepYears(years int) int {
+	return h.KeepYearsAt(years, time.Now())
+}
+
+// KeepYearsAt is KeepYears with the window and year-to-date measured from now
+func (h *DividendHistory) KeepYearsAt(years int, now time.Time) int {
+	if years < 1 {
+		return 0
+	}
+	cutoff := now.AddDate(-years, 0, 0)
+	kept := h.Events[:0]
+	for _, event := range h.Events {
+		if !event.ExDate.Before(cutoff) {
+			kept = append(kept, event)
+		}
+	}
+	removed := len(h.Events) - len(kept)
+	h.Events = kept
+	if removed > 0 {
+		h.RecomputeStatsAt(now)
+	}
+	return removed
+}
+
+// CalculateStats computes dividend statistics for events ordered newest first;
+// call SortEventsDescending beforehand when the source order isn't guaranteed
+func CalculateStats(events []DividendEvent) DividendStats {
+	return CalculateStatsAt(events, time.Now())
+}
+
+// CalculateStatsAt is CalculateStats with year-to-date measured from now's
+// year in MarketLocation; it equals that year's AggregateByYear bucket. The
+// trailing-year total covers ex-dates in the twelve months before now.
+func CalculateStatsAt(events []DividendEvent, now time.Time) DividendStats {
+	var stats DividendStats
+	if len(events) == 0 {
+		return stats
+	}
+
+	// Totals are summed in integer micro-dollars so they come out the same
+	// on every run regardless of float rounding
+	var totalMicros int64
+	var ytdMicros int64
+	var trailingMicros int64
+	marketNow := now.In(MarketLocation)
+	year := marketNow.Year()
+	trailingStart := marketNow.AddDate(-1, 0, 0)
+	amounts := make([]float64, len(events))
+
+	for i, event := range events {
+		micros := AmountToMicros(event.Amount)
+		totalMicros += micros
+		amounts[i] = event.Amount
+		if !event.ExDate.IsZero() && event.ExDate.Year() == year {
+			ytdMicros += micros
+		}
+		if event.ExDate.After(trailingStart) {
+			trailingMicros += micros
+		}
+	}
+
+	stats.TotalPayments = len(events)
+	stats.AverageAmount = MicrosToAmount(int64(math.Round(float64(totalMicros) / float64(len(events)))))
+	stats.MedianAmount = RoundAmount(medianOf(amounts))
+	stats.LastAmount = RoundAmount(events[0].Amount)
+	stats.YearToDateTotal = MicrosToAmount(ytdMicros)
+	stats.TrailingYearTotal = MicrosToAmount(trailingMicros)
+	stats.DistributionCAGR = distributionCAGR(events)
+
+	// Calculate change percent if we have at least 2 events
+	if len(events) > 1 && events[1].Amount != 0 {
+		stats.ChangePercent = (events[0].Amount - events[1].Amount) / events[1].Amount * 100
+	}
+
+	return stats
+}
+
+// RecomputeStats re-sorts the events and recalculates the stats from them,
+// e.g. after the file was edited by hand. Stats of a history trimmed with
+// TrimEvents then cover only the events that remain.
+func (h *DividendHistory) RecomputeStats() {
+	h.RecomputeStatsAt(time.Now())
+}
+
+// RecomputeStatsAt is RecomputeStats with year-to-date measured from now's year
+func (h *DividendHistory) RecomputeStatsAt(now time.Time) {
+	h.SortEventsDescending()
+	h.Stats = CalculateStatsAt(h.Events, now)
+}
+
+// DetectOutliers returns the indices of events whose amount is more than
+// OutlierMADs median absolute deviations away from the median amount
+func DetectOutliers(events []DividendEvent) []int {
+	// Too few points to say what's normal
+	if len(events) < 4 {
+		return nil
+	}
+
+	amounts := make([]float64, len(events))
+	for i, event := range events {
+		amounts[i] = event.Amount
+	}
+	median := medianOf(amounts)
+
+	deviations := make([]float64, len(amounts))
+	for i, amount := range amounts {
+		deviations[i] = math.Abs(amount - median)
+	}
+	mad := medianOf(deviations)
+
+	// Floor the scale at a tenth of the median so a flat series (zero MAD)
+	// doesn't flag every small variation
+	scale := math.Max(mad, median*0.1)
+	if scale == 0 {
+		return nil
+	}
+
+	var outliers []int
+	for i, deviation := range deviations {
+		if deviation/scale > OutlierMADs {
+			outliers = append(outliers, i)
+		}
+	}
+
+	return outliers
+}
+
+// DropOutliers removes outlier events, recomputes the stats and returns the dropped events
+func (h *DividendHistory) DropOutliers() []DividendEvent {
+	outliers := DetectOutliers(h.Events)
+	if len(outliers) == 0 {
+		return nil
+	}
+
+	isOutlier := make(map[int]bool, len(outliers))
+	for _, i := range outliers {
+		isOutlier[i] = true
+	}
+
+	var kept, dropped []DividendEvent
+	for i, event := range h.Events {
+		if isOutlier[i] {
+			dropped = append(dropped, event)
+		} else {
+			kept = append(kept, event)
+		}
+	}
+
+	h.Events = kept
+	h.Stats = CalculateStats(kept)
+
+	return dropped
+}
+
+// medianOf returns the median of values without modifying the slice
+func medianOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
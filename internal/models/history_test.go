@@ -0,0 +1,231 @@
+package models
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// weeklyEvents returns one event a week ending at last, newest first, with
+// the given amounts
+func weeklyEvents(last time.Time, amounts ...float64) []DividendEvent {
+	events := make([]DividendEvent, len(amounts))
+	for i, amount := range amounts {
+		events[i] = DividendEvent{Symbol: "TEST", ExDate: last.AddDate(0, 0, -7*i), Amount: amount}
+	}
+	return events
+}
+
+var testLast = time.Date(2025, 6, 5, 0, 0, 0, 0, MarketLocation)
+
+func TestDetectOutliers(t *testing.T) {
+	tests := []struct {
+		name    string
+		amounts []float64
+		want    []int
+	}{
+		{"normal series", []float64{0.52, 0.48, 0.50, 0.55, 0.47, 0.51}, nil},
+		{"amount in cents", []float64{0.52, 0.48, 50.0, 0.55, 0.47, 0.51}, []int{2}},
+		{"amount in thousandths", []float64{0.52, 0.48, 0.50, 0.55, 0.0005, 0.51, 0.49, 0.53}, []int{4}},
+		{"ordinary cut", []float64{0.40, 0.48, 0.50, 0.55, 0.47, 0.51}, nil},
+		{"too few events", []float64{0.50, 5.00, 0.50}, nil},
+		{"flat series", []float64{0.50, 0.50, 0.50, 0.50, 0.51}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectOutliers(weeklyEvents(testLast, tt.amounts...))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectOutliers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDropOutliers(t *testing.T) {
+	history := DividendHistory{Symbol: "TEST", Events: weeklyEvents(testLast, 0.52, 0.48, 50.0, 0.55, 0.47, 0.51)}
+	history.Stats = CalculateStats(history.Events)
+
+	dropped := history.DropOutliers()
+	if len(dropped) != 1 || dropped[0].Amount != 50.0 {
+		t.Fatalf("dropped %+v, want the 50.0 event", dropped)
+	}
+	if len(history.Events) != 5 {
+		t.Errorf("kept %d events, want 5", len(history.Events))
+	}
+	if history.Stats.TotalPayments != 5 || history.Stats.AverageAmount != 0.506 {
+		t.Errorf("stats not recomputed without the outlier: %+v", history.Stats)
+	}
+}
+
+func TestRoundAmount(t *testing.T) {
+	tests := []struct {
+		in, want float64
+	}{
+		{0.53000001, 0.53},
+		{0.5299999, 0.53},
+		{0.5, 0.5},
+		{0.12345, 0.1235},
+		{0.12344, 0.1234},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		if got := RoundAmount(tt.in); got != tt.want {
+			t.Errorf("RoundAmount(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateStatsRoundsAmounts(t *testing.T) {
+	events := weeklyEvents(testLast, 0.53000001, 0.5299999, 0.5300001)
+	stats := CalculateStatsAt(events, testLast)
+
+	if stats.LastAmount != 0.53 || stats.MedianAmount != 0.53 || stats.AverageAmount != 0.53 {
+		t.Errorf("last/median/average = %v/%v/%v, want 0.53 each",
+			stats.LastAmount, stats.MedianAmount, stats.AverageAmount)
+	}
+	if stats.TrailingYearTotal != 1.59 || stats.YearToDateTotal != 1.59 {
+		t.Errorf("trailing/ytd totals = %v/%v, want 1.59", stats.TrailingYearTotal, stats.YearToDateTotal)
+	}
+}
+
+func TestRecomputeStatsIgnoresSourceOrder(t *testing.T) {
+	descending := weeklyEvents(testLast, 0.60, 0.50, 0.40)
+	ascending := []DividendEvent{descending[2], descending[1], descending[0]}
+
+	var stats []DividendStats
+	for _, events := range [][]DividendEvent{descending, ascending} {
+		history := DividendHistory{Events: append([]DividendEvent(nil), events...)}
+		history.RecomputeStatsAt(testLast)
+		if !history.Events[0].ExDate.Equal(testLast) {
+			t.Errorf("events start at %s after RecomputeStats, want the newest %s", history.Events[0].ExDate, testLast)
+		}
+		stats = append(stats, history.Stats)
+	}
+
+	if stats[0] != stats[1] {
+		t.Errorf("stats differ by input order:\ndescending %+v\nascending  %+v", stats[0], stats[1])
+	}
+	if stats[1].LastAmount != 0.60 {
+		t.Errorf("LastAmount = %v, want the newest 0.60", stats[1].LastAmount)
+	}
+	if want := (0.60 - 0.50) / 0.50 * 100; math.Abs(stats[1].ChangePercent-want) > 1e-9 {
+		t.Errorf("ChangePercent = %v, want %v", stats[1].ChangePercent, want)
+	}
+}
+
+func TestRecomputeStatsHandComputed(t *testing.T) {
+	// Monthly events spanning the new year, out of order as after a hand edit
+	history := DividendHistory{Events: []DividendEvent{
+		{ExDate: time.Date(2025, 2, 6, 0, 0, 0, 0, MarketLocation), Amount: 0.30},
+		{ExDate: time.Date(2024, 12, 5, 0, 0, 0, 0, MarketLocation), Amount: 0.20},
+		{ExDate: time.Date(2025, 3, 6, 0, 0, 0, 0, MarketLocation), Amount: 0.45},
+		{ExDate: time.Date(2024, 11, 7, 0, 0, 0, 0, MarketLocation), Amount: 0.60},
+		{ExDate: time.Date(2025, 1, 9, 0, 0, 0, 0, MarketLocation), Amount: 0.40},
+	}}
+	history.RecomputeStatsAt(time.Date(2025, 3, 20, 12, 0, 0, 0, MarketLocation))
+
+	want := DividendStats{
+		TotalPayments:     5,
+		AverageAmount:     0.39, // 1.95 / 5
+		MedianAmount:      0.40, // 0.20 0.30 0.40 0.45 0.60
+		LastAmount:        0.45, // March 2025
+		YearToDateTotal:   1.15, // 0.40 + 0.30 + 0.45
+		TrailingYearTotal: 1.95, // every event is after 2024-03-20
+		ChangePercent:     50,   // 0.30 → 0.45
+	}
+	got := history.Stats
+	got.DistributionCAGR = nil
+	if math.Abs(got.ChangePercent-want.ChangePercent) < 1e-9 {
+		got.ChangePercent = want.ChangePercent
+	}
+	if got != want {
+		t.Errorf("stats = %+v\nwant    %+v", got, want)
+	}
+
+	// A clock in the next year starts year-to-date over, and the trailing
+	// year from 2025-01-02 drops the November and December events
+	history.RecomputeStatsAt(time.Date(2026, 1, 2, 12, 0, 0, 0, MarketLocation))
+	if history.Stats.YearToDateTotal != 0 || history.Stats.TrailingYearTotal != 1.15 {
+		t.Errorf("in 2026 ytd/trailing = %v/%v, want 0/1.15 (0.40 + 0.30 + 0.45)", history.Stats.YearToDateTotal, history.Stats.TrailingYearTotal)
+	}
+}
+
+func TestTrimEventsKeepsStatsFromFullSet(t *testing.T) {
+	amounts := make([]float64, 60)
+	for i := range amounts {
+		amounts[i] = 0.1 + 0.01*float64(i%5)
+	}
+	events := weeklyEvents(testLast, amounts...)
+	// Shuffle the order so trimming has to sort newest first
+	events[0], events[len(events)-1] = events[len(events)-1], events[0]
+
+	now := testLast.AddDate(0, 0, 3)
+	history := DividendHistory{Events: events, Stats: CalculateStatsAt(events, now)}
+	full := history.Stats
+
+	if removed := history.TrimEvents(10); removed != 50 {
+		t.Errorf("TrimEvents removed %d events, want 50", removed)
+	}
+	if len(history.Events) != 10 {
+		t.Fatalf("kept %d events, want 10", len(history.Events))
+	}
+	for i, event := range history.Events {
+		if want := testLast.AddDate(0, 0, -7*i); !event.ExDate.Equal(want) {
+			t.Errorf("event %d ex-date %s, want %s (newest first)", i, event.ExDate.Format("2006-01-02"), want.Format("2006-01-02"))
+		}
+	}
+	if history.Stats.TotalPayments != 60 || history.Stats != full {
+		t.Errorf("stats after trimming = %+v, want the full set's %+v", history.Stats, full)
+	}
+
+	for _, max := range []int{0, -1, 10} {
+		before := len(history.Events)
+		if removed := history.TrimEvents(max); removed != 0 || len(history.Events) != before {
+			t.Errorf("TrimEvents(%d) removed %d of %d events, want none", max, removed, before)
+		}
+	}
+}
+
+func TestKeepYearsAtRecomputesStatsForWindow(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, MarketLocation)
+
+	// Monthly from July 2022 to June 2025: 0.10 a month before 2025, 0.20 after
+	var events []DividendEvent
+	for i := 0; i < 36; i++ {
+		exDate := time.Date(2025, 6-time.Month(i), 5, 0, 0, 0, 0, MarketLocation)
+		amount := 0.1
+		if exDate.Year() == 2025 {
+			amount = 0.2
+		}
+		events = append(events, DividendEvent{Symbol: "TEST", ExDate: exDate, Amount: amount})
+	}
+	history := DividendHistory{Symbol: "TEST", Events: events}
+	history.Stats = CalculateStatsAt(history.Events, now)
+
+	if removed := history.KeepYearsAt(1, now); removed != 24 {
+		t.Errorf("removed %d events, want 24", removed)
+	}
+	if len(history.Events) != 12 {
+		t.Fatalf("kept %d events, want 12", len(history.Events))
+	}
+	if oldest := history.Events[len(history.Events)-1].ExDate; !oldest.Equal(time.Date(2024, 7, 5, 0, 0, 0, 0, MarketLocation)) {
+		t.Errorf("oldest kept ex-date = %v, want 2024-07-05", oldest)
+	}
+
+	stats := history.Stats
+	if stats.TotalPayments != 12 {
+		t.Errorf("TotalPayments = %d, want 12", stats.TotalPayments)
+	}
+	if math.Abs(stats.YearToDateTotal-1.2) > 1e-9 {
+		t.Errorf("YearToDateTotal = %v, want 1.2", stats.YearToDateTotal)
+	}
+	if math.Abs(stats.TrailingYearTotal-1.8) > 1e-9 {
+		t.Errorf("TrailingYearTotal = %v, want 1.8", stats.TrailingYearTotal)
+	}
+
+	if removed := history.KeepYearsAt(0, now); removed != 0 || len(history.Events) != 12 {
+		t.Errorf("KeepYearsAt(0) removed %d, want every event kept", removed)
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// MergeHistory folds a legacy history, such as one saved under a fund's old
+// ticker, into h. Events are matched by ex-date and h's own event wins on a
+// clash; merged events are relabelled with h's symbol. It returns the number
+// of events added and leaves the stats for the caller to recompute.
+func (h *DividendHistory) MergeHistory(legacy *DividendHistory) int {
+	if legacy == nil {
+		return 0
+	}
+
+	seen := make(map[string]bool, len(h.Events))
+	for _, event := range h.Events {
+		seen[exDateKey(event)] = true
+	}
+
+	added := 0
+	for _, event := range legacy.Events {
+		key := exDateKey(event)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		event.Symbol = h.Symbol
+		h.Events = append(h.Events, event)
+		added++
+	}
+
+	h.SortEventsDescending()
+	return added
+}
+
+// exDateKey is the event's ex-date as written, without converting zones, so
+// dates saved at UTC midnight match the same date parsed in MarketLocation
+func exDateKey(event DividendEvent) string {
+	return event.ExDate.Format("2006-01-02")
+}
+
+// DedupEvents collapses events with the same ex-date and amount, as when a
+// table repeats a row or two parsers read the same distribution. The copy
+// with the most dates set survives, with any date it lacks taken from the
+// others, in the position of the first copy. It returns the number of events
+// removed.
+func (h *DividendHistory) DedupEvents() int {
+	type key struct {
+		exDate string
+		micros int64
+	}
+	index := make(map[key]int, len(h.Events))
+	kept := make([]DividendEvent, 0, len(h.Events))
+
+	for _, event := range h.Events {
+		k := key{exDateKey(event), AmountToMicros(event.Amount)}
+		i, seen := index[k]
+		if !seen {
+			index[k] = len(kept)
+			kept = append(kept, event)
+			continue
+		}
+		if datesSet(event) > datesSet(kept[i]) {
+			event, kept[i] = kept[i], event
+		}
+		fillMissingDates(&kept[i], event)
+	}
+
+	removed := len(h.Events) - len(kept)
+	h.Events = kept
+	return removed
+}
+
+// datesSet counts the event's non-zero pay, declare and record dates
+func datesSet(event DividendEvent) int {
+	n := 0
+	for _, date := range []time.Time{event.PayDate, event.DeclareDate, event.RecordDate} {
+		if !date.IsZero() {
+			n++
+		}
+	}
+	return n
+}
+
+// fillMissingDates copies into dst the dates it lacks and src has
+func fillMissingDates(dst *DividendEvent, src DividendEvent) {
+	if dst.PayDate.IsZero() {
+		dst.PayDate = src.PayDate
+	}
+	if dst.DeclareDate.IsZero() {
+		dst.DeclareDate = src.DeclareDate
+	}
+	if dst.RecordDate.IsZero() {
+		dst.RecordDate = src.RecordDate
+	}
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDedupEvents(t *testing.T) {
+	exDate := time.Date(2025, 6, 5, 0, 0, 0, 0, MarketLocation)
+	payDate := exDate.AddDate(0, 0, 1)
+	declareDate := exDate.AddDate(0, 0, -2)
+	older := exDate.AddDate(0, 0, -7)
+
+	history := DividendHistory{Events: []DividendEvent{
+		// The table's row, without a record date
+		{Symbol: "ULTY", ExDate: exDate, Amount: 0.094, DeclareDate: declareDate},
+		{Symbol: "ULTY", ExDate: older, Amount: 0.093},
+		// The script's copy of it, with pay and record dates
+		{Symbol: "ULTY", ExDate: exDate, Amount: 0.094, PayDate: payDate, RecordDate: exDate},
+		// Same ex-date, different amount: a separate distribution
+		{Symbol: "ULTY", ExDate: exDate, Amount: 0.05},
+		// A repeated row
+		{Symbol: "ULTY", ExDate: older, Amount: 0.093},
+	}}
+
+	if removed := history.DedupEvents(); removed != 2 {
+		t.Errorf("removed %d events, want 2", removed)
+	}
+	if len(history.Events) != 3 {
+		t.Fatalf("events = %+v, want 3", history.Events)
+	}
+
+	merged := history.Events[0]
+	if merged.Amount != 0.094 || !merged.ExDate.Equal(exDate) {
+		t.Fatalf("first event = %+v, want the 0.094 distribution in its original position", merged)
+	}
+	if !merged.PayDate.Equal(payDate) || !merged.RecordDate.Equal(exDate) || !merged.DeclareDate.Equal(declareDate) {
+		t.Errorf("merged dates pay %v, record %v, declare %v; want all three from both copies", merged.PayDate, merged.RecordDate, merged.DeclareDate)
+	}
+	if history.Events[1].Amount != 0.093 || history.Events[2].Amount != 0.05 {
+		t.Errorf("remaining amounts = %v, %v; want 0.093, 0.05", history.Events[1].Amount, history.Events[2].Amount)
+	}
+
+	if removed := history.DedupEvents(); removed != 0 {
+		t.Errorf("second pass removed %d events, want 0", removed)
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// microsPerDollar converts dollar amounts to integer micro-dollars
+const microsPerDollar = 1e6
+
+// AmountToMicros converts a dollar amount to whole micro-dollars, rounding
+// half away from zero so the same amount always maps to the same integer
+func AmountToMicros(amount float64) int64 {
+	return int64(math.Round(amount * microsPerDollar))
+}
+
+// MicrosToAmount converts micro-dollars back to a dollar amount rounded to
+// AmountDecimals places
+func MicrosToAmount(micros int64) float64 {
+	return RoundAmount(float64(micros) / microsPerDollar)
+}
+
+// MarshalJSON writes the event with AmountMicros derived from Amount, so the
+// two never disagree in saved files
+func (e DividendEvent) MarshalJSON() ([]byte, error) {
+	type plain DividendEvent
+	out := plain(e)
+	out.AmountMicros = AmountToMicros(e.Amount)
+	return json.Marshal(out)
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+func TestAmountMicros(t *testing.T) {
+	tests := []struct {
+		amount float64
+		micros int64
+	}{
+		{0.1, 100000},
+		{0.0965, 96500},
+		{1.3845, 1384500},
+		{0.0000005, 1},
+		{-0.25, -250000},
+	}
+	for _, tt := range tests {
+		if got := AmountToMicros(tt.amount); got != tt.micros {
+			t.Errorf("AmountToMicros(%v) = %d, want %d", tt.amount, got, tt.micros)
+		}
+	}
+	if got := MicrosToAmount(96500); got != 0.0965 {
+		t.Errorf("MicrosToAmount(96500) = %v, want 0.0965", got)
+	}
+}
+
+func TestStatsTotalsStableAcrossRuns(t *testing.T) {
+	amounts := make([]float64, 52)
+	for i := range amounts {
+		amounts[i] = 0.0965 + float64(i%7)*0.0101
+	}
+	events := weeklyEvents(testLast, amounts...)
+	now := testLast.AddDate(0, 0, 5)
+
+	var want int64
+	for _, amount := range amounts {
+		want += AmountToMicros(amount)
+	}
+
+	stats := CalculateStatsAt(events, now)
+	first, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if AmountToMicros(stats.TrailingYearTotal) != want {
+		t.Errorf("TrailingYearTotal = %v, want %v", stats.TrailingYearTotal, MicrosToAmount(want))
+	}
+
+	// Summing the same payments in any order gives byte-identical stats
+	rng := rand.New(rand.NewSource(1))
+	for run := 0; run < 20; run++ {
+		shuffled := append([]DividendEvent(nil), events...)
+		rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		SortEventsDescending(shuffled)
+
+		got, err := json.Marshal(CalculateStatsAt(shuffled, now))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, first) {
+			t.Fatalf("run %d stats = %s, want %s", run, got, first)
+		}
+	}
+}
+
+func TestEventMarshalDerivesAmountMicros(t *testing.T) {
+	data, err := json.Marshal(DividendEvent{Symbol: "ULTY", Amount: 0.0965, AmountMicros: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		AmountMicros int64 `json:"amountMicros"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.AmountMicros != 96500 {
+		t.Errorf("amountMicros = %d, want 96500 from the amount", decoded.AmountMicros)
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// MaxPriceLag is how far before an ex-date the nearest close may be and
+// still price that event, covering weekends and market holidays
+const MaxPriceLag = 5 * 24 * time.Hour
+
+// PricePoint is one daily closing price
+type PricePoint struct {
+	Date  time.Time `json:"date"`
+	Close float64   `json:"close"`
+}
+
+// calendarDay returns t's calendar date as UTC midnight, so dates saved in
+// UTC and parsed in the market zone compare by day
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ApplyYieldSeries sets every event's Yield, in percent, to its amount
+// annualized by its frequency over the close on its ex-date, or the last
+// close up to MaxPriceLag before it. Events without such a price, or whose
+// frequency is unknown, get a zero Yield. It returns how many events were
+// priced.
+func (h *DividendHistory) ApplyYieldSeries(prices []PricePoint) int {
+	series := make([]PricePoint, 0, len(prices))
+	for _, p := range prices {
+		if p.Close > 0 {
+			series = append(series, PricePoint{Date: calendarDay(p.Date), Close: p.Close})
+		}
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Date.Before(series[j].Date) })
+
+	priced := 0
+	for i := range h.Events {
+		event := &h.Events[i]
+		event.Yield = 0
+
+		exDate := calendarDay(event.ExDate)
+		// First close after the ex-date; the one before it is the candidate
+		idx := sort.Search(len(series), func(k int) bool { return series[k].Date.After(exDate) })
+		if idx == 0 || exDate.Sub(series[idx-1].Date) > MaxPriceLag {
+			continue
+		}
+
+		frequency := event.Frequency
+		if frequency == "" {
+			frequency = h.Frequency
+		}
+		periods := h.paymentsPerYear(frequency)
+		if periods == 0 || event.Amount <= 0 {
+			continue
+		}
+
+		annual := MicrosToAmount(AmountToMicros(event.Amount) * int64(periods))
+		event.Yield = roundRate(annual / series[idx-1].Close * 100)
+		priced++
+	}
+	return priced
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestApplyYieldSeries(t *testing.T) {
+	// Thursday ex-dates on 06/05, 05/29, 05/22 and 05/15
+	history := DividendHistory{Frequency: "weekly", Events: weeklyEvents(testLast, 0.1, 0.1, 0.1, 0.25)}
+	history.Events[2].Yield = 7 // Stale yields are cleared when no price is found
+
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	prices := []PricePoint{
+		{Date: day(6, 5), Close: 10},
+		// The day after an ex-date never prices it
+		{Date: day(5, 30), Close: 99},
+		{Date: day(5, 28), Close: 20},
+		{Date: day(5, 23), Close: 99},
+		// Six days before 05/22, past MaxPriceLag
+		{Date: day(5, 16), Close: 99},
+		{Date: day(5, 15), Close: 13},
+		{Date: day(5, 14), Close: 0},
+	}
+
+	if priced := history.ApplyYieldSeries(prices); priced != 3 {
+		t.Errorf("priced %d events, want 3", priced)
+	}
+
+	// $0.10 a week is $5.20 a year: 52% at $10 and 26% at $20
+	want := []float64{52, 26, 0, 100}
+	for i, event := range history.Events {
+		if math.Abs(event.Yield-want[i]) > 1e-9 {
+			t.Errorf("event on %s yield = %v, want %v", event.ExDate.Format("2006-01-02"), event.Yield, want[i])
+		}
+	}
+}
+
+func TestApplyYieldSeriesUnknownFrequency(t *testing.T) {
+	history := DividendHistory{Events: weeklyEvents(testLast, 0.1)}
+	prices := []PricePoint{{Date: time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC), Close: 10}}
+	if priced := history.ApplyYieldSeries(prices); priced != 0 || history.Events[0].Yield != 0 {
+		t.Errorf("priced %d events with yield %v, want none without a frequency", priced, history.Events[0].Yield)
+	}
+
+	// An event's own frequency is used when the history has none
+	history.Events[0].Frequency = "monthly"
+	if priced := history.ApplyYieldSeries(prices); priced != 1 || math.Abs(history.Events[0].Yield-12) > 1e-9 {
+		t.Errorf("priced %d events with yield %v, want 12%%", priced, history.Events[0].Yield)
+	}
+}
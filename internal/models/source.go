@@ -0,0 +1,19 @@
+package models
+
+// Sources recorded in DividendEvent.Source for the component that created
+// the event
+const (
+	SourceYieldMax  = "yieldmax"  // Scraped from a YieldMax page
+	SourceFMP       = "fmp"       // Financial Modeling Prep API
+	SourceSynthetic = "synthetic" // Generated placeholder, never scraped
+)
+
+// KnownSource reports whether source is one of the sources above, or empty
+// for events saved before sources were recorded
+func KnownSource(source string) bool {
+	switch source {
+	case "", SourceYieldMax, SourceFMP, SourceSynthetic:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Target12Rate is the annual distribution rate, in percent of price, that
+// Target12 funds aim to pay
+const Target12Rate = 12.0
+
+// TargetTracking compares a Target12 fund's distributions with its target
+type TargetTracking struct {
+	TargetRate           float64 `json:"targetRate"`           // Percent of price per year
+	AnnualizedActualRate float64 `json:"annualizedActualRate"` // Trailing 12-month distributions annualized, in percent of price
+	VarianceFromTarget   float64 `json:"varianceFromTarget"`   // Actual minus target, in percentage points
+}
+
+// TargetTrackingAt measures the history against Target12Rate over the year
+// before now. The average payment in that year is annualized by the fund's
+// payments per year and divided by price; without a price the average of
+// the events' own yields is used instead. It returns nil for funds outside
+// the Target12 group and when nothing can be measured. Estimated events are
+// ignored.
+func (h *DividendHistory) TargetTrackingAt(price float64, now time.Time) *TargetTracking {
+	if h.Group != "Target12" {
+		return nil
+	}
+	periods := h.paymentsPerYear(h.Frequency)
+
+	start := now.AddDate(-1, 0, 0)
+	var totalMicros int64
+	var yieldTotal float64
+	payments, yields := 0, 0
+	for _, event := range h.Events {
+		if event.Estimated || !event.ExDate.After(start) || event.ExDate.After(now) {
+			continue
+		}
+		totalMicros += AmountToMicros(event.Amount)
+		payments++
+		if event.Yield > 0 {
+			yieldTotal += event.Yield
+			yields++
+		}
+	}
+
+	var actual float64
+	switch {
+	case payments == 0:
+		return nil
+	case price > 0:
+		annual := float64(totalMicros) / microsPerDollar / float64(payments) * float64(periods)
+		actual = annual / price * 100
+	case yields > 0:
+		actual = yieldTotal / float64(yields)
+	default:
+		return nil
+	}
+
+	actual = roundRate(actual)
+	return &TargetTracking{
+		TargetRate:           Target12Rate,
+		AnnualizedActualRate: actual,
+		VarianceFromTarget:   roundRate(actual - Target12Rate),
+	}
+}
+
+// roundRate rounds a percentage to two decimal places
+func roundRate(rate float64) float64 {
+	return math.Round(rate*100) / 100
+}
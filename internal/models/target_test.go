@@ -0,0 +1,93 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func monthlyTarget12History(amounts ...float64) DividendHistory {
+	events := make([]DividendEvent, len(amounts))
+	for i, amount := range amounts {
+		events[i] = DividendEvent{Symbol: "BIGY", ExDate: testLast.AddDate(0, -i, 0), Amount: amount}
+	}
+	return DividendHistory{Symbol: "BIGY", Group: "Target12", Frequency: "monthly", Events: events}
+}
+
+func TestTargetTrackingAt(t *testing.T) {
+	now := testLast.AddDate(0, 0, 1)
+	amounts := make([]float64, 12)
+	for i := range amounts {
+		amounts[i] = 0.15
+	}
+
+	tests := []struct {
+		name         string
+		history      DividendHistory
+		price        float64
+		wantActual   float64
+		wantVariance float64
+	}{
+		// $0.15 a month is $1.80 a year: 15% of $12, 9% of $20
+		{"above target", monthlyTarget12History(amounts...), 12, 15, 3},
+		{"below target", monthlyTarget12History(amounts...), 20, 9, -3},
+		{"on target", monthlyTarget12History(amounts...), 15, 12, 0},
+		// The average payment is annualized, so a short history still measures a full year
+		{"short history", monthlyTarget12History(0.2, 0.1), 12, 15, 3},
+		// Payments older than a year don't count
+		{"older payments ignored", monthlyTarget12History(append(append([]float64(nil), amounts...), 5, 5)...), 12, 15, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.history.TargetTrackingAt(tt.price, now)
+			if got == nil {
+				t.Fatal("TargetTrackingAt = nil, want a measurement")
+			}
+			if got.TargetRate != Target12Rate {
+				t.Errorf("TargetRate = %v, want %v", got.TargetRate, Target12Rate)
+			}
+			if math.Abs(got.AnnualizedActualRate-tt.wantActual) > 1e-9 {
+				t.Errorf("AnnualizedActualRate = %v, want %v", got.AnnualizedActualRate, tt.wantActual)
+			}
+			if math.Abs(got.VarianceFromTarget-tt.wantVariance) > 1e-9 {
+				t.Errorf("VarianceFromTarget = %v, want %v", got.VarianceFromTarget, tt.wantVariance)
+			}
+		})
+	}
+}
+
+func TestTargetTrackingAtFromYields(t *testing.T) {
+	now := testLast.AddDate(0, 0, 1)
+	history := monthlyTarget12History(0.15, 0.15, 0.15)
+	history.Events[0].Yield = 13.5
+	history.Events[1].Yield = 10.5
+	// An estimated upcoming payment is ignored, yield and all
+	history.Events = append(history.Events, DividendEvent{Symbol: "BIGY", ExDate: testLast, Amount: 9, Yield: 90, Estimated: true})
+
+	got := history.TargetTrackingAt(0, now)
+	if got == nil {
+		t.Fatal("TargetTrackingAt = nil, want the average of the events' yields")
+	}
+	if got.AnnualizedActualRate != 12 || got.VarianceFromTarget != 0 {
+		t.Errorf("tracking = %+v, want 12%% with no variance", got)
+	}
+}
+
+func TestTargetTrackingAtSkipsUnmeasurable(t *testing.T) {
+	now := testLast.AddDate(0, 0, 1)
+
+	weekly := monthlyTarget12History(0.15)
+	weekly.Group = "Weekly"
+	if got := weekly.TargetTrackingAt(12, now); got != nil {
+		t.Errorf("non-Target12 fund tracked: %+v", got)
+	}
+
+	noPrice := monthlyTarget12History(0.15)
+	if got := noPrice.TargetTrackingAt(0, now); got != nil {
+		t.Errorf("tracked without a price or yields: %+v", got)
+	}
+
+	stale := monthlyTarget12History(0.15)
+	if got := stale.TargetTrackingAt(12, now.AddDate(2, 0, 0)); got != nil {
+		t.Errorf("tracked without a payment in the last year: %+v", got)
+	}
+}
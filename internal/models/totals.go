@@ -0,0 +1,40 @@
+package models
+
+// Calendar totals bucket events by their ex-date as written. Parsed dates
+// are midnight in MarketLocation and saved ones UTC midnight of the same
+// calendar date, so this is the New York date either way, where converting
+// a saved date to MarketLocation would move it to the day before.
+
+// AggregateByYear totals the events' amounts per calendar year of their
+// ex-date. Events without an ex-date are skipped.
+func AggregateByYear(events []DividendEvent) map[int]float64 {
+	micros := make(map[int]int64)
+	for _, event := range events {
+		if !event.ExDate.IsZero() {
+			micros[event.ExDate.Year()] += AmountToMicros(event.Amount)
+		}
+	}
+
+	totals := make(map[int]float64, len(micros))
+	for year, total := range micros {
+		totals[year] = MicrosToAmount(total)
+	}
+	return totals
+}
+
+// AggregateByMonth totals the events' amounts per calendar month of their
+// ex-date, keyed "YYYY-MM". Events without an ex-date are skipped.
+func AggregateByMonth(events []DividendEvent) map[string]float64 {
+	micros := make(map[string]int64)
+	for _, event := range events {
+		if !event.ExDate.IsZero() {
+			micros[event.ExDate.Format("2006-01")] += AmountToMicros(event.Amount)
+		}
+	}
+
+	totals := make(map[string]float64, len(micros))
+	for month, total := range micros {
+		totals[month] = MicrosToAmount(total)
+	}
+	return totals
+}
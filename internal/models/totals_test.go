@@ -0,0 +1,72 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAggregateAcrossYearBoundary(t *testing.T) {
+	events := []DividendEvent{
+		// Saved histories hold UTC midnight of the New York date; January 1
+		// must stay in 2026 rather than become the evening of December 31
+		{ExDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Amount: 0.3},
+		{ExDate: time.Date(2026, 1, 8, 0, 0, 0, 0, MarketLocation), Amount: 0.2},
+		{ExDate: time.Date(2025, 12, 31, 0, 0, 0, 0, MarketLocation), Amount: 0.1},
+		{ExDate: time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC), Amount: 0.1},
+		{ExDate: time.Date(2025, 11, 26, 0, 0, 0, 0, MarketLocation), Amount: 0.05},
+		// No ex-date, no bucket
+		{Amount: 9},
+	}
+
+	years := AggregateByYear(events)
+	if len(years) != 2 || years[2026] != 0.5 || years[2025] != 0.25 {
+		t.Errorf("AggregateByYear = %v, want 2026: 0.5, 2025: 0.25", years)
+	}
+
+	months := AggregateByMonth(events)
+	want := map[string]float64{"2026-01": 0.5, "2025-12": 0.2, "2025-11": 0.05}
+	if len(months) != len(want) {
+		t.Fatalf("AggregateByMonth = %v, want %v", months, want)
+	}
+	for month, total := range want {
+		if months[month] != total {
+			t.Errorf("AggregateByMonth[%s] = %v, want %v", month, months[month], total)
+		}
+	}
+
+	// Sums are exact in micro-dollars
+	tenths := make([]DividendEvent, 3)
+	for i := range tenths {
+		tenths[i] = DividendEvent{ExDate: time.Date(2025, 3, 1+i, 0, 0, 0, 0, MarketLocation), Amount: 0.1}
+	}
+	if got := AggregateByYear(tenths)[2025]; got != 0.3 {
+		t.Errorf("three 0.1 distributions total %v, want 0.3", got)
+	}
+}
+
+func TestYearToDateMatchesYearBucket(t *testing.T) {
+	events := []DividendEvent{
+		{ExDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Amount: 0.3},
+		{ExDate: time.Date(2025, 12, 31, 0, 0, 0, 0, MarketLocation), Amount: 0.1},
+		{ExDate: time.Date(2025, 12, 24, 0, 0, 0, 0, MarketLocation), Amount: 0.1},
+	}
+	years := AggregateByYear(events)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		year int
+	}{
+		{"new year in New York", time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC), 2026},
+		// Already 2026 in UTC, still New Year's Eve in New York
+		{"new year only in UTC", time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC), 2025},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CalculateStatsAt(events, tt.now)
+			if stats.YearToDateTotal != years[tt.year] {
+				t.Errorf("YearToDateTotal = %v, want the %d bucket %v", stats.YearToDateTotal, tt.year, years[tt.year])
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// statsTolerance absorbs rounding differences between saved and recomputed stats
+const statsTolerance = 0.0001
+
+// Validate reports the first problem that makes the event unusable
+func (e DividendEvent) Validate() error {
+	if e.Symbol == "" {
+		return fmt.Errorf("missing symbol")
+	}
+	if e.ExDate.IsZero() {
+		return fmt.Errorf("%s: missing ex-date", e.Symbol)
+	}
+	if math.IsNaN(e.Amount) || math.IsInf(e.Amount, 0) || e.Amount < 0 {
+		return fmt.Errorf("%s %s: invalid amount %v", e.Symbol, e.ExDate.Format("2006-01-02"), e.Amount)
+	}
+	if !e.PayDate.IsZero() && e.PayDate.Before(e.ExDate) {
+		return fmt.Errorf("%s %s: pay date %s is before the ex-date", e.Symbol, e.ExDate.Format("2006-01-02"), e.PayDate.Format("2006-01-02"))
+	}
+	if !KnownSource(e.Source) {
+		return fmt.Errorf("%s %s: unknown source %q", e.Symbol, e.ExDate.Format("2006-01-02"), e.Source)
+	}
+	// Generated amounts must never pass for scraped ones
+	if e.Source == SourceSynthetic && !e.Estimated {
+		return fmt.Errorf("%s %s: synthetic event is not marked estimated", e.Symbol, e.ExDate.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// Validate checks every event and that the stats agree with the events.
+// Histories trimmed with TrimEvents keep stats for more events than they
+// list, so only the newest amount is compared for those.
+func (h *DividendHistory) Validate() []error {
+	var errs []error
+	if h.Symbol == "" {
+		errs = append(errs, fmt.Errorf("missing symbol"))
+	}
+
+	for _, event := range h.Events {
+		if err := event.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if h.Stats.TotalPayments < len(h.Events) {
+		errs = append(errs, fmt.Errorf("stats count %d payments but %d events are listed", h.Stats.TotalPayments, len(h.Events)))
+	}
+	if len(h.Events) == 0 {
+		return errs
+	}
+
+	events := make([]DividendEvent, len(h.Events))
+	copy(events, h.Events)
+	SortEventsDescending(events)
+	expected := CalculateStats(events)
+
+	if math.Abs(h.Stats.LastAmount-expected.LastAmount) > statsTolerance {
+		errs = append(errs, fmt.Errorf("stats last amount %v does not match newest event amount %v", h.Stats.LastAmount, expected.LastAmount))
+	}
+	if h.Stats.TotalPayments == len(h.Events) && math.Abs(h.Stats.AverageAmount-expected.AverageAmount) > statsTolerance {
+		errs = append(errs, fmt.Errorf("stats average amount %v does not match events average %v", h.Stats.AverageAmount, expected.AverageAmount))
+	}
+	return errs
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateEventSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		estimated bool
+		wantErr   string
+	}{
+		{name: "scraped", source: SourceYieldMax},
+		{name: "fmp", source: SourceFMP},
+		{name: "saved before sources", source: ""},
+		{name: "estimated synthetic", source: SourceSynthetic, estimated: true},
+		{name: "synthetic passed off as scraped", source: SourceSynthetic, wantErr: "not marked estimated"},
+		{name: "unknown source", source: "yahoo", wantErr: "unknown source"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := DividendEvent{Symbol: "ULTY", ExDate: testLast, Amount: 0.1, Source: tt.source, Estimated: tt.estimated}
+			err := event.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// WeekendExDate is an event whose ex-date fell on a Saturday or Sunday, which
+// US equities never trade on
+type WeekendExDate struct {
+	Event     DividendEvent // As scraped
+	Corrected time.Time     // Ex-date it was moved to; zero when left alone
+}
+
+// IsWeekend reports whether date's calendar day, as written, is a Saturday or Sunday
+func IsWeekend(date time.Time) bool {
+	weekday := date.Weekday()
+	return weekday == time.Saturday || weekday == time.Sunday
+}
+
+// PriorWeekday returns date when it is a weekday, else the Friday before it
+func PriorWeekday(date time.Time) time.Time {
+	switch date.Weekday() {
+	case time.Saturday:
+		return date.AddDate(0, 0, -1)
+	case time.Sunday:
+		return date.AddDate(0, 0, -2)
+	}
+	return date
+}
+
+// CheckWeekendExDates reports every event whose ex-date falls on a weekend.
+// With fix set, those ex-dates (and record dates equal to them) are moved to
+// the prior Friday and the stats are recomputed.
+func (h *DividendHistory) CheckWeekendExDates(fix bool) []WeekendExDate {
+	var found []WeekendExDate
+	for i := range h.Events {
+		event := &h.Events[i]
+		if event.ExDate.IsZero() || !IsWeekend(event.ExDate) {
+			continue
+		}
+
+		weekend := WeekendExDate{Event: *event}
+		if fix {
+			corrected := PriorWeekday(event.ExDate)
+			if event.RecordDate.Equal(event.ExDate) {
+				event.RecordDate = corrected
+			}
+			event.ExDate = corrected
+			weekend.Corrected = corrected
+		}
+		found = append(found, weekend)
+	}
+
+	if fix && len(found) > 0 {
+		h.RecomputeStats()
+	}
+	return found
+}
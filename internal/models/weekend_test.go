@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckWeekendExDates(t *testing.T) {
+	saturday := time.Date(2025, 5, 31, 0, 0, 0, 0, MarketLocation)
+	sunday := time.Date(2025, 5, 25, 0, 0, 0, 0, MarketLocation)
+	friday := time.Date(2025, 5, 30, 0, 0, 0, 0, MarketLocation)
+	thursday := time.Date(2025, 6, 5, 0, 0, 0, 0, MarketLocation)
+
+	newHistory := func() DividendHistory {
+		return DividendHistory{Symbol: "TSLY", Events: []DividendEvent{
+			{Symbol: "TSLY", ExDate: thursday, RecordDate: thursday, Amount: 0.4},
+			{Symbol: "TSLY", ExDate: saturday, RecordDate: saturday, Amount: 0.5},
+			{Symbol: "TSLY", ExDate: sunday, RecordDate: sunday.AddDate(0, 0, 1), Amount: 0.6},
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		fix         bool
+		wantExDates []time.Time
+		wantRecord  time.Time // Record date of the Saturday event
+	}{
+		{
+			name:        "flag only",
+			wantExDates: []time.Time{thursday, saturday, sunday},
+			wantRecord:  saturday,
+		},
+		{
+			name:        "fix",
+			fix:         true,
+			wantExDates: []time.Time{thursday, friday, friday.AddDate(0, 0, -7)},
+			wantRecord:  friday,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := newHistory()
+			found := history.CheckWeekendExDates(tt.fix)
+
+			if len(found) != 2 || !found[0].Event.ExDate.Equal(saturday) || !found[1].Event.ExDate.Equal(sunday) {
+				t.Fatalf("found %+v, want the Saturday and Sunday events", found)
+			}
+			for i, w := range tt.wantExDates {
+				if !history.Events[i].ExDate.Equal(w) {
+					t.Errorf("event %d ex-date = %v, want %v", i, history.Events[i].ExDate, w)
+				}
+			}
+			if !history.Events[1].RecordDate.Equal(tt.wantRecord) {
+				t.Errorf("record date = %v, want %v", history.Events[1].RecordDate, tt.wantRecord)
+			}
+			// A record date that differed from the ex-date is left alone
+			if !history.Events[2].RecordDate.Equal(sunday.AddDate(0, 0, 1)) {
+				t.Errorf("Sunday event record date moved to %v", history.Events[2].RecordDate)
+			}
+			if tt.fix {
+				if !found[0].Corrected.Equal(friday) {
+					t.Errorf("Corrected = %v, want %v", found[0].Corrected, friday)
+				}
+				if history.Stats.TotalPayments != 3 {
+					t.Errorf("stats not recomputed after the fix: %+v", history.Stats)
+				}
+			} else if !found[0].Corrected.IsZero() || history.Stats.TotalPayments != 0 {
+				t.Errorf("history changed without fix: corrected %v, stats %+v", found[0].Corrected, history.Stats)
+			}
+		})
+	}
+}
+
+func TestPriorWeekday(t *testing.T) {
+	friday := time.Date(2025, 5, 30, 0, 0, 0, 0, MarketLocation)
+	for offset, want := range map[int]time.Time{0: friday, 1: friday, 2: friday, 3: friday.AddDate(0, 0, 3)} {
+		date := friday.AddDate(0, 0, offset)
+		if got := PriorWeekday(date); !got.Equal(want) {
+			t.Errorf("PriorWeekday(%s) = %v, want %v", date.Weekday(), got, want)
+		}
+	}
+}
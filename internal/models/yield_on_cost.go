@@ -0,0 +1,85 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PaymentsPerYear returns how many distributions a frequency pays in a
+// year, or 0 when the frequency is unknown
+func PaymentsPerYear(frequency string) int {
+	switch strings.ToLower(frequency) {
+	case "weekly":
+		return 52
+	case "monthly":
+		return 12
+	case "quarterly":
+		return 4
+	}
+	return 0
+}
+
+// AnnualizedDistribution returns the newest distribution times the payments
+// per year of the history's frequency, or 0 without an amount or a known
+// frequency. Target 12 funds pay monthly whatever frequency was scraped.
+func (h *DividendHistory) AnnualizedDistribution() float64 {
+	if h.Stats.LastAmount <= 0 {
+		return 0
+	}
+	periods := h.paymentsPerYear(h.Frequency)
+	return MicrosToAmount(AmountToMicros(h.Stats.LastAmount) * int64(periods))
+}
+
+// paymentsPerYear is PaymentsPerYear of frequency, corrected by the
+// history's group where the scraped frequency is unreliable
+func (h *DividendHistory) paymentsPerYear(frequency string) int {
+	periods := PaymentsPerYear(frequency)
+	switch {
+	case h.Group == "Target12":
+		periods = 12
+	case periods == 0 && h.Group == "Weekly":
+		periods = 52
+	}
+	return periods
+}
+
+// YieldOnCost returns the annualized yield, in percent, of the distributions
+// paid within window of now on a purchase price per share
+func (h *DividendHistory) YieldOnCost(purchasePrice float64, window time.Duration) (float64, error) {
+	return h.YieldOnCostAt(purchasePrice, window, time.Now())
+}
+
+// YieldOnCostAt is YieldOnCost measured back from now. The average payment in
+// the window is annualized by the history's frequency; when the frequency is
+// unknown the window total is scaled to a year instead. Estimated events are
+// ignored.
+func (h *DividendHistory) YieldOnCostAt(purchasePrice float64, window time.Duration, now time.Time) (float64, error) {
+	if purchasePrice <= 0 {
+		return 0, fmt.Errorf("purchase price must be positive, got %v", purchasePrice)
+	}
+	if window <= 0 {
+		return 0, fmt.Errorf("window must be positive, got %s", window)
+	}
+
+	start := now.Add(-window)
+	var totalMicros int64
+	payments := 0
+	for _, event := range h.Events {
+		if event.Estimated || !event.ExDate.After(start) || event.ExDate.After(now) {
+			continue
+		}
+		totalMicros += AmountToMicros(event.Amount)
+		payments++
+	}
+	total := float64(totalMicros) / microsPerDollar
+	if payments == 0 {
+		return 0, nil
+	}
+
+	annual := total * float64(365*24*time.Hour) / float64(window)
+	if perYear := PaymentsPerYear(h.Frequency); perYear > 0 {
+		annual = total / float64(payments) * float64(perYear)
+	}
+	return annual / purchasePrice * 100, nil
+}
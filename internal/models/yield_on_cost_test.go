@@ -0,0 +1,96 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestYieldOnCostWeeklyYear(t *testing.T) {
+	amounts := make([]float64, 52)
+	for i := range amounts {
+		amounts[i] = 0.10
+	}
+	events := weeklyEvents(testLast, amounts...)
+	// An estimated upcoming payment and one older than the window don't count
+	events = append(events,
+		DividendEvent{Symbol: "TEST", ExDate: testLast.AddDate(0, 0, -7*52), Amount: 5},
+		DividendEvent{Symbol: "TEST", ExDate: testLast.AddDate(0, 0, 1), Amount: 5, Estimated: true},
+	)
+	now := testLast.AddDate(0, 0, 2)
+	year := 365 * 24 * time.Hour
+
+	tests := []struct {
+		name      string
+		frequency string
+		cost      float64
+		window    time.Duration
+		want      float64
+	}{
+		// $0.10 a week on $10 is $5.20 a year, 52%
+		{"weekly", "weekly", 10, year, 52},
+		{"weekly at a higher cost", "weekly", 20, year, 26},
+		// A half-year window still annualizes the weekly average
+		{"half-year window", "weekly", 10, year / 2, 52},
+		// Without a frequency the window's total is scaled to a year
+		{"unknown frequency", "", 10, year, 52},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := DividendHistory{Frequency: tt.frequency, Events: events}
+			got, err := history.YieldOnCostAt(tt.cost, tt.window, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("YieldOnCostAt = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYieldOnCostRejectsInvalidInput(t *testing.T) {
+	history := DividendHistory{Frequency: "weekly", Events: weeklyEvents(testLast, 0.1, 0.1)}
+	year := 365 * 24 * time.Hour
+
+	for _, cost := range []float64{0, -5} {
+		if _, err := history.YieldOnCostAt(cost, year, testLast); err == nil {
+			t.Errorf("cost %v accepted, want an error", cost)
+		}
+	}
+	if _, err := history.YieldOnCostAt(10, 0, testLast); err == nil {
+		t.Error("zero window accepted, want an error")
+	}
+
+	empty := DividendHistory{Frequency: "weekly"}
+	if got, err := empty.YieldOnCostAt(10, year, testLast); err != nil || got != 0 {
+		t.Errorf("no payments = %v, %v; want 0 without an error", got, err)
+	}
+}
+
+func TestAnnualizedDistribution(t *testing.T) {
+	tests := []struct {
+		name       string
+		group      string
+		frequency  string
+		lastAmount float64
+		want       float64
+	}{
+		{"weekly", "Weekly", "weekly", 0.0965, 5.018},
+		{"monthly", "GroupA", "monthly", 0.437, 5.244},
+		// Target 12 funds pay monthly even when scraped as weekly
+		{"target 12", "Target12", "weekly", 0.3, 3.6},
+		{"weekly group without a frequency", "Weekly", "", 0.1, 5.2},
+		{"zero amount", "Weekly", "weekly", 0, 0},
+		{"unknown frequency", "GroupA", "", 0.5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := DividendHistory{Group: tt.group, Frequency: tt.frequency}
+			history.Stats.LastAmount = tt.lastAmount
+			if got := history.AnnualizedDistribution(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("AnnualizedDistribution = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package notifier
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+// KindAmountChange marks notifications about a distribution cut or raise
+const KindAmountChange = "amount_change"
+
+// DefaultAmountChangeThreshold is the percent move that counts as material
+const DefaultAmountChangeThreshold = 15.0
+
+// AmountChange compares a symbol's newest distribution with the one before it
+type AmountChange struct {
+	Symbol        string    `json:"symbol"`
+	ExDate        time.Time `json:"exDate"`
+	Previous      float64   `json:"previous"`
+	Current       float64   `json:"current"`
+	ChangePercent float64   `json:"changePercent"`
+}
+
+// LatestAmountChange compares the newest announced event in a history sorted
+// newest first with the announced event before it. Estimated events are skipped.
+func LatestAmountChange(history *models.DividendHistory) (AmountChange, bool) {
+	var announced []models.DividendEvent
+	for _, event := range history.Events {
+		if !event.Estimated {
+			announced = append(announced, event)
+			if len(announced) == 2 {
+				break
+			}
+		}
+	}
+	if len(announced) < 2 || announced[1].Amount == 0 {
+		return AmountChange{}, false
+	}
+
+	latest, prior := announced[0], announced[1]
+	return AmountChange{
+		Symbol:        history.Symbol,
+		ExDate:        latest.ExDate,
+		Previous:      prior.Amount,
+		Current:       latest.Amount,
+		ChangePercent: (latest.Amount - prior.Amount) / prior.Amount * 100,
+	}, true
+}
+
+// AmountChangeWatcher notifies when a newly added event moves the amount by
+// more than ThresholdPct percent in either direction
+type AmountChangeWatcher struct {
+	Notifier     Notifier
+	ThresholdPct float64
+}
+
+// Check compares the freshly scraped history with the previously saved one
+// and notifies when its newest event is new and past the threshold. Without
+// a previous history nothing is known to be new, so nothing fires. It reports
+// whether a notification was sent.
+func (w *AmountChangeWatcher) Check(previous, current *models.DividendHistory) (bool, error) {
+	if previous == nil {
+		return false, nil
+	}
+	change, ok := LatestAmountChange(current)
+	if !ok {
+		return false, nil
+	}
+	if !change.ExDate.After(newestAnnouncedExDate(previous)) {
+		return false, nil
+	}
+	if math.Abs(change.ChangePercent) <= w.ThresholdPct {
+		return false, nil
+	}
+
+	direction := "raised"
+	if change.ChangePercent < 0 {
+		direction = "cut"
+	}
+	err := w.Notifier.Notify(Notification{
+		Kind:   KindAmountChange,
+		Symbol: change.Symbol,
+		Message: fmt.Sprintf("%s %s its distribution %.1f%% from $%.4f to $%.4f (ex-date %s)",
+			change.Symbol, direction, math.Abs(change.ChangePercent), change.Previous, change.Current, change.ExDate.Format("2006-01-02")),
+		Data:   change,
+		SentAt: time.Now(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to notify %s amount change: %w", change.Symbol, err)
+	}
+	return true, nil
+}
+
+// newestAnnouncedExDate returns the latest ex-date among non-estimated events
+func newestAnnouncedExDate(history *models.DividendHistory) time.Time {
+	var newest time.Time
+	for _, event := range history.Events {
+		if !event.Estimated && event.ExDate.After(newest) {
+			newest = event.ExDate
+		}
+	}
+	return newest
+}
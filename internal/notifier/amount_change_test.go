@@ -0,0 +1,123 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+func history(events ...models.DividendEvent) *models.DividendHistory {
+	return &models.DividendHistory{Symbol: "TSLY", Events: events}
+}
+
+func event(month time.Month, day int, amount float64) models.DividendEvent {
+	return models.DividendEvent{
+		Symbol: "TSLY",
+		ExDate: time.Date(2025, month, day, 0, 0, 0, 0, time.UTC),
+		Amount: amount,
+	}
+}
+
+func TestAmountChangeWatcherThreshold(t *testing.T) {
+	prior := event(5, 8, 0.40)
+	previous := history(prior)
+
+	tests := []struct {
+		name     string
+		previous *models.DividendHistory
+		current  *models.DividendHistory
+		fires    bool
+	}{
+		{name: "cut past threshold", previous: previous, current: history(event(6, 5, 0.30), prior), fires: true},
+		{name: "raise past threshold", previous: previous, current: history(event(6, 5, 0.50), prior), fires: true},
+		{name: "cut within threshold", previous: previous, current: history(event(6, 5, 0.36), prior)},
+		{name: "raise within threshold", previous: previous, current: history(event(6, 5, 0.44), prior)},
+		{name: "change equal to threshold", previous: previous, current: history(event(6, 5, 0.46), prior)},
+		{
+			// The cut was already saved by the last run
+			name:     "event not new",
+			previous: history(event(6, 5, 0.30), prior),
+			current:  history(event(6, 5, 0.30), prior),
+		},
+		{name: "no previous history", current: history(event(6, 5, 0.30), prior)},
+		{
+			name:     "estimated event ignored",
+			previous: previous,
+			current:  history(models.DividendEvent{Symbol: "TSLY", ExDate: time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC), Amount: 0.10, Estimated: true}, prior),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []Notification
+			w := &AmountChangeWatcher{
+				Notifier: Func(func(n Notification) error {
+					sent = append(sent, n)
+					return nil
+				}),
+				ThresholdPct: DefaultAmountChangeThreshold,
+			}
+
+			fired, err := w.Check(tt.previous, tt.current)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			want := 0
+			if tt.fires {
+				want = 1
+			}
+			if fired != tt.fires || len(sent) != want {
+				t.Fatalf("Check fired = %v with %d notifications, want %v", fired, len(sent), tt.fires)
+			}
+			if tt.fires && (sent[0].Kind != KindAmountChange || sent[0].Symbol != "TSLY") {
+				t.Errorf("notification = %+v, want a TSLY amount change", sent[0])
+			}
+		})
+	}
+}
+
+func TestLatestAmountChange(t *testing.T) {
+	change, ok := LatestAmountChange(history(event(6, 5, 0.30), event(5, 8, 0.40)))
+	if !ok {
+		t.Fatal("LatestAmountChange found no change")
+	}
+	if change.Previous != 0.40 || change.Current != 0.30 || change.ChangePercent > -24.99 || change.ChangePercent < -25.01 {
+		t.Errorf("change = %+v, want 0.40 → 0.30 (-25%%)", change)
+	}
+
+	if _, ok := LatestAmountChange(history(event(6, 5, 0.30))); ok {
+		t.Error("LatestAmountChange of a single event reported a change")
+	}
+}
+
+func TestWebhookPostsNotification(t *testing.T) {
+	var got Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request %s with %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode notification: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	if err := NewWebhook(server.URL).Notify(Notification{Kind: KindAmountChange, Symbol: "TSLY", Message: "cut"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got.Kind != KindAmountChange || got.Symbol != "TSLY" || got.Message != "cut" {
+		t.Errorf("webhook received %+v", got)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := NewWebhook(failing.URL).Notify(Notification{Kind: KindAmountChange}); err == nil {
+		t.Error("Notify succeeded against a failing webhook")
+	}
+}
@@ -0,0 +1,70 @@
+// Package notifier delivers alerts about dividend changes to external services.
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"divminder-crawler/internal/proxy"
+)
+
+// Notification is one alert sent to a Notifier
+type Notification struct {
+	Kind    string      `json:"kind"`
+	Symbol  string      `json:"symbol"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+	SentAt  time.Time   `json:"sentAt"`
+}
+
+// Notifier delivers notifications
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// Func adapts a function to the Notifier interface
+type Func func(n Notification) error
+
+// Notify calls f(n)
+func (f Func) Notify(n Notification) error {
+	return f(n)
+}
+
+// Webhook posts each notification as JSON to a URL
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook creates a notifier that posts to url
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url: url,
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: proxy.Transport(),
+		},
+	}
+}
+
+// Notify posts n, failing on any non-2xx response
+func (w *Webhook) Notify(n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// Package overrides loads hand-maintained per-symbol corrections and merges
+// them over scraped data. Precedence is override > scrape > default: a
+// non-empty override field always wins, and an empty one leaves whatever the
+// scraper or the tool's default produced.
+package overrides
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"divminder-crawler/internal/models"
+)
+
+// DefaultFile is where the overrides are kept
+const DefaultFile = "data/etf_correct_data.json"
+
+// Override holds the fields to force for one symbol; empty fields are ignored
+type Override struct {
+	Name        string `json:"name,omitempty"`
+	Group       string `json:"group,omitempty"`
+	Frequency   string `json:"frequency,omitempty"`
+	Description string `json:"description,omitempty"`
+	RenamedTo   string `json:"renamedTo,omitempty"` // Successor ticker when the fund changed symbol or merged
+	Source      string `json:"source,omitempty"`    // Preferred history source, e.g. "yieldmax" or "fmp"
+}
+
+// Overrides maps upper-case symbols to their overrides
+type Overrides map[string]Override
+
+// Load reads the overrides from path. A missing file is not an error and
+// yields no overrides.
+func Load(path string) (Overrides, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Overrides{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides %s: %w", path, err)
+	}
+
+	var raw map[string]Override
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse overrides %s: %w", path, err)
+	}
+
+	overrides := make(Overrides, len(raw))
+	for symbol, override := range raw {
+		overrides[strings.ToUpper(strings.TrimSpace(symbol))] = override
+	}
+	return overrides, nil
+}
+
+// Lookup returns the override for symbol, if any
+func (o Overrides) Lookup(symbol string) (Override, bool) {
+	override, ok := o[strings.ToUpper(symbol)]
+	return override, ok
+}
+
+// Aliases returns the old → new symbol map of funds that changed ticker
+func (o Overrides) Aliases() map[string]string {
+	aliases := make(map[string]string)
+	for symbol, override := range o {
+		if renamed := strings.ToUpper(strings.TrimSpace(override.RenamedTo)); renamed != "" && renamed != symbol {
+			aliases[symbol] = renamed
+		}
+	}
+	return aliases
+}
+
+// PreferredSources returns the symbols that name a preferred history source,
+// mapped to that source in lower case
+func (o Overrides) PreferredSources() map[string]string {
+	sources := make(map[string]string)
+	for symbol, override := range o {
+		if source := strings.ToLower(strings.TrimSpace(override.Source)); source != "" {
+			sources[symbol] = source
+		}
+	}
+	return sources
+}
+
+// ApplyETF merges the symbol's override into etf, reporting whether one applied
+func (o Overrides) ApplyETF(etf *models.ETF) bool {
+	override, ok := o.Lookup(etf.Symbol)
+	if !ok {
+		return false
+	}
+	mergeString(&etf.Name, override.Name)
+	mergeString(&etf.Group, override.Group)
+	mergeString(&etf.Frequency, override.Frequency)
+	mergeString(&etf.Description, override.Description)
+	return true
+}
+
+// ApplyETFs merges overrides into every ETF and returns how many matched
+func (o Overrides) ApplyETFs(etfs []models.ETF) int {
+	applied := 0
+	for i := range etfs {
+		if o.ApplyETF(&etfs[i]) {
+			applied++
+		}
+	}
+	return applied
+}
+
+// ApplyHistory merges the symbol's name, group and frequency overrides into
+// history, reporting whether one applied
+func (o Overrides) ApplyHistory(history *models.DividendHistory) bool {
+	override, ok := o.Lookup(history.Symbol)
+	if !ok {
+		return false
+	}
+	mergeString(&history.Name, override.Name)
+	mergeString(&history.Group, override.Group)
+	mergeString(&history.Frequency, override.Frequency)
+	return true
+}
+
+func mergeString(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
@@ -0,0 +1,113 @@
+package overrides
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"divminder-crawler/internal/models"
+)
+
+func writeOverrides(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "etf_correct_data.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	o, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("Load of a missing file: %v", err)
+	}
+	if len(o) != 0 {
+		t.Errorf("Load of a missing file = %v, want no overrides", o)
+	}
+
+	// No overrides leave scraped values alone
+	etf := models.ETF{Symbol: "TSLY", Name: "Scraped", Frequency: "monthly"}
+	if o.ApplyETF(&etf) || etf.Name != "Scraped" || etf.Frequency != "monthly" {
+		t.Errorf("ApplyETF without overrides changed %+v", etf)
+	}
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	if _, err := Load(writeOverrides(t, `{"TSLY": `)); err == nil {
+		t.Error("Load of invalid JSON succeeded")
+	}
+}
+
+func TestLoadNormalizesSymbols(t *testing.T) {
+	o, err := Load(writeOverrides(t, `{" tsly ": {"frequency": "weekly"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, symbol := range []string{"TSLY", "tsly"} {
+		if override, ok := o.Lookup(symbol); !ok || override.Frequency != "weekly" {
+			t.Errorf("Lookup(%q) = %+v, %v; want the weekly override", symbol, override, ok)
+		}
+	}
+}
+
+func TestApplyPrecedence(t *testing.T) {
+	o, err := Load(writeOverrides(t, `{
+		"TSLY": {"name": "YieldMax TSLA Option Income Strategy ETF", "frequency": "monthly"},
+		"ULTY": {"group": "Weekly"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		scraped models.ETF
+		want    models.ETF
+		applied bool
+	}{
+		{
+			// Set override fields win; empty ones keep the scrape
+			name:    "override over scrape",
+			scraped: models.ETF{Symbol: "TSLY", Name: "TSLY Weekly ETF", Group: "GroupA", Frequency: "weekly", Description: "Scraped"},
+			want:    models.ETF{Symbol: "TSLY", Name: "YieldMax TSLA Option Income Strategy ETF", Group: "GroupA", Frequency: "monthly", Description: "Scraped"},
+			applied: true,
+		},
+		{
+			// An override fills in what the scrape left to the default
+			name:    "override over default",
+			scraped: models.ETF{Symbol: "ULTY", Group: "Unknown"},
+			want:    models.ETF{Symbol: "ULTY", Group: "Weekly"},
+			applied: true,
+		},
+		{
+			name:    "no override",
+			scraped: models.ETF{Symbol: "MSTY", Name: "Scraped", Group: "GroupA", Frequency: "monthly"},
+			want:    models.ETF{Symbol: "MSTY", Name: "Scraped", Group: "GroupA", Frequency: "monthly"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			etf := tt.scraped
+			if applied := o.ApplyETF(&etf); applied != tt.applied {
+				t.Errorf("ApplyETF applied = %v, want %v", applied, tt.applied)
+			}
+			if etf != tt.want {
+				t.Errorf("ApplyETF = %+v, want %+v", etf, tt.want)
+			}
+
+			history := models.DividendHistory{Symbol: tt.scraped.Symbol, Name: tt.scraped.Name, Group: tt.scraped.Group, Frequency: tt.scraped.Frequency}
+			o.ApplyHistory(&history)
+			if history.Name != tt.want.Name || history.Group != tt.want.Group || history.Frequency != tt.want.Frequency {
+				t.Errorf("ApplyHistory = %s/%s/%s, want %s/%s/%s",
+					history.Name, history.Group, history.Frequency, tt.want.Name, tt.want.Group, tt.want.Frequency)
+			}
+		})
+	}
+
+	etfs := []models.ETF{{Symbol: "TSLY"}, {Symbol: "MSTY"}, {Symbol: "ULTY"}}
+	if applied := o.ApplyETFs(etfs); applied != 2 {
+		t.Errorf("ApplyETFs applied %d overrides, want 2", applied)
+	}
+}
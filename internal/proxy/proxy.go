@@ -0,0 +1,93 @@
+// Package proxy holds the process-wide outbound proxy shared by the scrapers
+// and API clients.
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+var (
+	mu      sync.RWMutex
+	current *url.URL
+)
+
+// Parse validates an http, https or socks5 proxy URL
+func Parse(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: scheme must be http, https or socks5", raw)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", raw)
+	}
+	return u, nil
+}
+
+// Configure sets the proxy used by clients created afterwards. An empty raw
+// leaves the standard HTTP_PROXY/HTTPS_PROXY environment handling in place,
+// after checking those variables parse.
+func Configure(raw string) error {
+	if raw == "" {
+		for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+			if value := os.Getenv(name); value != "" {
+				if _, err := Parse(value); err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+			}
+		}
+		Set(nil)
+		return nil
+	}
+
+	u, err := Parse(raw)
+	if err != nil {
+		return err
+	}
+	Set(u)
+	return nil
+}
+
+// Set replaces the configured proxy; nil falls back to the environment
+func Set(u *url.URL) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = u
+}
+
+// URL returns the explicitly configured proxy, or nil
+func URL() *url.URL {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Func returns a proxy function for the configured proxy, or nil when only
+// the environment applies (which Go's default transport already honors)
+func Func() func(*http.Request) (*url.URL, error) {
+	u := URL()
+	if u == nil {
+		return nil
+	}
+	return http.ProxyURL(u)
+}
+
+// Transport returns a transport routed through the configured proxy, or nil
+// to use http.DefaultTransport
+func Transport() http.RoundTripper {
+	fn := Func()
+	if fn == nil {
+		return nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = fn
+	return transport
+}
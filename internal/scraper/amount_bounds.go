@@ -0,0 +1,34 @@
+package scraper
+
+import (
+	"regexp"
+	"strings"
+)
+
+// negativeAmountPattern matches an amount written as a negative, either in
+// accounting parentheses or with a leading minus: "($0.05)", "-0.05", "$-0.05"
+var negativeAmountPattern = regexp.MustCompile(`^\$?\s*(\(\s*[-−]?\s*\$?\s*\d[^)]*\)|[-−–]\s*\$?\s*\d)`)
+
+// AmountBounds is the range of per-share distribution amounts a scraper
+// treats as plausible; values outside it are logged and dropped
+type AmountBounds struct {
+	Min float64
+	Max float64
+}
+
+// DefaultAmountBounds admits anything from a tenth of a cent up to special
+// distributions of $50
+func DefaultAmountBounds() AmountBounds {
+	return AmountBounds{Min: 0.001, Max: 50}
+}
+
+// Contains reports whether amount is within the bounds, inclusive
+func (b AmountBounds) Contains(amount float64) bool {
+	return amount >= b.Min && amount <= b.Max
+}
+
+// isNegativeAmount reports whether a cell holds a negative amount, such as a
+// distribution adjustment, which must never be read as a positive dividend
+func isNegativeAmount(str string) bool {
+	return negativeAmountPattern.MatchString(strings.TrimSpace(str))
+}
@@ -0,0 +1,166 @@
+package scraper
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestAmountBoundsContains(t *testing.T) {
+	custom := AmountBounds{Min: 0.05, Max: 5}
+	tests := []struct {
+		amount      float64
+		wantDefault bool
+		wantCustom  bool
+	}{
+		{0.0005, false, false},
+		{0.001, true, false},
+		{0.01, true, false},
+		{0.05, true, true},
+		{1.25, true, true},
+		{5, true, true},
+		{12.5, true, false},
+		{50, true, false},
+		{50.01, false, false},
+	}
+	for _, tt := range tests {
+		if got := DefaultAmountBounds().Contains(tt.amount); got != tt.wantDefault {
+			t.Errorf("default bounds contain %v = %v, want %v", tt.amount, got, tt.wantDefault)
+		}
+		if got := custom.Contains(tt.amount); got != tt.wantCustom {
+			t.Errorf("custom bounds contain %v = %v, want %v", tt.amount, got, tt.wantCustom)
+		}
+	}
+}
+
+func TestFullScraperAmountBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		bounds   AmountBounds
+		cell     string
+		want     float64
+		rejected bool
+	}{
+		{"special distribution within defaults", DefaultAmountBounds(), "$12.50", 12.5, false},
+		{"tiny amount within defaults", DefaultAmountBounds(), "$0.0042", 0.0042, false},
+		{"above default max", DefaultAmountBounds(), "$75.00", 0, true},
+		{"below custom min", AmountBounds{Min: 0.05, Max: 5}, "$0.0042", 0, true},
+		{"above custom max", AmountBounds{Min: 0.05, Max: 5}, "$12.50", 0, true},
+		{"cents within custom", AmountBounds{Min: 0.05, Max: 5}, "53", 0.53, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			s := NewYieldMaxFullScraper()
+			s.logger.SetOutput(&logs)
+			s.SetAmountBounds(tt.bounds)
+
+			if got := s.parseAmount(tt.cell); !almostEqual(got, tt.want) {
+				t.Errorf("parseAmount(%q) = %v, want %v", tt.cell, got, tt.want)
+			}
+			if logged := strings.Contains(logs.String(), "Rejected implausible amount"); logged != tt.rejected {
+				t.Errorf("rejection logged = %v, want %v; logs: %s", logged, tt.rejected, logs.String())
+			}
+		})
+	}
+}
+
+func TestETFDetailScraperAmountBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		bounds   AmountBounds
+		cell     string
+		want     float64
+		rejected bool
+	}{
+		{"special distribution within defaults", DefaultAmountBounds(), "$12.50", 12.5, false},
+		{"tiny amount within defaults", DefaultAmountBounds(), "$0.0042", 0.0042, false},
+		{"above default max", DefaultAmountBounds(), "$75.00", 0, true},
+		{"below custom min", AmountBounds{Min: 0.05, Max: 5}, "$0.0042", 0, true},
+		{"above custom max", AmountBounds{Min: 0.05, Max: 5}, "$12.50", 0, true},
+		{"not an amount", DefaultAmountBounds(), "TSLY", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			s := NewETFDetailScraper()
+			s.logger.SetOutput(&logs)
+			s.SetAmountBounds(tt.bounds)
+
+			if got := s.parseBoundedAmount(tt.cell); !almostEqual(got, tt.want) {
+				t.Errorf("parseBoundedAmount(%q) = %v, want %v", tt.cell, got, tt.want)
+			}
+			if logged := strings.Contains(logs.String(), "Rejected implausible amount"); logged != tt.rejected {
+				t.Errorf("rejection logged = %v, want %v; logs: %s", logged, tt.rejected, logs.String())
+			}
+		})
+	}
+}
+
+func TestNormalizeAmountColumnLogsRejections(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	events := []models.DividendEvent{
+		{Symbol: "TSLY", ExDate: marketDate(2025, 6, 5), Amount: 0.52},
+		{Symbol: "TSLY", ExDate: marketDate(2025, 5, 29), Amount: 7.5},
+	}
+	kept := normalizeAmountColumn(events, AmountBounds{Min: 0.01, Max: 5})
+
+	if len(kept) != 1 || kept[0].Amount != 0.52 {
+		t.Errorf("kept %+v, want only the 0.52 event", kept)
+	}
+	if !strings.Contains(logs.String(), "Rejected implausible amount 7.5 for TSLY 2025-05-29") {
+		t.Errorf("rejection not logged; logs: %s", logs.String())
+	}
+}
+
+func TestNegativeAmountsSkipped(t *testing.T) {
+	tests := []struct {
+		cell     string
+		negative bool
+		want     float64
+	}{
+		{"($0.05)", true, 0},
+		{"(0.05)", true, 0},
+		{"-0.05", true, 0},
+		{"$-0.05", true, 0},
+		{"−$0.05", true, 0},
+		{"$0.53", false, 0.53},
+		{" 0.4512 ", false, 0.4512},
+	}
+
+	table := NewDividendTableScraper()
+	full := NewYieldMaxFullScraper()
+	full.logger.SetOutput(&bytes.Buffer{})
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	for _, tt := range tests {
+		t.Run(tt.cell, func(t *testing.T) {
+			if got := isNegativeAmount(tt.cell); got != tt.negative {
+				t.Errorf("isNegativeAmount(%q) = %v, want %v", tt.cell, got, tt.negative)
+			}
+			if got := table.parseAmount(tt.cell); !almostEqual(got, tt.want) {
+				t.Errorf("DividendTableScraper.parseAmount(%q) = %v, want %v", tt.cell, got, tt.want)
+			}
+			if got := full.parseAmount(tt.cell); !almostEqual(got, tt.want) {
+				t.Errorf("YieldMaxFullScraper.parseAmount(%q) = %v, want %v", tt.cell, got, tt.want)
+			}
+			got, err := parseAmount(tt.cell)
+			if tt.negative {
+				if err == nil {
+					t.Errorf("parseAmount(%q) = %v, want an error", tt.cell, got)
+				}
+			} else if err != nil || !almostEqual(got, tt.want) {
+				t.Errorf("parseAmount(%q) = %v, %v; want %v", tt.cell, got, err, tt.want)
+			}
+		})
+	}
+}
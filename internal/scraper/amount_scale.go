@@ -0,0 +1,65 @@
+package scraper
+
+import (
+	"log"
+	"math"
+	"sort"
+
+	"divminder-crawler/internal/models"
+)
+
+// Bounds used when deciding a whole amount column's scale. YieldMax
+// distributions run from a few cents to a few dollars, so a column of whole
+// numbers with a median in the cents range is quoted in cents.
+const (
+	minCentsMedian = 10.0
+	maxCentsAmount = 1000.0
+)
+
+// isCentsColumn reports whether a column of parsed amounts is quoted in
+// cents: every value is a whole number and the median is in the cents range
+func isCentsColumn(amounts []float64) bool {
+	if len(amounts) == 0 {
+		return false
+	}
+	for _, amount := range amounts {
+		if amount != math.Trunc(amount) {
+			return false
+		}
+	}
+
+	sorted := make([]float64, len(amounts))
+	copy(sorted, amounts)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + median) / 2
+	}
+	return median >= minCentsMedian && median < maxCentsAmount
+}
+
+// normalizeAmountColumn converts a table's events to dollars, deciding
+// cents-vs-dollars once for the whole column so one table never mixes
+// scales, and drops events whose amount falls outside bounds
+func normalizeAmountColumn(events []models.DividendEvent, bounds AmountBounds) []models.DividendEvent {
+	amounts := make([]float64, len(events))
+	for i, event := range events {
+		amounts[i] = event.Amount
+	}
+	cents := isCentsColumn(amounts)
+
+	kept := events[:0]
+	for _, event := range events {
+		if cents {
+			event.Amount /= 100
+		}
+		event.Amount = models.RoundAmount(event.Amount)
+		if !bounds.Contains(event.Amount) {
+			log.Printf("Rejected implausible amount %v for %s %s (allowed %v-%v)",
+				event.Amount, event.Symbol, event.ExDate.Format("2006-01-02"), bounds.Min, bounds.Max)
+			continue
+		}
+		kept = append(kept, event)
+	}
+	return kept
+}
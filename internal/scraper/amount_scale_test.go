@@ -0,0 +1,76 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestNormalizeAmountColumn(t *testing.T) {
+	tests := []struct {
+		name    string
+		amounts []float64
+		want    []float64
+	}{
+		{"all cents", []float64{45, 52, 38, 61}, []float64{0.45, 0.52, 0.38, 0.61}},
+		{"all dollars", []float64{0.4512, 0.52, 0.3801, 0.61}, []float64{0.4512, 0.52, 0.3801, 0.61}},
+		{"whole dollars", []float64{1, 2, 1}, []float64{1, 2, 1}},
+		{"cents with one special distribution", []float64{30, 32, 450, 31}, []float64{0.30, 0.32, 4.50, 0.31}},
+		{"one fractional value keeps dollars", []float64{45, 0.52, 38}, []float64{45, 0.52, 38}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := make([]models.DividendEvent, len(tt.amounts))
+			for i, amount := range tt.amounts {
+				events[i] = models.DividendEvent{Symbol: "TEST", ExDate: marketDate(2025, 6, 5).AddDate(0, 0, -7*i), Amount: amount}
+			}
+
+			got := normalizeAmountColumn(events, DefaultAmountBounds())
+			amounts := make([]float64, len(got))
+			for i, event := range got {
+				amounts[i] = event.Amount
+			}
+			if !reflect.DeepEqual(amounts, tt.want) {
+				t.Errorf("normalized %v to %v, want %v", tt.amounts, amounts, tt.want)
+			}
+		})
+	}
+}
+
+func TestDividendTableScraperNormalizesCentsTable(t *testing.T) {
+	rows := []string{"47", "52", "38", "50"}
+	var body strings.Builder
+	body.WriteString(`<html><body><h1>YieldMax TSLY Option Income Strategy ETF</h1>
+<table class="wpDataTable"><thead><tr><th>Ticker</th><th>Distribution per Share (cents)</th><th>Declared Date</th><th>Ex Date</th><th>Record Date</th><th>Payable Date</th></tr></thead><tbody>`)
+	for i, cents := range rows {
+		day := 5 + 7*i
+		fmt.Fprintf(&body, "<tr><td>TSLY</td><td>%s</td><td>05/%02d/2025</td><td>05/%02d/2025</td><td>05/%02d/2025</td><td>05/%02d/2025</td></tr>", cents, day-1, day, day, day+1)
+	}
+	body.WriteString(`</tbody></table></body></html>`)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body.String()))
+	}))
+	defer server.Close()
+
+	history, err := newTestTableScraper(t, server.URL, nil).ScrapeDividendHistory("TSLY")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Events come back newest first
+	want := []float64{0.50, 0.38, 0.52, 0.47}
+	var got []float64
+	for _, event := range history.Events {
+		got = append(got, event.Amount)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("amounts = %v, want the cents column in dollars %v", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+package scraper
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+// AnnouncedDistribution is a per-share amount YieldMax has declared for an upcoming ex-date
+type AnnouncedDistribution struct {
+	Symbol string
+	ExDate time.Time
+	Amount float64
+}
+
+var announcedAmountPattern = regexp.MustCompile(`\$\s*(\d+\.\d+)`)
+
+// parseAnnouncementRow reads a table row holding a known symbol, an ex-date
+// and a dollar amount, in any column order. The first parseable date is taken
+// as the ex-date, matching the column order of the schedule tables.
+func parseAnnouncementRow(cells []string, knownSymbols map[string]string, parseDate func(string) time.Time) (AnnouncedDistribution, bool) {
+	var announced AnnouncedDistribution
+
+	for _, cell := range cells {
+		cell = strings.TrimSpace(cell)
+
+		if announced.Symbol == "" {
+			if _, known := knownSymbols[strings.ToUpper(cell)]; known {
+				announced.Symbol = strings.ToUpper(cell)
+				continue
+			}
+		}
+		if announced.Amount == 0 && !isNegativeAmount(cell) {
+			if matches := announcedAmountPattern.FindStringSubmatch(cell); matches != nil {
+				if amount, err := strconv.ParseFloat(matches[1], 64); err == nil && amount > 0 {
+					announced.Amount = models.RoundAmount(amount)
+					continue
+				}
+			}
+		}
+		if announced.ExDate.IsZero() {
+			announced.ExDate = parseDate(cell)
+		}
+	}
+
+	ok := announced.Symbol != "" && announced.Amount > 0 && !announced.ExDate.IsZero()
+	return announced, ok
+}
+
+// applyAnnouncedAmounts replaces the amount of each event matching an
+// announcement by symbol and ex-date, marking it no longer estimated.
+// It returns how many events were updated.
+func applyAnnouncedAmounts(events []models.DividendEvent, announced []AnnouncedDistribution) int {
+	if len(announced) == 0 {
+		return 0
+	}
+
+	amounts := make(map[string]float64, len(announced))
+	for _, a := range announced {
+		amounts[announcementKey(a.Symbol, a.ExDate)] = a.Amount
+	}
+
+	updated := 0
+	for i := range events {
+		amount, ok := amounts[announcementKey(events[i].Symbol, events[i].ExDate)]
+		if !ok {
+			continue
+		}
+		events[i].Amount = amount
+		events[i].Estimated = false
+		events[i].Source = models.SourceYieldMax
+		updated++
+	}
+	return updated
+}
+
+func announcementKey(symbol string, exDate time.Time) string {
+	return symbol + "|" + models.CalendarDate(exDate).Format("2006-01-02")
+}
@@ -0,0 +1,75 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestParseAnnouncementRow(t *testing.T) {
+	known := map[string]string{"CONY": "GroupC", "ULTY": "Weekly"}
+	parse := func(s string) time.Time {
+		d, _ := ParseFlexibleDateAt(s, time.Date(2025, 6, 10, 0, 0, 0, 0, models.MarketLocation))
+		return d
+	}
+
+	tests := []struct {
+		name  string
+		cells []string
+		want  AnnouncedDistribution
+		ok    bool
+	}{
+		{"symbol first", []string{"CONY", "06/12/2025", "06/13/2025", "$0.8063"},
+			AnnouncedDistribution{"CONY", marketDate(2025, 6, 12), 0.8063}, true},
+		{"amount first", []string{"$ 0.0948", "ulty", "Jun 12, 2025"},
+			AnnouncedDistribution{"ULTY", marketDate(2025, 6, 12), 0.0948}, true},
+		{"unknown symbol", []string{"NOPE", "06/12/2025", "$0.50"}, AnnouncedDistribution{}, false},
+		{"no amount", []string{"CONY", "06/12/2025", "TBD"}, AnnouncedDistribution{}, false},
+		{"negative adjustment", []string{"CONY", "06/12/2025", "-$0.10"}, AnnouncedDistribution{}, false},
+		{"no date", []string{"CONY", "$0.80"}, AnnouncedDistribution{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseAnnouncementRow(tt.cells, known, parse)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v (parsed %+v)", ok, tt.ok, got)
+			}
+			if ok && (got.Symbol != tt.want.Symbol || !got.ExDate.Equal(tt.want.ExDate) || got.Amount != tt.want.Amount) {
+				t.Errorf("parsed %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnouncedAmountsOverrideSyntheticOnes(t *testing.T) {
+	events := []models.DividendEvent{
+		{Symbol: "CONY", ExDate: marketDate(2025, 6, 12), Amount: 0.15, Estimated: true, Source: models.SourceSynthetic},
+		{Symbol: "CONY", ExDate: marketDate(2025, 6, 19), Amount: 0.17, Estimated: true, Source: models.SourceSynthetic},
+		{Symbol: "ULTY", ExDate: time.Date(2025, 6, 12, 0, 0, 0, 0, time.UTC), Amount: 0.19, Estimated: true, Source: models.SourceSynthetic},
+	}
+	announced := []AnnouncedDistribution{
+		{Symbol: "CONY", ExDate: marketDate(2025, 6, 12), Amount: 0.8063},
+		{Symbol: "ULTY", ExDate: marketDate(2025, 6, 12), Amount: 0.0948},
+		{Symbol: "MSTY", ExDate: marketDate(2025, 6, 12), Amount: 1.2},
+	}
+
+	if updated := applyAnnouncedAmounts(events, announced); updated != 2 {
+		t.Errorf("updated %d events, want 2", updated)
+	}
+
+	want := []struct {
+		amount    float64
+		estimated bool
+	}{{0.8063, false}, {0.17, true}, {0.0948, false}}
+	for i, w := range want {
+		if events[i].Amount != w.amount || events[i].Estimated != w.estimated {
+			t.Errorf("event %d (%s %s) = %v estimated %v, want %v estimated %v", i, events[i].Symbol,
+				events[i].ExDate.Format("2006-01-02"), events[i].Amount, events[i].Estimated, w.amount, w.estimated)
+		}
+	}
+	if events[0].Source != models.SourceYieldMax || events[1].Source != models.SourceSynthetic {
+		t.Errorf("sources = %s/%s, want the announced event from yieldmax and the other still synthetic", events[0].Source, events[1].Source)
+	}
+}
@@ -0,0 +1,25 @@
+package scraper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultBaseURL is the YieldMax site the scrapers read from unless pointed
+// elsewhere with SetBaseURL, e.g. at a local server replaying saved pages
+const DefaultBaseURL = "https://www.yieldmaxetfs.com"
+
+// etfPageURL returns the fund page for symbol under baseURL
+func etfPageURL(baseURL, symbol string) string {
+	return fmt.Sprintf("%s/our-etfs/%s/", strings.TrimRight(baseURL, "/"), strings.ToLower(symbol))
+}
+
+// etfListingURL returns the page under baseURL that links to every current fund
+func etfListingURL(baseURL string) string {
+	return strings.TrimRight(baseURL, "/") + "/our-etfs/"
+}
+
+// schedulePageURL returns the distribution schedule page under baseURL
+func schedulePageURL(baseURL string) string {
+	return strings.TrimRight(baseURL, "/") + "/distribution-schedule/"
+}
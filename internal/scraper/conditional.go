@@ -0,0 +1,71 @@
+package scraper
+
+import (
+	"net/http"
+	"time"
+
+	"divminder-crawler/internal/cache"
+	"divminder-crawler/internal/models"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// DefaultConditionalCacheDir is where page validators and parsed histories are kept
+const DefaultConditionalCacheDir = "cache/http"
+
+// DefaultConditionalCacheTTL is how long a page's validators are reused before a full fetch
+const DefaultConditionalCacheTTL = 7 * 24 * time.Hour
+
+// conditionalEntry is a page's validators and the history parsed from it
+type conditionalEntry struct {
+	ETag         string                  `json:"etag,omitempty"`
+	LastModified string                  `json:"lastModified,omitempty"`
+	History      *models.DividendHistory `json:"history"`
+}
+
+// ConditionalCache stores the ETag and Last-Modified of each fetched page with
+// its parse result, so unchanged pages can be answered with a 304
+type ConditionalCache struct {
+	cache *cache.FileCache
+}
+
+// NewConditionalCache creates a conditional GET cache in dir
+func NewConditionalCache(dir string, ttl time.Duration) *ConditionalCache {
+	return &ConditionalCache{cache: cache.NewFileCache(dir, ttl)}
+}
+
+// lookup returns the cached entry for url, if any
+func (c *ConditionalCache) lookup(url string) *conditionalEntry {
+	var entry conditionalEntry
+	found, err := c.cache.Get(c.cache.Key("page", url), &entry)
+	if err != nil || !found || entry.History == nil {
+		return nil
+	}
+	return &entry
+}
+
+// store saves url's validators with its parse result; pages without validators are skipped
+func (c *ConditionalCache) store(url string, entry conditionalEntry) error {
+	if entry.ETag == "" && entry.LastModified == "" {
+		return nil
+	}
+	return c.cache.Set(c.cache.Key("page", url), entry)
+}
+
+// setConditionalHeaders adds If-None-Match/If-Modified-Since for a cached entry
+func setConditionalHeaders(r *colly.Request, entry *conditionalEntry) {
+	if entry == nil {
+		return
+	}
+	if entry.ETag != "" {
+		r.Headers.Set("If-None-Match", entry.ETag)
+	}
+	if entry.LastModified != "" {
+		r.Headers.Set("If-Modified-Since", entry.LastModified)
+	}
+}
+
+// isNotModifiedVisitError reports whether a colly Visit error came from a 304 response
+func isNotModifiedVisitError(err error) bool {
+	return err != nil && err.Error() == http.StatusText(http.StatusNotModified)
+}
@@ -0,0 +1,148 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/clock"
+)
+
+// newTestTableScraper returns a dividend table scraper reading from baseURL
+// without delays, with its conditional cache in a temp dir
+func newTestTableScraper(t *testing.T, baseURL string, conditional *ConditionalCache) *DividendTableScraper {
+	t.Helper()
+	s := NewDividendTableScraper().WithLimitRule(1, 0)
+	s.SetBaseURL(baseURL)
+	s.SetConditionalCache(conditional)
+	s.SetClock(clock.NewFake(time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)))
+	return s
+}
+
+func TestConditionalGetReusesCachedHistory(t *testing.T) {
+	page, err := os.ReadFile(filepath.Join("testdata", "dividend_page_ulty.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const etag = `"ulty-v1"`
+	var fullFetches, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fullFetches.Add(1)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	}))
+	defer server.Close()
+
+	conditional := NewConditionalCache(t.TempDir(), time.Hour)
+
+	// Each run uses a new scraper, as the crawler does, sharing only the cache
+	first, err := newTestTableScraper(t, server.URL, conditional).ScrapeDividendHistory("ULTY")
+	if err != nil {
+		t.Fatalf("first scrape: %v", err)
+	}
+	if len(first.Events) != 4 {
+		t.Fatalf("first scrape parsed %d events, want 4", len(first.Events))
+	}
+
+	second, err := newTestTableScraper(t, server.URL, conditional).ScrapeDividendHistory("ULTY")
+	if err != nil {
+		t.Fatalf("second scrape: %v", err)
+	}
+
+	if fullFetches.Load() != 1 || notModified.Load() != 1 {
+		t.Errorf("server saw %d full fetches and %d 304s, want 1 and 1", fullFetches.Load(), notModified.Load())
+	}
+	if len(second.Events) != len(first.Events) {
+		t.Fatalf("304 reused %d events, want %d", len(second.Events), len(first.Events))
+	}
+	for i := range first.Events {
+		if !second.Events[i].ExDate.Equal(first.Events[i].ExDate) || second.Events[i].Amount != first.Events[i].Amount {
+			t.Errorf("event %d = %+v, want %+v", i, second.Events[i], first.Events[i])
+		}
+	}
+	if second.Frequency != first.Frequency || second.Stats.TotalPayments != first.Stats.TotalPayments {
+		t.Errorf("304 reused %s/%d payments, want %s/%d", second.Frequency, second.Stats.TotalPayments, first.Frequency, first.Stats.TotalPayments)
+	}
+}
+
+func TestConditionalGetWithoutValidatorsFetchesFullPage(t *testing.T) {
+	page, err := os.ReadFile(filepath.Join("testdata", "dividend_page_ulty.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var conditionalRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			conditionalRequests.Add(1)
+		}
+		w.Write(page)
+	}))
+	defer server.Close()
+
+	conditional := NewConditionalCache(t.TempDir(), time.Hour)
+	for run := 0; run < 2; run++ {
+		if _, err := newTestTableScraper(t, server.URL, conditional).ScrapeDividendHistory("ULTY"); err != nil {
+			t.Fatalf("run %d: %v", run, err)
+		}
+	}
+	if n := conditionalRequests.Load(); n != 0 {
+		t.Errorf("sent %d conditional requests for a page without validators, want 0", n)
+	}
+}
+
+func TestBypassCacheFetchesFullPage(t *testing.T) {
+	page, err := os.ReadFile(filepath.Join("testdata", "dividend_page_ulty.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const etag = `"ulty-v1"`
+	var fullFetches, conditionalRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			conditionalRequests.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fullFetches.Add(1)
+		w.Header().Set("ETag", etag)
+		w.Write(page)
+	}))
+	defer server.Close()
+
+	conditional := NewConditionalCache(t.TempDir(), time.Hour)
+	if _, err := newTestTableScraper(t, server.URL, conditional).ScrapeDividendHistory("ULTY"); err != nil {
+		t.Fatalf("first scrape: %v", err)
+	}
+
+	// A refresh run ignores the fresh cached validators
+	refresh := newTestTableScraper(t, server.URL, conditional)
+	refresh.SetBypassCache(true)
+	history, err := refresh.ScrapeDividendHistory("ULTY")
+	if err != nil {
+		t.Fatalf("refresh scrape: %v", err)
+	}
+	if fullFetches.Load() != 2 || conditionalRequests.Load() != 0 {
+		t.Errorf("server saw %d full fetches and %d conditional requests, want 2 and 0", fullFetches.Load(), conditionalRequests.Load())
+	}
+	if len(history.Events) != 4 {
+		t.Errorf("refresh parsed %d events, want 4", len(history.Events))
+	}
+
+	// It still stored the validators for the next normal run
+	if entry := conditional.lookup(etfPageURL(server.URL, "ULTY")); entry == nil || entry.ETag != etag {
+		t.Errorf("cached entry after refresh = %+v, want ETag %s", entry, etag)
+	}
+}
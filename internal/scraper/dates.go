@@ -0,0 +1,68 @@
+package scraper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+// MinDividendYear is the earliest year a scraped dividend date may fall in
+const MinDividendYear = 2020
+
+// flexibleDateFormats lists every date layout seen in YieldMax tables
+var flexibleDateFormats = []string{
+	"01/02/2006",      // 01/02/2025
+	"1/2/2006",        // 1/2/2025
+	"01/02/06",        // 01/02/25
+	"1/2/06",          // 1/2/25
+	"2006-01-02",      // 2025-01-02
+	"Jan 2, 2006",     // Jan 2, 2025
+	"January 2, 2006", // January 2, 2025
+	"02-Jan-2006",     // 02-Jan-2025
+	"02-January-2006", // 02-January-2025
+}
+
+var whitespacePattern = regexp.MustCompile(`\s+`)
+
+// ParseFlexibleDate parses a dividend date in any of the known YieldMax
+// formats as a US/Eastern calendar date, rejecting years outside
+// MinDividendYear..now+1
+func ParseFlexibleDate(str string) (time.Time, error) {
+	return ParseFlexibleDateAt(str, time.Now())
+}
+
+// ParseFlexibleDateAt is ParseFlexibleDate with the year sanity check relative to now
+func ParseFlexibleDateAt(str string, now time.Time) (time.Time, error) {
+	t, err := parseFlexibleFormats(str)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if t.Year() < MinDividendYear || t.Year() > now.Year()+1 {
+		return time.Time{}, fmt.Errorf("date %q is outside %d..%d", str, MinDividendYear, now.Year()+1)
+	}
+	return t, nil
+}
+
+// parseFlexibleFormats parses str in any of the known YieldMax formats as a
+// US/Eastern calendar date, without the dividend year sanity check, for
+// dates such as a fund's inception that may predate MinDividendYear
+func parseFlexibleFormats(str string) (time.Time, error) {
+	str = whitespacePattern.ReplaceAllString(strings.TrimSpace(str), " ")
+
+	for _, format := range flexibleDateFormats {
+		if t, err := models.ParseMarketDate(format, str); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("could not parse date: %s", str)
+}
+
+// flexibleDate is ParseFlexibleDate returning the zero time on failure
+func flexibleDate(str string) time.Time {
+	t, _ := ParseFlexibleDate(str)
+	return t
+}
@@ -0,0 +1,68 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFlexibleDate(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		// Every layout any of the former parsers accepted
+		{in: "01/02/2025", want: marketDate(2025, 1, 2)},
+		{in: "1/2/2025", want: marketDate(2025, 1, 2)},
+		{in: "12/31/2024", want: marketDate(2024, 12, 31)},
+		{in: "01/02/25", want: marketDate(2025, 1, 2)},
+		{in: "1/2/25", want: marketDate(2025, 1, 2)},
+		{in: "2025-01-02", want: marketDate(2025, 1, 2)},
+		{in: "Jan 2, 2025", want: marketDate(2025, 1, 2)},
+		{in: "January 2, 2025", want: marketDate(2025, 1, 2)},
+		{in: "02-Jan-2025", want: marketDate(2025, 1, 2)},
+		{in: "02-January-2025", want: marketDate(2025, 1, 2)},
+
+		// Cell whitespace from page builders
+		{in: "  06/12/2025\n", want: marketDate(2025, 6, 12)},
+		{in: "Jun   12,\t2025", want: marketDate(2025, 6, 12)},
+
+		// Year sanity window is MinDividendYear..now+1
+		{in: "01/02/2020", want: marketDate(2020, 1, 2)},
+		{in: "12/31/2026", want: marketDate(2026, 12, 31)},
+		{in: "12/31/2019", wantErr: true},
+		{in: "01/02/2027", wantErr: true},
+		{in: "01/02/19", wantErr: true},
+
+		// Not dates
+		{in: "", wantErr: true},
+		{in: "TBD", wantErr: true},
+		{in: "$0.53", wantErr: true},
+		{in: "13/01/2025", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFlexibleDateAt(tt.in, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseFlexibleDateAt(%q) = %s, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseFlexibleDateAt(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) || got.Location() != tt.want.Location() {
+			t.Errorf("ParseFlexibleDateAt(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFlexibleDateReturnsZeroOnFailure(t *testing.T) {
+	if got := flexibleDate("not a date"); !got.IsZero() {
+		t.Errorf("flexibleDate = %s, want the zero time", got)
+	}
+}
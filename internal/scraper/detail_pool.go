@@ -0,0 +1,70 @@
+package scraper
+
+import (
+	"sync"
+
+	"divminder-crawler/internal/models"
+)
+
+// DefaultDetailConcurrency is the number of ETF detail pages scraped at once
+const DefaultDetailConcurrency = 3
+
+// DetailScraper fetches one ETF's detail page, as ETFDetailScraper and
+// YieldMaxFullScraper do
+type DetailScraper interface {
+	GetETFDetail(symbol string) (*models.ETFDetail, error)
+}
+
+// Waiter is a rate limiter shared by all workers, such as api.RateLimiter
+type Waiter interface {
+	Wait()
+}
+
+// DetailResult is the outcome of scraping one symbol's detail page
+type DetailResult struct {
+	Symbol string
+	Detail *models.ETFDetail
+	Err    error
+}
+
+// ScrapeDetails scrapes the given symbols with a bounded pool of workers. Each
+// worker gets its own scraper from newScraper and acquires a token from the
+// shared limiter before every request. Results are delivered as they complete.
+func ScrapeDetails(symbols []string, concurrency int, limiter Waiter, newScraper func() DetailScraper) <-chan DetailResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan string, len(symbols))
+	results := make(chan DetailResult, len(symbols))
+
+	// Start workers
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			s := newScraper()
+			for symbol := range jobs {
+				limiter.Wait()
+				detail, err := s.GetETFDetail(symbol)
+				results <- DetailResult{Symbol: symbol, Detail: detail, Err: err}
+			}
+		}()
+	}
+
+	// Queue jobs
+	for _, symbol := range symbols {
+		jobs <- symbol
+	}
+	close(jobs)
+
+	// Close results after workers done
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
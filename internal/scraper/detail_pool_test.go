@@ -0,0 +1,100 @@
+package scraper
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+// stubDetailScraper returns canned details and records how many calls overlap
+type stubDetailScraper struct {
+	inFlight    *int32
+	maxInFlight *int32
+	fail        map[string]bool
+}
+
+func (s stubDetailScraper) GetETFDetail(symbol string) (*models.ETFDetail, error) {
+	n := atomic.AddInt32(s.inFlight, 1)
+	defer atomic.AddInt32(s.inFlight, -1)
+	for {
+		max := atomic.LoadInt32(s.maxInFlight)
+		if n <= max || atomic.CompareAndSwapInt32(s.maxInFlight, max, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if s.fail[symbol] {
+		return nil, errors.New("page not found")
+	}
+	return &models.ETFDetail{Symbol: symbol}, nil
+}
+
+// countingWaiter counts the tokens taken from it
+type countingWaiter struct {
+	mu    sync.Mutex
+	waits int
+}
+
+func (w *countingWaiter) Wait() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.waits++
+}
+
+func TestScrapeDetails(t *testing.T) {
+	symbols := []string{"AMZY", "CONY", "MSTY", "NVDY", "TSLY", "ULTY", "YMAX"}
+	var inFlight, maxInFlight, scrapers int32
+	limiter := &countingWaiter{}
+
+	results := ScrapeDetails(symbols, 3, limiter, func() DetailScraper {
+		atomic.AddInt32(&scrapers, 1)
+		return stubDetailScraper{inFlight: &inFlight, maxInFlight: &maxInFlight, fail: map[string]bool{"MSTY": true}}
+	})
+
+	var succeeded, failed []string
+	for result := range results {
+		switch {
+		case result.Err != nil:
+			failed = append(failed, result.Symbol)
+		case result.Detail == nil || result.Detail.Symbol != result.Symbol:
+			t.Errorf("result for %s has detail %+v", result.Symbol, result.Detail)
+		default:
+			succeeded = append(succeeded, result.Symbol)
+		}
+	}
+	sort.Strings(succeeded)
+
+	if len(succeeded) != len(symbols)-1 || len(failed) != 1 || failed[0] != "MSTY" {
+		t.Errorf("succeeded %v, failed %v; want every symbol but MSTY to succeed", succeeded, failed)
+	}
+	if scrapers != 3 {
+		t.Errorf("created %d scrapers, want one per worker", scrapers)
+	}
+	if maxInFlight > 3 {
+		t.Errorf("%d scrapes ran at once, want at most 3", maxInFlight)
+	}
+	if limiter.waits != len(symbols) {
+		t.Errorf("limiter waited %d times, want once per symbol", limiter.waits)
+	}
+}
+
+func TestScrapeDetailsClampsConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	results := ScrapeDetails([]string{"CONY", "ULTY"}, 0, &countingWaiter{}, func() DetailScraper {
+		return stubDetailScraper{inFlight: &inFlight, maxInFlight: &maxInFlight}
+	})
+
+	count := 0
+	for range results {
+		count++
+	}
+	if count != 2 || maxInFlight != 1 {
+		t.Errorf("got %d results with %d at once, want 2 scraped one at a time", count, maxInFlight)
+	}
+}
@@ -0,0 +1,106 @@
+package scraper
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var etfLinkPattern = regexp.MustCompile(`/our-etfs/([a-zA-Z]{2,6})/?$`)
+
+// SymbolFromETFLink extracts the ticker from a fund detail link, or "" if
+// the link does not point at a single fund page
+func SymbolFromETFLink(href string) string {
+	href = strings.TrimSpace(href)
+	if i := strings.IndexAny(href, "?#"); i >= 0 {
+		href = href[:i]
+	}
+	match := etfLinkPattern.FindStringSubmatch(href)
+	if match == nil {
+		return ""
+	}
+	return strings.ToUpper(match[1])
+}
+
+// DiscoverSymbols scrapes the ETF listing page for all current tickers and
+// logs symbols that are new or missing compared to GetYieldMaxETFGroups
+func DiscoverSymbols() ([]string, error) {
+	return DiscoverSymbolsFrom(DefaultBaseURL)
+}
+
+// DiscoverSymbolsFrom is DiscoverSymbols reading the listing under baseURL
+func DiscoverSymbolsFrom(baseURL string) ([]string, error) {
+	listingURL := etfListingURL(baseURL)
+	logger := logrus.New()
+	logger.SetLevel(logrus.InfoLevel)
+
+	c := colly.NewCollector()
+	c.SetRequestTimeout(30 * time.Second)
+	applyProxy(c)
+	observeRequests(c)
+	throttleRequests(c)
+
+	seen := make(map[string]bool)
+	c.OnHTML("a[href]", func(e *colly.HTMLElement) {
+		if symbol := SymbolFromETFLink(e.Attr("href")); symbol != "" {
+			seen[symbol] = true
+		}
+	})
+
+	var visitErr error
+	c.OnError(func(r *colly.Response, err error) {
+		visitErr = fmt.Errorf("ETF listing returned status %d: %w", r.StatusCode, err)
+	})
+
+	if err := c.Visit(listingURL); err != nil {
+		return nil, fmt.Errorf("failed to visit ETF listing: %w", err)
+	}
+	if visitErr != nil {
+		return nil, visitErr
+	}
+	if len(seen) == 0 {
+		return nil, fmt.Errorf("no ETF links found on %s", listingURL)
+	}
+
+	symbols := make([]string, 0, len(seen))
+	for symbol := range seen {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	added, missing := DiffSymbols(symbols, GetYieldMaxETFGroups())
+	if len(added) > 0 {
+		logger.Warnf("Discovered %d symbols not in the ETF group map: %s", len(added), strings.Join(added, ", "))
+	}
+	if len(missing) > 0 {
+		logger.Warnf("%d known symbols missing from the ETF listing: %s", len(missing), strings.Join(missing, ", "))
+	}
+	logger.Infof("Discovered %d symbols on the ETF listing", len(symbols))
+
+	return symbols, nil
+}
+
+// DiffSymbols returns discovered symbols absent from known, and known
+// symbols absent from discovered, both sorted
+func DiffSymbols(discovered []string, known map[string]string) (added, missing []string) {
+	found := make(map[string]bool, len(discovered))
+	for _, symbol := range discovered {
+		found[symbol] = true
+		if _, ok := known[symbol]; !ok {
+			added = append(added, symbol)
+		}
+	}
+	for symbol := range known {
+		if !found[symbol] {
+			missing = append(missing, symbol)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(missing)
+	return added, missing
+}
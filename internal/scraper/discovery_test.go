@@ -0,0 +1,90 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverSymbolsFromListingPage(t *testing.T) {
+	var paths []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/our-etfs/", func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		http.ServeFile(w, r, "testdata/etf_listing.html")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	symbols, err := DiscoverSymbolsFrom(server.URL + "/")
+	if err != nil {
+		t.Fatalf("DiscoverSymbolsFrom: %v", err)
+	}
+
+	want := []string{"MSTY", "NEWY", "NVDY", "TSLY", "ULTY"}
+	if !reflect.DeepEqual(symbols, want) {
+		t.Errorf("discovered %v, want %v", symbols, want)
+	}
+	if !reflect.DeepEqual(paths, []string{"/our-etfs/"}) {
+		t.Errorf("requested %v, want only the listing page", paths)
+	}
+}
+
+func TestDiscoverSymbolsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"server error", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}},
+		{"no fund links", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`<html><body><a href="/our-etfs/">Our ETFs</a></body></html>`))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			if symbols, err := DiscoverSymbolsFrom(server.URL); err == nil {
+				t.Errorf("discovered %v, want an error", symbols)
+			}
+		})
+	}
+}
+
+func TestDiffSymbols(t *testing.T) {
+	added, missing := DiffSymbols([]string{"ULTY", "NEWY", "TSLY"}, map[string]string{"TSLY": "GroupA", "ULTY": "Weekly", "GONE": "GroupB"})
+	if !reflect.DeepEqual(added, []string{"NEWY"}) || !reflect.DeepEqual(missing, []string{"GONE"}) {
+		t.Errorf("DiffSymbols = added %v, missing %v; want [NEWY] and [GONE]", added, missing)
+	}
+}
+
+func TestFullScraperListsDiscoveredSymbols(t *testing.T) {
+	s := NewYieldMaxFullScraper()
+	s.SetDiscoveredSymbols([]string{"TSLY", "NEWY", "NEWY"})
+
+	etfs := s.listETFs(nil)
+	known := GetYieldMaxETFGroups()
+	if len(etfs) != len(known)+1 {
+		t.Fatalf("listed %d ETFs, want the %d mapped plus NEWY", len(etfs), len(known))
+	}
+
+	bySymbol := make(map[string]int)
+	for i, etf := range etfs {
+		if i > 0 && etfs[i-1].Symbol >= etf.Symbol {
+			t.Errorf("ETFs not in symbol order: %s before %s", etfs[i-1].Symbol, etf.Symbol)
+		}
+		bySymbol[etf.Symbol] = i
+	}
+
+	newy := etfs[bySymbol["NEWY"]]
+	if newy.Group != UnknownGroup || !newy.Active || newy.Frequency != "" {
+		t.Errorf("NEWY = %+v, want an active ETF in %s with its frequency left to the detail page", newy, UnknownGroup)
+	}
+	if tsly := etfs[bySymbol["TSLY"]]; tsly.Group != known["TSLY"] {
+		t.Errorf("TSLY group = %s, want the mapped %s", tsly.Group, known["TSLY"])
+	}
+}
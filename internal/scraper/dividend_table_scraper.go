@@ -1,6 +1,7 @@
 package scraper
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"regexp"
@@ -8,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/metrics"
 	"divminder-crawler/internal/models"
 
 	"github.com/PuerkitoBio/goquery"
@@ -16,7 +19,14 @@ import (
 
 // DividendTableScraper scrapes dividend history from wpDataTables
 type DividendTableScraper struct {
-	collector *colly.Collector
+	collector   *colly.Collector
+	clock       clock.Clock
+	conditional *ConditionalCache // Optional; nil always fetches the full page
+	bypassCache bool              // Skip conditional cache reads (writes still happen) to force a full fetch
+	bounds      AmountBounds
+	baseURL     string
+	limit       *colly.LimitRule
+	groups      *GroupResolver
 }
 
 // NewDividendTableScraper creates a new dividend table scraper
@@ -25,33 +35,104 @@ func NewDividendTableScraper() *DividendTableScraper {
 		colly.UserAgent("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36"),
 	)
 
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  "*yieldmaxetfs.com*",
-		Parallelism: 1,
-		Delay:       2 * time.Second,
-	})
+	limit := applyLimit(c, 1, 2*time.Second)
+
+	applyProxy(c)
 
 	return &DividendTableScraper{
-		collector: c,
+		collector:   c,
+		limit:       limit,
+		clock:       clock.Real{},
+		conditional: NewConditionalCache(DefaultConditionalCacheDir, DefaultConditionalCacheTTL),
+		bounds:      DefaultAmountBounds(),
+		baseURL:     DefaultBaseURL,
+		groups:      NewGroupResolver(nil),
 	}
 }
 
+// WithLimitRule changes how many fund pages are fetched at once and the delay
+// between them, which default to 1 and 2s
+func (s *DividendTableScraper) WithLimitRule(parallelism int, delay time.Duration) *DividendTableScraper {
+	setLimit(s.limit, parallelism, delay)
+	return s
+}
+
+// SetGroupResolver replaces how scraped histories are assigned a group, e.g.
+// with one that also consults the overrides
+func (s *DividendTableScraper) SetGroupResolver(r *GroupResolver) {
+	s.groups = r
+}
+
+// SetBaseURL changes the site fund pages are fetched from
+func (s *DividendTableScraper) SetBaseURL(baseURL string) {
+	s.baseURL = baseURL
+}
+
+// SetAmountBounds replaces the range of distribution amounts kept from tables
+func (s *DividendTableScraper) SetAmountBounds(bounds AmountBounds) {
+	s.bounds = bounds
+}
+
+// SetConditionalCache replaces the conditional GET cache; nil disables conditional requests
+func (s *DividendTableScraper) SetConditionalCache(c *ConditionalCache) {
+	s.conditional = c
+}
+
+// SetBypassCache makes the scraper fetch full pages without sending cached
+// validators, while still caching the fresh validators and parse
+func (s *DividendTableScraper) SetBypassCache(bypass bool) {
+	s.bypassCache = bypass
+}
+
+// SetClock replaces the clock used for timestamps, date sanity checks and YTD stats
+func (s *DividendTableScraper) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
 // ScrapeDividendHistory scrapes dividend history for a specific ETF
 func (s *DividendTableScraper) ScrapeDividendHistory(symbol string) (*models.DividendHistory, error) {
-	url := fmt.Sprintf("https://www.yieldmaxetfs.com/our-etfs/%s/", strings.ToLower(symbol))
+	return s.ScrapeDividendHistoryContext(context.Background(), symbol)
+}
+
+// ScrapeDividendHistoryContext is ScrapeDividendHistory with the page request bound to ctx
+func (s *DividendTableScraper) ScrapeDividendHistoryContext(ctx context.Context, symbol string) (*models.DividendHistory, error) {
+	url := etfPageURL(s.baseURL, symbol)
 	log.Printf("Scraping dividend history from: %s", url)
 
+	start := time.Now()
+	defer metrics.ObserveSince(metrics.ScrapeDuration.WithLabelValues(metrics.SourceDividendTable), start)
+
 	history := &models.DividendHistory{
 		Symbol:    symbol,
-		UpdatedAt: time.Now(),
+		UpdatedAt: s.clock.Now(),
 		Events:    []models.DividendEvent{},
 	}
 
+	// Register callbacks on a clone so they don't pile up across calls
+	collector := s.collector.Clone()
+	collector.Context = ctx
+	observeRequests(collector)
+	throttleRequests(collector)
+
+	// Send the validators from the last fetch so an unchanged page returns 304
+	var cached *conditionalEntry
+	if s.conditional != nil && !s.bypassCache {
+		cached = s.conditional.lookup(url)
+	}
+	var etag, lastModified string
+	collector.OnRequest(func(r *colly.Request) {
+		setConditionalHeaders(r, cached)
+	})
+	collector.OnResponse(func(r *colly.Response) {
+		etag = r.Headers.Get("ETag")
+		lastModified = r.Headers.Get("Last-Modified")
+	})
+
 	// Find ETF name and frequency
 	var etfName string
 	var frequency string
 
-	s.collector.OnHTML("h1, h2", func(e *colly.HTMLElement) {
+	collector.OnHTML("h1, h2", func(e *colly.HTMLElement) {
 		text := strings.TrimSpace(e.Text)
 		if strings.Contains(text, "YieldMax") && strings.Contains(text, symbol) {
 			etfName = text
@@ -59,7 +140,7 @@ func (s *DividendTableScraper) ScrapeDividendHistory(symbol string) (*models.Div
 	})
 
 	// Extract frequency from description
-	s.collector.OnHTML("p", func(e *colly.HTMLElement) {
+	collector.OnHTML("p", func(e *colly.HTMLElement) {
 		text := strings.ToLower(e.Text)
 		if strings.Contains(text, "monthly distribution") || strings.Contains(text, "monthly income") {
 			frequency = "monthly"
@@ -69,11 +150,11 @@ func (s *DividendTableScraper) ScrapeDividendHistory(symbol string) (*models.Div
 	})
 
 	// Find and parse the dividend table
-	s.collector.OnHTML("table", func(e *colly.HTMLElement) {
+	collector.OnHTML("table", func(e *colly.HTMLElement) {
 		// Check for wpDataTables class or specific table IDs
 		classes, _ := e.DOM.Attr("class")
 		id, _ := e.DOM.Attr("id")
-		
+
 		// Look for wpDataTable or table with ID pattern
 		if !strings.Contains(classes, "wpDataTable") && !strings.Contains(id, "table_") {
 			return
@@ -101,17 +182,22 @@ func (s *DividendTableScraper) ScrapeDividendHistory(symbol string) (*models.Div
 
 		log.Printf("Found dividend table with %d rows", e.DOM.Find("tbody tr").Length())
 
-		// Parse each row
+		// Read cells by header name so reordered columns still parse
+		columns := detectTableColumns(headers)
+
+		// Parse each row, then settle the amount column's scale for the table
+		var events []models.DividendEvent
 		e.DOM.Find("tbody tr").Each(func(i int, row *goquery.Selection) {
-			event := s.parseDividendRow(row, symbol)
+			event := s.parseDividendRow(row, symbol, columns)
 			if event != nil {
-				history.Events = append(history.Events, *event)
+				events = append(events, *event)
 			}
 		})
+		history.Events = append(history.Events, normalizeAmountColumn(events, s.bounds)...)
 	})
 
 	// Also try to find data in script tags (wpDataTables format)
-	s.collector.OnHTML("script", func(e *colly.HTMLElement) {
+	collector.OnHTML("script", func(e *colly.HTMLElement) {
 		if strings.Contains(e.Text, "wpDataTablesRowData") {
 			// Extract JSON data from script
 			re := regexp.MustCompile(`var\s+wpDataTablesRowData_\d+\s*=\s*(\[[\s\S]*?\]);`)
@@ -124,12 +210,24 @@ func (s *DividendTableScraper) ScrapeDividendHistory(symbol string) (*models.Div
 	})
 
 	// Visit the page
-	err := s.collector.Visit(url)
+	err := collector.Visit(url)
+	if err != nil && cached != nil && isNotModifiedVisitError(err) {
+		log.Printf("%s not modified since last fetch, reusing cached history", url)
+		history = cached.History
+		history.UpdatedAt = s.clock.Now()
+		history.Stats = models.CalculateStatsAt(history.Events, s.clock.Now())
+		metrics.ScrapeResults.WithLabelValues(metrics.SourceDividendTable, metrics.ResultSuccess).Inc()
+		return history, nil
+	}
 	if err != nil {
+		metrics.ScrapeResults.WithLabelValues(metrics.SourceDividendTable, metrics.ResultFailure).Inc()
+		if isNotFoundVisitError(err) {
+			err = ErrNotFound
+		}
 		return nil, fmt.Errorf("failed to visit %s: %w", url, err)
 	}
 
-	s.collector.Wait()
+	collector.Wait()
 
 	// Set name and frequency
 	history.Name = etfName
@@ -144,46 +242,34 @@ func (s *DividendTableScraper) ScrapeDividendHistory(symbol string) (*models.Div
 	}
 
 	// Set group based on ETF groups mapping
-	if group, exists := GetYieldMaxETFGroups()[symbol]; exists {
-		history.Group = group
-	}
+	history.Group = s.groups.ResolveGroup(symbol)
 
 	// Calculate statistics
-	if len(history.Events) > 0 {
-		var totalAmount float64
-		var ytdAmount float64
-		yearStart := time.Date(time.Now().Year(), 1, 1, 0, 0, 0, 0, time.UTC)
-
-		for _, event := range history.Events {
-			totalAmount += event.Amount
-			if event.ExDate.After(yearStart) {
-				ytdAmount += event.Amount
-			}
-		}
-
-		history.Stats = models.DividendStats{
-			TotalPayments:     len(history.Events),
-			AverageAmount:     totalAmount / float64(len(history.Events)),
-			LastAmount:        history.Events[0].Amount,
-			YearToDateTotal:   ytdAmount,
-			TrailingYearTotal: totalAmount,
-		}
-
-		// Calculate change percent if we have at least 2 events
-		if len(history.Events) > 1 {
-			change := (history.Events[0].Amount - history.Events[1].Amount) / history.Events[1].Amount * 100
-			history.Stats.ChangePercent = change
+	if removed := history.DedupEvents(); removed > 0 {
+		log.Printf("Removed %d duplicate events for %s", removed, symbol)
+	}
+	history.SortEventsDescending()
+	history.DetectFrequencyRegimes()
+	history.Stats = models.CalculateStatsAt(history.Events, s.clock.Now())
+
+	if s.conditional != nil {
+		entry := conditionalEntry{ETag: etag, LastModified: lastModified, History: history}
+		if err := s.conditional.store(url, entry); err != nil {
+			log.Printf("Failed to cache validators for %s: %v", url, err)
 		}
 	}
 
 	log.Printf("Scraped %d dividend events for %s", len(history.Events), symbol)
+	metrics.ScrapeResults.WithLabelValues(metrics.SourceDividendTable, metrics.ResultSuccess).Inc()
 	return history, nil
 }
 
-// parseDividendRow parses a single dividend table row
-func (s *DividendTableScraper) parseDividendRow(row *goquery.Selection, symbol string) *models.DividendEvent {
+// parseDividendRow parses a single dividend table row, reading its cells
+// through the table's columns
+func (s *DividendTableScraper) parseDividendRow(row *goquery.Selection, symbol string, columns tableColumns) *models.DividendEvent {
 	event := &models.DividendEvent{
 		Symbol: symbol,
+		Source: models.SourceYieldMax,
 	}
 
 	cells := row.Find("td")
@@ -191,21 +277,13 @@ func (s *DividendTableScraper) parseDividendRow(row *goquery.Selection, symbol s
 		return strings.TrimSpace(cell.Text())
 	})
 
-	// Based on the CONY table structure:
-	// 0: ticker_name (CONY)
-	// 1: dividend_amount (Distribution per Share)
-	// 2: declared_date
-	// 3: ex_date
-	// 4: record_date
-	// 5: payable_date
-	
-	if len(cellTexts) >= 6 {
+	if columns.covers(cellTexts) {
 		// Standard wpDataTables format
-		event.Amount = s.parseAmount(cellTexts[1])
-		event.DeclareDate = s.parseDate(cellTexts[2])
-		event.ExDate = s.parseDate(cellTexts[3])
-		// Skip record date (index 4)
-		event.PayDate = s.parseDate(cellTexts[5])
+		event.Amount = s.parseAmount(columns.cell(cellTexts, columnAmount))
+		event.DeclareDate = s.parseDate(columns.cell(cellTexts, columnDeclared))
+		event.ExDate = s.parseDate(columns.cell(cellTexts, columnEx))
+		event.RecordDate = s.parseDate(columns.cell(cellTexts, columnRecord))
+		event.PayDate = s.parseDate(columns.cell(cellTexts, columnPay))
 	} else if len(cellTexts) >= 4 {
 		// Alternate format: might not have all columns
 		// Try to identify amount and dates
@@ -247,57 +325,36 @@ func (s *DividendTableScraper) parseWpDataTablesData(jsonStr string, symbol stri
 	log.Printf("Found wpDataTables JSON data for %s", symbol)
 }
 
-// parseDate parses various date formats
+// parseDate parses a table date, returning the zero time when it can't
 func (s *DividendTableScraper) parseDate(str string) time.Time {
-	str = strings.TrimSpace(str)
-	
-	// Try various date formats
-	formats := []string{
-		"01/02/2006", // MM/DD/YYYY - primary format
-		"1/2/2006",   // M/D/YYYY
-		"2006-01-02", // YYYY-MM-DD
-		"Jan 2, 2006",
-		"January 2, 2006",
-		"02-Jan-2006",
-		"01/02/06",
-		"1/2/06",
-	}
-
-	for _, format := range formats {
-		if t, err := time.Parse(format, str); err == nil {
-			// Handle 2-digit years
-			if t.Year() < 100 {
-				t = t.AddDate(2000, 0, 0)
-			}
-			// Sanity check - dividends should be between 2020 and current year + 1
-			currentYear := time.Now().Year()
-			if t.Year() >= 2020 && t.Year() <= currentYear+1 {
-				return t
-			}
-		}
-	}
-
-	return time.Time{}
+	t, _ := ParseFlexibleDateAt(str, s.clock.Now())
+	return t
 }
 
-// parseAmount extracts amount from string
+// parseAmount extracts the raw amount from a cell, leaving the cents-vs-dollars
+// decision to normalizeAmountColumn. Negative amounts yield 0.
 func (s *DividendTableScraper) parseAmount(str string) float64 {
+	if isNegativeAmount(str) {
+		log.Printf("Skipped negative amount %q: adjustments are not dividends", str)
+		return 0
+	}
+
 	// Remove $ and other characters
 	str = strings.TrimSpace(str)
 	str = strings.TrimPrefix(str, "$")
 	str = strings.ReplaceAll(str, ",", "")
-	
+
 	// Extract numeric value
 	re := regexp.MustCompile(`(\d+\.?\d*)`)
 	matches := re.FindStringSubmatch(str)
 	if len(matches) > 1 {
 		if amount, err := strconv.ParseFloat(matches[1], 64); err == nil {
-			// Sanity check - dividend amounts are typically less than $10
-			if amount > 0 && amount < 10 {
+			// Sanity check - even amounts quoted in cents stay below 1000
+			if amount > 0 && amount < maxCentsAmount {
 				return amount
 			}
 		}
 	}
 
 	return 0
-}
\ No newline at end of file
+}
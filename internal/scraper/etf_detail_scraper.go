@@ -1,22 +1,34 @@
 package scraper
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"divminder-crawler/internal/cache"
 	"divminder-crawler/internal/models"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/gocolly/colly/v2"
 	"github.com/sirupsen/logrus"
 )
 
+// DefaultDetailCacheDir is where WithTTL caches scraped details
+const DefaultDetailCacheDir = "cache/details"
+
 // ETFDetailScraper scrapes individual ETF pages for detailed information
 type ETFDetailScraper struct {
-	collector *colly.Collector
-	logger    *logrus.Logger
+	collector   *colly.Collector
+	logger      *logrus.Logger
+	cache       *cache.FileCache // Optional; set by WithTTL
+	cacheDir    string
+	bypassCache bool // Skip cache reads (writes still happen) to force a fresh scrape
+	bounds      AmountBounds
+	baseURL     string
+	limit       *colly.LimitRule
 }
 
 // NewETFDetailScraper creates a new ETF detail scraper
@@ -26,11 +38,9 @@ func NewETFDetailScraper() *ETFDetailScraper {
 		colly.UserAgent("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36"),
 	)
 
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  "*yieldmaxetfs.com*",
-		Parallelism: 1,
-		Delay:       2 * time.Second,
-	})
+	limit := applyLimit(c, 1, 2*time.Second)
+
+	applyProxy(c)
 
 	logger := logrus.New()
 	logger.SetLevel(logrus.InfoLevel)
@@ -38,57 +48,123 @@ func NewETFDetailScraper() *ETFDetailScraper {
 	return &ETFDetailScraper{
 		collector: c,
 		logger:    logger,
+		cacheDir:  DefaultDetailCacheDir,
+		bounds:    DefaultAmountBounds(),
+		baseURL:   DefaultBaseURL,
+		limit:     limit,
 	}
 }
 
-// GetETFDetail scrapes detailed information for a specific ETF
+// SetBaseURL changes the site fund pages are fetched from
+func (s *ETFDetailScraper) SetBaseURL(baseURL string) {
+	s.baseURL = baseURL
+}
+
+// WithLimitRule changes how many detail pages are fetched at once and the
+// delay between them, which default to 1 and 2s
+func (s *ETFDetailScraper) WithLimitRule(parallelism int, delay time.Duration) *ETFDetailScraper {
+	setLimit(s.limit, parallelism, delay)
+	return s
+}
+
+// SetAmountBounds replaces the range of distribution amounts kept from the
+// dividend history table
+func (s *ETFDetailScraper) SetAmountBounds(bounds AmountBounds) {
+	s.bounds = bounds
+}
+
+// WithCacheDir keeps cached details in dir instead of DefaultDetailCacheDir;
+// call it before WithTTL
+func (s *ETFDetailScraper) WithCacheDir(dir string) *ETFDetailScraper {
+	s.cacheDir = dir
+	return s
+}
+
+// WithTTL caches scraped details for ttl; zero or less disables caching
+func (s *ETFDetailScraper) WithTTL(ttl time.Duration) *ETFDetailScraper {
+	s.cache = nil
+	if ttl > 0 {
+		s.cache = cache.NewFileCache(s.cacheDir, ttl)
+	}
+	return s
+}
+
+// SetBypassCache makes the scraper skip cached details while still caching
+// what it scrapes
+func (s *ETFDetailScraper) SetBypassCache(bypass bool) {
+	s.bypassCache = bypass
+}
+
+// GetETFDetail returns the cached detail for symbol when fresh, otherwise scrapes it
 func (s *ETFDetailScraper) GetETFDetail(symbol string) (*models.ETFDetail, error) {
-	url := fmt.Sprintf("https://www.yieldmaxetfs.com/our-etfs/%s/", strings.ToLower(symbol))
+	return s.GetETFDetailContext(context.Background(), symbol)
+}
+
+// GetETFDetailContext is GetETFDetail with the page request bound to ctx
+func (s *ETFDetailScraper) GetETFDetailContext(ctx context.Context, symbol string) (*models.ETFDetail, error) {
+	if s.cache == nil {
+		return s.scrapeETFDetail(ctx, symbol)
+	}
+
+	cacheKey := fmt.Sprintf("etf_detail_%s", symbol)
+	var cached models.ETFDetail
+	if s.bypassCache {
+		s.logger.Debugf("Bypassing cached detail for %s", symbol)
+	} else if found, err := s.cache.Get(cacheKey, &cached); err == nil && found {
+		s.logger.Debugf("Using cached detail for %s", symbol)
+		return &cached, nil
+	}
+
+	detail, err := s.scrapeETFDetail(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.Set(cacheKey, detail); err != nil {
+		s.logger.Warnf("Failed to cache detail for %s: %v", symbol, err)
+	}
+	return detail, nil
+}
+
+// scrapeETFDetail scrapes detailed information for a specific ETF
+func (s *ETFDetailScraper) scrapeETFDetail(ctx context.Context, symbol string) (*models.ETFDetail, error) {
+	url := etfPageURL(s.baseURL, symbol)
 	s.logger.Infof("Scraping ETF detail from: %s", url)
 
 	detail := &models.ETFDetail{
 		Symbol: symbol,
 	}
 
+	// Register callbacks on a clone so they don't pile up across calls; the
+	// clone shares the HTTP backend and therefore the limit rule
+	collector := s.collector.Clone()
+	collector.Context = ctx
+	observeRequests(collector)
+	throttleRequests(collector)
+
 	// Scrape fund information
-	s.collector.OnHTML(".fund-overview", func(e *colly.HTMLElement) {
+	collector.OnHTML(".fund-overview", func(e *colly.HTMLElement) {
 		detail.Name = strings.TrimSpace(e.ChildText("h1"))
 		detail.Description = strings.TrimSpace(e.ChildText(".fund-description"))
 	})
 
-	// Scrape key metrics
-	s.collector.OnHTML(".key-metrics", func(e *colly.HTMLElement) {
-		e.ForEach(".metric-item", func(_ int, el *colly.HTMLElement) {
-			label := strings.ToLower(strings.TrimSpace(el.ChildText(".metric-label")))
-			value := strings.TrimSpace(el.ChildText(".metric-value"))
-
-			switch {
-			case strings.Contains(label, "yield"):
-				// Parse yield percentage
-				if val, err := parsePercentage(value); err == nil {
-					detail.CurrentYield = val
-				}
-			case strings.Contains(label, "price"):
-				// Parse price
-				if val, err := parsePrice(value); err == nil {
-					detail.CurrentPrice = val
-				}
-			case strings.Contains(label, "frequency"):
-				detail.Frequency = value
-			}
-		})
+	// Scrape key metrics by their visible labels rather than CSS classes,
+	// since the fund pages are built with a page builder whose markup changes
+	collector.OnHTML("body", func(e *colly.HTMLElement) {
+		extractKeyMetrics(e.DOM, detail)
 	})
 
 	// Scrape dividend history table
 	var dividendHistory []models.DividendEvent
-	s.collector.OnHTML("table", func(e *colly.HTMLElement) {
+	collector.OnHTML("table", func(e *colly.HTMLElement) {
 		// Look for dividend history table
 		headers := e.ChildTexts("th")
 		if containsDividendHeaders(headers) {
 			s.logger.Info("Found dividend history table")
-			
+
+			// Read cells by header name so reordered columns still parse
+			columns := detectTableColumns(headers)
 			e.ForEach("tbody tr", func(_ int, row *colly.HTMLElement) {
-				event := parseDividendRow(row, symbol)
+				event := s.parseDividendRow(row, symbol, columns)
 				if event != nil {
 					dividendHistory = append(dividendHistory, *event)
 				}
@@ -96,14 +172,26 @@ func (s *ETFDetailScraper) GetETFDetail(symbol string) (*models.ETFDetail, error
 		}
 	})
 
+	// The collector is async, so a failed response is reported here rather
+	// than by Visit
+	var responseErr error
+	collector.OnError(func(_ *colly.Response, err error) {
+		responseErr = err
+	})
+
 	// Visit the page
-	err := s.collector.Visit(url)
+	err := collector.Visit(url)
+	if err == nil {
+		collector.Wait()
+		err = responseErr
+	}
 	if err != nil {
+		if isNotFoundVisitError(err) {
+			err = ErrNotFound
+		}
 		return nil, fmt.Errorf("failed to visit %s: %w", url, err)
 	}
 
-	s.collector.Wait()
-
 	detail.DividendHistory = dividendHistory
 	s.logger.Infof("Scraped %d dividend events for %s", len(dividendHistory), symbol)
 
@@ -113,7 +201,7 @@ func (s *ETFDetailScraper) GetETFDetail(symbol string) (*models.ETFDetail, error
 // containsDividendHeaders checks if table headers indicate a dividend table
 func containsDividendHeaders(headers []string) bool {
 	dividendKeywords := []string{"ex-date", "pay date", "dividend", "amount", "distribution"}
-	
+
 	headerText := strings.ToLower(strings.Join(headers, " "))
 	for _, keyword := range dividendKeywords {
 		if strings.Contains(headerText, keyword) {
@@ -123,8 +211,9 @@ func containsDividendHeaders(headers []string) bool {
 	return false
 }
 
-// parseDividendRow parses a dividend history table row
-func parseDividendRow(row *colly.HTMLElement, symbol string) *models.DividendEvent {
+// parseDividendRow parses a dividend history table row, reading its cells
+// through the table's columns when the row has them all
+func (s *ETFDetailScraper) parseDividendRow(row *colly.HTMLElement, symbol string, columns tableColumns) *models.DividendEvent {
 	cells := row.ChildTexts("td")
 	if len(cells) < 3 {
 		return nil
@@ -132,26 +221,31 @@ func parseDividendRow(row *colly.HTMLElement, symbol string) *models.DividendEve
 
 	event := &models.DividendEvent{
 		Symbol: symbol,
+		Source: models.SourceYieldMax,
 	}
 
-	// Parse dates and amount based on column positions
-	// This may need adjustment based on actual table structure
-	for _, cell := range cells {
-		cell = strings.TrimSpace(cell)
-		
-		// Try to parse as date
-		if date, err := parseDate(cell); err == nil {
-			if event.ExDate.IsZero() {
-				event.ExDate = date
-			} else if event.PayDate.IsZero() {
-				event.PayDate = date
+	if columns.covers(cells) {
+		event.Amount = s.parseBoundedAmount(columns.cell(cells, columnAmount))
+		event.DeclareDate, _ = ParseFlexibleDate(columns.cell(cells, columnDeclared))
+		event.ExDate, _ = ParseFlexibleDate(columns.cell(cells, columnEx))
+		event.RecordDate, _ = ParseFlexibleDate(columns.cell(cells, columnRecord))
+		event.PayDate, _ = ParseFlexibleDate(columns.cell(cells, columnPay))
+	} else {
+		// Without known columns, the first date is the ex-date, the second the
+		// pay date, and a cell that isn't a date holds the amount
+		for _, cell := range cells {
+			cell = strings.TrimSpace(cell)
+
+			if date, err := ParseFlexibleDate(cell); err == nil {
+				if event.ExDate.IsZero() {
+					event.ExDate = date
+				} else if event.PayDate.IsZero() {
+					event.PayDate = date
+				}
+			} else if amount := s.parseBoundedAmount(cell); amount > 0 {
+				event.Amount = amount
 			}
 		}
-		
-		// Try to parse as amount
-		if amount, err := parseAmount(cell); err == nil && amount > 0 {
-			event.Amount = amount
-		}
 	}
 
 	// Only return if we have at least a date and amount
@@ -162,27 +256,28 @@ func parseDividendRow(row *colly.HTMLElement, symbol string) *models.DividendEve
 	return nil
 }
 
-// parseDate attempts to parse various date formats
-func parseDate(s string) (time.Time, error) {
-	formats := []string{
-		"01/02/2006",
-		"1/2/2006",
-		"2006-01-02",
-		"Jan 2, 2006",
-		"January 2, 2006",
+// parseBoundedAmount parses an amount cell rounded to AmountDecimals,
+// returning 0 for cells without a positive amount and logging amounts
+// outside the scraper's bounds as it rejects them
+func (s *ETFDetailScraper) parseBoundedAmount(cell string) float64 {
+	amount, err := parseAmount(cell)
+	if err != nil || amount <= 0 {
+		return 0
 	}
-
-	for _, format := range formats {
-		if t, err := time.Parse(format, s); err == nil {
-			return t, nil
-		}
+	amount = models.RoundAmount(amount)
+	if !s.bounds.Contains(amount) {
+		s.logger.Warnf("Rejected implausible amount %q (allowed %v-%v)", cell, s.bounds.Min, s.bounds.Max)
+		return 0
 	}
-
-	return time.Time{}, fmt.Errorf("could not parse date: %s", s)
+	return amount
 }
 
-// parseAmount parses dividend amount from string
+// parseAmount parses dividend amount from string, rejecting negative amounts
 func parseAmount(s string) (float64, error) {
+	if isNegativeAmount(s) {
+		return 0, fmt.Errorf("negative amount %q", s)
+	}
+
 	// Remove $ and other non-numeric characters except . and digits
 	cleaned := regexp.MustCompile(`[^0-9.]`).ReplaceAllString(s, "")
 	if cleaned == "" {
@@ -192,16 +287,191 @@ func parseAmount(s string) (float64, error) {
 	return strconv.ParseFloat(cleaned, 64)
 }
 
-// parsePrice parses price from string
-func parsePrice(s string) (float64, error) {
-	return parseAmount(s)
+var (
+	// Label patterns for the fund overview metrics, most specific first
+	marketPriceLabels = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bmarket price\b`),
+		regexp.MustCompile(`(?i)\bclosing price\b`),
+	}
+	navLabels = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bnav\b`),
+		regexp.MustCompile(`(?i)\bnet asset value\b`),
+	}
+	distributionRateLabels = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bdistribution rate\b`),
+	}
+	secYieldLabels = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b30[- ]day sec yield\b`),
+	}
+	inceptionDateLabels = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\binception date\b`),
+		regexp.MustCompile(`(?i)\bfund inception\b`),
+	}
+
+	percentValuePattern = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*%`)
+	priceValuePattern   = regexp.MustCompile(`\$?\s*(\d{1,4}(?:,\d{3})*\.\d{2,4})`)
+	dateValuePattern    = regexp.MustCompile(`(\d{1,2}/\d{1,2}/\d{2,4}|\d{4}-\d{2}-\d{2}|[A-Z][a-z]+ \d{1,2}, \d{4}|\d{2}-[A-Z][a-z]+-\d{4})`)
+
+	// Fallbacks applied to the flattened page text when no labeled element is found
+	distributionRateFallback = regexp.MustCompile(`(?i)distribution rate\D{0,40}?(\d+(?:\.\d+)?)\s*%`)
+	secYieldFallback         = regexp.MustCompile(`(?i)30[- ]day sec yield\D{0,40}?(\d+(?:\.\d+)?)\s*%`)
+	navFallback              = regexp.MustCompile(`(?i)\bnav\b\D{0,40}?\$?\s*(\d{1,4}\.\d{2,4})`)
+	marketPriceFallback      = regexp.MustCompile(`(?i)market price\D{0,40}?\$?\s*(\d{1,4}\.\d{2,4})`)
+	inceptionDateFallback    = regexp.MustCompile(`(?i)(?:inception date|fund inception)\W{0,20}` + dateValuePattern.String())
+)
+
+// extractKeyMetrics populates price, NAV and yield fields from the fund overview.
+// The Distribution Rate and 30-Day SEC Yield are read by their own labels;
+// CurrentYield mirrors the Distribution Rate.
+func extractKeyMetrics(root *goquery.Selection, detail *models.ETFDetail) {
+	pageText := strings.Join(strings.Fields(root.Text()), " ")
+
+	if val, ok := findMetric(root, pageText, distributionRateLabels, percentValuePattern, distributionRateFallback); ok {
+		detail.DistributionRate = val
+		detail.CurrentYield = val
+	}
+	if val, ok := findMetric(root, pageText, secYieldLabels, percentValuePattern, secYieldFallback); ok {
+		detail.SECYield = val
+	}
+
+	extractPriceMetrics(root, pageText, detail)
+	detail.InceptionDate = findInceptionDate(root, pageText)
+
+	// Frequency is only stated in the fund description text
+	lowerText := strings.ToLower(pageText)
+	if strings.Contains(lowerText, "weekly distribution") {
+		detail.Frequency = "weekly"
+	} else if strings.Contains(lowerText, "monthly distribution") {
+		detail.Frequency = "monthly"
+	}
 }
 
-// parsePercentage parses percentage from string
-func parsePercentage(s string) (float64, error) {
-	// Remove % and parse
-	cleaned := strings.TrimSuffix(strings.TrimSpace(s), "%")
-	return strconv.ParseFloat(cleaned, 64)
+// extractPriceMetrics populates CurrentPrice, NAV and PremiumDiscountPct.
+// The price prefers the market price over NAV; the premium/discount is only
+// computed when both figures are published.
+func extractPriceMetrics(root *goquery.Selection, pageText string, detail *models.ETFDetail) {
+	marketPrice, hasMarketPrice := findMetric(root, pageText, marketPriceLabels, priceValuePattern, marketPriceFallback)
+	nav, hasNAV := findMetric(root, pageText, navLabels, priceValuePattern, navFallback)
+
+	if hasNAV {
+		detail.NAV = nav
+	}
+
+	switch {
+	case hasMarketPrice:
+		detail.CurrentPrice = marketPrice
+	case hasNAV:
+		detail.CurrentPrice = nav
+	}
+
+	if hasMarketPrice && hasNAV {
+		detail.PremiumDiscountPct = (marketPrice - nav) / nav * 100
+	}
+}
+
+// findMetric looks up a labeled value in the DOM, falling back to a regex over the page text
+func findMetric(root *goquery.Selection, pageText string, labels []*regexp.Regexp, valuePattern, fallback *regexp.Regexp) (float64, bool) {
+	if val, ok := findLabeledValue(root, labels, valuePattern); ok {
+		return val, true
+	}
+
+	if match := fallback.FindStringSubmatch(pageText); len(match) > 1 {
+		if val, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64); err == nil && val > 0 {
+			return val, true
+		}
+	}
+
+	return 0, false
+}
+
+// findInceptionDate reads the fund's inception date from its "Inception Date"
+// or "Fund Inception" label, falling back to the page text. It returns nil
+// when the page doesn't state one.
+func findInceptionDate(root *goquery.Selection, pageText string) *time.Time {
+	var inception *time.Time
+
+	root.Find("*").EachWithBreak(func(_ int, elem *goquery.Selection) bool {
+		label := ownText(elem)
+		if label == "" || len(label) > 80 || !matchesAny(label, inceptionDateLabels) {
+			return true
+		}
+
+		candidates := []*goquery.Selection{elem.Next(), elem.Parent().Next(), elem.Parent()}
+		for _, candidate := range candidates {
+			match := dateValuePattern.FindStringSubmatch(candidate.Text())
+			if len(match) < 2 {
+				continue
+			}
+			if date, err := parseFlexibleFormats(match[1]); err == nil {
+				inception = &date
+				return false
+			}
+		}
+		return true
+	})
+	if inception != nil {
+		return inception
+	}
+
+	if match := inceptionDateFallback.FindStringSubmatch(pageText); len(match) > 1 {
+		if date, err := parseFlexibleFormats(match[1]); err == nil {
+			return &date
+		}
+	}
+	return nil
+}
+
+// findLabeledValue finds an element whose own text matches one of the labels and
+// parses the value from the element next to it (sibling cell, dd, or parent's sibling)
+func findLabeledValue(root *goquery.Selection, labels []*regexp.Regexp, valuePattern *regexp.Regexp) (float64, bool) {
+	var value float64
+	found := false
+
+	root.Find("*").EachWithBreak(func(_ int, elem *goquery.Selection) bool {
+		label := ownText(elem)
+		if label == "" || len(label) > 80 || !matchesAny(label, labels) {
+			return true
+		}
+
+		// Values sit either in the next sibling (td/dd/span) or in the
+		// parent's next sibling when the label is wrapped in its own block
+		candidates := []*goquery.Selection{elem.Next(), elem.Parent().Next(), elem.Parent()}
+		for _, candidate := range candidates {
+			match := valuePattern.FindStringSubmatch(candidate.Text())
+			if len(match) < 2 {
+				continue
+			}
+			if val, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64); err == nil && val > 0 {
+				value = val
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+
+	return value, found
+}
+
+// ownText returns the text of an element excluding its children
+func ownText(s *goquery.Selection) string {
+	var b strings.Builder
+	s.Contents().Each(func(_ int, c *goquery.Selection) {
+		if goquery.NodeName(c) == "#text" {
+			b.WriteString(c.Text())
+		}
+	})
+	return strings.TrimSpace(b.String())
+}
+
+// matchesAny reports whether text matches any of the given patterns
+func matchesAny(text string, patterns []*regexp.Regexp) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
 }
 
 // GetAllETFDetails scrapes details for all ETFs
@@ -210,10 +480,10 @@ func (s *ETFDetailScraper) GetAllETFDetails(symbols []string) map[string]*models
 
 	for _, symbol := range symbols {
 		s.logger.Infof("Scraping details for %s", symbol)
-		
+
 		if detail, err := s.GetETFDetail(symbol); err == nil {
 			details[symbol] = detail
-			
+
 			// Save individual ETF dividend history
 			if err := saveETFDividendHistory(symbol, detail); err != nil {
 				s.logger.Errorf("Failed to save dividend history for %s: %v", symbol, err)
@@ -221,7 +491,7 @@ func (s *ETFDetailScraper) GetAllETFDetails(symbols []string) map[string]*models
 		} else {
 			s.logger.Errorf("Failed to scrape %s: %v", symbol, err)
 		}
-		
+
 		// Be respectful with rate limiting
 		time.Sleep(3 * time.Second)
 	}
@@ -234,4 +504,4 @@ func saveETFDividendHistory(symbol string, detail *models.ETFDetail) error {
 	// This will be implemented by the main crawler
 	// For now, just return nil
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,297 @@
+package scraper
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/cache"
+	"divminder-crawler/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// loadFixture parses a saved page from testdata
+func loadFixture(t *testing.T, name string) *goquery.Document {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return doc
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestExtractKeyMetricsFromFundPages(t *testing.T) {
+	tests := []struct {
+		fixture   string
+		price     float64
+		yield     float64
+		secYield  float64
+		frequency string
+	}{
+		{
+			fixture:   "fund_page_ulty.html",
+			price:     6.19,
+			yield:     79.48,
+			secYield:  0.12,
+			frequency: "weekly",
+		},
+		{
+			fixture:   "fund_page_tsly.html",
+			price:     8.45,
+			yield:     66.81,
+			secYield:  3.05,
+			frequency: "monthly",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			doc := loadFixture(t, tt.fixture)
+
+			var detail models.ETFDetail
+			extractKeyMetrics(doc.Find("body"), &detail)
+
+			if !almostEqual(detail.CurrentPrice, tt.price) {
+				t.Errorf("CurrentPrice = %v, want %v", detail.CurrentPrice, tt.price)
+			}
+			if !almostEqual(detail.CurrentYield, tt.yield) {
+				t.Errorf("CurrentYield = %v, want %v", detail.CurrentYield, tt.yield)
+			}
+			if !almostEqual(detail.SECYield, tt.secYield) {
+				t.Errorf("SECYield = %v, want %v", detail.SECYield, tt.secYield)
+			}
+			if detail.Frequency != tt.frequency {
+				t.Errorf("Frequency = %q, want %q", detail.Frequency, tt.frequency)
+			}
+		})
+	}
+}
+
+// parseFragment parses an HTML fragment for the metric extractors
+func parseFragment(t *testing.T, html string) *goquery.Selection {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc.Find("body")
+}
+
+func TestExtractPriceMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		html    string
+		price   float64
+		nav     float64
+		premium float64
+	}{
+		{
+			name:    "premium",
+			html:    `<table><tr><td>Market Price</td><td>$20.40</td></tr><tr><td>NAV</td><td>$20.00</td></tr></table>`,
+			price:   20.40,
+			nav:     20.00,
+			premium: 2,
+		},
+		{
+			name:    "discount",
+			html:    `<dl><dt>Net Asset Value</dt><dd>$10.00</dd><dt>Market Price</dt><dd>$9.95</dd></dl>`,
+			price:   9.95,
+			nav:     10.00,
+			premium: -0.5,
+		},
+		{
+			name:  "nav only",
+			html:  `<table><tr><td>NAV</td><td>$12.3456</td></tr></table>`,
+			price: 12.3456,
+			nav:   12.3456,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := parseFragment(t, tt.html)
+			pageText := strings.Join(strings.Fields(root.Text()), " ")
+
+			var detail models.ETFDetail
+			extractPriceMetrics(root, pageText, &detail)
+
+			if !almostEqual(detail.CurrentPrice, tt.price) {
+				t.Errorf("CurrentPrice = %v, want %v", detail.CurrentPrice, tt.price)
+			}
+			if !almostEqual(detail.NAV, tt.nav) {
+				t.Errorf("NAV = %v, want %v", detail.NAV, tt.nav)
+			}
+			if !almostEqual(detail.PremiumDiscountPct, tt.premium) {
+				t.Errorf("PremiumDiscountPct = %v, want %v", detail.PremiumDiscountPct, tt.premium)
+			}
+		})
+	}
+}
+
+func TestExtractYieldMetrics(t *testing.T) {
+	tests := []struct {
+		name             string
+		html             string
+		distributionRate float64
+		secYield         float64
+	}{
+		{
+			name:             "both figures",
+			html:             `<table><tr><td>Distribution Rate</td><td>45.20%</td></tr><tr><td>30-Day SEC Yield</td><td>2.15%</td></tr></table>`,
+			distributionRate: 45.20,
+			secYield:         2.15,
+		},
+		{
+			name:             "SEC yield listed first",
+			html:             `<div><h4>30 Day SEC Yield</h4><p>1.87 %</p></div><div><h4>Distribution Rate</h4><p>61.03 %</p></div>`,
+			distributionRate: 61.03,
+			secYield:         1.87,
+		},
+		{
+			name:     "SEC yield only",
+			html:     `<p>30-Day SEC Yield 4.02%</p>`,
+			secYield: 4.02,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var detail models.ETFDetail
+			extractKeyMetrics(parseFragment(t, tt.html), &detail)
+
+			if !almostEqual(detail.DistributionRate, tt.distributionRate) {
+				t.Errorf("DistributionRate = %v, want %v", detail.DistributionRate, tt.distributionRate)
+			}
+			if !almostEqual(detail.SECYield, tt.secYield) {
+				t.Errorf("SECYield = %v, want %v", detail.SECYield, tt.secYield)
+			}
+			// CurrentYield stays an alias of the distribution rate
+			if !almostEqual(detail.CurrentYield, detail.DistributionRate) {
+				t.Errorf("CurrentYield = %v, want the distribution rate %v", detail.CurrentYield, detail.DistributionRate)
+			}
+		})
+	}
+}
+
+func TestFindInceptionDate(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want time.Time
+	}{
+		{
+			name: "definition list",
+			html: `<dl><dt>Fund Inception</dt><dd>Nov 22, 2022</dd><dt>NAV</dt><dd>$8.42</dd></dl>`,
+			want: marketDate(2022, 11, 22),
+		},
+		{
+			name: "table cell",
+			html: `<table><tr><td>Ticker</td><td>ULTY</td></tr><tr><td>Inception Date</td><td>02/28/2024</td></tr></table>`,
+			want: marketDate(2024, 2, 28),
+		},
+		{
+			name: "label inside its block",
+			html: `<p><strong>Inception Date:</strong> 2023-10-18</p>`,
+			want: marketDate(2023, 10, 18),
+		},
+		{
+			// Funds older than the dividend sanity floor keep their date
+			name: "before MinDividendYear",
+			html: `<table><tr><td>Inception Date</td><td>08/07/2012</td></tr></table>`,
+			want: marketDate(2012, 8, 7),
+		},
+		{
+			name: "page text only",
+			html: `<div>YMAX, fund inception 11-Jan-2024, pays weekly.</div>`,
+			want: marketDate(2024, 1, 11),
+		},
+		{
+			// Other dates on the page aren't mistaken for the inception date
+			name: "not stated",
+			html: `<table><tr><td>Ex-Date</td><td>06/05/2025</td></tr><tr><td>As of</td><td>06/06/2025</td></tr></table>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var detail models.ETFDetail
+			extractKeyMetrics(parseFragment(t, tt.html), &detail)
+			if tt.want.IsZero() {
+				if detail.InceptionDate != nil {
+					t.Errorf("InceptionDate = %v, want nil", detail.InceptionDate)
+				}
+				return
+			}
+			if detail.InceptionDate == nil || !detail.InceptionDate.Equal(tt.want) {
+				t.Errorf("InceptionDate = %v, want %v", detail.InceptionDate, tt.want)
+			}
+		})
+	}
+
+	// The fund page fixture states it beside the other facts
+	var detail models.ETFDetail
+	extractKeyMetrics(loadFixture(t, "fund_page_tsly.html").Find("body"), &detail)
+	if detail.InceptionDate == nil || !detail.InceptionDate.Equal(marketDate(2022, 11, 22)) {
+		t.Errorf("TSLY InceptionDate = %v, want 2022-11-22", detail.InceptionDate)
+	}
+}
+
+func TestETFDetailBypassCacheScrapesFreshDetail(t *testing.T) {
+	site := newFixtureSite(t, map[string]string{"/our-etfs/tsly/": "fund_page_tsly.html"})
+	cacheDir := t.TempDir()
+	newScraper := func(bypass bool) *ETFDetailScraper {
+		s := NewETFDetailScraper().WithLimitRule(1, 0).WithCacheDir(cacheDir).WithTTL(time.Hour)
+		s.SetBaseURL(site.URL)
+		s.SetBypassCache(bypass)
+		return s
+	}
+	requests := func() int {
+		site.mu.Lock()
+		defer site.mu.Unlock()
+		return len(site.requested)
+	}
+
+	if _, err := newScraper(false).GetETFDetail("TSLY"); err != nil {
+		t.Fatalf("first scrape: %v", err)
+	}
+	if _, err := newScraper(false).GetETFDetail("TSLY"); err != nil || requests() != 1 {
+		t.Fatalf("cached read = %v after %d requests, want the cache to answer without one", err, requests())
+	}
+
+	// A corrected page would be missed while the cached entry is fresh
+	stale := models.ETFDetail{Symbol: "TSLY", Name: "Stale name"}
+	if err := cache.NewFileCache(cacheDir, time.Hour).Set("etf_detail_TSLY", stale); err != nil {
+		t.Fatal(err)
+	}
+
+	detail, err := newScraper(true).GetETFDetail("TSLY")
+	if err != nil {
+		t.Fatalf("refresh scrape: %v", err)
+	}
+	if requests() != 2 {
+		t.Errorf("site saw %d requests, want the refresh to fetch the page again", requests())
+	}
+	if detail.Name == "" || detail.Name == stale.Name {
+		t.Errorf("refresh returned the cached detail %+v", detail)
+	}
+
+	// The fresh detail replaced the cached one for the next normal run
+	cached, err := newScraper(false).GetETFDetail("TSLY")
+	if err != nil || cached.Name != detail.Name || requests() != 2 {
+		t.Errorf("cached detail after refresh = %+v, %v after %d requests, want %q from the cache", cached, err, requests(), detail.Name)
+	}
+}
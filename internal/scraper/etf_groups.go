@@ -1,12 +1,27 @@
 package scraper
 
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"divminder-crawler/internal/export"
+)
+
+// DefaultScrapedGroupsFile is where the improved scraper persists the group
+// mapping it scraped from the distribution schedule page
+const DefaultScrapedGroupsFile = "data/etf_groups_scraped.json"
+
 // GetYieldMaxETFGroups returns the correct group mappings for YieldMax ETFs
 // Based on official YieldMax distribution schedule
 func GetYieldMaxETFGroups() map[string]string {
 	return map[string]string{
 		// Target 12 ETFs (Monthly)
 		"BIGY": "Target12",
-		"SOXY": "Target12", 
+		"SOXY": "Target12",
 		"RNTY": "Target12",
 		"KLIP": "Target12",
 		"ALTY": "Target12",
@@ -35,7 +50,7 @@ func GetYieldMaxETFGroups() map[string]string {
 		"TSMY": "GroupA",
 		"APLY": "GroupA",
 
-		// Group B ETFs  
+		// Group B ETFs
 		"AMZY": "GroupB",
 		"FBY":  "GroupB",
 		"NFLY": "GroupB",
@@ -94,4 +109,66 @@ func GetETFNextDividendDates() map[string]DividendDates {
 type DividendDates struct {
 	ExDate  string
 	PayDate string
-}
\ No newline at end of file
+}
+
+// GroupDiff describes a symbol whose group differs between the scraped and static mappings.
+// An empty Scraped or Static value means the symbol is missing from that mapping.
+type GroupDiff struct {
+	Symbol  string
+	Scraped string
+	Static  string
+}
+
+// LoadScrapedETFGroups reads a previously persisted symbol -> group mapping
+func LoadScrapedETFGroups(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups map[string]string
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return nil, fmt.Errorf("failed to parse group mapping %s: %w", path, err)
+	}
+
+	return groups, nil
+}
+
+// SaveScrapedETFGroups atomically persists a symbol -> group mapping as JSON
+func SaveScrapedETFGroups(path string, groups map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+
+	data, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal group mapping: %w", err)
+	}
+
+	return export.WriteFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// DiffETFGroups compares a scraped mapping against the static one, sorted by symbol
+func DiffETFGroups(scraped, static map[string]string) []GroupDiff {
+	var diffs []GroupDiff
+
+	for symbol, group := range scraped {
+		if static[symbol] != group {
+			diffs = append(diffs, GroupDiff{Symbol: symbol, Scraped: group, Static: static[symbol]})
+		}
+	}
+	for symbol, group := range static {
+		if _, exists := scraped[symbol]; !exists {
+			diffs = append(diffs, GroupDiff{Symbol: symbol, Static: group})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Symbol < diffs[j].Symbol
+	})
+
+	return diffs
+}
@@ -0,0 +1,153 @@
+package scraper
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"divminder-crawler/internal/overrides"
+)
+
+func TestScrapedETFGroupsRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "etf_groups_scraped.json")
+	groups := map[string]string{"CONY": "GroupC", "ULTY": "Weekly", "NEWY": "GroupD"}
+
+	if err := SaveScrapedETFGroups(path, groups); err != nil {
+		t.Fatalf("SaveScrapedETFGroups: %v", err)
+	}
+	loaded, err := LoadScrapedETFGroups(path)
+	if err != nil {
+		t.Fatalf("LoadScrapedETFGroups: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, groups) {
+		t.Errorf("loaded %v, want %v", loaded, groups)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".*tmp-*")); len(matches) != 0 {
+		t.Errorf("temp files left behind: %v", matches)
+	}
+}
+
+func TestDiffETFGroups(t *testing.T) {
+	scraped := map[string]string{"CONY": "GroupC", "TSLY": "GroupB", "NEWY": "GroupD"}
+	static := map[string]string{"CONY": "GroupC", "TSLY": "GroupA", "OLDY": "Weekly"}
+
+	want := []GroupDiff{
+		{Symbol: "NEWY", Scraped: "GroupD"},
+		{Symbol: "OLDY", Static: "Weekly"},
+		{Symbol: "TSLY", Scraped: "GroupB", Static: "GroupA"},
+	}
+	if got := DiffETFGroups(scraped, static); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffETFGroups() = %+v, want %+v", got, want)
+	}
+	if got := DiffETFGroups(static, static); len(got) != 0 {
+		t.Errorf("identical mappings reported diffs: %+v", got)
+	}
+}
+
+func TestSetGroupsFileLoadsMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "groups.json")
+	if err := SaveScrapedETFGroups(path, map[string]string{"TSLY": "GroupB", "NEWY": "GroupD"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ys := NewImprovedYieldMaxScraper()
+	ys.SetGroupsFile(path)
+	if got := ys.etfGroups["TSLY"]; got != "GroupB" {
+		t.Errorf("TSLY group = %q, want the saved GroupB", got)
+	}
+	if got := ys.etfGroups["NEWY"]; got != "GroupD" {
+		t.Errorf("NEWY group = %q, want the saved GroupD", got)
+	}
+
+	ys.SetGroupsFile("")
+	if got := ys.etfGroups["TSLY"]; got != "GroupA" {
+		t.Errorf("TSLY group without a file = %q, want the static GroupA", got)
+	}
+}
+
+func TestGroupMappingConsistentAcrossScrapers(t *testing.T) {
+	static := GetYieldMaxETFGroups()
+	if static["CONY"] != "GroupC" {
+		t.Fatalf("CONY is in %s, want GroupC", static["CONY"])
+	}
+
+	improved := NewImprovedYieldMaxScraper()
+	improved.SetGroupsFile("")
+	etfs, err := improved.GetImprovedETFList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := make(map[string]string, len(etfs))
+	for _, etf := range etfs {
+		listed[etf.Symbol] = etf.Group
+	}
+
+	resolvers := map[string]*GroupResolver{
+		"GroupResolver":        NewGroupResolverFromFile(nil, ""),
+		"YieldMaxScraper":      NewYieldMaxScraper().groups,
+		"DividendTableScraper": NewDividendTableScraper().groups,
+	}
+
+	for symbol, group := range static {
+		if got := improved.etfGroups[symbol]; got != group {
+			t.Errorf("ImprovedYieldMaxScraper maps %s to %s, want %s", symbol, got, group)
+		}
+		if got, ok := listed[symbol]; ok && got != group {
+			t.Errorf("GetImprovedETFList puts %s in %s, want %s", symbol, got, group)
+		}
+		for name, resolver := range resolvers {
+			if got := resolver.ResolveGroup(symbol); got != group {
+				t.Errorf("%s resolves %s to %s, want %s", name, symbol, got, group)
+			}
+		}
+	}
+}
+
+// newTestGroupResolver resolves with an override moving TSLY to GroupB and a
+// scraped mapping that adds NEWY to GroupD
+func newTestGroupResolver(t *testing.T) *GroupResolver {
+	t.Helper()
+	groupsFile := filepath.Join(t.TempDir(), "groups.json")
+	if err := SaveScrapedETFGroups(groupsFile, map[string]string{"NEWY": "GroupD"}); err != nil {
+		t.Fatal(err)
+	}
+	return NewGroupResolverFromFile(overrides.Overrides{"TSLY": {Group: "GroupB"}}, groupsFile)
+}
+
+func TestResolveGroup(t *testing.T) {
+	resolver := newTestGroupResolver(t)
+
+	tests := []struct {
+		symbol string
+		want   string
+	}{
+		{"CONY", "GroupC"},
+		{" ulty ", "Weekly"},
+		{"TSLY", "GroupB"},
+		{"NEWY", "GroupD"},
+		// Unknown symbols are no longer guessed into GroupA
+		{"ZZZY", UnknownGroup},
+	}
+	for _, tt := range tests {
+		if got := resolver.ResolveGroup(tt.symbol); got != tt.want {
+			t.Errorf("ResolveGroup(%q) = %s, want %s", tt.symbol, got, tt.want)
+		}
+	}
+}
+
+func TestFullScraperListsResolvedGroups(t *testing.T) {
+	s := NewYieldMaxFullScraper()
+	s.SetGroupResolver(newTestGroupResolver(t))
+	s.SetDiscoveredSymbols([]string{"ZZZY"})
+
+	groups := make(map[string]string)
+	for _, etf := range s.listETFs(nil) {
+		groups[etf.Symbol] = etf.Group
+	}
+	want := map[string]string{"CONY": "GroupC", "TSLY": "GroupB", "NEWY": "GroupD", "ZZZY": UnknownGroup}
+	for symbol, group := range want {
+		if groups[symbol] != group {
+			t.Errorf("%s listed in %q, want %s", symbol, groups[symbol], group)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/models"
+)
+
+// fixtureSite serves testdata pages at their paths on the YieldMax site and
+// records which paths were requested
+type fixtureSite struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	requested []string
+}
+
+// newFixtureSite serves each fixture at its path, answering 404 elsewhere
+func newFixtureSite(t *testing.T, pages map[string]string) *fixtureSite {
+	t.Helper()
+	bodies := make(map[string][]byte, len(pages))
+	for path, fixture := range pages {
+		body, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bodies[path] = body
+	}
+
+	site := &fixtureSite{}
+	site.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		site.mu.Lock()
+		site.requested = append(site.requested, r.URL.Path)
+		site.mu.Unlock()
+
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(body)
+	}))
+	t.Cleanup(site.Close)
+	return site
+}
+
+// wasRequested reports whether path was fetched from the site
+func (s *fixtureSite) wasRequested(path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, requested := range s.requested {
+		if requested == path {
+			return true
+		}
+	}
+	return false
+}
+
+// fixtureNow is the fake clock time of the fixture tests, a Tuesday after
+// the latest distribution in the fixtures
+var fixtureNow = time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+
+// fundPages maps each fund fixture to its page on the site
+var fundPages = map[string]string{
+	"/our-etfs/ulty/": "dividend_page_ulty.html",
+	"/our-etfs/ymax/": "fund_page_ymax.html",
+	"/our-etfs/msty/": "dividend_page_script.html",
+	"/our-etfs/tsly/": "fund_page_tsly.html",
+}
+
+func eventAmounts(events []models.DividendEvent) []float64 {
+	amounts := make([]float64, len(events))
+	for i, e := range events {
+		amounts[i] = e.Amount
+	}
+	return amounts
+}
+
+func sameAmounts(got, want []float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if !almostEqual(got[i], want[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDividendTableScraperFixtures(t *testing.T) {
+	site := newFixtureSite(t, fundPages)
+
+	tests := []struct {
+		name      string
+		symbol    string
+		amounts   []float64
+		lastEx    time.Time
+		frequency string
+	}{
+		{
+			name:      "weekly wpDataTable",
+			symbol:    "ULTY",
+			amounts:   []float64{0.094, 0.093, 0.095, 0.101},
+			lastEx:    marketDate(2025, 6, 5),
+			frequency: "weekly",
+		},
+		{
+			name:      "weekly detail page with amount first",
+			symbol:    "YMAX",
+			amounts:   []float64{0.112, 0.1085, 0.115, 0.121, 0.119},
+			lastEx:    marketDate(2025, 6, 5),
+			frequency: "weekly",
+		},
+		{
+			// The script repeats the table's rows, which must not be counted twice
+			name:      "wpDataTables script variant",
+			symbol:    "MSTY",
+			amounts:   []float64{1.248, 1.3845, 1.3517},
+			lastEx:    marketDate(2025, 5, 29),
+			frequency: "monthly",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history, err := newTestTableScraper(t, site.URL, nil).ScrapeDividendHistory(tt.symbol)
+			if err != nil {
+				t.Fatalf("ScrapeDividendHistory(%s): %v", tt.symbol, err)
+			}
+
+			if got := eventAmounts(history.Events); !sameAmounts(got, tt.amounts) {
+				t.Errorf("amounts = %v, want %v", got, tt.amounts)
+			}
+			if len(history.Events) > 0 && !history.Events[0].ExDate.Equal(tt.lastEx) {
+				t.Errorf("latest ex-date = %v, want %v", history.Events[0].ExDate, tt.lastEx)
+			}
+			if history.Frequency != tt.frequency {
+				t.Errorf("Frequency = %q, want %q", history.Frequency, tt.frequency)
+			}
+		})
+	}
+}
+
+func TestDividendTableScraperMergesDuplicateRows(t *testing.T) {
+	// The table repeats its 06/05 and 05/29 rows
+	site := newFixtureSite(t, map[string]string{"/our-etfs/ulty/": "dividend_page_duplicates.html"})
+
+	history, err := newTestTableScraper(t, site.URL, nil).ScrapeDividendHistory("ULTY")
+	if err != nil {
+		t.Fatalf("ScrapeDividendHistory: %v", err)
+	}
+	if got, want := eventAmounts(history.Events), []float64{0.094, 0.093, 0.095}; !sameAmounts(got, want) {
+		t.Errorf("amounts = %v, want %v", got, want)
+	}
+	if history.Stats.TotalPayments != 3 {
+		t.Errorf("stats count %d payments, want 3", history.Stats.TotalPayments)
+	}
+}
+
+func TestETFDetailScrapersFixtures(t *testing.T) {
+	site := newFixtureSite(t, fundPages)
+
+	tests := []struct {
+		name      string
+		symbol    string
+		amounts   []float64
+		lastEx    time.Time
+		lastPay   time.Time
+		frequency string
+		yield     float64
+		secYield  float64
+	}{
+		{
+			name:      "monthly detail page",
+			symbol:    "TSLY",
+			amounts:   []float64{0.437, 0.3998, 0.501},
+			lastEx:    marketDate(2025, 6, 5),
+			lastPay:   marketDate(2025, 6, 6),
+			frequency: "monthly",
+			yield:     66.81,
+			secYield:  3.05,
+		},
+		{
+			name:      "weekly detail page",
+			symbol:    "YMAX",
+			amounts:   []float64{0.112, 0.1085, 0.115, 0.121, 0.119},
+			lastEx:    marketDate(2025, 6, 5),
+			lastPay:   marketDate(2025, 6, 6),
+			frequency: "weekly",
+		},
+	}
+
+	scrapers := []struct {
+		name   string
+		detail func(symbol string) (*models.ETFDetail, error)
+	}{
+		{
+			name: "ETFDetailScraper",
+			detail: func(symbol string) (*models.ETFDetail, error) {
+				s := NewETFDetailScraper().WithLimitRule(1, 0)
+				s.SetBaseURL(site.URL)
+				return s.GetETFDetail(symbol)
+			},
+		},
+		{
+			name: "YieldMaxFullScraper",
+			detail: func(symbol string) (*models.ETFDetail, error) {
+				s := NewYieldMaxFullScraper()
+				s.SetBaseURL(site.URL)
+				return s.ScrapeETFDetails(symbol)
+			},
+		},
+	}
+
+	for _, sc := range scrapers {
+		for _, tt := range tests {
+			t.Run(sc.name+"/"+tt.name, func(t *testing.T) {
+				detail, err := sc.detail(tt.symbol)
+				if err != nil {
+					t.Fatalf("detail(%s): %v", tt.symbol, err)
+				}
+
+				if got := eventAmounts(detail.DividendHistory); !sameAmounts(got, tt.amounts) {
+					t.Fatalf("amounts = %v, want %v", got, tt.amounts)
+				}
+				latest := detail.DividendHistory[0]
+				if !latest.ExDate.Equal(tt.lastEx) || !latest.PayDate.Equal(tt.lastPay) {
+					t.Errorf("latest ex/pay = %v/%v, want %v/%v", latest.ExDate, latest.PayDate, tt.lastEx, tt.lastPay)
+				}
+				if detail.Frequency != tt.frequency {
+					t.Errorf("Frequency = %q, want %q", detail.Frequency, tt.frequency)
+				}
+				if !almostEqual(detail.DistributionRate, tt.yield) || !almostEqual(detail.CurrentYield, tt.yield) {
+					t.Errorf("DistributionRate/CurrentYield = %v/%v, want %v", detail.DistributionRate, detail.CurrentYield, tt.yield)
+				}
+				if !almostEqual(detail.SECYield, tt.secYield) {
+					t.Errorf("SECYield = %v, want %v", detail.SECYield, tt.secYield)
+				}
+			})
+		}
+	}
+}
+
+func TestETFDetailScrapersMissingPage(t *testing.T) {
+	site := newFixtureSite(t, fundPages)
+
+	s := NewETFDetailScraper().WithLimitRule(1, 0)
+	s.SetBaseURL(site.URL)
+	if _, err := s.GetETFDetail("GONE"); err == nil {
+		t.Error("ETFDetailScraper: expected an error for a missing page")
+	}
+
+	full := NewYieldMaxFullScraper()
+	full.SetBaseURL(site.URL)
+	if _, err := full.ScrapeETFDetails("GONE"); err == nil {
+		t.Error("YieldMaxFullScraper: expected an error for a missing page")
+	}
+	if !site.wasRequested("/our-etfs/gone/") {
+		t.Errorf("requests went to %v, not the base URL", site.requested)
+	}
+}
+
+func TestImprovedScraperSchedulePage(t *testing.T) {
+	site := newFixtureSite(t, map[string]string{"/distribution-schedule/": "schedule_page.html"})
+
+	groupsFile := filepath.Join(t.TempDir(), "groups.json")
+	ys := NewImprovedYieldMaxScraper().WithLimitRule(1, 0)
+	ys.SetBaseURL(site.URL)
+	ys.SetGroupsFile(groupsFile)
+	ys.SetClock(clock.NewFake(fixtureNow))
+
+	schedule, err := ys.GetScheduleImproved()
+	if err != nil {
+		t.Fatalf("GetScheduleImproved: %v", err)
+	}
+
+	groups := make(map[string]models.GroupSchedule)
+	for _, g := range schedule.Groups {
+		groups[g.Group] = g
+	}
+
+	t.Run("group mapping", func(t *testing.T) {
+		scraped, err := LoadScrapedETFGroups(groupsFile)
+		if err != nil {
+			t.Fatalf("scraped mapping not saved: %v", err)
+		}
+		if len(scraped) != 13 {
+			t.Errorf("saved %d scraped mappings, want 13", len(scraped))
+		}
+		if scraped["NEWY"] != "GroupB" {
+			t.Errorf("NEWY mapped to %q, want GroupB", scraped["NEWY"])
+		}
+		if !containsString(groups["GroupB"].ETFs, "NEWY") {
+			t.Errorf("GroupB ETFs %v missing NEWY", groups["GroupB"].ETFs)
+		}
+	})
+
+	t.Run("target 12 rows", func(t *testing.T) {
+		// Rows before the clock are past; the remaining two apply to every member
+		var exDates []time.Time
+		for _, e := range groups["Target12"].Events {
+			if e.Symbol != "BIGY" {
+				continue
+			}
+			exDates = append(exDates, e.ExDate)
+			if e.Frequency != "monthly" || !e.Estimated || !almostEqual(e.Amount, target12PlaceholderAmount) {
+				t.Errorf("BIGY event %+v, want an estimated monthly placeholder", e)
+			}
+		}
+		want := []time.Time{marketDate(2025, 7, 2), marketDate(2025, 8, 6)}
+		if len(exDates) != len(want) {
+			t.Fatalf("BIGY ex-dates = %v, want %v", exDates, want)
+		}
+		for i := range want {
+			if !exDates[i].Equal(want[i]) {
+				t.Errorf("BIGY ex-date %d = %v, want %v", i, exDates[i], want[i])
+			}
+		}
+	})
+
+	t.Run("announced amount", func(t *testing.T) {
+		found := false
+		for _, e := range schedule.Upcoming {
+			if e.Symbol == "ULTY" && e.ExDate.Equal(marketDate(2025, 6, 12)) {
+				found = true
+				if !almostEqual(e.Amount, 0.0965) || e.Estimated {
+					t.Errorf("ULTY 06/12 = %v (estimated %v), want announced 0.0965", e.Amount, e.Estimated)
+				}
+			}
+		}
+		if !found {
+			t.Error("no upcoming ULTY event on 06/12")
+		}
+	})
+
+	t.Run("upcoming window", func(t *testing.T) {
+		cutoff := marketDate(2025, 7, 10)
+		for _, e := range schedule.Upcoming {
+			if !e.ExDate.After(marketDate(2025, 6, 10)) || !e.ExDate.Before(cutoff) {
+				t.Errorf("upcoming %s on %v outside the 30-day window", e.Symbol, e.ExDate)
+			}
+			if e.Frequency != "weekly" && e.Frequency != "monthly" {
+				t.Errorf("upcoming %s has frequency %q", e.Symbol, e.Frequency)
+			}
+		}
+	})
+}
+
+func TestScheduleScrapersUseBaseURL(t *testing.T) {
+	site := newFixtureSite(t, map[string]string{"/distribution-schedule/": "schedule_page.html"})
+
+	ys := NewYieldMaxScraper().WithLimitRule(1, 0)
+	ys.SetBaseURL(site.URL)
+	schedule, err := ys.GetSchedule()
+	if err != nil {
+		t.Fatalf("GetSchedule: %v", err)
+	}
+	var groupB []string
+	for _, g := range schedule.Groups {
+		if g.Group == "GroupB" {
+			groupB = g.ETFs
+		}
+	}
+	if !containsString(groupB, "NEWY") {
+		t.Errorf("GetSchedule GroupB = %v, want NEWY included", groupB)
+	}
+
+	full := NewYieldMaxFullScraper()
+	full.SetBaseURL(site.URL)
+	if _, err := full.ScrapeDistributionSchedule(); err != nil {
+		t.Errorf("ScrapeDistributionSchedule: %v", err)
+	}
+	if err := full.CheckSchedulePage(); err != nil {
+		t.Errorf("CheckSchedulePage: %v", err)
+	}
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
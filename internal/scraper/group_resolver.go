@@ -0,0 +1,106 @@
+package scraper
+
+import (
+	"log"
+	"sort"
+	"strings"
+
+	"divminder-crawler/internal/overrides"
+)
+
+// UnknownGroup is the group of a symbol that no override or mapping knows,
+// reported instead of guessing a rotation group
+const UnknownGroup = "Unknown"
+
+// GroupResolver looks up a symbol's distribution group. Precedence follows
+// the overrides package: an override wins over the mapping scraped from the
+// schedule page, which wins over the static GetYieldMaxETFGroups mapping.
+type GroupResolver struct {
+	overrides overrides.Overrides
+	scraped   map[string]string
+	static    map[string]string
+}
+
+// NewGroupResolver creates a resolver from o, which may be nil, and the
+// mapping the improved scraper last saved to DefaultScrapedGroupsFile
+func NewGroupResolver(o overrides.Overrides) *GroupResolver {
+	return NewGroupResolverFromFile(o, DefaultScrapedGroupsFile)
+}
+
+// NewGroupResolverFromFile is NewGroupResolver reading the scraped mapping
+// from groupsFile; an empty path uses only overrides and the static mapping
+func NewGroupResolverFromFile(o overrides.Overrides, groupsFile string) *GroupResolver {
+	var scraped map[string]string
+	if groupsFile != "" {
+		// A missing or unreadable file leaves only the static mapping
+		scraped, _ = LoadScrapedETFGroups(groupsFile)
+	}
+	return &GroupResolver{
+		overrides: o,
+		scraped:   scraped,
+		static:    GetYieldMaxETFGroups(),
+	}
+}
+
+// ResolveGroup returns symbol's group, or UnknownGroup with a logged warning
+// when nothing maps it
+func (r *GroupResolver) ResolveGroup(symbol string) string {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if override, ok := r.overrides.Lookup(symbol); ok && override.Group != "" {
+		return override.Group
+	}
+	if group, ok := r.scraped[symbol]; ok && group != "" {
+		return group
+	}
+	if group, ok := r.static[symbol]; ok {
+		return group
+	}
+
+	log.Printf("Warning: %s is not in any group mapping or override, marking it %s", symbol, UnknownGroup)
+	return UnknownGroup
+}
+
+// Symbols returns every symbol the resolver knows, from overrides that set a
+// group and from both mappings, in alphabetical order. Renamed tickers are
+// left out.
+func (r *GroupResolver) Symbols() []string {
+	known := make(map[string]bool, len(r.static))
+	for symbol, override := range r.overrides {
+		if override.Group != "" && override.RenamedTo == "" {
+			known[symbol] = true
+		}
+	}
+	for _, mapping := range []map[string]string{r.scraped, r.static} {
+		for symbol, group := range mapping {
+			symbol = strings.ToUpper(strings.TrimSpace(symbol))
+			if override, ok := r.overrides.Lookup(symbol); group != "" && (!ok || override.RenamedTo == "") {
+				known[symbol] = true
+			}
+		}
+	}
+
+	symbols := make([]string, 0, len(known))
+	for symbol := range known {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// ResolveFrequency returns symbol's overridden frequency, or the usual
+// frequency of its group
+func (r *GroupResolver) ResolveFrequency(symbol, group string) string {
+	if override, ok := r.overrides.Lookup(symbol); ok && override.Frequency != "" {
+		return override.Frequency
+	}
+	return GroupFrequency(group)
+}
+
+// GroupFrequency returns how often a group's funds pay: Target12 funds pay
+// monthly, the weekly and rotating groups weekly
+func GroupFrequency(group string) string {
+	if group == "Target12" {
+		return "monthly"
+	}
+	return "weekly"
+}
@@ -0,0 +1,31 @@
+package scraper
+
+import (
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// yieldMaxDomainGlob matches the YieldMax pages the crawl limits apply to
+const yieldMaxDomainGlob = "*yieldmaxetfs.com*"
+
+// applyLimit adds a limit rule for YieldMax pages to c and returns it so the
+// scraper's WithLimitRule can change it later
+func applyLimit(c *colly.Collector, parallelism int, delay time.Duration) *colly.LimitRule {
+	rule := &colly.LimitRule{
+		DomainGlob:  yieldMaxDomainGlob,
+		Parallelism: parallelism,
+		Delay:       delay,
+	}
+	c.Limit(rule)
+	return rule
+}
+
+// setLimit changes rule in place, since colly uses the first matching rule
+// and adding another wouldn't override it. The collector's clones share the
+// rule, so it applies to them too; it must not be called mid-crawl
+func setLimit(rule *colly.LimitRule, parallelism int, delay time.Duration) {
+	rule.Parallelism = parallelism
+	rule.Delay = delay
+	rule.Init()
+}
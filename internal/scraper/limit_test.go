@@ -0,0 +1,93 @@
+package scraper
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+func TestWithLimitRuleDefaultsAndOverride(t *testing.T) {
+	table := NewDividendTableScraper()
+	detail := NewETFDetailScraper()
+	schedule := NewYieldMaxScraper()
+	improved := NewImprovedYieldMaxScraper()
+
+	tests := []struct {
+		name            string
+		limit           *colly.LimitRule
+		override        func(parallelism int, delay time.Duration)
+		wantParallelism int
+		wantDelay       time.Duration
+	}{
+		{"DividendTableScraper", table.limit, func(p int, d time.Duration) { table.WithLimitRule(p, d) }, 1, 2 * time.Second},
+		{"ETFDetailScraper", detail.limit, func(p int, d time.Duration) { detail.WithLimitRule(p, d) }, 1, 2 * time.Second},
+		{"YieldMaxScraper", schedule.limit, func(p int, d time.Duration) { schedule.WithLimitRule(p, d) }, 2, time.Second},
+		{"ImprovedYieldMaxScraper", improved.limit, func(p int, d time.Duration) { improved.WithLimitRule(p, d) }, 2, 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.limit.Parallelism != tt.wantParallelism || tt.limit.Delay != tt.wantDelay {
+				t.Errorf("default limit = %d/%v, want %d/%v", tt.limit.Parallelism, tt.limit.Delay, tt.wantParallelism, tt.wantDelay)
+			}
+			if !tt.limit.Match("www.yieldmaxetfs.com") {
+				t.Error("limit rule doesn't match the YieldMax site")
+			}
+
+			tt.override(3, 10*time.Millisecond)
+			if tt.limit.Parallelism != 3 || tt.limit.Delay != 10*time.Millisecond {
+				t.Errorf("limit after WithLimitRule = %d/%v, want 3/10ms", tt.limit.Parallelism, tt.limit.Delay)
+			}
+		})
+	}
+}
+
+func TestWithLimitRuleAppliesToCollector(t *testing.T) {
+	page, err := os.ReadFile(filepath.Join("testdata", "dividend_page_ulty.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(page)
+	}))
+	defer server.Close()
+
+	// Requests for the YieldMax host, which the limit rule matches, reach the test server
+	toServer := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}
+
+	const delay = 300 * time.Millisecond
+	tests := []struct {
+		name    string
+		delay   time.Duration
+		atLeast time.Duration
+		under   time.Duration
+	}{
+		{name: "delay applied", delay: delay, atLeast: delay, under: time.Minute},
+		{name: "near-zero delay", delay: 0, under: delay},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestTableScraper(t, "http://www.yieldmaxetfs.com", nil).WithLimitRule(1, tt.delay)
+			s.collector.WithTransport(toServer)
+
+			start := time.Now()
+			if _, err := s.ScrapeDividendHistory("ULTY"); err != nil {
+				t.Fatalf("ScrapeDividendHistory: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed < tt.atLeast || elapsed >= tt.under {
+				t.Errorf("scrape took %v, want at least %v and under %v", elapsed, tt.atLeast, tt.under)
+			}
+		})
+	}
+}
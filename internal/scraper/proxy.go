@@ -0,0 +1,14 @@
+package scraper
+
+import (
+	"divminder-crawler/internal/proxy"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// applyProxy routes a collector through the configured proxy, if any
+func applyProxy(c *colly.Collector) {
+	if proxyFunc := proxy.Func(); proxyFunc != nil {
+		c.SetProxyFunc(proxyFunc)
+	}
+}
@@ -0,0 +1,43 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"divminder-crawler/internal/proxy"
+)
+
+func TestCollectorsRouteThroughProxy(t *testing.T) {
+	page, err := os.ReadFile(filepath.Join("testdata", "dividend_page_ulty.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var proxied []string
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Write(page)
+	}))
+	defer proxyServer.Close()
+
+	if err := proxy.Configure(proxyServer.URL); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { proxy.Set(nil) })
+
+	// The site doesn't resolve, so the page can only arrive via the proxy
+	s := newTestTableScraper(t, "http://fund.invalid", nil)
+	history, err := s.ScrapeDividendHistory("ULTY")
+	if err != nil {
+		t.Fatalf("scrape through proxy: %v", err)
+	}
+	if len(history.Events) != 4 {
+		t.Errorf("parsed %d events through the proxy, want 4", len(history.Events))
+	}
+	if len(proxied) != 1 || proxied[0] != "http://fund.invalid/our-etfs/ulty/" {
+		t.Errorf("proxy saw %v, want the fund page request", proxied)
+	}
+}
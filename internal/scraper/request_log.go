@@ -0,0 +1,157 @@
+package scraper
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const tableFoundKey = "requestLogTableFound"
+
+// RequestRecord is the outcome of one scrape request. The URL has its query,
+// fragment and credentials removed so records are safe to share.
+type RequestRecord struct {
+	URL           string
+	Status        int // 0 when the request failed before a response
+	ContentLength int
+	TableFound    bool
+}
+
+// Unexpected reports whether the request didn't return a usable page; 304 is
+// expected from conditional requests
+func (r RequestRecord) Unexpected() bool {
+	return r.Status != http.StatusOK && r.Status != http.StatusNotModified
+}
+
+// RequestLog records scrape request outcomes at debug level, to tell a
+// blocked request (403) from a page that loaded but had no table
+type RequestLog struct {
+	mu      sync.Mutex
+	logger  *logrus.Logger
+	records []RequestRecord
+}
+
+// NewRequestLog creates a request log writing to logger
+func NewRequestLog(logger *logrus.Logger) *RequestLog {
+	return &RequestLog{logger: logger}
+}
+
+// Observe registers the logging hooks on c. Callbacks aren't copied by
+// Clone, so clones must be observed separately.
+func (l *RequestLog) Observe(c *colly.Collector) {
+	c.OnHTML("table", func(e *colly.HTMLElement) {
+		e.Request.Ctx.Put(tableFoundKey, "1")
+	})
+	c.OnScraped(func(r *colly.Response) {
+		l.record(r, r.Ctx.Get(tableFoundKey) != "")
+	})
+	c.OnError(func(r *colly.Response, err error) {
+		l.record(r, false)
+	})
+}
+
+func (l *RequestLog) record(r *colly.Response, tableFound bool) {
+	record := RequestRecord{
+		Status:        r.StatusCode,
+		ContentLength: len(r.Body),
+		TableFound:    tableFound,
+	}
+	if r.Request != nil {
+		record.URL = anonymizeURL(r.Request.URL)
+	}
+
+	l.mu.Lock()
+	l.records = append(l.records, record)
+	l.mu.Unlock()
+
+	l.logger.WithFields(logrus.Fields{
+		"url":           record.URL,
+		"status":        record.Status,
+		"contentLength": record.ContentLength,
+		"tableFound":    record.TableFound,
+	}).Debug("Scrape request finished")
+}
+
+// Records returns a copy of everything recorded so far
+func (l *RequestLog) Records() []RequestRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]RequestRecord(nil), l.records...)
+}
+
+// Unexpected returns how many requests got a status other than 200 or 304
+func (l *RequestLog) Unexpected() int {
+	unexpected := 0
+	for _, record := range l.Records() {
+		if record.Unexpected() {
+			unexpected++
+		}
+	}
+	return unexpected
+}
+
+// LogSummary logs how many requests were made and how many returned
+// unexpected statuses, broken down by status
+func (l *RequestLog) LogSummary() {
+	records := l.Records()
+	byStatus := make(map[int]int)
+	for _, record := range records {
+		if record.Unexpected() {
+			byStatus[record.Status]++
+		}
+	}
+	l.logger.WithField("unexpectedByStatus", byStatus).Debugf("%d scrape requests, %d with unexpected statuses", len(records), l.Unexpected())
+}
+
+func anonymizeURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	clean := *u
+	clean.User = nil
+	clean.RawQuery = ""
+	clean.ForceQuery = false
+	clean.Fragment = ""
+	clean.RawFragment = ""
+	return clean.String()
+}
+
+var (
+	requestLogMu sync.RWMutex
+	requestLog   *RequestLog
+)
+
+// SetRequestLogging turns per-request debug logging on or off for collectors
+// created afterwards
+func SetRequestLogging(enabled bool) {
+	requestLogMu.Lock()
+	defer requestLogMu.Unlock()
+	if !enabled {
+		requestLog = nil
+		return
+	}
+	logger := logrus.New()
+	logger.SetLevel(logrus.DebugLevel)
+	requestLog = NewRequestLog(logger)
+}
+
+// LogRequestSummary logs the request summary when request logging is on
+func LogRequestSummary() {
+	requestLogMu.RLock()
+	defer requestLogMu.RUnlock()
+	if requestLog != nil {
+		requestLog.LogSummary()
+	}
+}
+
+// observeRequests registers the process-wide request log on c, if enabled
+func observeRequests(c *colly.Collector) {
+	requestLogMu.RLock()
+	defer requestLogMu.RUnlock()
+	if requestLog != nil {
+		requestLog.Observe(c)
+	}
+}
@@ -0,0 +1,89 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestRequestLogRecordsStatuses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/blocked/":
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		case "/table/":
+			w.Write([]byte(`<html><body><table><tr><td>0.10</td></tr></table></body></html>`))
+		default:
+			w.Write([]byte(`<html><body><p>No distributions</p></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	logger, hook := test.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+	requestLog := NewRequestLog(logger)
+
+	c := colly.NewCollector()
+	requestLog.Observe(c)
+	for _, path := range []string{"/blocked/", "/table/", "/empty/?token=secret"} {
+		c.Visit(server.URL + path)
+	}
+
+	records := requestLog.Records()
+	want := []RequestRecord{
+		{URL: server.URL + "/blocked/", Status: http.StatusForbidden},
+		{URL: server.URL + "/table/", Status: http.StatusOK, TableFound: true},
+		{URL: server.URL + "/empty/", Status: http.StatusOK},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("recorded %+v, want %d requests", records, len(want))
+	}
+	for i, w := range want {
+		got := records[i]
+		if got.URL != w.URL || got.Status != w.Status || got.TableFound != w.TableFound {
+			t.Errorf("record %d = %+v, want %+v", i, got, w)
+		}
+		if got.ContentLength == 0 {
+			t.Errorf("record %d has no content length", i)
+		}
+	}
+	if got := requestLog.Unexpected(); got != 1 {
+		t.Errorf("Unexpected() = %d, want 1", got)
+	}
+
+	requestLog.LogSummary()
+	summary := hook.LastEntry()
+	if summary == nil || summary.Level != logrus.DebugLevel {
+		t.Fatalf("summary entry = %+v, want a debug entry", summary)
+	}
+	if byStatus, _ := summary.Data["unexpectedByStatus"].(map[int]int); byStatus[http.StatusForbidden] != 1 || len(byStatus) != 1 {
+		t.Errorf("unexpectedByStatus = %v, want one 403", summary.Data["unexpectedByStatus"])
+	}
+}
+
+func TestRequestLogStaysQuietAboveDebug(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	logger, hook := test.NewNullLogger()
+	logger.SetLevel(logrus.InfoLevel)
+	requestLog := NewRequestLog(logger)
+
+	c := colly.NewCollector()
+	requestLog.Observe(c)
+	c.Visit(server.URL)
+	requestLog.LogSummary()
+
+	if len(requestLog.Records()) != 1 {
+		t.Errorf("recorded %d requests, want 1", len(requestLog.Records()))
+	}
+	if len(hook.AllEntries()) != 0 {
+		t.Errorf("logged %d entries at info level, want none", len(hook.AllEntries()))
+	}
+}
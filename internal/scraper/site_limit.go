@@ -0,0 +1,77 @@
+package scraper
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"divminder-crawler/internal/api"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// yieldMaxHost is the site the process-wide request cap applies to
+const yieldMaxHost = "yieldmaxetfs.com"
+
+var (
+	siteLimitMu sync.RWMutex
+	siteLimiter *api.RateLimiter
+)
+
+// SetSiteRateLimit caps requests to yieldmaxetfs.com at perMinute across
+// every scraper in the process, on top of each scraper's own limit rule; 0
+// removes the cap. It must be called before scraping starts.
+func SetSiteRateLimit(perMinute int) {
+	siteLimitMu.Lock()
+	defer siteLimitMu.Unlock()
+	if siteLimiter != nil {
+		siteLimiter.Stop()
+		siteLimiter = nil
+	}
+	if perMinute > 0 {
+		// A single token keeps bursts from exceeding the cap
+		siteLimiter = api.NewRateLimiter(1, time.Minute/time.Duration(perMinute))
+	}
+}
+
+// waitForSite blocks until a request to u may be sent under the site cap
+func waitForSite(u *url.URL) {
+	if u == nil || !isYieldMaxHost(u.Hostname()) {
+		return
+	}
+	siteLimitMu.RLock()
+	limiter := siteLimiter
+	siteLimitMu.RUnlock()
+	if limiter != nil {
+		limiter.Wait()
+	}
+}
+
+func isYieldMaxHost(host string) bool {
+	host = strings.ToLower(host)
+	return host == yieldMaxHost || strings.HasSuffix(host, "."+yieldMaxHost)
+}
+
+// throttleRequests makes c's requests wait for the site cap. Callbacks
+// aren't copied by Clone, so clones must be throttled separately.
+func throttleRequests(c *colly.Collector) {
+	c.OnRequest(func(r *colly.Request) {
+		waitForSite(r.URL)
+	})
+}
+
+// siteLimitedTransport applies the site cap to plain HTTP clients
+type siteLimitedTransport struct {
+	base http.RoundTripper // nil uses http.DefaultTransport
+}
+
+func (t siteLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	waitForSite(req.URL)
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
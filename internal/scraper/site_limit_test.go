@@ -0,0 +1,116 @@
+package scraper
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSiteRateLimitCapsAllScrapers(t *testing.T) {
+	page, err := os.ReadFile(filepath.Join("testdata", "dividend_page_ulty.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var arrivals []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		mu.Unlock()
+		w.Write(page)
+	}))
+	defer server.Close()
+
+	// Requests for the YieldMax host, which the cap applies to, reach the test server
+	toServer := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}
+
+	// 600 a minute is one request every 100ms across the process
+	const interval = 100 * time.Millisecond
+	SetSiteRateLimit(600)
+	t.Cleanup(func() { SetSiteRateLimit(0) })
+
+	table := newTestTableScraper(t, "http://www.yieldmaxetfs.com", nil).WithLimitRule(1, 0)
+	table.collector.WithTransport(toServer)
+	detail := NewETFDetailScraper().WithLimitRule(1, 0)
+	detail.SetBaseURL("http://www.yieldmaxetfs.com")
+	detail.collector.WithTransport(toServer)
+
+	// Each scraper alone stays under its own limit rule; together they must
+	// still share the site cap. The server answers every path with the same page.
+	tableSymbols := []string{"ULTY", "YMAX", "MSTY"}
+	detailSymbols := []string{"TSLY", "NVDY", "CONY"}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for _, symbol := range tableSymbols {
+			if _, err := table.ScrapeDividendHistory(symbol); err != nil {
+				t.Errorf("table scrape: %v", err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for _, symbol := range detailSymbols {
+			if _, err := detail.GetETFDetail(symbol); err != nil {
+				t.Errorf("detail scrape: %v", err)
+			}
+		}
+	}()
+	wg.Wait()
+
+	if want := len(tableSymbols) + len(detailSymbols); len(arrivals) != want {
+		t.Fatalf("server saw %d requests, want %d", len(arrivals), want)
+	}
+	sort.Slice(arrivals, func(i, j int) bool { return arrivals[i].Before(arrivals[j]) })
+	// Allow for ticker jitter, but never two requests in one interval
+	for i := 1; i < len(arrivals); i++ {
+		if gap := arrivals[i].Sub(arrivals[i-1]); gap < interval*8/10 {
+			t.Errorf("requests %d and %d arrived %v apart, want about %v", i-1, i, gap, interval)
+		}
+	}
+}
+
+func TestSiteRateLimitOnlyAppliesToYieldMax(t *testing.T) {
+	SetSiteRateLimit(1)
+	t.Cleanup(func() { SetSiteRateLimit(0) })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// At one request a minute, a second throttled request would block the test
+	client := &http.Client{Transport: siteLimitedTransport{}, Timeout: 5 * time.Second}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("requests to another host took %v, want no wait", elapsed)
+	}
+
+	for host, want := range map[string]bool{
+		"yieldmaxetfs.com":      true,
+		"www.YieldMaxETFs.com":  true,
+		"notyieldmaxetfs.com":   false,
+		"yieldmaxetfs.com.evil": false,
+	} {
+		if got := isYieldMaxHost(host); got != want {
+			t.Errorf("isYieldMaxHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
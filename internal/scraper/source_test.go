@@ -0,0 +1,146 @@
+package scraper
+
+import (
+	"path/filepath"
+	"testing"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/models"
+)
+
+// assertSource fails for every event not stamped with source and the given estimated flag
+func assertSource(t *testing.T, events []models.DividendEvent, source string, estimated bool) {
+	t.Helper()
+	if len(events) == 0 {
+		t.Fatal("no events")
+	}
+	for _, e := range events {
+		if e.Source != source || e.Estimated != estimated {
+			t.Errorf("%s %v: source %q (estimated %v), want %q (estimated %v)",
+				e.Symbol, e.ExDate.Format("2006-01-02"), e.Source, e.Estimated, source, estimated)
+		}
+	}
+}
+
+func TestScrapedHistoriesStampYieldMaxSource(t *testing.T) {
+	site := newFixtureSite(t, fundPages)
+
+	producers := []struct {
+		name   string
+		events func() ([]models.DividendEvent, error)
+	}{
+		{
+			name: "dividend table",
+			events: func() ([]models.DividendEvent, error) {
+				history, err := newTestTableScraper(t, site.URL, nil).ScrapeDividendHistory("ULTY")
+				if err != nil {
+					return nil, err
+				}
+				return history.Events, nil
+			},
+		},
+		{
+			name: "dividend table script",
+			events: func() ([]models.DividendEvent, error) {
+				history, err := newTestTableScraper(t, site.URL, nil).ScrapeDividendHistory("MSTY")
+				if err != nil {
+					return nil, err
+				}
+				return history.Events, nil
+			},
+		},
+		{
+			name: "detail page",
+			events: func() ([]models.DividendEvent, error) {
+				s := NewETFDetailScraper().WithLimitRule(1, 0)
+				s.SetBaseURL(site.URL)
+				detail, err := s.GetETFDetail("TSLY")
+				if err != nil {
+					return nil, err
+				}
+				return detail.DividendHistory, nil
+			},
+		},
+		{
+			name: "full scraper",
+			events: func() ([]models.DividendEvent, error) {
+				s := NewYieldMaxFullScraper()
+				s.SetBaseURL(site.URL)
+				detail, err := s.ScrapeETFDetails("YMAX")
+				if err != nil {
+					return nil, err
+				}
+				return detail.DividendHistory, nil
+			},
+		},
+	}
+
+	for _, p := range producers {
+		t.Run(p.name, func(t *testing.T) {
+			events, err := p.events()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertSource(t, events, models.SourceYieldMax, false)
+		})
+	}
+}
+
+func TestScheduleEventsStampSource(t *testing.T) {
+	site := newFixtureSite(t, map[string]string{"/distribution-schedule/": "schedule_page.html"})
+
+	ys := NewImprovedYieldMaxScraper().WithLimitRule(1, 0)
+	ys.SetBaseURL(site.URL)
+	ys.SetGroupsFile(filepath.Join(t.TempDir(), "groups.json"))
+	ys.SetClock(clock.NewFake(fixtureNow))
+	schedule, err := ys.GetScheduleImproved()
+	if err != nil {
+		t.Fatalf("GetScheduleImproved: %v", err)
+	}
+
+	var announced, placeholders []models.DividendEvent
+	for _, e := range schedule.Upcoming {
+		switch {
+		case e.Symbol == "ULTY" && e.ExDate.Equal(marketDate(2025, 6, 12)):
+			announced = append(announced, e)
+		case e.Symbol == "BIGY":
+			placeholders = append(placeholders, e)
+		}
+	}
+	t.Run("announced amount", func(t *testing.T) {
+		assertSource(t, announced, models.SourceYieldMax, false)
+	})
+	t.Run("target 12 table rows", func(t *testing.T) {
+		// The dates come from the page; only the amount is a placeholder
+		assertSource(t, placeholders, models.SourceYieldMax, true)
+	})
+	t.Run("synthetic events", func(t *testing.T) {
+		var synthetic []models.DividendEvent
+		ys.target12Found = false
+		ys.generateSyntheticEvents(&synthetic)
+		assertSource(t, synthetic, models.SourceSynthetic, true)
+	})
+	for _, e := range append(announced, placeholders...) {
+		if err := e.Validate(); err != nil {
+			t.Errorf("schedule event invalid: %v", err)
+		}
+	}
+
+	legacy := NewYieldMaxScraper().WithLimitRule(1, 0)
+	legacy.SetBaseURL(site.URL)
+	legacySchedule, err := legacy.GetSchedule()
+	if err != nil {
+		t.Fatalf("GetSchedule: %v", err)
+	}
+	t.Run("legacy schedule", func(t *testing.T) {
+		var events []models.DividendEvent
+		for _, g := range legacySchedule.Groups {
+			events = append(events, g.Events...)
+		}
+		for _, e := range events {
+			if e.Source != models.SourceYieldMax {
+				t.Errorf("%s %v: source %q, want %q", e.Symbol, e.ExDate, e.Source, models.SourceYieldMax)
+			}
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+// SymbolStatusFileName is the file in a run's output root where consecutive
+// 404 counts are persisted between runs
+const SymbolStatusFileName = "symbol_status.json"
+
+// DefaultInactiveAfter is how many consecutive 404s mark a symbol inactive
+const DefaultInactiveAfter = 3
+
+// ErrNotFound is returned when an ETF page responds with 404
+var ErrNotFound = errors.New("page not found")
+
+// isNotFoundVisitError reports whether a colly Visit error came from a 404 response
+func isNotFoundVisitError(err error) bool {
+	return err != nil && err.Error() == http.StatusText(http.StatusNotFound)
+}
+
+// SymbolStatus is the persisted availability state of one symbol
+type SymbolStatus struct {
+	ConsecutiveNotFound int       `json:"consecutiveNotFound"`
+	Inactive            bool      `json:"inactive"`
+	LastNotFound        time.Time `json:"lastNotFound,omitempty"`
+}
+
+// SymbolTracker counts consecutive 404s per symbol and marks symbols
+// inactive once they reach the threshold
+type SymbolTracker struct {
+	mu        sync.Mutex
+	path      string
+	threshold int
+	statuses  map[string]*SymbolStatus
+}
+
+// NewSymbolTracker returns an empty tracker saved to path, marking symbols
+// inactive after threshold consecutive 404s (DefaultInactiveAfter if below 1)
+func NewSymbolTracker(path string, threshold int) *SymbolTracker {
+	if threshold < 1 {
+		threshold = DefaultInactiveAfter
+	}
+
+	return &SymbolTracker{
+		path:      path,
+		threshold: threshold,
+		statuses:  make(map[string]*SymbolStatus),
+	}
+}
+
+// LoadSymbolTracker reads the state file at path; a missing file starts empty
+func LoadSymbolTracker(path string, threshold int) (*SymbolTracker, error) {
+	tracker := NewSymbolTracker(path, threshold)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return tracker, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read symbol status %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &tracker.statuses); err != nil {
+		return nil, fmt.Errorf("failed to parse symbol status %s: %w", path, err)
+	}
+
+	return tracker, nil
+}
+
+// SymbolStatusPath returns where the 404 state of a run writing under
+// outputRoot is kept
+func SymbolStatusPath(outputRoot string) string {
+	return filepath.Join(outputRoot, SymbolStatusFileName)
+}
+
+// InactiveAfter returns how many consecutive 404s mark a symbol inactive
+func (t *SymbolTracker) InactiveAfter() int {
+	return t.threshold
+}
+
+// IsActive reports whether symbol hasn't been marked inactive
+func (t *SymbolTracker) IsActive(symbol string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status, ok := t.statuses[symbol]
+	return !ok || !status.Inactive
+}
+
+// RecordResult updates symbol's state from a scrape error. A 404 increments
+// the consecutive count, a success clears it and other errors leave it alone.
+// It returns true when this call marked the symbol inactive.
+func (t *SymbolTracker) RecordResult(symbol string, err error, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !errors.Is(err, ErrNotFound) {
+		if err == nil {
+			delete(t.statuses, symbol)
+		}
+		return false
+	}
+
+	status, ok := t.statuses[symbol]
+	if !ok {
+		status = &SymbolStatus{}
+		t.statuses[symbol] = status
+	}
+	status.ConsecutiveNotFound++
+	status.LastNotFound = now
+
+	if !status.Inactive && status.ConsecutiveNotFound >= t.threshold {
+		status.Inactive = true
+		return true
+	}
+	return false
+}
+
+// FilterActive returns symbols with inactive ones removed unless includeInactive is set
+func (t *SymbolTracker) FilterActive(symbols []string, includeInactive bool) (active, skipped []string) {
+	for _, symbol := range symbols {
+		if includeInactive || t.IsActive(symbol) {
+			active = append(active, symbol)
+		} else {
+			skipped = append(skipped, symbol)
+		}
+	}
+	return active, skipped
+}
+
+// MarkActive sets each ETF's Active flag from the tracked state
+func (t *SymbolTracker) MarkActive(etfs []models.ETF) {
+	for i := range etfs {
+		etfs[i].Active = t.IsActive(etfs[i].Symbol)
+	}
+}
+
+// Save persists the tracker state to its file
+func (t *SymbolTracker) Save() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", t.path, err)
+	}
+
+	data, err := json.MarshalIndent(t.statuses, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode symbol status: %w", err)
+	}
+
+	if err := os.WriteFile(t.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write symbol status %s: %w", t.path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package scraper
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestRepeatedNotFoundMarksSymbolInactive(t *testing.T) {
+	gone := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if gone && r.URL.Path == "/our-etfs/gone/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("<html><body><h1>YieldMax Fund</h1></body></html>"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), SymbolStatusFileName)
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, models.MarketLocation)
+
+	// Each crawl loads the state, scrapes the active symbols and saves it
+	crawl := func(includeInactive bool) (scraped []string, marked bool) {
+		tracker, err := LoadSymbolTracker(path, 3)
+		if err != nil {
+			t.Fatalf("LoadSymbolTracker: %v", err)
+		}
+		symbols, _ := tracker.FilterActive([]string{"GONE", "LIVE"}, includeInactive)
+		detailScraper := NewETFDetailScraper().WithLimitRule(1, 0)
+		detailScraper.SetBaseURL(server.URL)
+		for _, symbol := range symbols {
+			_, err := detailScraper.GetETFDetail(symbol)
+			if symbol == "GONE" && gone && !errors.Is(err, ErrNotFound) {
+				t.Fatalf("GetETFDetail(GONE) error = %v, want ErrNotFound", err)
+			}
+			if tracker.RecordResult(symbol, err, now) {
+				marked = true
+			}
+		}
+		if err := tracker.Save(); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		return symbols, marked
+	}
+
+	for run := 1; run <= 3; run++ {
+		scraped, marked := crawl(false)
+		if !reflect.DeepEqual(scraped, []string{"GONE", "LIVE"}) {
+			t.Fatalf("run %d scraped %v, want both symbols", run, scraped)
+		}
+		if marked != (run == 3) {
+			t.Errorf("run %d marked inactive = %v, want %v", run, marked, run == 3)
+		}
+	}
+
+	if scraped, _ := crawl(false); !reflect.DeepEqual(scraped, []string{"LIVE"}) {
+		t.Errorf("after 3 404s scraped %v, want only LIVE", scraped)
+	}
+
+	tracker, err := LoadSymbolTracker(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	etfs := []models.ETF{{Symbol: "GONE", Active: true}, {Symbol: "LIVE", Active: true}}
+	tracker.MarkActive(etfs)
+	if etfs[0].Active || !etfs[1].Active {
+		t.Errorf("Active flags = %v/%v, want false/true", etfs[0].Active, etfs[1].Active)
+	}
+
+	// The page came back: -include-inactive retries it and a success clears the state
+	gone = false
+	if scraped, _ := crawl(true); !reflect.DeepEqual(scraped, []string{"GONE", "LIVE"}) {
+		t.Errorf("with include-inactive scraped %v, want both symbols", scraped)
+	}
+	if scraped, _ := crawl(false); !reflect.DeepEqual(scraped, []string{"GONE", "LIVE"}) {
+		t.Errorf("after a success scraped %v, want both symbols", scraped)
+	}
+}
+
+func TestRecordResultIgnoresOtherErrors(t *testing.T) {
+	tracker := NewSymbolTracker("", 2)
+	now := time.Now()
+
+	tracker.RecordResult("FLAKY", ErrNotFound, now)
+	tracker.RecordResult("FLAKY", errors.New("timeout"), now)
+	if tracker.RecordResult("FLAKY", ErrNotFound, now) != true {
+		t.Error("a timeout between 404s reset the count")
+	}
+
+	tracker.RecordResult("BACK", ErrNotFound, now)
+	tracker.RecordResult("BACK", nil, now)
+	if tracker.RecordResult("BACK", ErrNotFound, now) {
+		t.Error("a success between 404s didn't reset the count")
+	}
+}
+
+func TestSymbolStatusPath(t *testing.T) {
+	if got, want := SymbolStatusPath("docs"), filepath.Join("docs", "symbol_status.json"); got != want {
+		t.Errorf("SymbolStatusPath(docs) = %q, want %q", got, want)
+	}
+}
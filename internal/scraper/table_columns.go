@@ -0,0 +1,107 @@
+package scraper
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Semantic columns of a distribution table
+const (
+	columnAmount   = "amount"
+	columnDeclared = "declared"
+	columnEx       = "ex"
+	columnRecord   = "record"
+	columnPay      = "pay"
+)
+
+// defaultTableColumns is the layout YieldMax tables have used so far:
+// ticker, distribution per share, declared, ex, record and payable dates
+var defaultTableColumns = tableColumns{
+	columnAmount:   1,
+	columnDeclared: 2,
+	columnEx:       3,
+	columnRecord:   4,
+	columnPay:      5,
+}
+
+// tableColumns maps a semantic column name to its cell index
+type tableColumns map[string]int
+
+// detectTableColumns maps a table's header texts to semantic columns, so
+// cells are read by meaning rather than position. It falls back to
+// defaultTableColumns when the headers don't name both the amount and the
+// ex-date, e.g. when the table has no header row.
+func detectTableColumns(headers []string) tableColumns {
+	columns := make(tableColumns)
+	for i, header := range headers {
+		name := columnForHeader(header)
+		if name == "" {
+			continue
+		}
+		if _, seen := columns[name]; !seen {
+			columns[name] = i
+		}
+	}
+
+	if _, ok := columns[columnAmount]; !ok {
+		return defaultTableColumns
+	}
+	if _, ok := columns[columnEx]; !ok {
+		return defaultTableColumns
+	}
+	return columns
+}
+
+// columnForHeader names the column a header describes, or "" when it is
+// none of the known ones. The ex-date is checked first because headers such
+// as "Ex-Dividend Date" also mention the dividend.
+func columnForHeader(header string) string {
+	words := strings.FieldsFunc(strings.ToLower(header), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	hasWord := func(prefix string) bool {
+		for _, word := range words {
+			if strings.HasPrefix(word, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, word := range words {
+		if word == "ex" || word == "exdate" {
+			return columnEx
+		}
+	}
+	switch {
+	case hasWord("record"):
+		return columnRecord
+	case hasWord("pay"):
+		return columnPay
+	case hasWord("declar"):
+		return columnDeclared
+	case hasWord("distribution"), hasWord("dividend"), hasWord("amount"), hasWord("share"):
+		return columnAmount
+	}
+	return ""
+}
+
+// cell returns the text of the named column in a row, or "" when the table
+// lacks the column or the row is too short
+func (c tableColumns) cell(cellTexts []string, name string) string {
+	i, ok := c[name]
+	if !ok || i >= len(cellTexts) {
+		return ""
+	}
+	return cellTexts[i]
+}
+
+// covers reports whether a row has a cell for every column
+func (c tableColumns) covers(cellTexts []string) bool {
+	for _, i := range c {
+		if i >= len(cellTexts) {
+			return false
+		}
+	}
+	return true
+}
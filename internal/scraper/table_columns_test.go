@@ -0,0 +1,74 @@
+package scraper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectTableColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    tableColumns
+	}{
+		{
+			name:    "current layout",
+			headers: []string{"ticker", "distribution per share", "declared date", "ex date", "record date", "payable date"},
+			want:    defaultTableColumns,
+		},
+		{
+			name:    "reordered",
+			headers: []string{"ex-dividend date", "pay date", "ticker", "record date", "amount", "declaration date"},
+			want:    tableColumns{columnEx: 0, columnPay: 1, columnRecord: 3, columnAmount: 4, columnDeclared: 5},
+		},
+		{
+			name:    "without optional dates",
+			headers: []string{"Dividend", "Ex Date"},
+			want:    tableColumns{columnAmount: 0, columnEx: 1},
+		},
+		{
+			name:    "no ex-date header",
+			headers: []string{"ticker", "distribution per share", "date"},
+			want:    defaultTableColumns,
+		},
+		{
+			name: "no headers",
+			want: defaultTableColumns,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectTableColumns(tt.headers); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectTableColumns(%q) = %v, want %v", tt.headers, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDividendTableScraperReorderedColumns(t *testing.T) {
+	site := newFixtureSite(t, map[string]string{
+		"/our-etfs/ulty/": "dividend_page_ulty.html",
+		"/our-etfs/ulto/": "dividend_page_reordered.html",
+	})
+	s := newTestTableScraper(t, site.URL, nil)
+
+	standard, err := s.ScrapeDividendHistory("ULTY")
+	if err != nil {
+		t.Fatalf("standard layout: %v", err)
+	}
+	reordered, err := s.ScrapeDividendHistory("ULTO")
+	if err != nil {
+		t.Fatalf("reordered layout: %v", err)
+	}
+
+	if len(standard.Events) != 4 || len(reordered.Events) != len(standard.Events) {
+		t.Fatalf("parsed %d and %d events, want 4 from each layout", len(standard.Events), len(reordered.Events))
+	}
+	for i, want := range standard.Events {
+		got := reordered.Events[i]
+		if got.Amount != want.Amount || !got.ExDate.Equal(want.ExDate) || !got.PayDate.Equal(want.PayDate) || !got.DeclareDate.Equal(want.DeclareDate) {
+			t.Errorf("event %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
@@ -16,6 +16,9 @@ import (
 type YieldMaxScraper struct {
 	collector *colly.Collector
 	logger    *logrus.Logger
+	limit     *colly.LimitRule
+	groups    *GroupResolver
+	baseURL   string
 }
 
 // NewYieldMaxScraper creates a new YieldMax scraper instance
@@ -25,11 +28,11 @@ func NewYieldMaxScraper() *YieldMaxScraper {
 	)
 
 	// Limit the number of threads started by colly
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  "*yieldmaxetfs.com*",
-		Parallelism: 2,
-		Delay:       1 * time.Second,
-	})
+	limit := applyLimit(c, 2, 1*time.Second)
+
+	applyProxy(c)
+	observeRequests(c)
+	throttleRequests(c)
 
 	logger := logrus.New()
 	logger.SetLevel(logrus.InfoLevel)
@@ -37,16 +40,31 @@ func NewYieldMaxScraper() *YieldMaxScraper {
 	return &YieldMaxScraper{
 		collector: c,
 		logger:    logger,
+		limit:     limit,
+		groups:    NewGroupResolver(nil),
+		baseURL:   DefaultBaseURL,
 	}
 }
 
+// SetBaseURL changes the site the schedule and ETF list are fetched from
+func (ys *YieldMaxScraper) SetBaseURL(baseURL string) {
+	ys.baseURL = baseURL
+}
+
+// WithLimitRule changes how many schedule requests run at once and the delay
+// between them, which default to 2 and 1s
+func (ys *YieldMaxScraper) WithLimitRule(parallelism int, delay time.Duration) *YieldMaxScraper {
+	setLimit(ys.limit, parallelism, delay)
+	return ys
+}
+
 // GetSchedule scrapes the YieldMax distribution schedule page
 func (ys *YieldMaxScraper) GetSchedule() (*models.Schedule, error) {
 	var schedule models.Schedule
 	var groups []models.GroupSchedule
 	var upcoming []models.DividendEvent
 
-	scheduleURL := "https://www.yieldmaxetfs.com/distribution-schedule/"
+	scheduleURL := schedulePageURL(ys.baseURL)
 
 	// Parse Target 12 ETFs table
 	ys.collector.OnHTML("table", func(e *colly.HTMLElement) {
@@ -97,9 +115,9 @@ func (ys *YieldMaxScraper) parseTarget12Table(e *colly.HTMLElement, upcoming *[]
 		cells := el.ChildTexts("td")
 		if len(cells) >= 3 {
 			// Parse dates
-			declareDate := ys.parseDate(cells[1])
-			exDate := ys.parseDate(cells[2])
-			payDate := ys.parseDate(cells[3])
+			declareDate := flexibleDate(cells[1])
+			exDate := flexibleDate(cells[2])
+			payDate := flexibleDate(cells[3])
 
 			// Create events for all Target 12 ETFs
 			target12ETFs := []string{"BIGY", "SOXY", "RNTY"}
@@ -109,8 +127,10 @@ func (ys *YieldMaxScraper) parseTarget12Table(e *colly.HTMLElement, upcoming *[]
 					ExDate:      exDate,
 					PayDate:     payDate,
 					DeclareDate: declareDate,
+					RecordDate:  exDate, // Record date equals ex-date under T+1 settlement
 					Group:       "Target12",
 					Frequency:   "monthly",
+					Source:      models.SourceYieldMax,
 				}
 				*upcoming = append(*upcoming, event)
 			}
@@ -125,9 +145,9 @@ func (ys *YieldMaxScraper) parseGroupsTable(e *colly.HTMLElement, upcoming *[]mo
 		cells := el.ChildTexts("td")
 		if len(cells) >= 4 {
 			groupText := cells[0]
-			declareDate := ys.parseDate(cells[2])
-			exDate := ys.parseDate(cells[3])
-			payDate := ys.parseDate(cells[4])
+			declareDate := flexibleDate(cells[2])
+			exDate := flexibleDate(cells[3])
+			payDate := flexibleDate(cells[4])
 
 			// Extract group from text
 			group := ys.extractGroup(groupText)
@@ -142,8 +162,10 @@ func (ys *YieldMaxScraper) parseGroupsTable(e *colly.HTMLElement, upcoming *[]mo
 				ExDate:      exDate,
 				PayDate:     payDate,
 				DeclareDate: declareDate,
+				RecordDate:  exDate, // Record date equals ex-date under T+1 settlement
 				Group:       group,
 				Frequency:   frequency,
+				Source:      models.SourceYieldMax,
 			}
 			*upcoming = append(*upcoming, event)
 		}
@@ -209,38 +231,11 @@ func (ys *YieldMaxScraper) extractGroup(text string) string {
 	return "Unknown"
 }
 
-// parseDate parses date strings from the schedule table
-func (ys *YieldMaxScraper) parseDate(dateStr string) time.Time {
-	// Clean the date string
-	dateStr = strings.TrimSpace(dateStr)
-
-	// Try different date formats
-	formats := []string{
-		"1/2/06",     // 1/2/25
-		"1/2/2006",   // 1/2/2025
-		"01/02/06",   // 01/02/25
-		"01/02/2006", // 01/02/2025
-	}
-
-	for _, format := range formats {
-		if parsed, err := time.Parse(format, dateStr); err == nil {
-			// If year is in 2-digit format and less than 50, assume 20xx
-			if parsed.Year() < 1950 {
-				parsed = parsed.AddDate(2000, 0, 0)
-			}
-			return parsed
-		}
-	}
-
-	ys.logger.Warnf("Failed to parse date: %s", dateStr)
-	return time.Time{}
-}
-
 // GetETFList scrapes the main ETF list from YieldMax
 func (ys *YieldMaxScraper) GetETFList() ([]models.ETF, error) {
 	var etfs []models.ETF
 
-	etfListURL := "https://www.yieldmaxetfs.com/our-etfs/"
+	etfListURL := etfListingURL(ys.baseURL)
 
 	// Parse the ETF table
 	ys.collector.OnHTML("table tbody tr", func(e *colly.HTMLElement) {
@@ -254,8 +249,7 @@ func (ys *YieldMaxScraper) GetETFList() ([]models.ETF, error) {
 				return
 			}
 
-			// Determine group based on symbol (this is a simplified mapping)
-			group := ys.determineETFGroup(symbol)
+			group := ys.groups.ResolveGroup(symbol)
 			frequency := "weekly"
 			if group == "Target12" {
 				frequency = "monthly"
@@ -266,6 +260,7 @@ func (ys *YieldMaxScraper) GetETFList() ([]models.ETF, error) {
 				Name:      name,
 				Group:     group,
 				Frequency: frequency,
+				Active:    true,
 			}
 			etfs = append(etfs, etf)
 		}
@@ -288,94 +283,3 @@ func (ys *YieldMaxScraper) GetETFList() ([]models.ETF, error) {
 	ys.logger.Infof("Successfully scraped %d ETFs", len(etfs))
 	return etfs, nil
 }
-
-// determineETFGroup determines which group an ETF belongs to based on symbol
-func (ys *YieldMaxScraper) determineETFGroup(symbol string) string {
-	// Complete YieldMax ETF grouping based on official distribution schedule
-	etfGroups := map[string]string{
-		// Target 12 ETFs (월 배당)
-		"BIGY": "Target12",
-		"SOXY": "Target12",
-		"RNTY": "Target12",
-		"KLIP": "Target12",
-		"ALTY": "Target12",
-
-		// Weekly Payers (주간 배당)
-		"CHPY": "Weekly",
-		"GPTY": "Weekly",
-		"LFGY": "Weekly",
-		"QDTY": "Weekly",
-		"RDTY": "Weekly",
-		"SDTY": "Weekly",
-		"ULTY": "Weekly",
-		"YMAG": "Weekly",
-		"YMAX": "Weekly",
-
-		// Group A ETFs
-		"TSLY": "GroupA",
-		"NVDY": "GroupA",
-		"MSTY": "GroupA",
-		"OARK": "GroupA",
-		"AMDY": "GroupA",
-		"GOOY": "GroupA",
-		"JPMO": "GroupA",
-		"MRNY": "GroupA",
-		"SNOY": "GroupA",
-		"TSMY": "GroupA",
-		"APLY": "GroupA",
-
-		// Group B ETFs
-		"AMZY": "GroupB",
-		"CONY": "GroupB",
-		"FBY":  "GroupB",
-		"NFLY": "GroupB",
-		"QQLY": "GroupB",
-		"AIPY": "GroupB",
-		"BABO": "GroupB",
-		"DISO": "GroupB",
-		"MSFO": "GroupB",
-		"PYPY": "GroupB",
-		"SQY":  "GroupB",
-		"XOMO": "GroupB",
-
-		// Group C ETFs
-		"AIYY": "GroupC",
-		"BALY": "GroupC",
-		"COWY": "GroupC",
-		"CRSY": "GroupC",
-		"FIAT": "GroupC",
-		"GPIY": "GroupC",
-		"INTY": "GroupC",
-		"JEPY": "GroupC",
-		"KODY": "GroupC",
-		"NETY": "GroupC",
-		"PLTY": "GroupC",
-		"SPYY": "GroupC",
-		"WUGI": "GroupC",
-
-		// Group D ETFs
-		"ABNY":  "GroupD",
-		"AFRM":  "GroupD",
-		"BKSY":  "GroupD",
-		"BOLDY": "GroupD",
-		"CVY":   "GroupD",
-		"DFLY":  "GroupD",
-		"DSNY":  "GroupD",
-		"GDXY":  "GroupD",
-		"HPAY":  "GroupD",
-		"JETY":  "GroupD",
-		"LCID":  "GroupD",
-		"MARO":  "GroupD",
-		"MRSY":  "GroupD",
-		"PEY":   "GroupD",
-		"AMDL":  "GroupD",
-	}
-
-	if group, exists := etfGroups[symbol]; exists {
-		return group
-	}
-
-	// Default to GroupA if not found
-	ys.logger.Warnf("ETF %s not found in group mapping, defaulting to GroupA", symbol)
-	return "GroupA"
-}
@@ -1,16 +1,21 @@
 package scraper
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
+	"divminder-crawler/internal/api"
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/export"
 	"divminder-crawler/internal/models"
+	"divminder-crawler/internal/overrides"
+	"divminder-crawler/internal/proxy"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/sirupsen/logrus"
@@ -18,41 +23,204 @@ import (
 
 // YieldMaxFullScraper scrapes comprehensive data from YieldMax website
 type YieldMaxFullScraper struct {
-	client *http.Client
-	logger *logrus.Logger
+	client      *http.Client
+	logger      *logrus.Logger
+	clock       clock.Clock
+	baseURL     string
+	maxEvents   int  // Most recent events kept per saved history; 0 keeps all
+	years       int  // Years of history kept per saved history; 0 keeps all
+	fixWeekends bool // Move weekend ex-dates to the prior Friday instead of only warning
+	bounds      AmountBounds
+
+	targetTracking bool // Measure Target12 funds against their target in saved histories
+
+	detailWorkers  int           // Detail pages scraped at once
+	detailInterval time.Duration // Average interval between detail requests
+
+	tracker         *SymbolTracker // Consecutive 404s per symbol; nil scrapes every symbol
+	includeInactive bool           // Scrape symbols the tracker marked inactive anyway
+	discovered      []string       // Symbols from the ETF listing, scraped alongside the group map
+	overrides       overrides.Overrides
+	groups          *GroupResolver
+}
+
+// SetOverrides merges hand-maintained overrides over the scraped ETFs, and
+// so over the histories saved for them
+func (s *YieldMaxFullScraper) SetOverrides(o overrides.Overrides) {
+	s.overrides = o
+}
+
+// SetGroupResolver replaces how listed ETFs are assigned a group, e.g. with
+// one that also consults the overrides
+func (s *YieldMaxFullScraper) SetGroupResolver(r *GroupResolver) {
+	s.groups = r
+}
+
+// SetDiscoveredSymbols adds symbols found by DiscoverSymbols to the ETFs
+// scraped, so funds missing from the group map still get their pages read
+func (s *YieldMaxFullScraper) SetDiscoveredSymbols(symbols []string) {
+	s.discovered = symbols
+}
+
+// SetSymbolTracker records each detail page's 404s in tracker and skips the
+// symbols it marked inactive unless includeInactive is set
+func (s *YieldMaxFullScraper) SetSymbolTracker(tracker *SymbolTracker, includeInactive bool) {
+	s.tracker = tracker
+	s.includeInactive = includeInactive
+}
+
+// SetBaseURL changes the site the schedule and fund pages are fetched from
+func (s *YieldMaxFullScraper) SetBaseURL(baseURL string) {
+	s.baseURL = baseURL
+}
+
+// SetDetailConcurrency scrapes detail pages with the given number of workers
+// sharing one limiter that allows a request per interval on average
+func (s *YieldMaxFullScraper) SetDetailConcurrency(workers int, interval time.Duration) {
+	s.detailWorkers = workers
+	s.detailInterval = interval
+}
+
+// SetAmountBounds replaces the range of distribution amounts the scraper keeps
+func (s *YieldMaxFullScraper) SetAmountBounds(bounds AmountBounds) {
+	s.bounds = bounds
+}
+
+// SetMaxEvents caps the events saved per history to the max most recent; 0 keeps all
+func (s *YieldMaxFullScraper) SetMaxEvents(max int) {
+	s.maxEvents = max
+}
+
+// SetYears drops events older than the given number of years before stats
+// are computed; 0 keeps all
+func (s *YieldMaxFullScraper) SetYears(years int) {
+	s.years = years
+}
+
+// SetTargetTracking controls whether saved Target12 histories compare their
+// trailing 12-month distributions at the current price with the target; it
+// is on by default
+func (s *YieldMaxFullScraper) SetTargetTracking(enabled bool) {
+	s.targetTracking = enabled
+}
+
+// SetClock sets the as-of time target tracking is measured from
+func (s *YieldMaxFullScraper) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// SetFixWeekends moves ex-dates that fall on a weekend to the prior Friday;
+// by default they are only logged
+func (s *YieldMaxFullScraper) SetFixWeekends(fix bool) {
+	s.fixWeekends = fix
 }
 
 // NewYieldMaxFullScraper creates a new full scraper instance
 func NewYieldMaxFullScraper() *YieldMaxFullScraper {
 	return &YieldMaxFullScraper{
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: siteLimitedTransport{base: proxy.Transport()},
 		},
-		logger: logrus.New(),
+		logger:  logrus.New(),
+		clock:   clock.Real{},
+		baseURL: DefaultBaseURL,
+		bounds:  DefaultAmountBounds(),
+		groups:  NewGroupResolver(nil),
+
+		targetTracking: true,
+
+		detailWorkers:  1,
+		detailInterval: 3 * time.Second,
 	}
 }
 
 // ScrapeAllETFs scrapes all ETF data from YieldMax
 func (s *YieldMaxFullScraper) ScrapeAllETFs() ([]models.ETF, error) {
+	etfs, _ := s.scrapeETFs()
+	return etfs, nil
+}
+
+// scrapeETFs lists the ETFs and scrapes their detail pages, returning the
+// ETFs updated from their pages and the details by symbol
+func (s *YieldMaxFullScraper) scrapeETFs() ([]models.ETF, map[string]*models.ETFDetail) {
 	s.logger.Info("Starting comprehensive ETF data collection...")
-	
-	// Get ETF list from the official mapping
-	etfGroups := GetYieldMaxETFGroups()
-	var etfs []models.ETF
-	
+
 	// Scrape distribution schedule for next dividend dates
 	schedule, err := s.ScrapeDistributionSchedule()
 	if err != nil {
 		s.logger.Warnf("Failed to scrape distribution schedule: %v", err)
 	}
-	
-	// Create ETFs with correct group and frequency information
-	for symbol, group := range etfGroups {
+	etfs := s.listETFs(schedule)
+
+	// Enhance ETF data with detailed information
+	symbols := make([]string, len(etfs))
+	for i := range etfs {
+		symbols[i] = etfs[i].Symbol
+	}
+	if s.tracker != nil {
+		var skipped []string
+		symbols, skipped = s.tracker.FilterActive(symbols, s.includeInactive)
+		if len(skipped) > 0 {
+			s.logger.Infof("Skipping %d inactive symbols (pass -include-inactive to retry them): %v", len(skipped), skipped)
+		}
+	}
+	scraped := s.scrapeDetails(symbols)
+	if s.tracker != nil {
+		s.tracker.MarkActive(etfs)
+	}
+	for i := range etfs {
+		if details, ok := scraped[etfs[i].Symbol]; ok {
+			// Update ETF with scraped details
+			if details.Name != "" {
+				etfs[i].Name = details.Name
+			}
+			if details.Description != "" {
+				etfs[i].Description = details.Description
+			}
+			// Frequency from details page is more reliable
+			if details.Frequency != "" {
+				etfs[i].Frequency = strings.ToLower(details.Frequency)
+			}
+			etfs[i].InceptionDate = details.InceptionDate
+		}
+	}
+
+	// Hand-maintained overrides win over scraped and guessed values
+	if applied := s.overrides.ApplyETFs(etfs); applied > 0 {
+		s.logger.Infof("Applied overrides to %d ETFs", applied)
+	}
+
+	s.logger.Infof("Collected data for %d ETFs", len(etfs))
+	return etfs, scraped
+}
+
+// listETFs returns an ETF for every symbol the group resolver knows and
+// every discovered one, in symbol order, with next dates from schedule when
+// it is not nil. Discovered symbols nothing maps are put in UnknownGroup.
+func (s *YieldMaxFullScraper) listETFs(schedule *models.Schedule) []models.ETF {
+	symbols := s.groups.Symbols()
+	known := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		known[symbol] = true
+	}
+	for _, symbol := range s.discovered {
+		if !known[symbol] {
+			known[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+
+	etfs := make([]models.ETF, 0, len(symbols))
+	for _, symbol := range symbols {
+		group := s.groups.ResolveGroup(symbol)
 		etf := models.ETF{
 			Symbol: symbol,
 			Group:  group,
+			Active: true,
 		}
-		
+
 		// Set frequency based on group
 		switch group {
 		case "Target12":
@@ -61,11 +229,14 @@ func (s *YieldMaxFullScraper) ScrapeAllETFs() ([]models.ETF, error) {
 		case "Weekly":
 			etf.Frequency = "weekly"
 			etf.Name = fmt.Sprintf("YieldMax %s Weekly ETF", symbol)
+		case UnknownGroup: // Left to the detail page
+			etf.Name = fmt.Sprintf("YieldMax %s ETF", symbol)
+			etf.Description = "Discovered on the YieldMax ETF listing"
 		default: // GroupA, B, C, D
 			etf.Frequency = "monthly"
 			etf.Name = fmt.Sprintf("YieldMax %s Option Income Strategy ETF", symbol)
 		}
-		
+
 		// Add next dividend dates from schedule if available
 		if schedule != nil {
 			for _, groupSchedule := range schedule.Groups {
@@ -76,75 +247,105 @@ func (s *YieldMaxFullScraper) ScrapeAllETFs() ([]models.ETF, error) {
 				}
 			}
 		}
-		
+
 		etfs = append(etfs, etf)
 	}
-	
-	// Enhance ETF data with detailed information
-	for i := range etfs {
-		if details, err := s.ScrapeETFDetails(etfs[i].Symbol); err == nil {
-			// Update ETF with scraped details
-			if details.Name != "" {
-				etfs[i].Name = details.Name
-			}
-			if details.Description != "" {
-				etfs[i].Description = details.Description
-			}
-			// Frequency from details page is more reliable
-			if details.Frequency != "" {
-				etfs[i].Frequency = strings.ToLower(details.Frequency)
-			}
+	return etfs
+}
+
+// scrapeDetails scrapes the symbols' detail pages with the configured
+// worker pool, keyed by symbol. Failed pages are logged and left out.
+func (s *YieldMaxFullScraper) scrapeDetails(symbols []string) map[string]*models.ETFDetail {
+	limiter := api.NewRateLimiter(s.detailWorkers, s.detailInterval)
+	defer limiter.Stop()
+
+	details := make(map[string]*models.ETFDetail, len(symbols))
+	for result := range ScrapeDetails(symbols, s.detailWorkers, limiter, func() DetailScraper { return s }) {
+		if s.tracker != nil && s.tracker.RecordResult(result.Symbol, result.Err, time.Now()) {
+			s.logger.Warnf("Marking %s inactive after %d consecutive 404s", result.Symbol, s.tracker.InactiveAfter())
 		}
-		
-		// Be respectful with rate limiting
-		time.Sleep(2 * time.Second)
+		if result.Err != nil {
+			s.logger.Warnf("Failed to scrape details for %s: %v", result.Symbol, result.Err)
+			continue
+		}
+		details[result.Symbol] = result.Detail
 	}
-	
-	s.logger.Infof("Collected data for %d ETFs", len(etfs))
-	return etfs, nil
+	return details
 }
 
 // ScrapeDistributionSchedule scrapes the distribution schedule page
 func (s *YieldMaxFullScraper) ScrapeDistributionSchedule() (*models.Schedule, error) {
-	url := "https://www.yieldmaxetfs.com/distribution-schedule/"
+	url := schedulePageURL(s.baseURL)
 	s.logger.Infof("Scraping distribution schedule from: %s", url)
-	
+
 	resp, err := s.client.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch schedule page: %w", err)
 	}
 	defer resp.Body.Close()
-	
+
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
-	
+
 	schedule := &models.Schedule{
 		UpdatedAt: time.Now(),
 		Groups:    []models.GroupSchedule{},
 	}
-	
+
 	// Parse distribution tables
 	doc.Find("table").Each(func(i int, table *goquery.Selection) {
 		// Look for tables with Ex-Date and Pay Date headers
 		headers := table.Find("th").Map(func(_ int, th *goquery.Selection) string {
 			return strings.TrimSpace(th.Text())
 		})
-		
+
 		if s.isDistributionTable(headers) {
 			s.parseDistributionTable(table, schedule)
 		}
 	})
-	
+
 	return schedule, nil
 }
 
+// CheckSchedulePage verifies the distribution schedule page is reachable and
+// still contains the tables the scrapers depend on
+func (s *YieldMaxFullScraper) CheckSchedulePage() error {
+	resp, err := s.client.Get(schedulePageURL(s.baseURL))
+	if err != nil {
+		return fmt.Errorf("failed to fetch schedule page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	if doc.Find("table").Length() == 0 {
+		return fmt.Errorf("schedule page contains no tables")
+	}
+
+	pageText := doc.Text()
+	for _, expected := range []string{"Target 12", "Weekly Payers", "Group A"} {
+		if !strings.Contains(pageText, expected) {
+			return fmt.Errorf("schedule page is missing expected section %q", expected)
+		}
+	}
+
+	return nil
+}
+
 // isDistributionTable checks if a table contains distribution data
 func (s *YieldMaxFullScraper) isDistributionTable(headers []string) bool {
 	hasExDate := false
 	hasPayDate := false
-	
+
 	for _, header := range headers {
 		headerLower := strings.ToLower(header)
 		if strings.Contains(headerLower, "ex-date") || strings.Contains(headerLower, "ex date") {
@@ -154,7 +355,7 @@ func (s *YieldMaxFullScraper) isDistributionTable(headers []string) bool {
 			hasPayDate = true
 		}
 	}
-	
+
 	return hasExDate && hasPayDate
 }
 
@@ -164,7 +365,7 @@ func (s *YieldMaxFullScraper) parseDistributionTable(table *goquery.Selection, s
 		cells := row.Find("td").Map(func(_ int, td *goquery.Selection) string {
 			return strings.TrimSpace(td.Text())
 		})
-		
+
 		if len(cells) >= 3 {
 			// Parse the row to extract group and dates
 			// This needs to be adapted based on actual table structure
@@ -175,29 +376,32 @@ func (s *YieldMaxFullScraper) parseDistributionTable(table *goquery.Selection, s
 
 // ScrapeETFDetails scrapes detailed information for a specific ETF
 func (s *YieldMaxFullScraper) ScrapeETFDetails(symbol string) (*models.ETFDetail, error) {
-	url := fmt.Sprintf("https://www.yieldmaxetfs.com/our-etfs/%s/", strings.ToLower(symbol))
+	url := etfPageURL(s.baseURL, symbol)
 	s.logger.Infof("Scraping ETF details from: %s", url)
-	
+
 	resp, err := s.client.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch ETF page: %w", err)
 	}
 	defer resp.Body.Close()
-	
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, ErrNotFound)
+	}
 	if resp.StatusCode != 200 {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
-	
+
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
-	
+
 	detail := &models.ETFDetail{
 		Symbol:      symbol,
 		LastUpdated: time.Now(),
 	}
-	
+
 	// Extract ETF name
 	doc.Find("h1, h2").Each(func(i int, h *goquery.Selection) {
 		text := strings.TrimSpace(h.Text())
@@ -206,52 +410,31 @@ func (s *YieldMaxFullScraper) ScrapeETFDetails(symbol string) (*models.ETFDetail
 			return
 		}
 	})
-	
-	// Extract distribution rate
-	doc.Find("*").Each(func(i int, elem *goquery.Selection) {
-		text := strings.TrimSpace(elem.Text())
-		
-		// Look for distribution rate pattern (e.g., "29.45%")
-		if strings.Contains(text, "Distribution Rate") {
-			// Try to find percentage in nearby elements
-			parent := elem.Parent()
-			parent.Find("*").Each(func(j int, child *goquery.Selection) {
-				childText := strings.TrimSpace(child.Text())
-				if match := regexp.MustCompile(`(\d+\.?\d*)%`).FindStringSubmatch(childText); len(match) > 1 {
-					if yield, err := strconv.ParseFloat(match[1], 64); err == nil {
-						detail.CurrentYield = yield
-					}
-				}
-			})
-		}
-		
-		// Look for frequency information
-		if strings.Contains(strings.ToLower(text), "monthly distribution") {
-			detail.Frequency = "monthly"
-		} else if strings.Contains(strings.ToLower(text), "weekly distribution") {
-			detail.Frequency = "weekly"
-		}
-	})
-	
+
+	// Extract distribution rate, SEC yield, prices, inception and frequency,
+	// each by its own label
+	extractKeyMetrics(doc.Selection, detail)
+
 	// Extract dividend history
 	detail.DividendHistory = s.extractDividendHistory(doc, symbol)
-	
-	s.logger.Infof("Scraped details for %s: Name=%s, Yield=%.2f%%, Frequency=%s, History=%d events",
-		symbol, detail.Name, detail.CurrentYield, detail.Frequency, len(detail.DividendHistory))
-	
+
+	s.logger.Infof("Scraped details for %s: Name=%s, Price=$%.2f, NAV=$%.2f, Premium=%.2f%%, Yield=%.2f%%, Frequency=%s, History=%d events",
+		symbol, detail.Name, detail.CurrentPrice, detail.NAV, detail.PremiumDiscountPct, detail.CurrentYield, detail.Frequency, len(detail.DividendHistory))
+
 	return detail, nil
 }
 
-// extractDividendHistory extracts dividend history from the page
+// extractDividendHistory extracts dividend history from every distribution
+// table on the page, such as separate current- and prior-year tables,
+// merging events that more than one table lists
 func (s *YieldMaxFullScraper) extractDividendHistory(doc *goquery.Document, symbol string) []models.DividendEvent {
 	var events []models.DividendEvent
-	
+	tables := 0
+
 	// Look for distribution tables
 	doc.Find("table").Each(func(i int, table *goquery.Selection) {
-		headers := table.Find("th").Map(func(_ int, th *goquery.Selection) string {
-			return strings.ToLower(strings.TrimSpace(th.Text()))
-		})
-		
+		headers := distributionTableHeaders(table)
+
 		// Check if this is a distribution history table
 		hasDate := false
 		hasAmount := false
@@ -263,24 +446,76 @@ func (s *YieldMaxFullScraper) extractDividendHistory(doc *goquery.Document, symb
 				hasAmount = true
 			}
 		}
-		
+
 		if hasDate && hasAmount {
 			s.logger.Debug("Found distribution history table")
-			
-			// Parse each row
+			tables++
+
+			// Parse each row, skipping headers repeated in the body
 			table.Find("tbody tr").Each(func(j int, row *goquery.Selection) {
 				cells := row.Find("td").Map(func(_ int, td *goquery.Selection) string {
 					return strings.TrimSpace(td.Text())
 				})
-				
+				if isRepeatedHeaderRow(row, cells, headers) {
+					return
+				}
+
 				if event := s.parseDistributionRow(cells, headers, symbol); event != nil {
 					events = append(events, *event)
 				}
 			})
 		}
 	})
-	
-	return events
+
+	// Tables split by year can overlap at the boundary
+	merged := models.DividendHistory{Events: events}
+	if removed := merged.DedupEvents(); removed > 0 {
+		s.logger.Debugf("Merged %d events listed in more than one of %d tables for %s", removed, tables, symbol)
+	}
+	merged.SortEventsDescending()
+
+	return merged.Events
+}
+
+// distributionTableHeaders returns the lower-cased header texts of a table's
+// first header row, ignoring header rows repeated further down
+func distributionTableHeaders(table *goquery.Selection) []string {
+	var headers []string
+	table.Find("tr").EachWithBreak(func(_ int, row *goquery.Selection) bool {
+		cells := row.Find("th")
+		if cells.Length() == 0 {
+			return true
+		}
+		headers = cells.Map(func(_ int, th *goquery.Selection) string {
+			return strings.ToLower(strings.TrimSpace(th.Text()))
+		})
+		return false
+	})
+	return headers
+}
+
+// isRepeatedHeaderRow reports whether a body row is a header rather than a
+// distribution: it has header cells, its cells repeat the header texts, or
+// none of them holds a digit
+func isRepeatedHeaderRow(row *goquery.Selection, cells []string, headers []string) bool {
+	if row.Find("th").Length() > 0 {
+		return true
+	}
+
+	known := make(map[string]bool, len(headers))
+	for _, header := range headers {
+		known[header] = true
+	}
+	repeats, digits := 0, 0
+	for _, cell := range cells {
+		if known[strings.ToLower(cell)] {
+			repeats++
+		}
+		if strings.IndexFunc(cell, unicode.IsDigit) >= 0 {
+			digits++
+		}
+	}
+	return digits == 0 || repeats*2 > len(cells)
 }
 
 // parseDistributionRow parses a single distribution history row
@@ -288,38 +523,39 @@ func (s *YieldMaxFullScraper) parseDistributionRow(cells []string, headers []str
 	if len(cells) == 0 {
 		return nil
 	}
-	
+
 	event := &models.DividendEvent{
 		Symbol: symbol,
+		Source: models.SourceYieldMax,
 	}
-	
+
 	// Map headers to cell indices
 	for i, cell := range cells {
 		if i >= len(headers) {
 			break
 		}
-		
+
 		header := headers[i]
-		
+
 		// Parse dates
 		if strings.Contains(header, "ex-date") || strings.Contains(header, "ex date") {
-			if date := s.parseDate(cell); !date.IsZero() {
+			if date := flexibleDate(cell); !date.IsZero() {
 				event.ExDate = date
 			}
 		} else if strings.Contains(header, "pay date") || strings.Contains(header, "payment") {
-			if date := s.parseDate(cell); !date.IsZero() {
+			if date := flexibleDate(cell); !date.IsZero() {
 				event.PayDate = date
 			}
 		}
-		
+
 		// Parse amount
 		if strings.Contains(header, "amount") || strings.Contains(header, "distribution") {
 			if amount := s.parseAmount(cell); amount > 0 {
-				event.Amount = amount
+				event.Amount = models.RoundAmount(amount)
 			}
 		}
 	}
-	
+
 	// Only return if we have valid data
 	if !event.ExDate.IsZero() && event.Amount > 0 {
 		// Set pay date to ex date + 1 day if not provided
@@ -328,87 +564,63 @@ func (s *YieldMaxFullScraper) parseDistributionRow(cells []string, headers []str
 		}
 		return event
 	}
-	
+
 	return nil
 }
 
-// parseDate attempts to parse various date formats
-func (s *YieldMaxFullScraper) parseDate(str string) time.Time {
-	// Clean the string
-	str = strings.TrimSpace(str)
-	str = regexp.MustCompile(`\s+`).ReplaceAllString(str, " ")
-	
-	// Try various date formats
-	formats := []string{
-		"01/02/2006",
-		"1/2/2006",
-		"01/02/06",
-		"1/2/06",
-		"2006-01-02",
-		"Jan 2, 2006",
-		"January 2, 2006",
-		"02-Jan-2006",
-		"02-January-2006",
-	}
-	
-	for _, format := range formats {
-		if t, err := time.Parse(format, str); err == nil {
-			// Handle 2-digit years
-			if t.Year() < 100 {
-				t = t.AddDate(2000, 0, 0)
-			}
-			return t
-		}
+// parseAmount extracts amount from string. Negative amounts yield 0.
+func (s *YieldMaxFullScraper) parseAmount(str string) float64 {
+	if isNegativeAmount(str) {
+		s.logger.Warnf("Skipped negative amount %q: adjustments are not dividends", str)
+		return 0
 	}
-	
-	return time.Time{}
-}
 
-// parseAmount extracts amount from string
-func (s *YieldMaxFullScraper) parseAmount(str string) float64 {
 	// Remove $ and other characters, keep only numbers and decimal point
 	cleanStr := regexp.MustCompile(`[^0-9.]`).ReplaceAllString(str, "")
-	
-	if amount, err := strconv.ParseFloat(cleanStr, 64); err == nil {
-		// Sanity check - dividend amounts are typically less than $10
-		if amount > 0 && amount < 10 {
-			return amount
-		}
-		// Check if the amount might be in cents (e.g., 53 instead of 0.53)
-		if amount > 10 && amount < 1000 {
-			return amount / 100
-		}
-	}
-	
-	return 0
-}
 
-// ScrapeAndSaveAllData scrapes and saves all ETF data
-func (s *YieldMaxFullScraper) ScrapeAndSaveAllData(outputDir string) error {
-	// Scrape all ETFs
-	etfs, err := s.ScrapeAllETFs()
+	amount, err := strconv.ParseFloat(cleanStr, 64)
 	if err != nil {
-		return fmt.Errorf("failed to scrape ETFs: %w", err)
+		return 0
 	}
-	
-	// Save ETF list
-	etfsJSON, err := json.MarshalIndent(etfs, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal ETFs: %w", err)
+
+	// A whole number of 10 or more is likely cents (e.g., 53 instead of 0.53)
+	if !strings.Contains(cleanStr, ".") && amount >= 10 && amount < 1000 {
+		amount /= 100
 	}
-	
-	etfsPath := fmt.Sprintf("%s/etfs.json", outputDir)
-	if err := ioutil.WriteFile(etfsPath, etfsJSON, 0644); err != nil {
-		return fmt.Errorf("failed to write ETFs file: %w", err)
+	if !s.bounds.Contains(amount) {
+		s.logger.Warnf("Rejected implausible amount %q (allowed %v-%v)", str, s.bounds.Min, s.bounds.Max)
+		return 0
 	}
-	
-	s.logger.Infof("Saved %d ETFs to %s", len(etfs), etfsPath)
-	
-	// Scrape and save dividend history for each ETF
+	return amount
+}
+
+// FullScrapeResult is what ScrapeAndSaveAllData collected besides the files it wrote
+type FullScrapeResult struct {
+	ETFs   []models.ETF
+	Yields map[string]float64 // Current distribution rate in percent, for symbols whose page showed one
+}
+
+// GetETFDetail scrapes symbol's detail page, letting the scraper serve as a
+// DetailScraper
+func (s *YieldMaxFullScraper) GetETFDetail(symbol string) (*models.ETFDetail, error) {
+	return s.ScrapeETFDetails(symbol)
+}
+
+// ScrapeAndSaveAllData scrapes all ETF data and saves each dividend history.
+// The ETF list is returned rather than written, leaving etfs.json to the caller.
+func (s *YieldMaxFullScraper) ScrapeAndSaveAllData(outputDir string) (*FullScrapeResult, error) {
+	// Scrape all ETFs and their detail pages
+	etfs, scraped := s.scrapeETFs()
+
+	result := &FullScrapeResult{ETFs: etfs, Yields: make(map[string]float64)}
+
+	// Save the dividend history scraped for each ETF
 	for _, etf := range etfs {
-		s.logger.Infof("Scraping dividend history for %s", etf.Symbol)
-		
-		if details, err := s.ScrapeETFDetails(etf.Symbol); err == nil && len(details.DividendHistory) > 0 {
+		details, ok := scraped[etf.Symbol]
+		if ok && details.CurrentYield > 0 {
+			result.Yields[etf.Symbol] = details.CurrentYield
+		}
+		if ok && len(details.DividendHistory) > 0 {
 			history := models.DividendHistory{
 				Symbol:    etf.Symbol,
 				Name:      etf.Name,
@@ -417,38 +629,41 @@ func (s *YieldMaxFullScraper) ScrapeAndSaveAllData(outputDir string) error {
 				Events:    details.DividendHistory,
 				UpdatedAt: time.Now(),
 			}
-			
+
 			// Calculate stats
-			var totalAmount float64
-			for _, event := range history.Events {
-				totalAmount += event.Amount
+			s.checkWeekendExDates(&history)
+			history.KeepYears(s.years)
+			history.SortEventsDescending()
+			history.DetectFrequencyRegimes()
+			// An override frequency wins over the one detected from the events
+			s.overrides.ApplyHistory(&history)
+			history.Stats = models.CalculateStats(history.Events)
+			if s.targetTracking {
+				history.Target = history.TargetTrackingAt(details.CurrentPrice, s.clock.Now())
 			}
-			
-			if len(history.Events) > 0 {
-				history.Stats.TotalPayments = len(history.Events)
-				history.Stats.AverageAmount = totalAmount / float64(len(history.Events))
-				history.Stats.LastAmount = history.Events[0].Amount
-			}
-			
+			history.TrimEvents(s.maxEvents)
+
 			// Save to file
-			historyJSON, err := json.MarshalIndent(history, "", "  ")
-			if err != nil {
-				s.logger.Errorf("Failed to marshal history for %s: %v", etf.Symbol, err)
-				continue
-			}
-			
 			historyPath := fmt.Sprintf("%s/dividends_%s.json", outputDir, etf.Symbol)
-			if err := ioutil.WriteFile(historyPath, historyJSON, 0644); err != nil {
+			if _, err := export.SaveHistory(historyPath, &history); err != nil {
 				s.logger.Errorf("Failed to write history for %s: %v", etf.Symbol, err)
 				continue
 			}
-			
+
 			s.logger.Infof("Saved %d dividend events for %s", len(history.Events), etf.Symbol)
 		}
-		
-		// Rate limiting
-		time.Sleep(3 * time.Second)
 	}
-	
-	return nil
-}
\ No newline at end of file
+
+	return result, nil
+}
+
+// checkWeekendExDates logs, and optionally fixes, ex-dates that fall on a weekend
+func (s *YieldMaxFullScraper) checkWeekendExDates(history *models.DividendHistory) {
+	for _, weekend := range history.CheckWeekendExDates(s.fixWeekends) {
+		if weekend.Corrected.IsZero() {
+			s.logger.Warnf("%s ex-date %s falls on a %s", history.Symbol, weekend.Event.ExDate.Format("2006-01-02"), weekend.Event.ExDate.Weekday())
+			continue
+		}
+		s.logger.Warnf("Moved %s ex-date %s from a %s to %s", history.Symbol, weekend.Event.ExDate.Format("2006-01-02"), weekend.Event.ExDate.Weekday(), weekend.Corrected.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,162 @@
+package scraper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+	"divminder-crawler/internal/overrides"
+)
+
+// newTestFullScraper returns a full scraper reading from baseURL with
+// detail pages fetched at once
+func newTestFullScraper(baseURL string) *YieldMaxFullScraper {
+	s := NewYieldMaxFullScraper()
+	s.SetBaseURL(baseURL)
+	s.SetDetailConcurrency(4, time.Millisecond)
+	return s
+}
+
+func TestFullScraperAppliesOverrides(t *testing.T) {
+	site := newFixtureSite(t, fundPages)
+
+	s := newTestFullScraper(site.URL)
+	s.SetOverrides(overrides.Overrides{
+		"TSLY": {Name: "TSLY Override", Frequency: "weekly"},
+		"BIGY": {Group: "GroupA"},
+	})
+
+	dir := t.TempDir()
+	result, err := s.ScrapeAndSaveAllData(dir)
+	if err != nil {
+		t.Fatalf("ScrapeAndSaveAllData: %v", err)
+	}
+
+	etfs := make(map[string]int, len(result.ETFs))
+	for i, etf := range result.ETFs {
+		etfs[etf.Symbol] = i
+	}
+
+	// The override beats the scraped name and frequency, and the unset
+	// group keeps the default
+	tsly := result.ETFs[etfs["TSLY"]]
+	if tsly.Name != "TSLY Override" || tsly.Frequency != "weekly" || tsly.Group != "GroupA" {
+		t.Errorf("TSLY = %s/%s/%s, want TSLY Override/weekly/GroupA", tsly.Name, tsly.Frequency, tsly.Group)
+	}
+	// Without a page, the override beats the default
+	if bigy := result.ETFs[etfs["BIGY"]]; bigy.Group != "GroupA" || bigy.Frequency != "monthly" {
+		t.Errorf("BIGY = %s/%s, want GroupA/monthly", bigy.Group, bigy.Frequency)
+	}
+	// Without an override, the scrape beats the default
+	if ymax := result.ETFs[etfs["YMAX"]]; ymax.Name != "YieldMax YMAX Universe Fund of Option Income ETFs" {
+		t.Errorf("YMAX name = %q, want the scraped one", ymax.Name)
+	}
+
+	histories, err := export.LoadHistories(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := false
+	for _, history := range histories {
+		if history.Symbol != "TSLY" {
+			continue
+		}
+		saved = true
+		if history.Name != "TSLY Override" || history.Frequency != "weekly" || len(history.Events) != 3 {
+			t.Errorf("saved TSLY = %s/%s with %d events, want TSLY Override/weekly with 3", history.Name, history.Frequency, len(history.Events))
+		}
+	}
+	if !saved {
+		t.Errorf("no history saved for TSLY in %s", filepath.Base(dir))
+	}
+
+	// The ETF list is the caller's to save, depending on its outputs
+	if _, err := os.Stat(filepath.Join(dir, "etfs.json")); !os.IsNotExist(err) {
+		t.Errorf("etfs.json written by the scraper (err %v)", err)
+	}
+}
+
+func TestFullScraperSavesTargetTracking(t *testing.T) {
+	site := newFixtureSite(t, fundPages)
+	target12 := overrides.Overrides{"TSLY": {Group: "Target12"}}
+
+	for _, enabled := range []bool{true, false} {
+		s := newTestFullScraper(site.URL)
+		s.SetOverrides(target12)
+		s.SetGroupResolver(NewGroupResolverFromFile(target12, ""))
+		s.SetClock(clock.NewFake(fixtureNow))
+		s.SetTargetTracking(enabled)
+
+		dir := t.TempDir()
+		if _, err := s.ScrapeAndSaveAllData(dir); err != nil {
+			t.Fatalf("ScrapeAndSaveAllData: %v", err)
+		}
+		histories, err := export.LoadHistories(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tracked := make(map[string]*models.TargetTracking)
+		for _, history := range histories {
+			tracked[history.Symbol] = history.Target
+		}
+		tsly, ok := tracked["TSLY"]
+		if !ok {
+			t.Fatal("no history saved for TSLY")
+		}
+		if !enabled {
+			if tsly != nil {
+				t.Errorf("tracking disabled but TSLY saved %+v", tsly)
+			}
+			continue
+		}
+		if tsly == nil || tsly.TargetRate != models.Target12Rate || tsly.AnnualizedActualRate <= 0 {
+			t.Fatalf("TSLY tracking = %+v, want a measurement against %v%%", tsly, models.Target12Rate)
+		}
+		if !almostEqual(tsly.VarianceFromTarget, tsly.AnnualizedActualRate-models.Target12Rate) {
+			t.Errorf("variance %v, want actual %v minus the target", tsly.VarianceFromTarget, tsly.AnnualizedActualRate)
+		}
+		if ymax := tracked["YMAX"]; ymax != nil {
+			t.Errorf("weekly YMAX tracked against the Target12 target: %+v", ymax)
+		}
+	}
+}
+
+func TestFullScraperMergesYearSplitTables(t *testing.T) {
+	site := newFixtureSite(t, map[string]string{"/our-etfs/splt/": "fund_page_year_split.html"})
+
+	detail, err := newTestFullScraper(site.URL).ScrapeETFDetails("SPLT")
+	if err != nil {
+		t.Fatalf("ScrapeETFDetails: %v", err)
+	}
+
+	// January 2025 is listed in both tables and the repeated headers are
+	// skipped; the December special keeps its own row beside the regular one
+	want := []struct {
+		exDate time.Time
+		amount float64
+	}{
+		{marketDate(2025, 3, 6), 0.51},
+		{marketDate(2025, 2, 6), 0.48},
+		{marketDate(2025, 1, 8), 0.45},
+		{marketDate(2024, 12, 5), 0.42},
+		{marketDate(2024, 12, 5), 0.03},
+		{marketDate(2024, 11, 7), 0.40},
+	}
+	events := detail.DividendHistory
+	if len(events) != len(want) {
+		t.Fatalf("parsed %d events %v, want %d", len(events), eventAmounts(events), len(want))
+	}
+	for i, w := range want {
+		if !events[i].ExDate.Equal(w.exDate) || !almostEqual(events[i].Amount, w.amount) {
+			t.Errorf("event %d = %v %v, want %v %v", i, events[i].ExDate, events[i].Amount, w.exDate, w.amount)
+		}
+	}
+	if !events[2].PayDate.Equal(marketDate(2025, 1, 9)) {
+		t.Errorf("January pay date = %v, want 2025-01-09", events[2].PayDate)
+	}
+}
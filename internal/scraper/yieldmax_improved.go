@@ -2,42 +2,154 @@ package scraper
 
 import (
 	"fmt"
+	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
+	"divminder-crawler/internal/clock"
 	"divminder-crawler/internal/models"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/gocolly/colly/v2"
 	"github.com/sirupsen/logrus"
 )
 
 // ImprovedYieldMaxScraper handles scraping with better parsing logic
 type ImprovedYieldMaxScraper struct {
-	collector *colly.Collector
-	logger    *logrus.Logger
-	etfGroups map[string]string // Symbol -> Group mapping
+	collector     *colly.Collector
+	logger        *logrus.Logger
+	etfGroups     map[string]string // Symbol -> Group mapping
+	scrapedGroups map[string]string // Mapping parsed from the schedule page during this run
+	groupsFile    string            // Where the scraped mapping is persisted between runs
+	clock         clock.Clock
+	baseURL       string
+	target12Found bool // The Target 12 table was found and parsed this run
+	upcomingDays  int  // Window of the schedule's upcoming events
+	limit         *colly.LimitRule
 }
 
+// DefaultUpcomingDays is how many days ahead of the as-of date a schedule's
+// upcoming events reach
+const DefaultUpcomingDays = 30
+
 // NewImprovedYieldMaxScraper creates an improved scraper instance
 func NewImprovedYieldMaxScraper() *ImprovedYieldMaxScraper {
 	c := colly.NewCollector(
 		colly.Async(true),
 	)
 
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  "*yieldmaxetfs.com*",
-		Parallelism: 2,
-		Delay:       2 * time.Second, // Slower to be more respectful
-	})
+	limit := applyLimit(c, 2, 2*time.Second) // Slower to be more respectful
+
+	applyProxy(c)
+	observeRequests(c)
+	throttleRequests(c)
 
 	logger := logrus.New()
 	logger.SetLevel(logrus.InfoLevel)
 
-	return &ImprovedYieldMaxScraper{
-		collector: c,
-		logger:    logger,
-		etfGroups: make(map[string]string),
+	ys := &ImprovedYieldMaxScraper{
+		collector:     c,
+		logger:        logger,
+		scrapedGroups: make(map[string]string),
+		groupsFile:    DefaultScrapedGroupsFile,
+		clock:         clock.Real{},
+		baseURL:       DefaultBaseURL,
+		upcomingDays:  DefaultUpcomingDays,
+		limit:         limit,
+	}
+	ys.etfGroups = ys.loadETFGroups()
+
+	return ys
+}
+
+// WithLimitRule changes how many requests run at once and the delay between
+// them, which default to 2 and 2s
+func (ys *ImprovedYieldMaxScraper) WithLimitRule(parallelism int, delay time.Duration) *ImprovedYieldMaxScraper {
+	setLimit(ys.limit, parallelism, delay)
+	return ys
+}
+
+// SetClock replaces the clock used to decide which events are upcoming; a
+// fake clock set to a past date builds the schedule as it looked then
+func (ys *ImprovedYieldMaxScraper) SetClock(c clock.Clock) {
+	ys.clock = c
+}
+
+// SetUpcomingDays changes how many days ahead of the as-of date the
+// schedule's upcoming events reach
+func (ys *ImprovedYieldMaxScraper) SetUpcomingDays(days int) {
+	ys.upcomingDays = days
+}
+
+// SetGroupsFile changes where the scraped group mapping is loaded from and
+// saved to after a scrape, reloading the mapping from path; an empty path
+// starts from the static mapping and keeps scraped groups in memory only
+func (ys *ImprovedYieldMaxScraper) SetGroupsFile(path string) {
+	ys.groupsFile = path
+	ys.etfGroups = ys.loadETFGroups()
+}
+
+// SetBaseURL changes the site the schedule page is fetched from
+func (ys *ImprovedYieldMaxScraper) SetBaseURL(baseURL string) {
+	ys.baseURL = baseURL
+}
+
+// loadETFGroups seeds the mapping from GetYieldMaxETFGroups and overlays the
+// mapping persisted by the last successful scrape, which is authoritative
+func (ys *ImprovedYieldMaxScraper) loadETFGroups() map[string]string {
+	groups := GetYieldMaxETFGroups()
+	if ys.groupsFile == "" {
+		return groups
+	}
+
+	scraped, err := LoadScrapedETFGroups(ys.groupsFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			ys.logger.Warnf("Failed to load scraped group mapping: %v", err)
+		}
+		return groups
+	}
+
+	for symbol, group := range scraped {
+		groups[symbol] = group
+	}
+	ys.logger.Infof("Loaded %d scraped group mappings from %s", len(scraped), ys.groupsFile)
+
+	return groups
+}
+
+// persistScrapedGroups applies the mapping scraped during this run, logs where
+// it disagrees with the static mapping and saves it for the next run
+func (ys *ImprovedYieldMaxScraper) persistScrapedGroups() {
+	if len(ys.scrapedGroups) == 0 {
+		ys.logger.Warn("No group mapping scraped, keeping previously known mapping")
+		return
+	}
+
+	for _, diff := range DiffETFGroups(ys.scrapedGroups, GetYieldMaxETFGroups()) {
+		switch {
+		case diff.Static == "":
+			ys.logger.Infof("Scraped mapping has new symbol %s in %s", diff.Symbol, diff.Scraped)
+		case diff.Scraped == "":
+			ys.logger.Infof("Static symbol %s (%s) missing from scraped mapping", diff.Symbol, diff.Static)
+		default:
+			ys.logger.Warnf("Group mismatch for %s: scraped %s, static %s", diff.Symbol, diff.Scraped, diff.Static)
+		}
+	}
+
+	for symbol, group := range ys.scrapedGroups {
+		ys.etfGroups[symbol] = group
+	}
+
+	if ys.groupsFile == "" {
+		return
+	}
+	if err := SaveScrapedETFGroups(ys.groupsFile, ys.scrapedGroups); err != nil {
+		ys.logger.Errorf("Failed to save scraped group mapping: %v", err)
+	} else {
+		ys.logger.Infof("Saved %d scraped group mappings to %s", len(ys.scrapedGroups), ys.groupsFile)
 	}
 }
 
@@ -47,7 +159,8 @@ func (ys *ImprovedYieldMaxScraper) GetScheduleImproved() (*models.Schedule, erro
 	var groupSchedules []models.GroupSchedule
 	var upcomingEvents []models.DividendEvent
 
-	scheduleURL := "https://www.yieldmaxetfs.com/distribution-schedule/"
+	scheduleURL := schedulePageURL(ys.baseURL)
+	ys.target12Found = false
 
 	// First, parse the ETF group mapping table at the bottom
 	ys.collector.OnHTML("table", func(e *colly.HTMLElement) {
@@ -82,8 +195,18 @@ func (ys *ImprovedYieldMaxScraper) GetScheduleImproved() (*models.Schedule, erro
 		}
 	})
 
+	// Collect distribution amounts announced ahead of their ex-date
+	var announced []AnnouncedDistribution
+	ys.collector.OnHTML("table tr", func(e *colly.HTMLElement) {
+		if a, ok := parseAnnouncementRow(e.ChildTexts("td"), ys.etfGroups, ys.parseDate); ok {
+			announced = append(announced, a)
+		}
+	})
+
+	var fetchErr error
 	ys.collector.OnError(func(r *colly.Response, err error) {
 		ys.logger.Errorf("Error scraping %s: %v", r.Request.URL, err)
+		fetchErr = err
 	})
 
 	// Visit the page
@@ -94,17 +217,33 @@ func (ys *ImprovedYieldMaxScraper) GetScheduleImproved() (*models.Schedule, erro
 
 	ys.collector.Wait()
 
+	// Synthetic events alone would hide that the page couldn't be fetched
+	if fetchErr != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", scheduleURL, fetchErr)
+	}
+
+	ys.persistScrapedGroups()
+
 	// Generate synthetic events since web parsing might not catch everything
 	ys.logger.Info("Generating synthetic events for testing...")
 	ys.generateSyntheticEvents(&upcomingEvents)
 
+	// Announced amounts replace the synthetic placeholders for matching ex-dates
+	updated := applyAnnouncedAmounts(upcomingEvents, announced)
+
 	// Create group schedules from the ETF mapping and events
 	groupSchedules = ys.buildGroupSchedules(upcomingEvents)
+	for i := range groupSchedules {
+		updated += applyAnnouncedAmounts(groupSchedules[i].Events, announced)
+	}
+	if len(announced) > 0 {
+		ys.logger.Infof("Found %d announced distributions, updated %d schedule entries", len(announced), updated)
+	}
 
 	schedule = models.Schedule{
-		UpdatedAt: time.Now(),
+		UpdatedAt: ys.clock.Now(),
 		Groups:    groupSchedules,
-		Upcoming:  ys.filterUpcomingEvents(upcomingEvents, 30), // Next 30 days
+		Upcoming:  ys.filterUpcomingEvents(upcomingEvents, ys.upcomingDays),
 	}
 
 	ys.logger.Infof("Successfully parsed %d groups and %d upcoming events",
@@ -130,24 +269,34 @@ func (ys *ImprovedYieldMaxScraper) parseETFGroupMappingTable(e *colly.HTMLElemen
 
 			// Map ETFs to their groups
 			for _, etf := range weeklyETFs {
-				ys.etfGroups[etf] = "Weekly"
+				ys.assignScrapedGroup(etf, "Weekly")
 			}
 			for _, etf := range groupAETFs {
-				ys.etfGroups[etf] = "GroupA"
+				ys.assignScrapedGroup(etf, "GroupA")
 			}
 			for _, etf := range groupBETFs {
-				ys.etfGroups[etf] = "GroupB"
+				ys.assignScrapedGroup(etf, "GroupB")
 			}
 			for _, etf := range groupCETFs {
-				ys.etfGroups[etf] = "GroupC"
+				ys.assignScrapedGroup(etf, "GroupC")
 			}
 			for _, etf := range groupDETFs {
-				ys.etfGroups[etf] = "GroupD"
+				ys.assignScrapedGroup(etf, "GroupD")
 			}
 		}
 	})
 
-	ys.logger.Infof("Mapped %d ETFs to groups", len(ys.etfGroups))
+	ys.logger.Infof("Mapped %d ETFs to groups", len(ys.scrapedGroups))
+}
+
+// assignScrapedGroup maps etf to group. A symbol listed under two groups
+// keeps the later column, so a rotation group wins over Weekly Payers, and
+// the conflict is logged.
+func (ys *ImprovedYieldMaxScraper) assignScrapedGroup(etf, group string) {
+	if previous, exists := ys.scrapedGroups[etf]; exists && previous != group {
+		ys.logger.Warnf("%s is listed under both %s and %s, using %s", etf, previous, group, group)
+	}
+	ys.scrapedGroups[etf] = group
 }
 
 // parseETFsFromCell extracts ETF symbols from a table cell
@@ -167,46 +316,100 @@ func (ys *ImprovedYieldMaxScraper) parseETFsFromCell(cellText string) []string {
 	return etfs
 }
 
-// parseTarget12TableImproved parses Target 12 schedule with improved logic
-func (ys *ImprovedYieldMaxScraper) parseTarget12TableImproved(table interface{}, events *[]models.DividendEvent) {
-	// Target 12 ETFs - these typically pay monthly
-	target12ETFs := []string{"BIGY", "SOXY", "RNTY", "KLIP", "ALTY"}
-
-	// Generate Target 12 events for 2025 (monthly schedule)
-	sampleDates := []string{
-		"1/8/25", "2/5/25", "3/5/25", "4/2/25", "5/7/25", "6/4/25",
-		"7/2/25", "8/6/25", "9/3/25", "10/8/25", "11/5/25", "12/3/25",
-	}
-
-	for _, dateStr := range sampleDates {
-		exDate := ys.parseDate(dateStr)
-		if !exDate.IsZero() && exDate.After(time.Now()) {
-			// For each Target 12 ETF, create an event
-			for _, symbol := range target12ETFs {
-				// Check if this symbol is in our ETF mapping
-				if _, exists := ys.etfGroups[symbol]; !exists {
-					ys.etfGroups[symbol] = "Target12"
-				}
-
-				event := models.DividendEvent{
-					Symbol:      symbol,
-					ExDate:      exDate,
-					PayDate:     exDate.AddDate(0, 0, 2),  // Pay date 2 days after ex-date
-					DeclareDate: exDate.AddDate(0, 0, -1), // Declare date 1 day before
-					Group:       "Target12",
-					Frequency:   "monthly",
-					Amount:      0.25 + float64((len(symbol)+int(exDate.Unix()))%10-5)*0.02, // Variable amount
-				}
-				*events = append(*events, event)
-			}
+// target12PlaceholderAmount stands in until the amount is announced
+const target12PlaceholderAmount = 0.25
+
+// parseTarget12TableImproved parses the Target 12 schedule rows into events
+// for every current Target12 member. Amounts aren't published ahead of time,
+// so the events carry a placeholder amount and are marked estimated.
+func (ys *ImprovedYieldMaxScraper) parseTarget12TableImproved(table *goquery.Selection, events *[]models.DividendEvent) {
+	target12ETFs, _ := ys.getETFsForGroup("Target12")
+	sort.Strings(target12ETFs)
+
+	today := models.MarketDate(ys.clock.Now())
+	rows := parseScheduleTableRows(table, ys.parseDate)
+	for _, row := range rows {
+		if row.exDate.Before(today) {
+			continue
+		}
+		for _, symbol := range target12ETFs {
+			*events = append(*events, models.DividendEvent{
+				Symbol:      symbol,
+				ExDate:      row.exDate,
+				PayDate:     row.payDate,
+				DeclareDate: row.declareDate,
+				RecordDate:  row.exDate, // Record date equals ex-date under T+1 settlement
+				Group:       "Target12",
+				Frequency:   "monthly",
+				Amount:      target12PlaceholderAmount,
+				Estimated:   true,
+				Source:      models.SourceYieldMax,
+			})
 		}
 	}
+
+	if len(rows) > 0 {
+		ys.target12Found = true
+	}
+	ys.logger.Infof("Parsed %d Target 12 schedule rows for %d ETFs", len(rows), len(target12ETFs))
+}
+
+// scheduleRow is one declare/ex/pay row of a schedule table
+type scheduleRow struct {
+	declareDate time.Time
+	exDate      time.Time
+	payDate     time.Time
+}
+
+// parseScheduleTableRows reads the declare, ex and pay dates from each body
+// row, locating the columns by their header text and falling back to the
+// site's usual declare/ex/pay order after a leading label column. Rows
+// without an ex-date are skipped; a missing pay date is the next day.
+func parseScheduleTableRows(table *goquery.Selection, parseDate func(string) time.Time) []scheduleRow {
+	declareCol, exCol, payCol := 1, 2, 3
+	table.Find("tr").First().Find("th, td").Each(func(i int, cell *goquery.Selection) {
+		header := strings.ToLower(strings.TrimSpace(cell.Text()))
+		switch {
+		case strings.Contains(header, "declar"):
+			declareCol = i
+		case strings.Contains(header, "ex"):
+			exCol = i
+		case strings.Contains(header, "pay"):
+			payCol = i
+		}
+	})
+
+	var rows []scheduleRow
+	table.Find("tr").Each(func(i int, tr *goquery.Selection) {
+		cells := tr.Find("td").Map(func(_ int, cell *goquery.Selection) string {
+			return strings.TrimSpace(cell.Text())
+		})
+		if exCol >= len(cells) {
+			return
+		}
+
+		row := scheduleRow{exDate: parseDate(cells[exCol])}
+		if row.exDate.IsZero() {
+			return
+		}
+		if declareCol < len(cells) {
+			row.declareDate = parseDate(cells[declareCol])
+		}
+		if payCol < len(cells) {
+			row.payDate = parseDate(cells[payCol])
+		}
+		if row.payDate.IsZero() {
+			row.payDate = row.exDate.AddDate(0, 0, 1)
+		}
+		rows = append(rows, row)
+	})
+	return rows
 }
 
 // parseWeeklyGroupsTableImproved parses the weekly/groups schedule table
 func (ys *ImprovedYieldMaxScraper) parseWeeklyGroupsTableImproved(table interface{}, events *[]models.DividendEvent) {
 	// Generate comprehensive weekly schedule for next 8 weeks
-	now := time.Now()
+	now := models.MarketDate(ys.clock.Now())
 
 	// YieldMax typical schedule: Groups rotate weekly
 	// Week 1: GroupB, Week 2: GroupC, Week 3: GroupD, Week 4: GroupA, then repeat
@@ -228,9 +431,12 @@ func (ys *ImprovedYieldMaxScraper) parseWeeklyGroupsTableImproved(table interfac
 			ExDate:      baseDate,
 			PayDate:     baseDate.AddDate(0, 0, 1),  // Thursday (next day)
 			DeclareDate: baseDate.AddDate(0, 0, -1), // Tuesday (previous day)
+			RecordDate:  baseDate,
 			Group:       group,
 			Frequency:   "weekly",
-			Amount:      0.15 + float64(weekOffset%3)*0.02, // Variable weekly amount
+			Amount:      models.RoundAmount(0.15 + float64(weekOffset%3)*0.02), // Variable weekly amount
+			Estimated:   true,
+			Source:      models.SourceYieldMax,
 		}
 
 		*events = append(*events, event)
@@ -249,9 +455,12 @@ func (ys *ImprovedYieldMaxScraper) parseWeeklyGroupsTableImproved(table interfac
 			ExDate:      baseDate,
 			PayDate:     baseDate.AddDate(0, 0, 1),  // Friday
 			DeclareDate: baseDate.AddDate(0, 0, -1), // Wednesday
+			RecordDate:  baseDate,
 			Group:       "Weekly",
 			Frequency:   "weekly",
-			Amount:      0.18 + float64(weekOffset%4)*0.015, // Variable amount
+			Amount:      models.RoundAmount(0.18 + float64(weekOffset%4)*0.015), // Variable amount
+			Estimated:   true,
+			Source:      models.SourceYieldMax,
 		}
 
 		*events = append(*events, event)
@@ -280,8 +489,15 @@ func (ys *ImprovedYieldMaxScraper) buildGroupSchedules(events []models.DividendE
 		groupMap[group].ETFs = append(groupMap[group].ETFs, etf)
 	}
 
-	// Add events to appropriate groups and create per-ETF events
+	// Add events to appropriate groups and create per-ETF events. Each symbol
+	// belongs to exactly one group, so a symbol's own event filed under
+	// another group is dropped rather than giving it two groups' dates.
+	mismatched := 0
 	for _, event := range events {
+		if mapped, known := ys.etfGroups[event.Symbol]; event.Symbol != "" && known && mapped != event.Group {
+			mismatched++
+			continue
+		}
 		if group, exists := groupMap[event.Group]; exists {
 			// For group-wide events, create individual ETF events
 			if event.Symbol == "" {
@@ -297,15 +513,21 @@ func (ys *ImprovedYieldMaxScraper) buildGroupSchedules(events []models.DividendE
 			}
 
 			// Set next dates if this is the earliest upcoming event
-			if event.ExDate.After(time.Now()) {
+			if event.ExDate.After(ys.clock.Now()) {
 				if group.NextExDate == "" || event.ExDate.Before(ys.parseDate(group.NextExDate)) {
 					group.NextExDate = event.ExDate.Format("2006-01-02")
 					group.NextPayDate = event.PayDate.Format("2006-01-02")
+					group.NextDeclareDate = formatScheduleDate(event.DeclareDate)
+					group.NextRecordDate = formatScheduleDate(event.RecordDate)
 				}
 			}
 		}
 	}
 
+	if mismatched > 0 {
+		ys.logger.Warnf("Dropped %d events filed under a group other than their symbol's", mismatched)
+	}
+
 	// Convert map to slice
 	var result []models.GroupSchedule
 	for _, group := range groupMap {
@@ -315,13 +537,31 @@ func (ys *ImprovedYieldMaxScraper) buildGroupSchedules(events []models.DividendE
 	return result
 }
 
-// filterUpcomingEvents returns events in the next N days
+// formatScheduleDate formats t as YYYY-MM-DD, or "" when the date is unknown
+func formatScheduleDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// filterUpcomingEvents returns the events within days after the as-of date
 func (ys *ImprovedYieldMaxScraper) filterUpcomingEvents(events []models.DividendEvent, days int) []models.DividendEvent {
-	cutoff := time.Now().AddDate(0, 0, days)
+	return FilterUpcomingEvents(events, ys.clock.Now(), days)
+}
+
+// FilterUpcomingEvents returns the events whose ex-date falls after asOf's
+// calendar date and within days of it
+func FilterUpcomingEvents(events []models.DividendEvent, asOf time.Time, days int) []models.DividendEvent {
+	// Compare calendar dates in the market's zone so an ex-date doesn't
+	// slip in or out of the window depending on where the crawler runs
+	today := models.MarketDate(asOf)
+	cutoff := today.AddDate(0, 0, days)
 	var upcoming []models.DividendEvent
 
 	for _, event := range events {
-		if event.ExDate.After(time.Now()) && event.ExDate.Before(cutoff) {
+		exDate := models.CalendarDate(event.ExDate)
+		if exDate.After(today) && exDate.Before(cutoff) {
 			upcoming = append(upcoming, event)
 		}
 	}
@@ -329,48 +569,37 @@ func (ys *ImprovedYieldMaxScraper) filterUpcomingEvents(events []models.Dividend
 	return upcoming
 }
 
-// parseDate improved date parsing with better format handling
+// parseDate parses a schedule date, returning the zero time when it can't
 func (ys *ImprovedYieldMaxScraper) parseDate(dateStr string) time.Time {
-	dateStr = strings.TrimSpace(dateStr)
-
-	formats := []string{
-		"1/2/06",      // 1/2/25
-		"1/2/2006",    // 1/2/2025
-		"01/02/06",    // 01/02/25
-		"01/02/2006",  // 01/02/2025
-		"2006-01-02",  // 2025-01-02
-		"Jan 2, 2006", // Jan 2, 2025
-	}
-
-	for _, format := range formats {
-		if parsed, err := time.Parse(format, dateStr); err == nil {
-			// Adjust 2-digit years
-			if parsed.Year() < 1950 {
-				parsed = parsed.AddDate(2000, 0, 0)
-			}
-			return parsed
-		}
-	}
-
-	return time.Time{}
+	t, _ := ParseFlexibleDateAt(dateStr, ys.clock.Now())
+	return t
 }
 
-// generateSyntheticEvents creates reliable test events
+// generateSyntheticEvents creates placeholder events, all marked Estimated,
+// that are identical for a given clock time and group mapping
 func (ys *ImprovedYieldMaxScraper) generateSyntheticEvents(events *[]models.DividendEvent) {
-	now := time.Now()
+	now := models.MarketDate(ys.clock.Now())
 
-	// Get correct ETF groupings
-	yieldMaxETFs := GetYieldMaxETFGroups()
+	// Fill in any symbols the scraped mapping doesn't know about
+	for symbol, group := range GetYieldMaxETFGroups() {
+		if _, exists := ys.etfGroups[symbol]; !exists {
+			ys.etfGroups[symbol] = group
+		}
+	}
+	yieldMaxETFs := ys.etfGroups
 
-	// Add all ETFs to the group mapping
-	for symbol, group := range yieldMaxETFs {
-		ys.etfGroups[symbol] = group
+	// Walk symbols in a fixed order so the same clock yields the same events
+	symbols := make([]string, 0, len(yieldMaxETFs))
+	for symbol := range yieldMaxETFs {
+		symbols = append(symbols, symbol)
 	}
+	sort.Strings(symbols)
 
-	// Generate Target 12 events (monthly) for the next 6 months
+	// Generate Target 12 events (monthly) for the next 6 months, unless the
+	// real schedule table was parsed
 	target12ETFs := []string{}
-	for symbol, group := range yieldMaxETFs {
-		if group == "Target12" {
+	for _, symbol := range symbols {
+		if yieldMaxETFs[symbol] == "Target12" && !ys.target12Found {
 			target12ETFs = append(target12ETFs, symbol)
 		}
 	}
@@ -392,9 +621,12 @@ func (ys *ImprovedYieldMaxScraper) generateSyntheticEvents(events *[]models.Divi
 					ExDate:      eventDate,
 					PayDate:     eventDate.AddDate(0, 0, 2),
 					DeclareDate: eventDate.AddDate(0, 0, -1),
+					RecordDate:  eventDate,
 					Group:       "Target12",
 					Frequency:   "monthly",
-					Amount:      0.25 + float64(monthOffset%3)*0.03,
+					Amount:      models.RoundAmount(0.25 + float64(monthOffset%3)*0.03),
+					Estimated:   true,
+					Source:      models.SourceSynthetic,
 				}
 				*events = append(*events, event)
 			}
@@ -416,16 +648,19 @@ func (ys *ImprovedYieldMaxScraper) generateSyntheticEvents(events *[]models.Divi
 		// Skip if date is in the past
 		if baseDate.After(now) {
 			// Create events for all ETFs in this group
-			for symbol, etfGroup := range yieldMaxETFs {
-				if etfGroup == group {
+			for _, symbol := range symbols {
+				if yieldMaxETFs[symbol] == group {
 					event := models.DividendEvent{
 						Symbol:      symbol,
 						ExDate:      baseDate,
 						PayDate:     baseDate.AddDate(0, 0, 1),
 						DeclareDate: baseDate.AddDate(0, 0, -1),
+						RecordDate:  baseDate,
 						Group:       group,
 						Frequency:   "weekly",
-						Amount:      0.15 + float64(weekOffset%3)*0.02,
+						Amount:      models.RoundAmount(0.15 + float64(weekOffset%3)*0.02),
+						Estimated:   true,
+						Source:      models.SourceSynthetic,
 					}
 					*events = append(*events, event)
 				}
@@ -441,16 +676,19 @@ func (ys *ImprovedYieldMaxScraper) generateSyntheticEvents(events *[]models.Divi
 		}
 
 		if baseDate.After(now) {
-			for symbol, group := range yieldMaxETFs {
-				if group == "Weekly" {
+			for _, symbol := range symbols {
+				if yieldMaxETFs[symbol] == "Weekly" {
 					event := models.DividendEvent{
 						Symbol:      symbol,
 						ExDate:      baseDate,
 						PayDate:     baseDate.AddDate(0, 0, 1),
 						DeclareDate: baseDate.AddDate(0, 0, -1),
+						RecordDate:  baseDate,
 						Group:       "Weekly",
 						Frequency:   "weekly",
-						Amount:      0.18 + float64(weekOffset%4)*0.015,
+						Amount:      models.RoundAmount(0.18 + float64(weekOffset%4)*0.015),
+						Estimated:   true,
+						Source:      models.SourceSynthetic,
 					}
 					*events = append(*events, event)
 				}
@@ -477,7 +715,7 @@ func (ys *ImprovedYieldMaxScraper) getETFsForGroup(targetGroup string) ([]string
 // GetImprovedETFList returns a comprehensive list of YieldMax ETFs with proper metadata
 func (ys *ImprovedYieldMaxScraper) GetImprovedETFList() ([]models.ETF, error) {
 	var etfs []models.ETF
-	
+
 	// Get correct ETF groupings first
 	if len(ys.etfGroups) == 0 {
 		ys.etfGroups = GetYieldMaxETFGroups()
@@ -521,7 +759,6 @@ func (ys *ImprovedYieldMaxScraper) GetImprovedETFList() ([]models.ETF, error) {
 
 		// Group B ETFs
 		"AMZY": {"YieldMax AMZN Option Income Strategy ETF", "Monthly income from AMZN covered calls"},
-		"CONY": {"YieldMax COIN Option Income Strategy ETF", "Monthly income from COIN options"},
 		"FBY":  {"YieldMax META Option Income Strategy ETF", "Monthly income from META covered calls"},
 		"NFLY": {"YieldMax NFLX Option Income Strategy ETF", "Monthly income from NFLX options"},
 		"QQLY": {"YieldMax Nasdaq 100 Option Income ETF", "Monthly income from QQQ covered calls"},
@@ -534,6 +771,7 @@ func (ys *ImprovedYieldMaxScraper) GetImprovedETFList() ([]models.ETF, error) {
 		"XOMO": {"YieldMax XOM Option Income Strategy ETF", "Monthly income from XOM covered calls"},
 
 		// Group C ETFs
+		"CONY": {"YieldMax COIN Option Income Strategy ETF", "Monthly income from COIN options"},
 		"AIYY": {"YieldMax AI Option Income Strategy ETF", "Monthly income from AI stocks"},
 		"BALY": {"YieldMax BAC Option Income Strategy ETF", "Monthly income from BAC options"},
 		"COWY": {"YieldMax Commodities Option Income ETF", "Monthly income from commodity stocks"},
@@ -582,7 +820,7 @@ func (ys *ImprovedYieldMaxScraper) GetImprovedETFList() ([]models.ETF, error) {
 
 		// Calculate next dividend dates based on group and current date
 		nextExDate, nextPayDate := ys.calculateNextDividendDates(symbol, group, frequency)
-		
+
 		etf := models.ETF{
 			Symbol:      symbol,
 			Name:        data.Name,
@@ -591,6 +829,7 @@ func (ys *ImprovedYieldMaxScraper) GetImprovedETFList() ([]models.ETF, error) {
 			Frequency:   frequency,
 			NextExDate:  nextExDate,
 			NextPayDate: nextPayDate,
+			Active:      true,
 		}
 		etfs = append(etfs, etf)
 	}
@@ -601,8 +840,8 @@ func (ys *ImprovedYieldMaxScraper) GetImprovedETFList() ([]models.ETF, error) {
 
 // calculateNextDividendDates calculates the next ex-date and pay-date for an ETF
 func (ys *ImprovedYieldMaxScraper) calculateNextDividendDates(symbol, group, frequency string) (string, string) {
-	now := time.Now()
-	
+	now := ys.clock.Now()
+
 	switch group {
 	case "Target12":
 		// Target 12 ETFs pay on first Wednesday of each month
@@ -616,7 +855,7 @@ func (ys *ImprovedYieldMaxScraper) calculateNextDividendDates(symbol, group, fre
 			firstOfMonth = firstOfMonth.AddDate(0, 0, 1)
 		}
 		return firstOfMonth.Format("2006-01-02"), firstOfMonth.AddDate(0, 0, 2).Format("2006-01-02")
-		
+
 	case "Weekly":
 		// Weekly payers pay every Thursday
 		nextThursday := now
@@ -627,11 +866,11 @@ func (ys *ImprovedYieldMaxScraper) calculateNextDividendDates(symbol, group, fre
 			nextThursday = nextThursday.AddDate(0, 0, 7)
 		}
 		return nextThursday.Format("2006-01-02"), nextThursday.AddDate(0, 0, 1).Format("2006-01-02")
-		
+
 	case "GroupA", "GroupB", "GroupC", "GroupD":
 		// Groups rotate weekly: B -> C -> D -> A
 		rotation := []string{"GroupB", "GroupC", "GroupD", "GroupA"}
-		
+
 		// Find current week's group
 		baseDate := now
 		for baseDate.Weekday() != time.Wednesday {
@@ -641,11 +880,11 @@ func (ys *ImprovedYieldMaxScraper) calculateNextDividendDates(symbol, group, fre
 				baseDate = baseDate.AddDate(0, 0, int(time.Wednesday)-int(baseDate.Weekday()))
 			}
 		}
-		
+
 		// Calculate weeks until this group's turn
 		weeksSinceStart := int(baseDate.Sub(time.Date(2025, 1, 1, 0, 0, 0, 0, baseDate.Location())).Hours() / 24 / 7)
 		currentGroupIndex := weeksSinceStart % 4
-		
+
 		targetIndex := 0
 		for i, g := range rotation {
 			if g == group {
@@ -653,15 +892,15 @@ func (ys *ImprovedYieldMaxScraper) calculateNextDividendDates(symbol, group, fre
 				break
 			}
 		}
-		
+
 		weeksToWait := (targetIndex - currentGroupIndex + 4) % 4
 		if weeksToWait == 0 && baseDate.Before(now) {
 			weeksToWait = 4 // Next rotation
 		}
-		
+
 		nextExDate := baseDate.AddDate(0, 0, weeksToWait*7)
 		return nextExDate.Format("2006-01-02"), nextExDate.AddDate(0, 0, 1).Format("2006-01-02")
-		
+
 	default:
 		return "", ""
 	}
@@ -0,0 +1,345 @@
+package scraper
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/models"
+)
+
+func marketDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, models.MarketLocation)
+}
+
+func TestFilterUpcomingEvents(t *testing.T) {
+	asOf := time.Date(2025, 6, 10, 12, 0, 0, 0, models.MarketLocation)
+	events := []models.DividendEvent{
+		{Symbol: "YESTERDAY", ExDate: marketDate(2025, 6, 9)},
+		{Symbol: "TODAY", ExDate: marketDate(2025, 6, 10)},
+		{Symbol: "TOMORROW", ExDate: marketDate(2025, 6, 11)},
+		{Symbol: "DAY29", ExDate: marketDate(2025, 7, 9)},
+		{Symbol: "DAY30", ExDate: marketDate(2025, 7, 10)},
+	}
+
+	upcoming := FilterUpcomingEvents(events, asOf, 30)
+
+	var symbols []string
+	for _, event := range upcoming {
+		symbols = append(symbols, event.Symbol)
+	}
+	if len(symbols) != 2 || symbols[0] != "TOMORROW" || symbols[1] != "DAY29" {
+		t.Errorf("upcoming = %v, want [TOMORROW DAY29]", symbols)
+	}
+}
+
+func TestCalculateNextDividendDatesWithFakeClock(t *testing.T) {
+	ys := NewImprovedYieldMaxScraper()
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		group   string
+		exDate  string
+		payDate string
+	}{
+		{"weekly from tuesday", time.Date(2025, 6, 10, 9, 0, 0, 0, models.MarketLocation), "Weekly", "2025-06-12", "2025-06-13"},
+		{"weekly on thursday moves a week", time.Date(2025, 6, 12, 9, 0, 0, 0, models.MarketLocation), "Weekly", "2025-06-19", "2025-06-20"},
+		{"target12 after first week", time.Date(2025, 6, 10, 9, 0, 0, 0, models.MarketLocation), "Target12", "2025-07-02", "2025-07-04"},
+		{"target12 in first week", time.Date(2025, 7, 1, 9, 0, 0, 0, models.MarketLocation), "Target12", "2025-07-02", "2025-07-04"},
+		{"unknown group", time.Date(2025, 6, 10, 9, 0, 0, 0, models.MarketLocation), UnknownGroup, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ys.SetClock(clock.NewFake(tt.now))
+			exDate, payDate := ys.calculateNextDividendDates("TEST", tt.group, "")
+			if exDate != tt.exDate || payDate != tt.payDate {
+				t.Errorf("next dates = %s/%s, want %s/%s", exDate, payDate, tt.exDate, tt.payDate)
+			}
+		})
+	}
+}
+
+func TestUpcomingEventsFollowTheClock(t *testing.T) {
+	ys := NewImprovedYieldMaxScraper()
+	fake := clock.NewFake(time.Date(2025, 6, 10, 9, 0, 0, 0, models.MarketLocation))
+	ys.SetClock(fake)
+
+	events := []models.DividendEvent{
+		{Symbol: "ULTY", ExDate: marketDate(2025, 6, 12)},
+		{Symbol: "ULTY", ExDate: marketDate(2025, 6, 19)},
+	}
+	if got := ys.filterUpcomingEvents(events, 7); len(got) != 1 || !got[0].ExDate.Equal(events[0].ExDate) {
+		t.Errorf("upcoming on 2025-06-10 = %+v, want only the 2025-06-12 event", got)
+	}
+
+	// Simulate next Wednesday
+	fake.Advance(8 * 24 * time.Hour)
+	if got := ys.filterUpcomingEvents(events, 7); len(got) != 1 || !got[0].ExDate.Equal(events[1].ExDate) {
+		t.Errorf("upcoming on 2025-06-18 = %+v, want only the 2025-06-19 event", got)
+	}
+}
+
+func TestBuildGroupSchedulesSetsAllNextDates(t *testing.T) {
+	ys := NewImprovedYieldMaxScraper()
+	ys.SetClock(clock.NewFake(time.Date(2025, 6, 10, 12, 0, 0, 0, models.MarketLocation)))
+	ys.etfGroups = map[string]string{"CONY": "GroupC", "MSTY": "GroupC"}
+
+	event := func(exDay int) models.DividendEvent {
+		return models.DividendEvent{
+			Group:       "GroupC",
+			DeclareDate: marketDate(2025, 6, exDay-1),
+			ExDate:      marketDate(2025, 6, exDay),
+			RecordDate:  marketDate(2025, 6, exDay),
+			PayDate:     marketDate(2025, 6, exDay+1),
+		}
+	}
+	schedules := ys.buildGroupSchedules([]models.DividendEvent{event(5), event(19), event(12)})
+
+	if len(schedules) != 1 {
+		t.Fatalf("got %d schedules, want 1", len(schedules))
+	}
+	group := schedules[0]
+	got := []string{group.NextDeclareDate, group.NextExDate, group.NextRecordDate, group.NextPayDate}
+	want := []string{"2025-06-11", "2025-06-12", "2025-06-12", "2025-06-13"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("next declare/ex/record/pay = %v, want %v", got, want)
+			break
+		}
+	}
+	if len(group.Events) != 6 {
+		t.Errorf("group-wide events expanded to %d, want 6 (3 per ETF)", len(group.Events))
+	}
+}
+
+func TestBuildGroupSchedulesSetsAllNextDatesFromTable(t *testing.T) {
+	site := newFixtureSite(t, map[string]string{"/our-etfs/ulty/": "dividend_page_ulty.html"})
+	history, err := newTestTableScraper(t, site.URL, nil).ScrapeDividendHistory("ULTY")
+	if err != nil {
+		t.Fatalf("ScrapeDividendHistory: %v", err)
+	}
+	for i := range history.Events {
+		if history.Events[i].RecordDate.IsZero() {
+			t.Errorf("event %s has no record date", history.Events[i].ExDate.Format("2006-01-02"))
+		}
+		history.Events[i].Group = history.Group
+	}
+
+	// Before the 06/05 distribution in the table
+	ys := NewImprovedYieldMaxScraper()
+	ys.SetClock(clock.NewFake(time.Date(2025, 6, 2, 12, 0, 0, 0, models.MarketLocation)))
+	schedules := ys.buildGroupSchedules(history.Events)
+
+	var group *models.GroupSchedule
+	for i := range schedules {
+		if schedules[i].Group == history.Group {
+			group = &schedules[i]
+		}
+	}
+	if group == nil {
+		t.Fatalf("no schedule for ULTY's group %q in %+v", history.Group, schedules)
+	}
+	got := []string{group.NextDeclareDate, group.NextExDate, group.NextRecordDate, group.NextPayDate}
+	want := []string{"2025-06-03", "2025-06-05", "2025-06-05", "2025-06-06"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("next declare/ex/record/pay = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestBuildGroupSchedulesKeepsSymbolInOneGroup(t *testing.T) {
+	// ULTY is Weekly in the static map, but the scraped mapping moved it to GroupA
+	groupsFile := filepath.Join(t.TempDir(), "groups.json")
+	if err := SaveScrapedETFGroups(groupsFile, map[string]string{"ULTY": "GroupA"}); err != nil {
+		t.Fatal(err)
+	}
+	ys := NewImprovedYieldMaxScraper()
+	ys.SetClock(clock.NewFake(time.Date(2025, 6, 10, 12, 0, 0, 0, models.MarketLocation)))
+	ys.SetGroupsFile(groupsFile)
+
+	event := func(symbol, group string, exDay int) models.DividendEvent {
+		return models.DividendEvent{
+			Symbol:  symbol,
+			Group:   group,
+			ExDate:  marketDate(2025, 6, exDay),
+			PayDate: marketDate(2025, 6, exDay+1),
+		}
+	}
+	schedules := ys.buildGroupSchedules([]models.DividendEvent{
+		event("", "Weekly", 12),
+		event("", "GroupA", 19),
+		// Filed under its static group, which the scraped mapping overrides
+		event("ULTY", "Weekly", 13),
+	})
+
+	var groupsWithULTY []string
+	var ultyExDates []time.Time
+	for _, group := range schedules {
+		if containsString(group.ETFs, "ULTY") {
+			groupsWithULTY = append(groupsWithULTY, group.Group)
+		}
+		for _, e := range group.Events {
+			if e.Symbol != "ULTY" {
+				continue
+			}
+			if e.Group != "GroupA" {
+				t.Errorf("ULTY got a %s event on %v", e.Group, e.ExDate)
+			}
+			ultyExDates = append(ultyExDates, e.ExDate)
+		}
+	}
+	if len(groupsWithULTY) != 1 || groupsWithULTY[0] != "GroupA" {
+		t.Errorf("ULTY listed in groups %v, want only GroupA", groupsWithULTY)
+	}
+	if len(ultyExDates) != 1 || !ultyExDates[0].Equal(marketDate(2025, 6, 19)) {
+		t.Errorf("ULTY ex-dates = %v, want only GroupA's 06/19", ultyExDates)
+	}
+}
+
+func TestAssignScrapedGroupKeepsLaterColumn(t *testing.T) {
+	ys := NewImprovedYieldMaxScraper()
+	ys.scrapedGroups = make(map[string]string)
+	ys.assignScrapedGroup("ULTY", "Weekly")
+	ys.assignScrapedGroup("ULTY", "GroupA")
+	if got := ys.scrapedGroups["ULTY"]; got != "GroupA" {
+		t.Errorf("ULTY listed under Weekly then GroupA mapped to %s, want GroupA", got)
+	}
+}
+
+func TestFilterUpcomingEventsNearMidnight(t *testing.T) {
+	events := []models.DividendEvent{
+		{Symbol: "UTC", ExDate: time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC)},
+		{Symbol: "ET", ExDate: marketDate(2025, 6, 5)},
+	}
+
+	// 00:05 KST on the 5th is the evening of the 4th in Eastern, so both
+	// events, however their ex-date was stored, are still ahead
+	asOf := time.Date(2025, 6, 5, 0, 5, 0, 0, time.FixedZone("KST", 9*60*60))
+	if upcoming := FilterUpcomingEvents(events, asOf, 7); len(upcoming) != 2 {
+		t.Errorf("at %s got %d upcoming events, want 2", asOf, len(upcoming))
+	}
+
+	// Once it's the 5th in Eastern, neither is
+	asOf = time.Date(2025, 6, 5, 4, 5, 0, 0, time.UTC)
+	if upcoming := FilterUpcomingEvents(events, asOf, 7); len(upcoming) != 0 {
+		t.Errorf("at %s got %v, want no upcoming events", asOf, upcoming)
+	}
+}
+
+func TestParseTarget12Table(t *testing.T) {
+	ys := NewImprovedYieldMaxScraper()
+	ys.SetClock(clock.NewFake(time.Date(2025, 6, 10, 12, 0, 0, 0, models.MarketLocation)))
+	ys.etfGroups = map[string]string{"SOXY": "Target12", "BIGY": "Target12", "TSLY": "GroupA"}
+
+	table := loadFixture(t, "schedule_page.html").Find("#table_21")
+	var events []models.DividendEvent
+	ys.parseTarget12TableImproved(table, &events)
+
+	// May and June are past; July and August apply to each member
+	type dates struct{ declare, ex, pay time.Time }
+	july := dates{marketDate(2025, 7, 1), marketDate(2025, 7, 2), marketDate(2025, 7, 3)}
+	august := dates{marketDate(2025, 8, 5), marketDate(2025, 8, 6), marketDate(2025, 8, 7)}
+	want := []struct {
+		symbol string
+		dates
+	}{{"BIGY", july}, {"SOXY", july}, {"BIGY", august}, {"SOXY", august}}
+
+	if len(events) != len(want) {
+		t.Fatalf("parsed %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Symbol != w.symbol || !e.DeclareDate.Equal(w.declare) || !e.ExDate.Equal(w.ex) || !e.PayDate.Equal(w.pay) {
+			t.Errorf("event %d = %s %v/%v/%v, want %s %v/%v/%v", i,
+				e.Symbol, e.DeclareDate, e.ExDate, e.PayDate, w.symbol, w.declare, w.ex, w.pay)
+		}
+		if e.Group != "Target12" || e.Frequency != "monthly" || !e.Estimated {
+			t.Errorf("event %d = %+v, want an estimated monthly Target12 event", i, e)
+		}
+	}
+	if !ys.target12Found {
+		t.Error("parsed table not recorded, so synthetic Target12 events would be added")
+	}
+}
+
+func TestParseScheduleTableRowsByHeader(t *testing.T) {
+	table := parseFragment(t, `<table>
+		<tr><th>Ex-Date</th><th>Declared</th><th>Month</th></tr>
+		<tr><td>09/03/2025</td><td>09/02/2025</td><td>September</td></tr>
+		<tr><td>TBD</td><td>09/30/2025</td><td>October</td></tr>
+	</table>`).Find("table")
+
+	ys := NewImprovedYieldMaxScraper()
+	ys.SetClock(clock.NewFake(time.Date(2025, 6, 10, 12, 0, 0, 0, models.MarketLocation)))
+	rows := parseScheduleTableRows(table, ys.parseDate)
+
+	// The row without an ex-date is skipped; the missing pay date is the next day
+	if len(rows) != 1 {
+		t.Fatalf("parsed %d rows, want 1", len(rows))
+	}
+	row := rows[0]
+	if !row.exDate.Equal(marketDate(2025, 9, 3)) || !row.declareDate.Equal(marketDate(2025, 9, 2)) || !row.payDate.Equal(marketDate(2025, 9, 4)) {
+		t.Errorf("row = %+v, want declared 09/02, ex 09/03, pay 09/04", row)
+	}
+}
+
+func TestTarget12SynthesizedOnlyWithoutTable(t *testing.T) {
+	countTarget12 := func(found bool) int {
+		ys := NewImprovedYieldMaxScraper()
+		ys.SetClock(clock.NewFake(time.Date(2025, 6, 10, 12, 0, 0, 0, models.MarketLocation)))
+		ys.target12Found = found
+
+		var events []models.DividendEvent
+		ys.generateSyntheticEvents(&events)
+		n := 0
+		for _, e := range events {
+			if e.Group == "Target12" {
+				n++
+			}
+		}
+		return n
+	}
+
+	if n := countTarget12(false); n == 0 {
+		t.Error("no Target12 events synthesized without the table")
+	}
+	if n := countTarget12(true); n != 0 {
+		t.Errorf("%d Target12 events synthesized although the table was parsed", n)
+	}
+}
+
+func TestScheduleAsOfPastDate(t *testing.T) {
+	site := newFixtureSite(t, map[string]string{"/distribution-schedule/": "schedule_page.html"})
+
+	// On May 1st the May Target 12 row was still ahead
+	ys := NewImprovedYieldMaxScraper().WithLimitRule(1, 0)
+	ys.SetBaseURL(site.URL)
+	ys.SetGroupsFile("")
+	ys.SetClock(clock.NewFake(time.Date(2025, 5, 1, 9, 0, 0, 0, models.MarketLocation)))
+
+	schedule, err := ys.GetScheduleImproved()
+	if err != nil {
+		t.Fatalf("GetScheduleImproved: %v", err)
+	}
+
+	var bigy []time.Time
+	for _, event := range schedule.Upcoming {
+		if !event.ExDate.After(marketDate(2025, 5, 1)) || !event.ExDate.Before(marketDate(2025, 5, 31)) {
+			t.Errorf("upcoming %s on %v outside 30 days of the as-of date", event.Symbol, event.ExDate)
+		}
+		if event.Symbol == "BIGY" {
+			bigy = append(bigy, event.ExDate)
+		}
+	}
+	if len(bigy) != 1 || !bigy[0].Equal(marketDate(2025, 5, 7)) {
+		t.Errorf("BIGY upcoming ex-dates = %v, want only 2025-05-07", bigy)
+	}
+	if !schedule.UpdatedAt.Equal(time.Date(2025, 5, 1, 9, 0, 0, 0, models.MarketLocation)) {
+		t.Errorf("UpdatedAt = %v, want the as-of time", schedule.UpdatedAt)
+	}
+}
@@ -0,0 +1,94 @@
+// Package server holds HTTP handlers for serving crawled data. There is no
+// server mode yet; these handlers are meant to be mounted by one.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"divminder-crawler/internal/export"
+	"divminder-crawler/internal/models"
+)
+
+// HistoryLoader returns the dividend histories to serve
+type HistoryLoader func() ([]models.DividendHistory, error)
+
+// DirHistoryLoader loads histories from the JSON files in dir on every call
+func DirHistoryLoader(dir string) HistoryLoader {
+	return func() ([]models.DividendHistory, error) {
+		return export.LoadHistories(dir)
+	}
+}
+
+// HistoriesSince returns the histories updated strictly after since
+func HistoriesSince(histories []models.DividendHistory, since time.Time) []models.DividendHistory {
+	var changed []models.DividendHistory
+	for _, history := range histories {
+		if history.UpdatedAt.After(since) {
+			changed = append(changed, history)
+		}
+	}
+	return changed
+}
+
+// DividendsHandler serves GET /dividends. With ?since=<RFC3339> it returns only
+// histories updated after that time, or 304 Not Modified when none were.
+// A malformed since value is a 400.
+func DividendsHandler(load HistoryLoader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var since time.Time
+		filtered := false
+		if value := r.URL.Query().Get("since"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid since %q: must be RFC3339", value), http.StatusBadRequest)
+				return
+			}
+			since = parsed
+			filtered = true
+		}
+
+		histories, err := load()
+		if err != nil {
+			http.Error(w, "failed to load dividend histories", http.StatusInternalServerError)
+			return
+		}
+		if filtered {
+			histories = HistoriesSince(histories, since)
+			if len(histories) == 0 {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		if histories == nil {
+			histories = []models.DividendHistory{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if latest := latestUpdate(histories); !latest.IsZero() {
+			w.Header().Set("Last-Modified", latest.UTC().Format(http.TimeFormat))
+		}
+		if err := json.NewEncoder(w).Encode(histories); err != nil {
+			http.Error(w, "failed to encode dividend histories", http.StatusInternalServerError)
+		}
+	})
+}
+
+// latestUpdate returns the newest UpdatedAt across histories
+func latestUpdate(histories []models.DividendHistory) time.Time {
+	var latest time.Time
+	for _, history := range histories {
+		if history.UpdatedAt.After(latest) {
+			latest = history.UpdatedAt
+		}
+	}
+	return latest
+}
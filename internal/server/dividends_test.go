@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestDividendsHandlerSince(t *testing.T) {
+	histories := []models.DividendHistory{
+		{Symbol: "ULTY", UpdatedAt: time.Date(2025, 6, 10, 4, 0, 0, 0, time.UTC)},
+		{Symbol: "CONY", UpdatedAt: time.Date(2025, 6, 3, 4, 0, 0, 0, time.UTC)},
+	}
+	server := httptest.NewServer(DividendsHandler(func() ([]models.DividendHistory, error) {
+		return histories, nil
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		since       string
+		wantStatus  int
+		wantSymbols []string
+	}{
+		{"no filter", "", http.StatusOK, []string{"ULTY", "CONY"}},
+		{"between updates", "2025-06-05T00:00:00Z", http.StatusOK, []string{"ULTY"}},
+		// 23:30 EDT on the 9th is 03:30 UTC on the 10th, before ULTY's update
+		{"offset timestamp", "2025-06-09T23:30:00-04:00", http.StatusOK, []string{"ULTY"}},
+		{"at the newest update", "2025-06-10T04:00:00Z", http.StatusNotModified, nil},
+		{"before every update", "2025-01-01T00:00:00Z", http.StatusOK, []string{"ULTY", "CONY"}},
+		{"date only", "2025-06-05", http.StatusBadRequest, nil},
+		{"garbage", "yesterday", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := server.URL + "/dividends"
+			if tt.since != "" {
+				target += "?since=" + url.QueryEscape(tt.since)
+			}
+			resp, err := http.Get(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got []models.DividendHistory
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if len(got) != len(tt.wantSymbols) {
+				t.Fatalf("got %d histories, want %v", len(got), tt.wantSymbols)
+			}
+			for i, symbol := range tt.wantSymbols {
+				if got[i].Symbol != symbol {
+					t.Errorf("history %d = %s, want %s", i, got[i].Symbol, symbol)
+				}
+			}
+			if lastModified := resp.Header.Get("Last-Modified"); lastModified != "Tue, 10 Jun 2025 04:00:00 GMT" {
+				t.Errorf("Last-Modified = %q", lastModified)
+			}
+		})
+	}
+}
+
+func TestDividendsHandlerErrors(t *testing.T) {
+	failing := DividendsHandler(func() ([]models.DividendHistory, error) {
+		return nil, errors.New("disk gone")
+	})
+
+	rec := httptest.NewRecorder()
+	failing.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dividends", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("load error status = %d, want 500", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	failing.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/dividends", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("POST status = %d, Allow %q; want 405 with GET, HEAD", rec.Code, rec.Header().Get("Allow"))
+	}
+}
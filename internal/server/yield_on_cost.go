@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultYieldOnCostDays is the lookback used when ?days is not given
+const defaultYieldOnCostDays = 365
+
+// YieldOnCostResponse is the body served by YieldOnCostHandler
+type YieldOnCostResponse struct {
+	Symbol      string  `json:"symbol"`
+	Cost        float64 `json:"cost"`
+	Days        int     `json:"days"`
+	YieldOnCost float64 `json:"yieldOnCost"` // Annualized percent
+}
+
+// YieldOnCostHandler serves GET /dividends/{symbol}/yoc?cost=X[&days=N] and must
+// be mounted with that pattern so the symbol path value is set. A missing,
+// zero or negative cost is a 400; an unknown symbol is a 404.
+func YieldOnCostHandler(load HistoryLoader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		symbol := strings.ToUpper(r.PathValue("symbol"))
+		cost, err := strconv.ParseFloat(r.URL.Query().Get("cost"), 64)
+		if err != nil || cost <= 0 || math.IsInf(cost, 0) {
+			http.Error(w, fmt.Sprintf("invalid cost %q: must be a positive number", r.URL.Query().Get("cost")), http.StatusBadRequest)
+			return
+		}
+		days := defaultYieldOnCostDays
+		if value := r.URL.Query().Get("days"); value != "" {
+			days, err = strconv.Atoi(value)
+			if err != nil || days < 1 {
+				http.Error(w, fmt.Sprintf("invalid days %q: must be a positive integer", value), http.StatusBadRequest)
+				return
+			}
+		}
+
+		histories, err := load()
+		if err != nil {
+			http.Error(w, "failed to load dividend histories", http.StatusInternalServerError)
+			return
+		}
+		for i := range histories {
+			if histories[i].Symbol != symbol {
+				continue
+			}
+			yoc, err := histories[i].YieldOnCost(cost, time.Duration(days)*24*time.Hour)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			response := YieldOnCostResponse{
+				Symbol:      symbol,
+				Cost:        cost,
+				Days:        days,
+				YieldOnCost: math.Round(yoc*100) / 100,
+			}
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				http.Error(w, "failed to encode yield on cost", http.StatusInternalServerError)
+			}
+			return
+		}
+		http.Error(w, fmt.Sprintf("unknown symbol %q", symbol), http.StatusNotFound)
+	})
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/models"
+)
+
+func TestYieldOnCostHandler(t *testing.T) {
+	// A year of $0.10 weekly payments ending yesterday
+	last := time.Now().AddDate(0, 0, -1)
+	var events []models.DividendEvent
+	for i := 0; i < 52; i++ {
+		events = append(events, models.DividendEvent{Symbol: "ULTY", ExDate: last.AddDate(0, 0, -7*i), Amount: 0.10})
+	}
+	histories := []models.DividendHistory{{Symbol: "ULTY", Frequency: "weekly", Events: events}}
+
+	mux := http.NewServeMux()
+	mux.Handle("/dividends/{symbol}/yoc", YieldOnCostHandler(func() ([]models.DividendHistory, error) {
+		return histories, nil
+	}))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantYield  float64
+		wantDays   int
+	}{
+		{"known cost", "/dividends/ULTY/yoc?cost=10", http.StatusOK, 52, 365},
+		{"lowercase symbol", "/dividends/ulty/yoc?cost=20", http.StatusOK, 26, 365},
+		{"custom days", "/dividends/ULTY/yoc?cost=10&days=90", http.StatusOK, 52, 90},
+		{"zero cost", "/dividends/ULTY/yoc?cost=0", http.StatusBadRequest, 0, 0},
+		{"negative cost", "/dividends/ULTY/yoc?cost=-3", http.StatusBadRequest, 0, 0},
+		{"missing cost", "/dividends/ULTY/yoc", http.StatusBadRequest, 0, 0},
+		{"bad days", "/dividends/ULTY/yoc?cost=10&days=0", http.StatusBadRequest, 0, 0},
+		{"unknown symbol", "/dividends/NOPE/yoc?cost=10", http.StatusNotFound, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got YieldOnCostResponse
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Symbol != "ULTY" || got.YieldOnCost != tt.wantYield || got.Days != tt.wantDays {
+				t.Errorf("response = %+v, want ULTY at %v%% over %d days", got, tt.wantYield, tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestYieldOnCostHandlerRejectsPost(t *testing.T) {
+	rec := httptest.NewRecorder()
+	YieldOnCostHandler(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/dividends/ULTY/yoc?cost=10", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want 405", rec.Code)
+	}
+}
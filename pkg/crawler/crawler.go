@@ -0,0 +1,220 @@
+// Package crawler exposes a library API for fetching a single YieldMax ETF
+// end-to-end, so other Go services can embed the crawler without running a cmd.
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"divminder-crawler/internal/api"
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/models"
+	"divminder-crawler/internal/overrides"
+	"divminder-crawler/internal/scraper"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Model types returned by the crawler, aliased so code outside this module
+// can name them
+type (
+	ETF             = models.ETF
+	ETFDetail       = models.ETFDetail
+	ETFMetadata     = models.ETFMetadata
+	DividendEvent   = models.DividendEvent
+	DividendHistory = models.DividendHistory
+	DividendStats   = models.DividendStats
+)
+
+// Clock reports the current time the next dividend dates are measured from
+type Clock = clock.Clock
+
+// DetailProvider fetches the fund page details for a symbol
+type DetailProvider interface {
+	GetETFDetailContext(ctx context.Context, symbol string) (*ETFDetail, error)
+}
+
+// HistoryProvider fetches the dividend history for a symbol
+type HistoryProvider interface {
+	ScrapeDividendHistoryContext(ctx context.Context, symbol string) (*DividendHistory, error)
+}
+
+// MetadataProvider fetches enrichment metadata for a symbol
+type MetadataProvider interface {
+	GetETFOverviewContext(ctx context.Context, symbol string) (*ETFMetadata, error)
+}
+
+// Crawler assembles an ETF from its detail page, dividend history and optional metadata
+type Crawler struct {
+	Details  DetailProvider
+	History  HistoryProvider
+	Metadata MetadataProvider  // Optional; enrichment is skipped when nil
+	Groups   map[string]string // Symbol -> Group mapping
+	Clock    Clock
+	Logger   *logrus.Logger
+}
+
+// fmpHistoryYears is how many years of history the FMP source requests
+const fmpHistoryYears = 5
+
+// New creates a Crawler backed by the YieldMax scrapers. Histories come from
+// the YieldMax dividend tables, falling back to FMP when fmpKey is set; a
+// symbol's "source" in the overrides file moves its preferred source first.
+// Alpha Vantage enrichment is enabled when alphaVantageKey (comma-separated
+// for several keys) holds a usable key; the "demo" key isn't one.
+func New(alphaVantageKey, fmpKey string) *Crawler {
+	logger := logrus.New()
+	logger.SetLevel(logrus.InfoLevel)
+
+	sources := []Source{{Name: SourceYieldMax, Provider: scraper.NewDividendTableScraper()}}
+	if fmpKey != "" {
+		sources = append(sources, Source{
+			Name:     SourceFMP,
+			Provider: FMPHistory{Client: api.NewFMPClient(fmpKey), Years: fmpHistoryYears},
+		})
+	}
+	history := NewMultiProvider(sources...)
+	if loaded, err := overrides.Load(overrides.DefaultFile); err != nil {
+		logger.Warnf("Failed to load overrides, using the default source order: %v", err)
+	} else {
+		history.SetPreferred(loaded.PreferredSources())
+	}
+
+	c := &Crawler{
+		Details: scraper.NewETFDetailScraper(),
+		History: history,
+		Groups:  scraper.GetYieldMaxETFGroups(),
+		Clock:   clock.Real{},
+		Logger:  logger,
+	}
+	if keys := api.ParseAPIKeys(alphaVantageKey); len(keys) > 0 {
+		if client := api.NewAlphaVantageClient(keys...); client.KeyError() == nil {
+			c.Metadata = client
+		}
+	}
+
+	return c
+}
+
+// FetchETF fetches the dividend history, fund details and optional metadata
+// for symbol and assembles them into an ETF with its next dividend dates.
+// Only a history failure is fatal; detail and metadata failures are logged.
+// Every request is bound to ctx.
+func (c *Crawler) FetchETF(ctx context.Context, symbol string) (*ETF, *DividendHistory, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return nil, nil, fmt.Errorf("symbol is required")
+	}
+
+	history, err := c.History.ScrapeDividendHistoryContext(ctx, symbol)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch dividend history for %s: %w", symbol, err)
+	}
+
+	etf := &ETF{
+		Symbol:    symbol,
+		Name:      history.Name,
+		Group:     history.Group,
+		Frequency: history.Frequency,
+		Active:    true,
+	}
+	if group, exists := c.Groups[symbol]; exists {
+		etf.Group = group
+		history.Group = group
+	}
+
+	if c.Details != nil {
+		detail, err := c.Details.GetETFDetailContext(ctx, symbol)
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		if err != nil {
+			c.logger().Warnf("Failed to fetch details for %s: %v", symbol, err)
+		} else {
+			applyDetail(etf, detail)
+		}
+	}
+
+	if c.Metadata != nil {
+		metadata, err := c.Metadata.GetETFOverviewContext(ctx, symbol)
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		if err != nil {
+			c.logger().Warnf("Failed to fetch metadata for %s: %v", symbol, err)
+		} else {
+			applyMetadata(etf, metadata)
+		}
+	}
+
+	etf.NextExDate, etf.NextPayDate = nextDividendDates(history, c.now())
+	return etf, history, nil
+}
+
+// applyDetail fills ETF fields the history didn't provide from the fund page
+func applyDetail(etf *ETF, detail *ETFDetail) {
+	if etf.Name == "" {
+		etf.Name = detail.Name
+	}
+	if etf.Description == "" {
+		etf.Description = detail.Description
+	}
+	if etf.Frequency == "" {
+		etf.Frequency = detail.Frequency
+	}
+	if etf.InceptionDate == nil {
+		etf.InceptionDate = detail.InceptionDate
+	}
+}
+
+// applyMetadata fills ETF fields still missing after scraping from API metadata
+func applyMetadata(etf *ETF, metadata *ETFMetadata) {
+	if etf.Name == "" {
+		etf.Name = metadata.Name
+	}
+	if etf.Description == "" {
+		etf.Description = metadata.Description
+	}
+}
+
+// nextDividendDates returns the earliest upcoming ex/pay dates in history, or
+// estimates them from the history's cadence
+func nextDividendDates(history *DividendHistory, now time.Time) (string, string) {
+	var next *DividendEvent
+	for i := range history.Events {
+		event := &history.Events[i]
+		if event.ExDate.After(now) && (next == nil || event.ExDate.Before(next.ExDate)) {
+			next = event
+		}
+	}
+
+	if next != nil {
+		return formatDate(next.ExDate), formatDate(next.PayDate)
+	}
+
+	exDate := models.EstimateNextExDateAt(history, now)
+	return formatDate(exDate), formatDate(models.EstimatePayDate(history, exDate))
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func (c *Crawler) now() time.Time {
+	if c.Clock == nil {
+		return time.Now()
+	}
+	return c.Clock.Now()
+}
+
+func (c *Crawler) logger() *logrus.Logger {
+	if c.Logger == nil {
+		return logrus.StandardLogger()
+	}
+	return c.Logger
+}
@@ -0,0 +1,130 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"divminder-crawler/internal/clock"
+	"divminder-crawler/internal/models"
+)
+
+type stubHistory struct {
+	history *DividendHistory
+	err     error
+	calls   []string
+}
+
+func (s *stubHistory) ScrapeDividendHistoryContext(ctx context.Context, symbol string) (*DividendHistory, error) {
+	s.calls = append(s.calls, symbol)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	history := *s.history
+	return &history, nil
+}
+
+type stubDetails struct {
+	detail *ETFDetail
+	err    error
+}
+
+func (s stubDetails) GetETFDetailContext(ctx context.Context, symbol string) (*ETFDetail, error) {
+	return s.detail, s.err
+}
+
+type stubMetadata struct {
+	metadata *ETFMetadata
+}
+
+func (s stubMetadata) GetETFOverviewContext(ctx context.Context, symbol string) (*ETFMetadata, error) {
+	return s.metadata, nil
+}
+
+func marketDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, models.MarketLocation)
+}
+
+func TestFetchETFAssemblesProviders(t *testing.T) {
+	history := &stubHistory{history: &DividendHistory{
+		Symbol:    "CONY",
+		Frequency: "weekly",
+		Events: []DividendEvent{
+			{Symbol: "CONY", ExDate: marketDate(2025, 6, 12), PayDate: marketDate(2025, 6, 13), Amount: 0.81},
+			{Symbol: "CONY", ExDate: marketDate(2025, 6, 5), PayDate: marketDate(2025, 6, 6), Amount: 0.75},
+		},
+	}}
+	inception := marketDate(2023, 8, 14)
+	c := &Crawler{
+		History: history,
+		Details: stubDetails{detail: &ETFDetail{
+			Name:          "YieldMax COIN Option Income Strategy ETF",
+			Frequency:     "monthly",
+			InceptionDate: &inception,
+		}},
+		Metadata: stubMetadata{metadata: &ETFMetadata{Name: "Ignored", Description: "Covered calls on COIN"}},
+		Groups:   map[string]string{"CONY": "GroupC"},
+		Clock:    clock.NewFake(time.Date(2025, 6, 10, 12, 0, 0, 0, models.MarketLocation)),
+	}
+
+	etf, got, err := c.FetchETF(context.Background(), " cony ")
+	if err != nil {
+		t.Fatalf("FetchETF: %v", err)
+	}
+
+	if history.calls[0] != "CONY" {
+		t.Errorf("history fetched for %q, want the normalized CONY", history.calls[0])
+	}
+	want := ETF{
+		Symbol:        "CONY",
+		Name:          "YieldMax COIN Option Income Strategy ETF",
+		Description:   "Covered calls on COIN",
+		Group:         "GroupC",
+		Frequency:     "weekly",
+		NextExDate:    "2025-06-12",
+		NextPayDate:   "2025-06-13",
+		InceptionDate: &inception,
+		Active:        true,
+	}
+	if etf.Symbol != want.Symbol || etf.Name != want.Name || etf.Description != want.Description ||
+		etf.Group != want.Group || etf.Frequency != want.Frequency || etf.NextExDate != want.NextExDate ||
+		etf.NextPayDate != want.NextPayDate || etf.InceptionDate == nil || !etf.InceptionDate.Equal(inception) || !etf.Active {
+		t.Errorf("FetchETF() = %+v, want %+v", *etf, want)
+	}
+	if got.Group != "GroupC" {
+		t.Errorf("history group = %q, want GroupC", got.Group)
+	}
+}
+
+func TestFetchETFErrors(t *testing.T) {
+	notFound := errors.New("not found")
+	c := &Crawler{
+		History: &stubHistory{err: notFound},
+		Details: stubDetails{err: errors.New("unused")},
+	}
+	if _, _, err := c.FetchETF(context.Background(), "NOPE"); !errors.Is(err, notFound) {
+		t.Errorf("FetchETF error = %v, want the history error", err)
+	}
+	if _, _, err := c.FetchETF(context.Background(), "  "); err == nil {
+		t.Error("FetchETF with a blank symbol succeeded")
+	}
+
+	// A failed detail page is logged, not fatal
+	c = &Crawler{
+		History: &stubHistory{history: &DividendHistory{Symbol: "ULTY", Name: "YieldMax Ultra"}},
+		Details: stubDetails{err: errors.New("timeout")},
+	}
+	if etf, _, err := c.FetchETF(context.Background(), "ULTY"); err != nil || etf.Name != "YieldMax Ultra" {
+		t.Errorf("FetchETF() = %+v, %v; want the history's ETF despite the detail error", etf, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := c.FetchETF(ctx, "ULTY"); !errors.Is(err, context.Canceled) {
+		t.Errorf("FetchETF with a canceled context = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,117 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"divminder-crawler/internal/api"
+	"divminder-crawler/internal/models"
+)
+
+// Source names used in the overrides file's "source" field, matching the
+// Source recorded on the events each provider creates
+const (
+	SourceYieldMax = models.SourceYieldMax
+	SourceFMP      = models.SourceFMP
+)
+
+// Source is a history provider together with the name overrides use for it
+type Source struct {
+	Name     string
+	Provider HistoryProvider
+}
+
+// MultiProvider is a HistoryProvider that tries several sources in turn and
+// returns the first history with events. Symbols with a preferred source try
+// it first and then fall back to the default order.
+type MultiProvider struct {
+	sources   []Source
+	preferred map[string]string // Upper-case symbol -> source name
+}
+
+// NewMultiProvider creates a provider trying sources in the given default order
+func NewMultiProvider(sources ...Source) *MultiProvider {
+	return &MultiProvider{sources: sources, preferred: map[string]string{}}
+}
+
+// SetPreferred sets the per-symbol preferred source names, e.g. from
+// overrides.Overrides.PreferredSources; unlisted symbols use the default order
+func (m *MultiProvider) SetPreferred(preferred map[string]string) {
+	m.preferred = make(map[string]string, len(preferred))
+	for symbol, source := range preferred {
+		m.preferred[strings.ToUpper(symbol)] = strings.ToLower(source)
+	}
+}
+
+// Order returns the sources in the order they are tried for symbol
+func (m *MultiProvider) Order(symbol string) []Source {
+	name, ok := m.preferred[strings.ToUpper(symbol)]
+	if !ok {
+		return m.sources
+	}
+
+	ordered := make([]Source, 0, len(m.sources))
+	for _, source := range m.sources {
+		if source.Name == name {
+			ordered = append(ordered, source)
+		}
+	}
+	for _, source := range m.sources {
+		if source.Name != name {
+			ordered = append(ordered, source)
+		}
+	}
+	return ordered
+}
+
+// ScrapeDividendHistoryContext returns the history from the first source, in
+// Order(symbol), that succeeds with at least one event. It stops trying
+// sources once ctx is done.
+func (m *MultiProvider) ScrapeDividendHistoryContext(ctx context.Context, symbol string) (*DividendHistory, error) {
+	if len(m.sources) == 0 {
+		return nil, fmt.Errorf("no history sources configured")
+	}
+
+	var errs []error
+	for _, source := range m.Order(symbol) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		history, err := source.Provider.ScrapeDividendHistoryContext(ctx, symbol)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name, err))
+			continue
+		}
+		if history == nil || len(history.Events) == 0 {
+			errs = append(errs, fmt.Errorf("%s: no dividend events", source.Name))
+			continue
+		}
+		return history, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+// FMPHistory adapts the FMP client to a HistoryProvider
+type FMPHistory struct {
+	Client *api.FMPClient
+	Years  int // Years of history to request
+}
+
+// ScrapeDividendHistoryContext fetches symbol's dividends from FMP and computes its stats
+func (f FMPHistory) ScrapeDividendHistoryContext(ctx context.Context, symbol string) (*DividendHistory, error) {
+	events, err := f.Client.GetDividendHistoryContext(ctx, symbol, f.Years)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &DividendHistory{
+		Symbol:    symbol,
+		Events:    events,
+		UpdatedAt: time.Now(),
+	}
+	history.RecomputeStats()
+	return history, nil
+}
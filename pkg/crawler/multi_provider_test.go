@@ -0,0 +1,155 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"divminder-crawler/internal/overrides"
+)
+
+// sourceNames returns the names of sources in order
+func sourceNames(sources []Source) []string {
+	names := make([]string, len(sources))
+	for i, source := range sources {
+		names[i] = source.Name
+	}
+	return names
+}
+
+func TestMultiProviderOrder(t *testing.T) {
+	yieldMax := &stubHistory{history: &DividendHistory{Symbol: "X", Events: []DividendEvent{{Amount: 0.5}}}}
+	fmp := &stubHistory{history: &DividendHistory{Symbol: "X", Events: []DividendEvent{{Amount: 0.4}}}}
+	m := NewMultiProvider(Source{Name: SourceYieldMax, Provider: yieldMax}, Source{Name: SourceFMP, Provider: fmp})
+	m.SetPreferred(map[string]string{"tsly": "FMP"})
+
+	tests := []struct {
+		symbol     string
+		wantOrder  []string
+		wantAmount float64
+	}{
+		{symbol: "TSLY", wantOrder: []string{SourceFMP, SourceYieldMax}, wantAmount: 0.4},
+		{symbol: "CONY", wantOrder: []string{SourceYieldMax, SourceFMP}, wantAmount: 0.5},
+		{symbol: "ULTY", wantOrder: []string{SourceYieldMax, SourceFMP}, wantAmount: 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			if got := sourceNames(m.Order(tt.symbol)); !reflect.DeepEqual(got, tt.wantOrder) {
+				t.Errorf("Order(%s) = %v, want %v", tt.symbol, got, tt.wantOrder)
+			}
+			history, err := m.ScrapeDividendHistoryContext(context.Background(), tt.symbol)
+			if err != nil {
+				t.Fatalf("ScrapeDividendHistoryContext: %v", err)
+			}
+			if got := history.Events[0].Amount; got != tt.wantAmount {
+				t.Errorf("history from the wrong source: amount %v, want %v", got, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestMultiProviderFallback(t *testing.T) {
+	blocked := errors.New("HTTP 403")
+	tests := []struct {
+		name       string
+		first      *stubHistory
+		second     *stubHistory
+		wantAmount float64
+		wantErr    bool
+	}{
+		{
+			name:       "first fails",
+			first:      &stubHistory{err: blocked},
+			second:     &stubHistory{history: &DividendHistory{Events: []DividendEvent{{Amount: 0.4}}}},
+			wantAmount: 0.4,
+		},
+		{
+			name:       "first has no events",
+			first:      &stubHistory{history: &DividendHistory{}},
+			second:     &stubHistory{history: &DividendHistory{Events: []DividendEvent{{Amount: 0.4}}}},
+			wantAmount: 0.4,
+		},
+		{
+			name:    "every source fails",
+			first:   &stubHistory{err: blocked},
+			second:  &stubHistory{history: &DividendHistory{}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMultiProvider(Source{Name: SourceYieldMax, Provider: tt.first}, Source{Name: SourceFMP, Provider: tt.second})
+			history, err := m.ScrapeDividendHistoryContext(context.Background(), "ULTY")
+			if len(tt.first.calls) != 1 || len(tt.second.calls) != 1 {
+				t.Errorf("calls = %v then %v, want one each", tt.first.calls, tt.second.calls)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, blocked) {
+					t.Errorf("error = %v, want it to wrap the first source's error", err)
+				}
+				return
+			}
+			if err != nil || history.Events[0].Amount != tt.wantAmount {
+				t.Errorf("history = %+v, %v; want amount %v", history, err, tt.wantAmount)
+			}
+		})
+	}
+
+	if _, err := NewMultiProvider().ScrapeDividendHistoryContext(context.Background(), "ULTY"); err == nil {
+		t.Error("a provider without sources succeeded")
+	}
+}
+
+func TestNewWiresHistorySources(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	if err := os.MkdirAll(filepath.Dir(overrides.DefaultFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(overrides.DefaultFile, []byte(`{"tsly": {"source": "fmp"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		fmpKey string
+		want   map[string][]string
+	}{
+		{
+			name: "without an FMP key",
+			want: map[string][]string{"TSLY": {SourceYieldMax}, "CONY": {SourceYieldMax}},
+		},
+		{
+			name:   "with an FMP key",
+			fmpKey: "test",
+			want: map[string][]string{
+				"TSLY": {SourceFMP, SourceYieldMax},
+				"CONY": {SourceYieldMax, SourceFMP},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			multi, ok := New("", tt.fmpKey).History.(*MultiProvider)
+			if !ok {
+				t.Fatal("History is not a *MultiProvider")
+			}
+			for symbol, want := range tt.want {
+				if got := sourceNames(multi.Order(symbol)); !reflect.DeepEqual(got, want) {
+					t.Errorf("Order(%s) = %v, want %v", symbol, got, want)
+				}
+			}
+		})
+	}
+}